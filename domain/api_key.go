@@ -0,0 +1,49 @@
+package domain
+
+import "slices"
+
+// API key permissions. A key may only call the routes its permissions cover,
+// and only do there what the user it acts as may do.
+const (
+	PermissionReviewsRead    = "reviews:read"    // read reviews, comments, summaries and owners
+	PermissionReviewsWrite   = "reviews:write"   // create, edit and delete reviews and comments
+	PermissionResponsesWrite = "responses:write" // post official responses and manage owners
+)
+
+// APIKey lets a backend service call the API as UserID without a human user.
+// The key itself is only returned when it is created (in Key); Hash is the
+// SHA-256 digest kept to check it, and Prefix the part of the key that
+// identifies it in lists and logs. A non-empty ReviewableType restricts the
+// key to reviews of that type.
+type APIKey struct {
+	ID             int      `db:"id" json:"id"`
+	Name           string   `db:"name" json:"name"`
+	Prefix         string   `db:"prefix" json:"prefix"`
+	Hash           string   `db:"hash" json:"-"`
+	Key            string   `db:"-" json:"key,omitempty"`
+	UserID         int      `db:"user_id" json:"user_id"`
+	Permissions    []string `db:"-" json:"permissions"`
+	ReviewableType string   `db:"reviewable_type" json:"reviewable_type,omitempty"`
+	CreatedBy      int      `db:"created_by" json:"created_by"`
+	CreatedAt      string   `db:"created_at" json:"created_at"`
+	LastUsedAt     *string  `db:"last_used_at" json:"last_used_at,omitempty"`
+}
+
+// Allows reports whether the key has the permission.
+func (k APIKey) Allows(permission string) bool {
+	return slices.Contains(k.Permissions, permission)
+}
+
+// AllowsType reports whether the key may access reviews of reviewableType.
+func (k APIKey) AllowsType(reviewableType string) bool {
+	return k.ReviewableType == "" || k.ReviewableType == reviewableType
+}
+
+// CreateAPIKeyRequest is the payload for creating an API key. The key acts
+// as UserID, or as the admin creating it when UserID is zero.
+type CreateAPIKeyRequest struct {
+	Name           string   `json:"name"`
+	UserID         int      `json:"user_id,omitempty"`
+	Permissions    []string `json:"permissions"`
+	ReviewableType string   `json:"reviewable_type,omitempty"`
+}
@@ -0,0 +1,27 @@
+package domain
+
+import "encoding/json"
+
+// Event types written to the outbox.
+const (
+	EventReviewCreated = "review.created" // created, or approved after moderation
+	EventReviewUpdated = "review.updated" // edited or restored
+	EventReviewDeleted = "review.deleted"
+	EventCommentAdded  = "comment.added"
+)
+
+// Event is a domain event recorded in the outbox in the same transaction as
+// the change it describes, and published to other services afterwards.
+// Payload is the Review as stored after the change, or the ReviewComment for
+// EventCommentAdded. IDs grow with time and identify an event across
+// redeliveries. Attempts counts the failed attempts to publish the event; it
+// is not part of the published event.
+type Event struct {
+	ID             int             `db:"id" json:"id"`
+	Type           string          `db:"event_type" json:"type"`
+	ReviewID       int             `db:"review_id" json:"review_id"`
+	ReviewableType string          `db:"reviewable_type" json:"reviewable_type"` // of the review
+	Payload        json.RawMessage `db:"payload" json:"payload"`
+	CreatedAt      string          `db:"created_at" json:"created_at"`
+	Attempts       int             `db:"attempts" json:"-"`
+}
@@ -0,0 +1,20 @@
+package domain
+
+// IdempotencyRecord remembers a request made with an Idempotency-Key header
+// and, once it has been answered, the response to replay to its retries.
+// Fingerprint identifies the request (method, path and body) so that reusing
+// a key for a different request can be refused. Status is 0 while the request
+// is still being handled.
+type IdempotencyRecord struct {
+	Key         string `db:"key" json:"key"`
+	Fingerprint string `db:"fingerprint" json:"fingerprint"`
+	Status      int    `db:"status" json:"status,omitempty"`
+	ContentType string `db:"content_type" json:"content_type,omitempty"`
+	Body        []byte `db:"body" json:"body,omitempty"`
+	CreatedAt   string `db:"created_at" json:"created_at"`
+}
+
+// Completed reports whether the response of the request has been stored.
+func (r IdempotencyRecord) Completed() bool {
+	return r.Status != 0
+}
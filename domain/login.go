@@ -0,0 +1,35 @@
+package domain
+
+import "time"
+
+// LoginRequest is the payload for logging in with email and password.
+type LoginRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// LoginAttempts counts the failed logins recorded under Key, an account or
+// a client IP. Every attempt is counted before its password is checked and
+// taken back when it succeeds, so LastAttemptAt is the time of the last
+// attempt. Failures are counted afresh once it is older than the failure
+// window. While LockedUntil is in the future logins under the key
+// are refused without checking the password.
+type LoginAttempts struct {
+	Key           string    `db:"key" json:"key"`
+	Failures      int       `db:"failures" json:"failures"`
+	LastAttemptAt time.Time `db:"last_attempt_at" json:"last_attempt_at"`
+	LockedUntil   time.Time `db:"locked_until" json:"locked_until"`
+}
+
+// Locked reports whether logins under the key are refused at now.
+func (a LoginAttempts) Locked(now time.Time) bool {
+	return now.Before(a.LockedUntil)
+}
+
+// Session is the outcome of a successful login: the user and the token that
+// authenticates their requests until ExpiresAt.
+type Session struct {
+	User      User      `json:"-"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
@@ -0,0 +1,37 @@
+package domain
+
+// RatingCriterion is one dimension reviews of a reviewable type are rated on in
+// addition to the overall rating, e.g. "cleanliness" for hotels or "shipping"
+// for vendors.
+type RatingCriterion struct {
+	ReviewableType string `db:"reviewable_type" json:"reviewable_type"`
+	Key            string `db:"criterion" json:"key"` // e.g. "cleanliness"
+	Label          string `db:"label" json:"label"`   // human readable name
+	Required       bool   `db:"required" json:"required"`
+	SortOrder      int    `db:"sort_order" json:"sort_order"`
+}
+
+// SetCriteriaRequest is the payload replacing the criteria of a reviewable type.
+// The order of Criteria becomes their SortOrder.
+type SetCriteriaRequest struct {
+	Criteria []RatingCriterion `json:"criteria"`
+}
+
+// ReviewSummary aggregates the ratings of a reviewable entity.
+type ReviewSummary struct {
+	ReviewableType string             `json:"reviewable_type"`
+	ReviewableID   int                `json:"reviewable_id"`
+	Count          int                `json:"count"`
+	AverageRating  float64            `json:"average_rating"`
+	Criteria       []CriterionAverage `json:"criteria"`
+}
+
+// CriterionAverage is the average sub-rating of one criterion. Count is the
+// number of reviews that rated it, which may be below the summary's Count for
+// optional criteria.
+type CriterionAverage struct {
+	Key     string  `db:"criterion" json:"key"`
+	Label   string  `db:"-" json:"label"`
+	Count   int     `db:"count" json:"count"`
+	Average float64 `db:"average" json:"average"`
+}
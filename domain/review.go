@@ -1,6 +1,10 @@
 package domain
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"html"
+	"strings"
+)
 
 // Review represents a user review attached to a reviewable entity.
 type Review struct {
@@ -11,8 +15,17 @@ type Review struct {
 	Rating         int    `db:"rating" json:"rating"`   // 1..5
 	Title          string `db:"title" json:"title"`
 	Body           string `db:"body" json:"body"`
+	Status         string `db:"status" json:"status"`     // ReviewStatusPublished or ReviewStatusPending
+	Verified       bool   `db:"verified" json:"verified"` // author had purchased the reviewable when reviewing
 	CreatedAt      string `db:"created_at" json:"created_at"`
 	UpdatedAt      string `db:"updated_at" json:"updated_at"`
+
+	// SubRatings maps rating criterion keys to 1..5 ratings. They are stored by
+	// Create and attached by the read use-cases.
+	SubRatings map[string]int `db:"-" json:"sub_ratings,omitempty"`
+
+	// Response is the owner's official response, attached by the read use-cases.
+	Response *ReviewResponse `db:"-" json:"response,omitempty"`
 }
 
 // ReviewPhoto represents a photo attached to a review.
@@ -26,9 +39,13 @@ type ReviewPhoto struct {
 }
 
 // ReviewComment represents a comment left by a user on a review.
+// Replies point at the comment they answer through ParentID; Depth is 0 for
+// top-level comments and grows by one per reply level.
 type ReviewComment struct {
 	ID        int    `db:"id" json:"id"`
 	ReviewID  int    `db:"review_id" json:"review_id"`
+	ParentID  *int   `db:"parent_id" json:"parent_id"`
+	Depth     int    `db:"depth" json:"depth"`
 	UserID    int    `db:"user_id" json:"user_id"`
 	Body      string `db:"body" json:"body"`
 	CreatedAt string `db:"created_at" json:"created_at"`
@@ -43,6 +60,7 @@ type CreateReviewRequest struct {
 	ReviewableType string            `json:"reviewable_type" binding:"required"`
 	ReviewableID   int               `json:"reviewable_id" binding:"required"`
 	Rating         int               `json:"rating" binding:"required"`
+	SubRatings     map[string]int    `json:"sub_ratings,omitempty"` // criterion key -> 1..5
 	Title          string            `json:"title,omitempty"`
 	Body           string            `json:"body,omitempty"`
 	PhotoPaths     []string          `json:"photo_paths,omitempty"` // list of uploaded file paths / storage keys
@@ -50,7 +68,103 @@ type CreateReviewRequest struct {
 }
 
 // CreateCommentRequest is the payload for creating a new comment on a review.
+// A non-zero ParentID makes the comment a reply to that comment.
 type CreateCommentRequest struct {
 	ReviewID int    `json:"review_id" binding:"required"`
+	ParentID int    `json:"parent_id,omitempty"`
 	Body     string `json:"body" binding:"required"`
 }
+
+// ReviewSearchQuery describes a full-text search over review titles and bodies.
+type ReviewSearchQuery struct {
+	Query          string // free text, e.g. `battery "fast charging"`
+	ReviewableType string // optional filter
+	ReviewableID   int    // optional filter, requires ReviewableType
+	Limit          int
+	Offset         int
+}
+
+// ReviewSearchHit is a single search result. Highlights are HTML: the review
+// text is escaped and matched terms are wrapped in <mark>…</mark>.
+type ReviewSearchHit struct {
+	Review         Review  `json:"review"`
+	Rank           float64 `json:"rank"`
+	TitleHighlight string  `json:"title_highlight"`
+	BodyHighlight  string  `json:"body_highlight"`
+}
+
+// Search adapters mark matched terms with these private-use characters, which
+// HighlightHTML turns into <mark> tags once the text around them is escaped.
+const (
+	HighlightStart = "\uE000"
+	HighlightStop  = "\uE001"
+)
+
+var highlightTags = strings.NewReplacer(HighlightStart, "<mark>", HighlightStop, "</mark>")
+
+// HighlightHTML escapes text whose matches are delimited by HighlightStart
+// and HighlightStop and wraps the matches in <mark>…</mark>.
+func HighlightHTML(marked string) string {
+	return highlightTags.Replace(html.EscapeString(marked))
+}
+
+// ReviewSearchResult is a page of search hits ordered by descending rank.
+type ReviewSearchResult struct {
+	Hits   []ReviewSearchHit `json:"hits"`
+	Total  int               `json:"total"`
+	Limit  int               `json:"limit"`
+	Offset int               `json:"offset"`
+}
+
+// ReviewableOwner grants a user (a merchant or vendor account) ownership of a
+// reviewable entity, which allows them to respond officially to its reviews.
+type ReviewableOwner struct {
+	ReviewableType string `db:"reviewable_type" json:"reviewable_type"`
+	ReviewableID   int    `db:"reviewable_id" json:"reviewable_id"`
+	UserID         int    `db:"user_id" json:"user_id"`
+	CreatedAt      string `db:"created_at" json:"created_at"`
+}
+
+// ReviewResponse is the single official response of a reviewable's owner to a review.
+type ReviewResponse struct {
+	ID        int    `db:"id" json:"id"`
+	ReviewID  int    `db:"review_id" json:"review_id"`
+	UserID    int    `db:"user_id" json:"user_id"` // owner who wrote the current version
+	Body      string `db:"body" json:"body"`
+	CreatedAt string `db:"created_at" json:"created_at"`
+	UpdatedAt string `db:"updated_at" json:"updated_at"`
+}
+
+// ReviewResponseVersion is one saved version of a ReviewResponse, kept as edit history.
+type ReviewResponseVersion struct {
+	ID         int    `db:"id" json:"id"`
+	ResponseID int    `db:"response_id" json:"response_id"`
+	UserID     int    `db:"user_id" json:"user_id"`
+	Body       string `db:"body" json:"body"`
+	CreatedAt  string `db:"created_at" json:"created_at"`
+}
+
+// SaveResponseRequest is the payload for creating or editing an official response.
+type SaveResponseRequest struct {
+	Body string `json:"body" binding:"required"`
+}
+
+// UpdateReviewRequest is the payload for editing a review. Omitted fields keep their value.
+type UpdateReviewRequest struct {
+	Rating *int    `json:"rating,omitempty"`
+	Title  *string `json:"title,omitempty"`
+	Body   *string `json:"body,omitempty"`
+}
+
+// UpdateCommentRequest is the payload for editing a comment.
+type UpdateCommentRequest struct {
+	Body string `json:"body" binding:"required"`
+}
+
+// PurgedReviews reports the reviews hard-deleted by one purge batch together
+// with the file paths of their photos, which the caller removes from storage.
+// Paths still used by a photo of another review are left out.
+type PurgedReviews struct {
+	ReviewIDs []int
+	FilePaths []string
+}
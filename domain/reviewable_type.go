@@ -0,0 +1,35 @@
+package domain
+
+// Review statuses. Pending reviews are hidden from listings, searches and
+// summaries until a moderator approves them.
+const (
+	ReviewStatusPublished = "published"
+	ReviewStatusPending   = "pending"
+)
+
+// Moderation modes of a reviewable type.
+const (
+	ModerationPost = "post" // reviews are published immediately and moderated afterwards
+	ModerationPre  = "pre"  // reviews stay pending until a moderator approves them
+)
+
+// ReviewableType is a registered kind of reviewable entity together with the
+// rules applied to new reviews of that kind.
+type ReviewableType struct {
+	Name           string `db:"name" json:"name"` // e.g. "product"
+	PhotosAllowed  bool   `db:"photos_allowed" json:"photos_allowed"`
+	MaxBodyLength  int    `db:"max_body_length" json:"max_body_length"` // in characters, 0 for no limit
+	TitleRequired  bool   `db:"title_required" json:"title_required"`
+	ModerationMode string `db:"moderation_mode" json:"moderation_mode"` // ModerationPost or ModerationPre
+	CreatedAt      string `db:"created_at" json:"created_at"`
+	UpdatedAt      string `db:"updated_at" json:"updated_at"`
+}
+
+// SaveReviewableTypeRequest is the payload registering a reviewable type or
+// replacing its settings. An empty ModerationMode means ModerationPost.
+type SaveReviewableTypeRequest struct {
+	PhotosAllowed  bool   `json:"photos_allowed"`
+	MaxBodyLength  int    `json:"max_body_length"`
+	TitleRequired  bool   `json:"title_required"`
+	ModerationMode string `json:"moderation_mode"`
+}
@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// ErrReviewChanged is reported by a change of a review based on a version,
+// its UpdatedAt, that is no longer the stored one.
+var ErrReviewChanged = errors.New("review has changed")
+
+// Revision entity types.
+const (
+	RevisionEntityReview  = "review"
+	RevisionEntityComment = "comment"
+)
+
+// Revision records one edit of a review or of one of its comments. OldValues
+// and NewValues are JSON objects holding only the fields that changed.
+type Revision struct {
+	ID         int             `db:"id" json:"id"`
+	ReviewID   int             `db:"review_id" json:"review_id"`
+	EntityType string          `db:"entity_type" json:"entity_type"` // RevisionEntityReview or RevisionEntityComment
+	EntityID   int             `db:"entity_id" json:"entity_id"`
+	ActorID    int             `db:"actor_id" json:"actor_id"` // user who made the change
+	OldValues  json.RawMessage `db:"old_values" json:"old_values"`
+	NewValues  json.RawMessage `db:"new_values" json:"new_values"`
+	CreatedAt  string          `db:"created_at" json:"created_at"`
+}
+
+// ReviewChanges compares the editable fields of two versions of a review.
+// It returns the differing fields as JSON objects and whether anything changed.
+func ReviewChanges(before, after Review) (oldValues, newValues json.RawMessage, changed bool) {
+	o, n := map[string]any{}, map[string]any{}
+	if before.Rating != after.Rating {
+		o["rating"], n["rating"] = before.Rating, after.Rating
+	}
+	if before.Title != after.Title {
+		o["title"], n["title"] = before.Title, after.Title
+	}
+	if before.Body != after.Body {
+		o["body"], n["body"] = before.Body, after.Body
+	}
+	return marshalChanges(o, n)
+}
+
+// CommentChanges is ReviewChanges for comments, whose only editable field is the body.
+func CommentChanges(before, after ReviewComment) (oldValues, newValues json.RawMessage, changed bool) {
+	o, n := map[string]any{}, map[string]any{}
+	if before.Body != after.Body {
+		o["body"], n["body"] = before.Body, after.Body
+	}
+	return marshalChanges(o, n)
+}
+
+func marshalChanges(o, n map[string]any) (json.RawMessage, json.RawMessage, bool) {
+	if len(n) == 0 {
+		return nil, nil, false
+	}
+	// Maps of ints and strings always marshal.
+	oldJSON, _ := json.Marshal(o)
+	newJSON, _ := json.Marshal(n)
+	return oldJSON, newJSON, true
+}
@@ -1,8 +1,25 @@
 package domain
 
+// User roles. Moderators and admins are granted directly in the database;
+// users created through the API always get RoleUser.
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
+// User is an account. Password holds the password hash and is never
+// serialized.
 type User struct {
 	ID        int    `db:"id" json:"id"`
 	Email     string `db:"email" json:"email"`
-	Password  string `db:"password" json:"password"`
+	Password  string `db:"password" json:"-"`
+	Role      string `db:"role" json:"role"`
 	CreatedAt string `db:"created_at" json:"created_at"`
 }
+
+// CreateUserRequest is the payload for signing up.
+type CreateUserRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
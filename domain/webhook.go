@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+)
+
+// Webhook delivery statuses.
+const (
+	DeliveryPending   = "pending"   // waiting for its next attempt
+	DeliverySucceeded = "succeeded" // the receiver answered with a 2xx status
+	DeliveryDead      = "dead"      // gave up after the last attempt; redeliver by hand
+)
+
+// WebhookSubscription asks for domain events to be pushed to URL. Empty
+// Events and ReviewableType match every event. Secret is the key deliveries
+// are signed with; it is only returned when the subscription is created.
+type WebhookSubscription struct {
+	ID             int      `db:"id" json:"id"`
+	URL            string   `db:"url" json:"url"`
+	Events         []string `db:"-" json:"events"`
+	ReviewableType string   `db:"reviewable_type" json:"reviewable_type,omitempty"`
+	Secret         string   `db:"secret" json:"secret,omitempty"`
+	CreatedAt      string   `db:"created_at" json:"created_at"`
+}
+
+// Matches reports whether e should be delivered to the subscription. Events
+// of reviews waiting for moderation are never delivered: subscribers learn
+// of a review with the EventReviewCreated recorded when it is approved.
+func (s WebhookSubscription) Matches(e Event) bool {
+	if reviewPending(e) {
+		return false
+	}
+	if len(s.Events) > 0 && !slices.Contains(s.Events, e.Type) {
+		return false
+	}
+	return s.ReviewableType == "" || s.ReviewableType == e.ReviewableType
+}
+
+// reviewPending reports whether e is an event of a review, and that review
+// is pending.
+func reviewPending(e Event) bool {
+	if !strings.HasPrefix(e.Type, "review.") {
+		return false
+	}
+	var review struct {
+		Status string `json:"status"`
+	}
+	return json.Unmarshal(e.Payload, &review) == nil && review.Status == ReviewStatusPending
+}
+
+// CreateWebhookRequest is the payload for subscribing to webhooks. A secret
+// is generated when none is given.
+type CreateWebhookRequest struct {
+	URL            string   `json:"url"`
+	Events         []string `json:"events,omitempty"`
+	ReviewableType string   `json:"reviewable_type,omitempty"`
+	Secret         string   `json:"secret,omitempty"`
+}
+
+// WebhookDelivery is the delivery of one event to one subscription and the
+// outcome of its latest attempt. Payload is the JSON-encoded Event, sent as
+// the request body.
+type WebhookDelivery struct {
+	ID             int             `db:"id" json:"id"`
+	SubscriptionID int             `db:"subscription_id" json:"subscription_id"`
+	EventID        int             `db:"event_id" json:"event_id"`
+	EventType      string          `db:"event_type" json:"event_type"`
+	Payload        json.RawMessage `db:"payload" json:"-"`
+	Status         string          `db:"status" json:"status"` // DeliveryPending, DeliverySucceeded or DeliveryDead
+	Attempts       int             `db:"attempts" json:"attempts"`
+	NextAttemptAt  string          `db:"next_attempt_at" json:"next_attempt_at"`
+	LastStatusCode *int            `db:"last_status_code" json:"last_status_code"` // nil until a response was received
+	LastError      string          `db:"last_error" json:"last_error,omitempty"`
+	CreatedAt      string          `db:"created_at" json:"created_at"`
+	UpdatedAt      string          `db:"updated_at" json:"updated_at"`
+}
@@ -0,0 +1,113 @@
+package httpDelivery
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"eve/domain"
+	"eve/internal/usecase"
+
+	"github.com/labstack/echo/v4"
+)
+
+// userKey is the echo context key of the authenticated user of a request.
+const userKey = "user_id"
+
+// apiKeyRoutes maps the routes open to API keys to the permission they
+// need. Account, moderation and admin routes are left out, so keys cannot
+// call them whatever their user may do.
+var apiKeyRoutes = map[string]string{
+	"POST /reviews":                                 domain.PermissionReviewsWrite,
+	"POST /reviews/comments":                        domain.PermissionReviewsWrite,
+	"POST /reviews/:id/comments":                    domain.PermissionReviewsWrite,
+	"POST /reviews/:id/comments/:cid/replies":       domain.PermissionReviewsWrite,
+	"PATCH /reviews/:id":                            domain.PermissionReviewsWrite,
+	"PATCH /reviews/:id/comments/:cid":              domain.PermissionReviewsWrite,
+	"DELETE /reviews/:id":                           domain.PermissionReviewsWrite,
+	"GET /reviews":                                  domain.PermissionReviewsRead,
+	"GET /reviews/search":                           domain.PermissionReviewsRead,
+	"GET /reviews/:id":                              domain.PermissionReviewsRead,
+	"GET /reviews/:id/revisions":                    domain.PermissionReviewsRead,
+	"GET /reviewable-types":                         domain.PermissionReviewsRead,
+	"GET /reviewable-types/:type/criteria":          domain.PermissionReviewsRead,
+	"GET /reviewables/:type/:id/summary":            domain.PermissionReviewsRead,
+	"GET /reviewables/:type/:id/owners":             domain.PermissionResponsesWrite,
+	"PUT /reviewables/:type/:id/owners/:user_id":    domain.PermissionResponsesWrite,
+	"DELETE /reviewables/:type/:id/owners/:user_id": domain.PermissionResponsesWrite,
+	"PUT /reviews/:id/response":                     domain.PermissionResponsesWrite,
+	"GET /reviews/:id/response/versions":            domain.PermissionResponsesWrite,
+}
+
+// AuthMiddleware authenticates requests carrying a session token issued by
+// POST /login or an API key, either as "Authorization: Bearer <token>" or in
+// an "X-API-Key" header, and stores their user for extractUserID. A session
+// acts as its user on every route. An API key acts as its user only on the
+// routes its permissions cover. Requests without either are anonymous. An
+// "X-User-ID" header naming the user is honoured only when trustUserHeader is
+// set, which is meant for local development; otherwise it is refused, as is a
+// request that carries both the header and a token.
+func AuthMiddleware(keys *usecase.AuthenticateAPIKeyUseCase, sessions *usecase.AuthenticateSessionUseCase, trustUserHeader bool, log *slog.Logger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			secret := apiKeyOf(c.Request())
+			header := c.Request().Header.Get("X-User-ID")
+			switch {
+			case secret != "" && header != "":
+				return respondError(c, log, http.StatusBadRequest, "send either a token or X-User-ID, not both")
+			case header != "" && !trustUserHeader:
+				return respondError(c, log, http.StatusUnauthorized, "X-User-ID is not accepted; log in and send the session token")
+			case header != "":
+				id, err := strconv.Atoi(header)
+				if err != nil || id <= 0 {
+					return respondError(c, log, http.StatusUnauthorized, "invalid X-User-ID header")
+				}
+				c.Set(userKey, id)
+				return next(c)
+			case secret == "":
+				return next(c)
+			}
+
+			ctx := c.Request().Context()
+			if usecase.IsSessionToken(secret) {
+				id, err := sessions.Execute(ctx, secret)
+				if err != nil {
+					return respondError(c, log, errorStatus(err, http.StatusInternalServerError), err.Error())
+				}
+				c.Set(userKey, id)
+				return next(c)
+			}
+
+			key, err := keys.Execute(ctx, secret)
+			if err != nil {
+				return respondError(c, log, errorStatus(err, http.StatusInternalServerError), err.Error())
+			}
+
+			route := c.Request().Method + " " + routeOf(c)
+			permission, ok := apiKeyRoutes[route]
+			if !ok {
+				return respondError(c, log, http.StatusForbidden, "API keys may not call "+route)
+			}
+			if !key.Allows(permission) {
+				return respondError(c, log, http.StatusForbidden, "API key "+key.Prefix+" lacks the "+permission+" permission")
+			}
+
+			c.Set(userKey, key.UserID)
+			c.SetRequest(c.Request().WithContext(usecase.WithAPIKey(ctx, key)))
+			return next(c)
+		}
+	}
+}
+
+// apiKeyOf returns the session token or API key a request carries, if any.
+func apiKeyOf(r *http.Request) string {
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return key
+	}
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if ok && strings.EqualFold(scheme, "Bearer") {
+		return strings.TrimSpace(token)
+	}
+	return ""
+}
@@ -0,0 +1,92 @@
+package httpDelivery
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"eve/domain"
+	"eve/internal/usecase"
+
+	"github.com/labstack/echo/v4"
+)
+
+// APIKeyHandler holds use-cases for managing the API keys of backend
+// services. Every endpoint requires an admin.
+type APIKeyHandler struct {
+	createKey *usecase.CreateAPIKeyUseCase
+	listKeys  *usecase.ListAPIKeysUseCase
+	deleteKey *usecase.DeleteAPIKeyUseCase
+	log       *slog.Logger
+}
+
+// NewAPIKeyHandler constructs an APIKeyHandler.
+func NewAPIKeyHandler(ck *usecase.CreateAPIKeyUseCase, lk *usecase.ListAPIKeysUseCase, dk *usecase.DeleteAPIKeyUseCase, l *slog.Logger) *APIKeyHandler {
+	return &APIKeyHandler{
+		createKey: ck,
+		listKeys:  lk,
+		deleteKey: dk,
+		log:       l,
+	}
+}
+
+// CreateAPIKey handles POST /api-keys
+// Expects JSON body matching domain.CreateAPIKeyRequest and the credentials of an admin.
+// The response is the only one that includes the key.
+func (h *APIKeyHandler) CreateAPIKey(c echo.Context) error {
+	var req domain.CreateAPIKeyRequest
+	if err := c.Bind(&req); err != nil {
+		return respondError(c, h.log, http.StatusBadRequest, "invalid request body: "+err.Error())
+	}
+
+	userID, err := extractUserID(c)
+	if err != nil {
+		return respondError(c, h.log, http.StatusUnauthorized, err.Error())
+	}
+
+	key, err := h.createKey.Execute(c.Request().Context(), req, userID)
+	if err != nil {
+		return respondError(c, h.log, errorStatus(err, http.StatusInternalServerError), err.Error())
+	}
+
+	return c.JSON(http.StatusCreated, key)
+}
+
+// ListAPIKeys handles GET /api-keys
+// Requires the credentials of an admin.
+func (h *APIKeyHandler) ListAPIKeys(c echo.Context) error {
+	userID, err := extractUserID(c)
+	if err != nil {
+		return respondError(c, h.log, http.StatusUnauthorized, err.Error())
+	}
+
+	keys, err := h.listKeys.Execute(c.Request().Context(), userID)
+	if err != nil {
+		return respondError(c, h.log, errorStatus(err, http.StatusInternalServerError), err.Error())
+	}
+	if keys == nil {
+		keys = []domain.APIKey{}
+	}
+
+	return c.JSON(http.StatusOK, keys)
+}
+
+// DeleteAPIKey handles DELETE /api-keys/:id
+// Requires the credentials of an admin.
+func (h *APIKeyHandler) DeleteAPIKey(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return respondError(c, h.log, http.StatusBadRequest, "invalid id")
+	}
+
+	userID, err := extractUserID(c)
+	if err != nil {
+		return respondError(c, h.log, http.StatusUnauthorized, err.Error())
+	}
+
+	if err := h.deleteKey.Execute(c.Request().Context(), id, userID); err != nil {
+		return respondError(c, h.log, errorStatus(err, http.StatusInternalServerError), err.Error())
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,95 @@
+package httpDelivery_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"eve/domain"
+)
+
+func TestAPIKeys(t *testing.T) {
+	e, users := newTestServerWithUsers()
+	for _, email := range []string{"admin@example.com", "service@example.com"} {
+		if rec := do(e, http.MethodPost, "/user", fmt.Sprintf(`{"email":%q,"password":"pw"}`, email), nil); rec.Code != http.StatusCreated {
+			t.Fatalf("create user status = %d (body %s)", rec.Code, rec.Body)
+		}
+	}
+	users.SetRole(1, domain.RoleAdmin)
+	admin := map[string]string{"X-User-ID": "1"}
+	service := map[string]string{"X-User-ID": "2"}
+
+	body := `{"name":"catalog sync","user_id":2,"permissions":["reviews:read","reviews:write"],"reviewable_type":"product"}`
+	if rec := do(e, http.MethodPost, "/api-keys", body, service); rec.Code != http.StatusForbidden {
+		t.Errorf("create key as a user status = %d, want 403", rec.Code)
+	}
+	if rec := do(e, http.MethodPost, "/api-keys", `{"name":"sync","permissions":["everything"]}`, admin); rec.Code != http.StatusBadRequest {
+		t.Errorf("create key with an unknown permission status = %d, want 400", rec.Code)
+	}
+	rec := do(e, http.MethodPost, "/api-keys", body, admin)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create key status = %d (body %s)", rec.Code, rec.Body)
+	}
+	key := decode[domain.APIKey](t, rec)
+	if key.Key == "" || key.UserID != 2 {
+		t.Fatalf("created key = %+v, want the key of user 2", key)
+	}
+	bearer := map[string]string{"Authorization": "Bearer " + key.Key}
+
+	rec = do(e, http.MethodPost, "/reviews", `{"reviewable_type":"product","reviewable_id":1,"rating":5}`, bearer)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create review with the key status = %d (body %s)", rec.Code, rec.Body)
+	}
+	review := decode[map[string]any](t, rec)
+	rec = do(e, http.MethodGet, fmt.Sprintf("/reviews/%v", review["id"]), "", map[string]string{"X-API-Key": key.Key})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("get review with X-API-Key status = %d (body %s)", rec.Code, rec.Body)
+	}
+	if got := decode[struct{ Review domain.Review }](t, rec).Review; got.UserID != 2 {
+		t.Errorf("review author = %d, want the key's user 2", got.UserID)
+	}
+
+	for _, tt := range []struct {
+		name    string
+		method  string
+		target  string
+		body    string
+		headers map[string]string
+		want    int
+	}{
+		{"another type", http.MethodPost, "/reviews", `{"reviewable_type":"hotel","reviewable_id":1,"rating":5}`, bearer, http.StatusForbidden},
+		{"no permission", http.MethodPut, "/reviews/1/response", `{"body":"Thanks"}`, bearer, http.StatusForbidden},
+		{"route closed to keys", http.MethodGet, "/api-keys", "", bearer, http.StatusForbidden},
+		{"key and user", http.MethodGet, "/reviews/1", "", map[string]string{"Authorization": "Bearer " + key.Key, "X-User-ID": "1"}, http.StatusBadRequest},
+		{"wrong key", http.MethodGet, "/reviews/1", "", map[string]string{"Authorization": "Bearer " + key.Key + "0"}, http.StatusUnauthorized},
+	} {
+		if rec := do(e, tt.method, tt.target, tt.body, tt.headers); rec.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d (body %s)", tt.name, rec.Code, tt.want, rec.Body)
+		}
+	}
+
+	rec = do(e, http.MethodGet, "/api-keys", "", admin)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("list keys status = %d (body %s)", rec.Code, rec.Body)
+	}
+	keys := decode[[]map[string]any](t, rec)
+	if len(keys) != 1 || keys[0]["last_used_at"] == nil {
+		t.Fatalf("keys = %v, want the used key", keys)
+	}
+	if _, ok := keys[0]["key"]; ok {
+		t.Errorf("listed key = %v, want no key", keys[0])
+	}
+	if _, ok := keys[0]["hash"]; ok {
+		t.Errorf("listed key = %v, want no hash", keys[0])
+	}
+
+	if rec := do(e, http.MethodDelete, fmt.Sprintf("/api-keys/%d", key.ID), "", admin); rec.Code != http.StatusNoContent {
+		t.Fatalf("delete key status = %d (body %s)", rec.Code, rec.Body)
+	}
+	if rec := do(e, http.MethodGet, "/reviews/1", "", bearer); rec.Code != http.StatusUnauthorized {
+		t.Errorf("deleted key status = %d, want 401", rec.Code)
+	}
+	if rec := do(e, http.MethodDelete, fmt.Sprintf("/api-keys/%d", key.ID), "", admin); rec.Code != http.StatusNotFound {
+		t.Errorf("delete missing key status = %d, want 404", rec.Code)
+	}
+}
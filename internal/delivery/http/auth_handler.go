@@ -0,0 +1,78 @@
+package httpDelivery
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"eve/domain"
+	"eve/internal/usecase"
+
+	"github.com/labstack/echo/v4"
+)
+
+// AuthHandler holds use-cases for logging in and lifting login lockouts.
+type AuthHandler struct {
+	login  *usecase.LoginUseCase
+	unlock *usecase.UnlockUserUseCase
+	log    *slog.Logger
+}
+
+// NewAuthHandler constructs an AuthHandler.
+func NewAuthHandler(li *usecase.LoginUseCase, ul *usecase.UnlockUserUseCase, l *slog.Logger) *AuthHandler {
+	return &AuthHandler{
+		login:  li,
+		unlock: ul,
+		log:    l,
+	}
+}
+
+// Login handles POST /login
+// Expects JSON body matching domain.LoginRequest and responds with the user's
+// id, email and role and a session token to send as "Authorization: Bearer
+// <token>" until expires_at. Wrong credentials get 401; too many failed
+// logins from the account or the client IP get 429 with Retry-After. The
+// client IP is found by the echo instance's IPExtractor, so clients cannot
+// escape the throttling by claiming another one.
+func (h *AuthHandler) Login(c echo.Context) error {
+	var req domain.LoginRequest
+	if err := c.Bind(&req); err != nil {
+		return respondError(c, h.log, http.StatusBadRequest, "invalid request body: "+err.Error())
+	}
+
+	session, err := h.login.Execute(c.Request().Context(), req, c.RealIP())
+	if err != nil {
+		if wait, ok := usecase.RetryAfter(err); ok {
+			c.Response().Header().Set("Retry-After", ceilSeconds(wait))
+		}
+		return respondError(c, h.log, errorStatus(err, http.StatusInternalServerError), err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]any{
+		"id":         session.User.ID,
+		"email":      session.User.Email,
+		"role":       session.User.Role,
+		"token":      session.Token,
+		"expires_at": session.ExpiresAt,
+	})
+}
+
+// UnlockUser handles POST /user/:id/unlock
+// Requires the credentials of an admin.
+func (h *AuthHandler) UnlockUser(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return respondError(c, h.log, http.StatusBadRequest, "invalid id")
+	}
+
+	userID, err := extractUserID(c)
+	if err != nil {
+		return respondError(c, h.log, http.StatusUnauthorized, err.Error())
+	}
+
+	if err := h.unlock.Execute(c.Request().Context(), id, userID); err != nil {
+		return respondError(c, h.log, errorStatus(err, http.StatusInternalServerError), err.Error())
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,167 @@
+package httpDelivery_test
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"eve/domain"
+	"eve/internal/usecase"
+
+	"github.com/labstack/echo/v4"
+)
+
+// testLoginPolicy delays the third failed login by a minute, so tests see
+// throttling without reaching the lockout.
+var testLoginPolicy = usecase.LoginPolicy{
+	FreeAttempts:    2,
+	BaseDelay:       time.Minute,
+	MaxDelay:        time.Minute,
+	AccountLockout:  5,
+	IPLockout:       100,
+	LockoutDuration: 15 * time.Minute,
+	FailureWindow:   time.Hour,
+}
+
+// testSessionPolicy signs session tokens with a fixed secret.
+var testSessionPolicy = usecase.SessionPolicy{Secret: []byte("0123456789abcdef0123456789abcdef"), TTL: time.Hour}
+
+func TestLoginAndUnlock(t *testing.T) {
+	e, users := newTestServerWithUsers()
+	for _, email := range []string{"admin@example.com", "alice@example.com"} {
+		if rec := do(e, http.MethodPost, "/user", fmt.Sprintf(`{"email":%q,"password":"pw"}`, email), nil); rec.Code != http.StatusCreated {
+			t.Fatalf("create user status = %d (body %s)", rec.Code, rec.Body)
+		}
+	}
+	users.SetRole(1, domain.RoleAdmin)
+	admin := map[string]string{"X-User-ID": "1"}
+	alice := map[string]string{"X-User-ID": "2"}
+
+	rec := do(e, http.MethodPost, "/login", `{"email":"alice@example.com","password":"pw"}`, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("login status = %d (body %s)", rec.Code, rec.Body)
+	}
+	got := decode[map[string]any](t, rec)
+	if got["id"] != float64(2) || got["email"] != "alice@example.com" || got["role"] != domain.RoleUser {
+		t.Errorf("login body = %v, want alice", got)
+	}
+	if token, _ := got["token"].(string); !usecase.IsSessionToken(token) || got["expires_at"] == nil {
+		t.Errorf("login body = %v, want a session token and its expiry", got)
+	}
+	if _, ok := got["password"]; ok {
+		t.Errorf("login body = %v, want no password", got)
+	}
+
+	if rec := do(e, http.MethodPost, "/login", `{"email":`, nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("login with a malformed body status = %d, want 400", rec.Code)
+	}
+	for i := range 3 {
+		rec := do(e, http.MethodPost, "/login", `{"email":"alice@example.com","password":"wrong"}`, nil)
+		if rec.Code != http.StatusUnauthorized {
+			t.Fatalf("wrong password #%d status = %d, want 401", i+1, rec.Code)
+		}
+	}
+	rec = do(e, http.MethodPost, "/login", `{"email":"alice@example.com","password":"pw"}`, nil)
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") != "60" {
+		t.Fatalf("throttled login = %d with Retry-After %q, want 429 with 60", rec.Code, rec.Header().Get("Retry-After"))
+	}
+
+	for _, tt := range []struct {
+		name    string
+		target  string
+		headers map[string]string
+		want    int
+	}{
+		{"anonymous", "/user/2/unlock", nil, http.StatusUnauthorized},
+		{"not an admin", "/user/2/unlock", alice, http.StatusForbidden},
+		{"invalid id", "/user/x/unlock", admin, http.StatusBadRequest},
+		{"unknown user", "/user/999/unlock", admin, http.StatusNotFound},
+		{"admin", "/user/2/unlock", admin, http.StatusNoContent},
+	} {
+		if rec := do(e, http.MethodPost, tt.target, "", tt.headers); rec.Code != tt.want {
+			t.Errorf("unlock (%s) status = %d, want %d (body %s)", tt.name, rec.Code, tt.want, rec.Body)
+		}
+	}
+
+	// The IP the failures came from stays throttled; other IPs may log in.
+	rec = do(e, http.MethodPost, "/login", `{"email":"alice@example.com","password":"pw"}`, map[string]string{"X-Forwarded-For": "198.51.100.7"})
+	if rec.Code != http.StatusOK {
+		t.Errorf("login after unlock status = %d (body %s)", rec.Code, rec.Body)
+	}
+	rec = do(e, http.MethodPost, "/login", `{"email":"alice@example.com","password":"pw"}`, nil)
+	if rec.Code != http.StatusTooManyRequests {
+		t.Errorf("login from the throttled IP after unlock status = %d, want 429", rec.Code)
+	}
+}
+
+func TestLoginThrottlesSpoofedIPs(t *testing.T) {
+	e := newTestServer()
+	e.IPExtractor = echo.ExtractIPDirect()
+	if rec := do(e, http.MethodPost, "/user", `{"email":"alice@example.com","password":"pw"}`, nil); rec.Code != http.StatusCreated {
+		t.Fatalf("create user status = %d (body %s)", rec.Code, rec.Body)
+	}
+
+	// Without trusted proxies X-Forwarded-For and X-Real-IP are ignored, so
+	// every attempt counts against the connection's IP.
+	for i := range 3 {
+		ip := fmt.Sprintf("203.0.113.%d", i+1)
+		rec := do(e, http.MethodPost, "/login", fmt.Sprintf(`{"email":"ghost%d@example.com","password":"wrong"}`, i), map[string]string{"X-Forwarded-For": ip, "X-Real-IP": ip})
+		if rec.Code != http.StatusUnauthorized {
+			t.Fatalf("wrong password #%d status = %d, want 401", i+1, rec.Code)
+		}
+	}
+	rec := do(e, http.MethodPost, "/login", `{"email":"alice@example.com","password":"pw"}`, map[string]string{"X-Forwarded-For": "198.51.100.9", "X-Real-IP": "198.51.100.9"})
+	if rec.Code != http.StatusTooManyRequests {
+		t.Errorf("login claiming a fresh IP status = %d, want 429 for the throttled connection", rec.Code)
+	}
+}
+
+func TestSessions(t *testing.T) {
+	e, users := newTestServerWithAuth(false)
+	for _, email := range []string{"admin@example.com", "alice@example.com"} {
+		if rec := do(e, http.MethodPost, "/user", fmt.Sprintf(`{"email":%q,"password":"pw"}`, email), nil); rec.Code != http.StatusCreated {
+			t.Fatalf("create user status = %d (body %s)", rec.Code, rec.Body)
+		}
+	}
+	users.SetRole(1, domain.RoleAdmin)
+
+	login := func(email string) string {
+		t.Helper()
+		rec := do(e, http.MethodPost, "/login", fmt.Sprintf(`{"email":%q,"password":"pw"}`, email), nil)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("login %s status = %d (body %s)", email, rec.Code, rec.Body)
+		}
+		return decode[map[string]any](t, rec)["token"].(string)
+	}
+	admin, alice := login("admin@example.com"), login("alice@example.com")
+
+	for _, tt := range []struct {
+		name    string
+		headers map[string]string
+		want    int
+	}{
+		{"anonymous", nil, http.StatusUnauthorized},
+		{"X-User-ID of an admin", map[string]string{"X-User-ID": "1"}, http.StatusUnauthorized},
+		{"admin session", map[string]string{"Authorization": "Bearer " + admin}, http.StatusOK},
+		{"admin session in X-API-Key", map[string]string{"X-API-Key": admin}, http.StatusOK},
+		{"user session", map[string]string{"Authorization": "Bearer " + alice}, http.StatusForbidden},
+		{"forged session", map[string]string{"Authorization": "Bearer " + strings.Replace(alice, "evs_2_", "evs_1_", 1)}, http.StatusUnauthorized},
+		{"session and X-User-ID", map[string]string{"Authorization": "Bearer " + admin, "X-User-ID": "1"}, http.StatusBadRequest},
+	} {
+		if rec := do(e, http.MethodGet, "/api-keys", "", tt.headers); rec.Code != tt.want {
+			t.Errorf("list api keys (%s) status = %d, want %d (body %s)", tt.name, rec.Code, tt.want, rec.Body)
+		}
+	}
+
+	rec := do(e, http.MethodPost, "/reviews", `{"reviewable_type":"product","reviewable_id":1,"rating":5,"title":"Good","body":"Works"}`,
+		map[string]string{"Authorization": "Bearer " + alice})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create review with a session status = %d (body %s)", rec.Code, rec.Body)
+	}
+	rec = do(e, http.MethodGet, fmt.Sprintf("/reviews/%d", decode[map[string]int](t, rec)["id"]), "", nil)
+	if got := decode[struct{ Review domain.Review }](t, rec).Review; got.UserID != 2 {
+		t.Errorf("review author = %d, want the session's user 2", got.UserID)
+	}
+}
@@ -0,0 +1,32 @@
+package httpDelivery
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// IPExtractor returns how the client IP of a request, which rate limits and
+// login throttling are keyed on, is found. Without trusted proxies it is the
+// address of the connection, so that clients cannot choose it. Behind
+// proxies, list their ranges as comma-separated CIDRs, e.g.
+// "10.0.0.0/8, 127.0.0.1/32"; the IP is then the nearest address in
+// X-Forwarded-For outside those ranges.
+func IPExtractor(trustedProxies string) (echo.IPExtractor, error) {
+	if strings.TrimSpace(trustedProxies) == "" {
+		return echo.ExtractIPDirect(), nil
+	}
+	// Only the listed ranges are trusted, not loopback and private addresses
+	// as echo does by default.
+	options := []echo.TrustOption{echo.TrustLoopback(false), echo.TrustLinkLocal(false), echo.TrustPrivateNet(false)}
+	for _, cidr := range strings.Split(trustedProxies, ",") {
+		_, ipNet, err := net.ParseCIDR(strings.TrimSpace(cidr))
+		if err != nil {
+			return nil, fmt.Errorf("trusted proxy %q: %w", strings.TrimSpace(cidr), err)
+		}
+		options = append(options, echo.TrustIPRange(ipNet))
+	}
+	return echo.ExtractIPFromXFFHeader(options...), nil
+}
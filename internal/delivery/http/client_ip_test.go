@@ -0,0 +1,41 @@
+package httpDelivery_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	httpDelivery "eve/internal/delivery/http"
+)
+
+func TestIPExtractor(t *testing.T) {
+	for _, tt := range []struct {
+		name           string
+		trustedProxies string
+		remoteAddr     string
+		xff            string
+		want           string
+	}{
+		{"no proxies", "", "198.51.100.4:1234", "203.0.113.9", "198.51.100.4"},
+		{"trusted proxy", "10.0.0.0/8", "10.0.0.5:1234", "203.0.113.9", "203.0.113.9"},
+		{"chain of trusted proxies", "10.0.0.0/8", "10.0.0.5:1234", "203.0.113.9, 10.0.0.7", "203.0.113.9"},
+		{"spoofed hop before the proxy", "10.0.0.0/8", "10.0.0.5:1234", "192.0.2.66, 203.0.113.9", "203.0.113.9"},
+		{"untrusted peer", "10.0.0.0/8", "198.51.100.4:1234", "203.0.113.9", "198.51.100.4"},
+		{"loopback not listed", "10.0.0.0/8", "127.0.0.1:1234", "203.0.113.9", "127.0.0.1"},
+		{"several ranges", "10.0.0.0/8, 127.0.0.1/32", "127.0.0.1:1234", "203.0.113.9", "203.0.113.9"},
+	} {
+		extract, err := httpDelivery.IPExtractor(tt.trustedProxies)
+		if err != nil {
+			t.Fatalf("IPExtractor(%q) error = %v", tt.trustedProxies, err)
+		}
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = tt.remoteAddr
+		req.Header.Set("X-Forwarded-For", tt.xff)
+		if got := extract(req); got != tt.want {
+			t.Errorf("%s: client IP = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	if _, err := httpDelivery.IPExtractor("10.0.0.0/8, proxy"); err == nil {
+		t.Error("IPExtractor(invalid range) succeeded, want an error")
+	}
+}
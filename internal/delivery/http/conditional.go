@@ -0,0 +1,145 @@
+package httpDelivery
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"eve/domain"
+	"eve/internal/usecase"
+
+	"github.com/labstack/echo/v4"
+)
+
+// versionTag hashes the IDs and versions of everything a representation is
+// built from into a strong entity tag. It changes whenever one of them is
+// added, removed or updated.
+func versionTag(parts []string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// reviewETag is the entity tag of GET /reviews/:id: the review, its official
+// response and its comments.
+func reviewETag(review domain.Review, comments []domain.ReviewComment) string {
+	parts := reviewVersion(nil, review)
+	for _, cm := range comments {
+		parts = append(parts, "comment", strconv.Itoa(cm.ID), cm.UpdatedAt)
+	}
+	return versionTag(parts)
+}
+
+// listETag is the entity tag of a review listing.
+func listETag(reviews []domain.Review) string {
+	var parts []string
+	for _, r := range reviews {
+		parts = reviewVersion(parts, r)
+	}
+	return versionTag(parts)
+}
+
+func reviewVersion(parts []string, r domain.Review) []string {
+	parts = append(parts, "review", strconv.Itoa(r.ID), r.UpdatedAt)
+	if r.Response != nil {
+		parts = append(parts, "response", r.Response.UpdatedAt)
+	}
+	return parts
+}
+
+// reviewLastModified is the latest change to the review, its response or its
+// comments, truncated to the second precision of HTTP dates.
+func reviewLastModified(review domain.Review, comments []domain.ReviewComment) time.Time {
+	stamps := []string{review.UpdatedAt}
+	if review.Response != nil {
+		stamps = append(stamps, review.Response.UpdatedAt)
+	}
+	for _, cm := range comments {
+		stamps = append(stamps, cm.UpdatedAt)
+	}
+
+	var latest time.Time
+	for _, s := range stamps {
+		if t, err := time.Parse(time.RFC3339Nano, s); err == nil && t.After(latest) {
+			latest = t
+		}
+	}
+	return latest.Truncate(time.Second)
+}
+
+// notModified sets the validators of a GET response and reports whether the
+// client's copy is current, in which case a 304 should be sent instead of the
+// body. If-None-Match takes precedence over If-Modified-Since. A zero
+// lastModified omits Last-Modified.
+func notModified(c echo.Context, etag string, lastModified time.Time) bool {
+	h := c.Response().Header()
+	h.Set("ETag", etag)
+	if !lastModified.IsZero() {
+		h.Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	req := c.Request()
+	if inm := req.Header.Get("If-None-Match"); inm != "" {
+		return etagMatches(inm, etag, true)
+	}
+	if ims := req.Header.Get("If-Modified-Since"); ims != "" && !lastModified.IsZero() {
+		t, err := http.ParseTime(ims)
+		return err == nil && !lastModified.After(t)
+	}
+	return false
+}
+
+// etagMatches reports whether header, a list of entity tags or "*", contains
+// etag. The weak comparison ignores the W/ prefix; the strong one never
+// matches weak tags.
+func etagMatches(header, etag string, weak bool) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" {
+			return true
+		}
+		if weak {
+			tag = strings.TrimPrefix(tag, "W/")
+		}
+		if tag == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// errIfMatchRequired refuses a change of a review without If-Match (428).
+var errIfMatchRequired = errors.New("If-Match header with the review's ETag is required")
+
+// errReviewChanged refuses a change of a review whose ETag is not listed in
+// If-Match (412).
+var errReviewChanged = errors.New("review has changed; fetch it again and retry")
+
+// ifMatch is the precondition required to change a review, so that a client
+// cannot overwrite a change it has not seen: the request's If-Match header
+// must list the review's current ETag. The use-case checks it once the
+// caller is authorized, and refuses the change if the review changes after
+// the check.
+func ifMatch(c echo.Context) usecase.ReviewPrecondition {
+	header := c.Request().Header.Get("If-Match")
+	return func(review domain.Review, comments []domain.ReviewComment) error {
+		if header == "" {
+			return errIfMatchRequired
+		}
+		if !etagMatches(header, reviewETag(review, comments), false) {
+			return errReviewChanged
+		}
+		return nil
+	}
+}
+
+// setReviewETag sets the ETag of review id after a change, so that the
+// client can make its next change without fetching the review again.
+func setReviewETag(c echo.Context, getReview *usecase.GetReviewUseCase, id, userID int) {
+	if review, comments, err := getReview.Execute(c.Request().Context(), id, userID); err == nil {
+		c.Response().Header().Set("ETag", reviewETag(review, comments))
+	}
+}
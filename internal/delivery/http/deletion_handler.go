@@ -0,0 +1,63 @@
+package httpDelivery
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"eve/internal/usecase"
+
+	"github.com/labstack/echo/v4"
+)
+
+// DeletionHandler holds use-cases for soft-deleting and restoring reviews.
+type DeletionHandler struct {
+	deleteReview  *usecase.DeleteReviewUseCase
+	restoreReview *usecase.RestoreReviewUseCase
+	log           *slog.Logger
+}
+
+// NewDeletionHandler constructs a DeletionHandler.
+func NewDeletionHandler(dr *usecase.DeleteReviewUseCase, rr *usecase.RestoreReviewUseCase, l *slog.Logger) *DeletionHandler {
+	return &DeletionHandler{deleteReview: dr, restoreReview: rr, log: l}
+}
+
+// DeleteReview handles DELETE /reviews/:id
+// Requires the credentials of the review's author, a moderator or an
+// admin, and an "If-Match" header with the ETag of GET /reviews/:id.
+func (h *DeletionHandler) DeleteReview(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return respondError(c, h.log, http.StatusBadRequest, "invalid id")
+	}
+
+	userID, err := extractUserID(c)
+	if err != nil {
+		return respondError(c, h.log, http.StatusUnauthorized, err.Error())
+	}
+	if err := h.deleteReview.Execute(c.Request().Context(), id, userID, ifMatch(c)); err != nil {
+		return respondError(c, h.log, errorStatus(err, http.StatusInternalServerError), err.Error())
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// RestoreReview handles POST /reviews/:id/restore
+// Requires the credentials of a moderator or an admin.
+func (h *DeletionHandler) RestoreReview(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return respondError(c, h.log, http.StatusBadRequest, "invalid id")
+	}
+
+	userID, err := extractUserID(c)
+	if err != nil {
+		return respondError(c, h.log, http.StatusUnauthorized, err.Error())
+	}
+
+	if err := h.restoreReview.Execute(c.Request().Context(), id, userID); err != nil {
+		return respondError(c, h.log, errorStatus(err, http.StatusInternalServerError), err.Error())
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
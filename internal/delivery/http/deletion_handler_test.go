@@ -0,0 +1,69 @@
+package httpDelivery_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"eve/domain"
+)
+
+func TestDeletionHandlerFlow(t *testing.T) {
+	e, users := newTestServerWithUsers()
+	for _, email := range []string{"author@example.com", "stranger@example.com", "mod@example.com"} {
+		if rec := do(e, http.MethodPost, "/user", fmt.Sprintf(`{"email":%q,"password":"pw"}`, email), nil); rec.Code != http.StatusCreated {
+			t.Fatalf("create user status = %d (body %s)", rec.Code, rec.Body)
+		}
+	}
+	users.SetRole(3, domain.RoleModerator)
+	author := map[string]string{"X-User-ID": "1"}
+	stranger := map[string]string{"X-User-ID": "2"}
+	moderator := map[string]string{"X-User-ID": "3"}
+
+	rec := do(e, http.MethodPost, "/reviews", `{"reviewable_type":"product","reviewable_id":42,"rating":5,"title":"Great","body":"Love it"}`, author)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create review status = %d (body %s)", rec.Code, rec.Body)
+	}
+	reviewURL := fmt.Sprintf("/reviews/%d", decode[map[string]int](t, rec)["id"])
+
+	if rec := do(e, http.MethodDelete, reviewURL, "", nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("anonymous delete status = %d, want 401", rec.Code)
+	}
+	if rec := do(e, http.MethodDelete, reviewURL, "", stranger); rec.Code != http.StatusForbidden {
+		t.Errorf("stranger delete without If-Match status = %d, want 403", rec.Code)
+	}
+	if rec := do(e, http.MethodDelete, reviewURL, "", author); rec.Code != http.StatusPreconditionRequired {
+		t.Errorf("delete without If-Match status = %d, want 428", rec.Code)
+	}
+	if rec := do(e, http.MethodDelete, reviewURL, "", map[string]string{"X-User-ID": "1", "If-Match": `"stale"`}); rec.Code != http.StatusPreconditionFailed {
+		t.Errorf("delete with stale If-Match status = %d, want 412", rec.Code)
+	}
+	if rec := do(e, http.MethodDelete, reviewURL, "", ifMatch(t, e, reviewURL, stranger)); rec.Code != http.StatusForbidden {
+		t.Errorf("stranger delete status = %d, want 403", rec.Code)
+	}
+	if rec := do(e, http.MethodDelete, reviewURL, "", ifMatch(t, e, reviewURL, author)); rec.Code != http.StatusNoContent {
+		t.Fatalf("author delete status = %d (body %s)", rec.Code, rec.Body)
+	}
+	if rec := do(e, http.MethodGet, reviewURL, "", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("get deleted review status = %d, want 404", rec.Code)
+	}
+	if rec := do(e, http.MethodGet, "/reviews?reviewable_type=product&reviewable_id=42", "", nil); len(decode[[]domain.Review](t, rec)) != 0 {
+		t.Errorf("deleted review still listed: %s", rec.Body)
+	}
+	if rec := do(e, http.MethodDelete, reviewURL, "", map[string]string{"X-User-ID": "1", "If-Match": "*"}); rec.Code != http.StatusNotFound {
+		t.Errorf("second delete status = %d, want 404", rec.Code)
+	}
+
+	if rec := do(e, http.MethodPost, reviewURL+"/restore", "", author); rec.Code != http.StatusForbidden {
+		t.Errorf("author restore status = %d, want 403", rec.Code)
+	}
+	if rec := do(e, http.MethodPost, reviewURL+"/restore", "", moderator); rec.Code != http.StatusNoContent {
+		t.Fatalf("moderator restore status = %d (body %s)", rec.Code, rec.Body)
+	}
+	if rec := do(e, http.MethodPost, reviewURL+"/restore", "", moderator); rec.Code != http.StatusNotFound {
+		t.Errorf("restore live review status = %d, want 404", rec.Code)
+	}
+	if rec := do(e, http.MethodGet, reviewURL, "", nil); rec.Code != http.StatusOK {
+		t.Errorf("get restored review status = %d, want 200", rec.Code)
+	}
+}
@@ -0,0 +1,92 @@
+package httpDelivery
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"eve/internal/logging"
+	"eve/internal/usecase"
+
+	"github.com/labstack/echo/v4"
+)
+
+// statusClientClosedRequest is the non-standard status used when the client
+// went away before the response was ready.
+const statusClientClosedRequest = 499
+
+// errorStatus maps validation, authentication, authorization, throttling,
+// not-found, precondition and context errors to their HTTP statuses and falls
+// back to the given status for everything else.
+func errorStatus(err error, fallback int) int {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidInput):
+		return http.StatusBadRequest
+	case errors.Is(err, usecase.ErrUnauthenticated):
+		return http.StatusUnauthorized
+	case errors.Is(err, usecase.ErrThrottled):
+		return http.StatusTooManyRequests
+	case errors.Is(err, usecase.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, usecase.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, errIfMatchRequired):
+		return http.StatusPreconditionRequired
+	case errors.Is(err, usecase.ErrPreconditionFailed), errors.Is(err, errReviewChanged):
+		return http.StatusPreconditionFailed
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	case errors.Is(err, context.Canceled):
+		return statusClientClosedRequest
+	default:
+		return fallback
+	}
+}
+
+// respondError writes a JSON error body carrying the request ID so clients
+// can quote it in bug reports. Server-side failures are logged. A timeout
+// status is reported as 499 when the client is the one who went away, since
+// an error wrapping the deadline of an inner context does not say so.
+func respondError(c echo.Context, log *slog.Logger, status int, msg string) error {
+	ctx := c.Request().Context()
+	if status == http.StatusGatewayTimeout && errors.Is(ctx.Err(), context.Canceled) {
+		status = statusClientClosedRequest
+	}
+	if status >= http.StatusInternalServerError {
+		log.ErrorContext(ctx, "request failed", slog.Int("status", status), slog.String("error", msg))
+	}
+	return c.JSON(status, map[string]string{
+		"error":      msg,
+		"request_id": logging.RequestID(ctx),
+	})
+}
+
+// ErrorHandler replaces echo's default error handler so that errors returned
+// by handlers and middleware (binding errors, unknown routes, ...) use the same
+// JSON shape as respondError.
+func ErrorHandler(log *slog.Logger) echo.HTTPErrorHandler {
+	return func(err error, c echo.Context) {
+		if c.Response().Committed {
+			return
+		}
+
+		status := statusOf(c, err)
+		msg := http.StatusText(status)
+		var he *echo.HTTPError
+		if errors.As(err, &he) {
+			if m, ok := he.Message.(string); ok {
+				msg = m
+			}
+		}
+
+		if c.Request().Method == http.MethodHead {
+			err = c.NoContent(status)
+		} else {
+			err = respondError(c, log, status, msg)
+		}
+		if err != nil {
+			log.ErrorContext(c.Request().Context(), "write error response", slog.String("error", err.Error()))
+		}
+	}
+}
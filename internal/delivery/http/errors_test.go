@@ -0,0 +1,51 @@
+package httpDelivery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"eve/internal/logging"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestErrorStatusTimeout(t *testing.T) {
+	canceled := fmt.Errorf("get review: %w", context.DeadlineExceeded)
+	if got := errorStatus(canceled, http.StatusInternalServerError); got != http.StatusGatewayTimeout {
+		t.Errorf("errorStatus(deadline exceeded) = %d, want 504", got)
+	}
+	if got := errorStatus(errors.New("boom"), http.StatusInternalServerError); got != http.StatusInternalServerError {
+		t.Errorf("errorStatus(other error) = %d, want the fallback", got)
+	}
+
+	for _, tt := range []struct {
+		name string
+		ctx  func() context.Context
+		want int
+	}{
+		{"deadline", func() context.Context {
+			ctx, cancel := context.WithTimeout(context.Background(), 0)
+			cancel()
+			return ctx
+		}, http.StatusGatewayTimeout},
+		{"client gone", func() context.Context {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			return ctx
+		}, statusClientClosedRequest},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/reviews/1", nil).WithContext(tt.ctx())
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(req, rec)
+		if err := respondError(c, logging.Nop(), errorStatus(canceled, http.StatusInternalServerError), canceled.Error()); err != nil {
+			t.Fatalf("respondError() error = %v", err)
+		}
+		if rec.Code != tt.want {
+			t.Errorf("timeout (%s) status = %d, want %d", tt.name, rec.Code, tt.want)
+		}
+	}
+}
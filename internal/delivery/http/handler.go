@@ -3,6 +3,7 @@ package httpDelivery
 import (
 	"eve/domain"
 	"eve/internal/usecase"
+	"log/slog"
 	"net/http"
 
 	"github.com/labstack/echo/v4"
@@ -11,27 +12,39 @@ import (
 type Handler struct {
 	create *usecase.CreateUserUseCase
 	getAll *usecase.GetUserUseCase
+	log    *slog.Logger
 }
 
-func NewHandler(cu *usecase.CreateUserUseCase, gu *usecase.GetUserUseCase) *Handler {
+func NewHandler(cu *usecase.CreateUserUseCase, gu *usecase.GetUserUseCase, l *slog.Logger) *Handler {
 	return &Handler{
 		create: cu,
 		getAll: gu,
+		log:    l,
 	}
 }
 
 func (h *Handler) Create(c echo.Context) error {
-	var r domain.User
+	var r domain.CreateUserRequest
 	if err := c.Bind(&r); err != nil {
 		return err
 	}
-	if err := h.create.Execute(r); err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	if err := h.create.Execute(c.Request().Context(), domain.User{Email: r.Email, Password: r.Password}); err != nil {
+		return respondError(c, h.log, errorStatus(err, http.StatusBadRequest), err.Error())
 	}
 	return c.NoContent(http.StatusCreated)
 }
 
+// List handles GET /user
+// Requires the credentials of an admin.
 func (h *Handler) List(c echo.Context) error {
-	data, _ := h.getAll.Execute()
+	userID, err := extractUserID(c)
+	if err != nil {
+		return respondError(c, h.log, http.StatusUnauthorized, err.Error())
+	}
+
+	data, err := h.getAll.Execute(c.Request().Context(), userID)
+	if err != nil {
+		return respondError(c, h.log, errorStatus(err, http.StatusInternalServerError), err.Error())
+	}
 	return c.JSON(http.StatusOK, data)
 }
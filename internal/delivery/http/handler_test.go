@@ -0,0 +1,283 @@
+package httpDelivery_test
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"eve/domain"
+	httpDelivery "eve/internal/delivery/http"
+	"eve/internal/infrastructure"
+	"eve/internal/logging"
+	"eve/internal/repository/memory"
+	"eve/internal/usecase"
+
+	"github.com/labstack/echo/v4"
+)
+
+// newTestServer wires handlers to in-memory repositories the same way
+// cmd/eve.go wires them to Postgres.
+func newTestServer() *echo.Echo {
+	e, _ := newTestServerWithUsers()
+	return e
+}
+
+// newTestServerWithUsers is newTestServer that also returns the user store,
+// so tests can grant roles that have no API. Like a development setup, it
+// lets requests name their user in an "X-User-ID" header.
+func newTestServerWithUsers() (*echo.Echo, *memory.UserRepo) {
+	return newTestServerWithAuth(true)
+}
+
+// newTestServerWithAuth is newTestServerWithUsers that honours "X-User-ID"
+// only when trustUserHeader is set.
+func newTestServerWithAuth(trustUserHeader bool) (*echo.Echo, *memory.UserRepo) {
+	log := logging.Nop()
+	metrics := usecase.NopMetrics{}
+
+	userRepo := memory.NewUserRepo()
+	hashing := usecase.NewHashLimiter(4)
+	h := httpDelivery.NewHandler(
+		usecase.NewCreateUserUseCase(userRepo, infrastructure.NewFakeHasher(), hashing, metrics, log),
+		usecase.NewGetUserUseCase(userRepo, metrics, log),
+		log,
+	)
+
+	reviewRepo := memory.NewReviewRepo()
+	criteriaRepo := memory.NewCriteriaRepo()
+	typeRepo := memory.NewReviewableTypeRepo()
+	for _, name := range []string{"product", "hotel"} {
+		_, _ = typeRepo.SaveType(context.Background(), domain.ReviewableType{Name: name, PhotosAllowed: true, ModerationMode: domain.ModerationPost})
+	}
+	rh := httpDelivery.NewReviewHandler(
+		usecase.NewCreateReviewUseCase(reviewRepo, typeRepo, criteriaRepo, infrastructure.AllowAllResolver{}, infrastructure.NoPurchaseVerifier{}, metrics, log),
+		usecase.NewCreateCommentUseCase(reviewRepo, userRepo, metrics, log),
+		usecase.NewListReviewsUseCase(reviewRepo, metrics, log),
+		usecase.NewGetReviewUseCase(reviewRepo, userRepo, metrics, log),
+		usecase.NewSearchReviewsUseCase(reviewRepo, metrics, log),
+		log,
+	)
+
+	ownerRepo := memory.NewOwnerRepo()
+	resp := httpDelivery.NewResponseHandler(
+		usecase.NewAddOwnerUseCase(userRepo, ownerRepo, metrics, log),
+		usecase.NewRemoveOwnerUseCase(userRepo, ownerRepo, metrics, log),
+		usecase.NewListOwnersUseCase(ownerRepo, metrics, log),
+		usecase.NewSaveResponseUseCase(reviewRepo, ownerRepo, metrics, log),
+		usecase.NewListResponseVersionsUseCase(reviewRepo, userRepo, metrics, log),
+		log,
+	)
+
+	rv := httpDelivery.NewRevisionHandler(
+		usecase.NewUpdateReviewUseCase(reviewRepo, typeRepo, userRepo, metrics, log),
+		usecase.NewUpdateCommentUseCase(reviewRepo, userRepo, metrics, log),
+		usecase.NewListRevisionsUseCase(reviewRepo, userRepo, metrics, log),
+		usecase.NewGetReviewUseCase(reviewRepo, userRepo, metrics, log),
+		log,
+	)
+	del := httpDelivery.NewDeletionHandler(
+		usecase.NewDeleteReviewUseCase(reviewRepo, userRepo, metrics, log),
+		usecase.NewRestoreReviewUseCase(reviewRepo, userRepo, metrics, log),
+		log,
+	)
+
+	rt := httpDelivery.NewRatingHandler(
+		usecase.NewSetCriteriaUseCase(userRepo, typeRepo, criteriaRepo, metrics, log),
+		usecase.NewListCriteriaUseCase(criteriaRepo, metrics, log),
+		usecase.NewGetReviewSummaryUseCase(reviewRepo, criteriaRepo, metrics, log),
+		log,
+	)
+
+	ty := httpDelivery.NewReviewableTypeHandler(
+		usecase.NewSaveReviewableTypeUseCase(userRepo, typeRepo, metrics, log),
+		usecase.NewListReviewableTypesUseCase(typeRepo, metrics, log),
+		usecase.NewApproveReviewUseCase(reviewRepo, userRepo, metrics, log),
+		usecase.NewListPendingReviewsUseCase(reviewRepo, userRepo, metrics, log),
+		log,
+	)
+
+	webhookRepo := memory.NewWebhookRepo()
+	wh := httpDelivery.NewWebhookHandler(
+		usecase.NewCreateWebhookUseCase(userRepo, typeRepo, webhookRepo, metrics, log),
+		usecase.NewListWebhooksUseCase(userRepo, webhookRepo, metrics, log),
+		usecase.NewDeleteWebhookUseCase(userRepo, webhookRepo, metrics, log),
+		usecase.NewListWebhookDeliveriesUseCase(userRepo, webhookRepo, metrics, log),
+		usecase.NewRedeliverWebhookUseCase(userRepo, webhookRepo, metrics, log),
+		log,
+	)
+
+	loginAttempts := memory.NewLoginAttemptRepo()
+	auth := httpDelivery.NewAuthHandler(
+		usecase.NewLoginUseCase(userRepo, loginAttempts, infrastructure.NewFakeHasher(), hashing, testLoginPolicy, testSessionPolicy, metrics, log),
+		usecase.NewUnlockUserUseCase(userRepo, loginAttempts, metrics, log),
+		log,
+	)
+
+	apiKeyRepo := memory.NewAPIKeyRepo()
+	kh := httpDelivery.NewAPIKeyHandler(
+		usecase.NewCreateAPIKeyUseCase(userRepo, typeRepo, apiKeyRepo, metrics, log),
+		usecase.NewListAPIKeysUseCase(userRepo, apiKeyRepo, metrics, log),
+		usecase.NewDeleteAPIKeyUseCase(userRepo, apiKeyRepo, metrics, log),
+		log,
+	)
+
+	e := echo.New()
+	e.HTTPErrorHandler = httpDelivery.ErrorHandler(log)
+	// httptest requests come from 192.0.2.1, which plays the proxy.
+	e.IPExtractor, _ = httpDelivery.IPExtractor("192.0.2.1/32")
+	e.Use(httpDelivery.RequestIDMiddleware())
+	e.Use(httpDelivery.AuthMiddleware(
+		usecase.NewAuthenticateAPIKeyUseCase(apiKeyRepo, metrics, log),
+		usecase.NewAuthenticateSessionUseCase(testSessionPolicy, metrics, log),
+		trustUserHeader,
+		log,
+	))
+	idempotency := httpDelivery.IdempotencyMiddleware(memory.NewIdempotencyStore(), time.Minute, time.Hour, log)
+
+	e.POST("/user", h.Create, idempotency)
+	e.GET("/user", h.List)
+	e.POST("/user/:id/unlock", auth.UnlockUser)
+	e.POST("/login", auth.Login)
+
+	e.POST("/reviews", rh.CreateReview, idempotency)
+	e.POST("/reviews/comments", rh.CreateComment, idempotency)
+	e.POST("/reviews/:id/comments", rh.CreateComment, idempotency)
+	e.POST("/reviews/:id/comments/:cid/replies", rh.ReplyToComment, idempotency)
+	e.GET("/reviews", rh.ListReviews)
+	e.GET("/reviews/search", rh.SearchReviews)
+	e.GET("/reviews/pending", ty.ListPendingReviews)
+	e.GET("/reviews/:id", rh.GetReview)
+	e.PATCH("/reviews/:id", rv.UpdateReview)
+	e.PATCH("/reviews/:id/comments/:cid", rv.UpdateComment)
+	e.GET("/reviews/:id/revisions", rv.ListRevisions)
+	e.DELETE("/reviews/:id", del.DeleteReview)
+	e.POST("/reviews/:id/restore", del.RestoreReview)
+	e.POST("/reviews/:id/approve", ty.ApproveReview)
+
+	e.GET("/reviewables/:type/:id/owners", resp.ListOwners)
+	e.PUT("/reviewables/:type/:id/owners/:user_id", resp.AddOwner)
+	e.DELETE("/reviewables/:type/:id/owners/:user_id", resp.RemoveOwner)
+	e.PUT("/reviews/:id/response", resp.SaveResponse)
+	e.GET("/reviews/:id/response/versions", resp.ListResponseVersions)
+
+	e.GET("/reviewable-types", ty.ListTypes)
+	e.PUT("/reviewable-types/:type", ty.SaveType)
+	e.GET("/reviewable-types/:type/criteria", rt.ListCriteria)
+	e.PUT("/reviewable-types/:type/criteria", rt.SetCriteria)
+	e.GET("/reviewables/:type/:id/summary", rt.GetSummary)
+
+	e.POST("/webhooks", wh.CreateWebhook, idempotency)
+	e.GET("/webhooks", wh.ListWebhooks)
+	e.DELETE("/webhooks/:id", wh.DeleteWebhook)
+	e.GET("/webhooks/:id/deliveries", wh.ListDeliveries)
+	e.POST("/webhooks/:id/deliveries/:delivery_id/redeliver", wh.Redeliver)
+
+	e.POST("/api-keys", kh.CreateAPIKey, idempotency)
+	e.GET("/api-keys", kh.ListAPIKeys)
+	e.DELETE("/api-keys/:id", kh.DeleteAPIKey)
+	return e, userRepo
+}
+
+// do sends a request to e and returns the recorded response.
+func do(e *echo.Echo, method, target, body string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func decode[T any](t *testing.T, rec *httptest.ResponseRecorder) T {
+	t.Helper()
+	var v T
+	if err := json.Unmarshal(rec.Body.Bytes(), &v); err != nil {
+		t.Fatalf("decode response %q: %v", rec.Body.String(), err)
+	}
+	return v
+}
+
+// ifMatch returns headers plus an If-Match header with the current ETag of
+// the review at reviewURL.
+func ifMatch(t *testing.T, e *echo.Echo, reviewURL string, headers map[string]string) map[string]string {
+	t.Helper()
+	rec := do(e, http.MethodGet, reviewURL, "", nil)
+	if rec.Code != http.StatusOK || rec.Header().Get("ETag") == "" {
+		t.Fatalf("get %s = %d with ETag %q", reviewURL, rec.Code, rec.Header().Get("ETag"))
+	}
+	with := map[string]string{"If-Match": rec.Header().Get("ETag")}
+	maps.Copy(with, headers)
+	return with
+}
+
+func TestHandlerCreateAndList(t *testing.T) {
+	e, userRepo := newTestServerWithUsers()
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{name: "created", body: `{"email":"a@example.com","password":"secret"}`, wantStatus: http.StatusCreated},
+		{name: "duplicate email", body: `{"email":"a@example.com","password":"other"}`, wantStatus: http.StatusBadRequest},
+		{name: "malformed json", body: `{"email":`, wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := do(e, http.MethodPost, "/user", tt.body, nil)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %s)", rec.Code, tt.wantStatus, rec.Body)
+			}
+		})
+	}
+
+	if rec := do(e, http.MethodGet, "/user", "", nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("anonymous GET /user status = %d, want 401", rec.Code)
+	}
+	admin := map[string]string{"X-User-ID": "1"}
+	if rec := do(e, http.MethodGet, "/user", "", admin); rec.Code != http.StatusForbidden {
+		t.Errorf("GET /user as a user status = %d, want 403", rec.Code)
+	}
+	userRepo.SetRole(1, domain.RoleAdmin)
+	rec := do(e, http.MethodGet, "/user", "", admin)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /user status = %d", rec.Code)
+	}
+	users := decode[[]map[string]any](t, rec)
+	if len(users) != 1 || users[0]["email"] != "a@example.com" {
+		t.Errorf("GET /user = %v", users)
+	}
+	if _, ok := users[0]["password"]; ok {
+		t.Errorf("GET /user = %v, want no password hashes", users)
+	}
+}
+
+func TestErrorResponseCarriesRequestID(t *testing.T) {
+	e := newTestServer()
+
+	rec := do(e, http.MethodGet, "/reviews", "", map[string]string{echo.HeaderXRequestID: "req-1"})
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	if got := rec.Header().Get(echo.HeaderXRequestID); got != "req-1" {
+		t.Errorf("X-Request-ID header = %q, want %q", got, "req-1")
+	}
+	if body := decode[map[string]string](t, rec); body["request_id"] != "req-1" {
+		t.Errorf("request_id = %q, want %q", body["request_id"], "req-1")
+	}
+
+	rec = do(e, http.MethodGet, "/missing", "", nil)
+	body := decode[map[string]string](t, rec)
+	if rec.Code != http.StatusNotFound || body["request_id"] == "" || body["request_id"] != rec.Header().Get(echo.HeaderXRequestID) {
+		t.Errorf("GET /missing = %d %v, want 404 with generated request_id", rec.Code, body)
+	}
+}
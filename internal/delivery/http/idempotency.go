@@ -0,0 +1,140 @@
+package httpDelivery
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"eve/domain"
+	"eve/internal/usecase"
+
+	"github.com/labstack/echo/v4"
+)
+
+// IdempotencyKeyHeader carries the client's key for a create request.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// maxIdempotencyKeyLen caps the length of an Idempotency-Key; UUIDs need 36.
+const maxIdempotencyKeyLen = 255
+
+// maxIdempotentBodyLen caps the body of a request with an Idempotency-Key,
+// which is read into memory to be fingerprinted.
+const maxIdempotentBodyLen = 1 << 20
+
+// IdempotencyMiddleware makes the routes it wraps safe to retry. The first
+// request with a given Idempotency-Key header is handled as usual and its
+// response stored for ttl; retries with the same key get that response again
+// (marked with Idempotent-Replayed: true) instead of creating a duplicate.
+// Keys are scoped by caller. Reusing a key for a different request is refused
+// with 422, and a retry arriving while the first request is still running
+// with 409. The key is only held for lease while the request runs, so that
+// a crashed instance does not block it for ttl; lease should outlast the
+// handling of a request. Server errors are not stored, so the request can be
+// retried. Requests without the header are not affected.
+func IdempotencyMiddleware(store usecase.IdempotencyStore, lease, ttl time.Duration, log *slog.Logger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			key := c.Request().Header.Get(IdempotencyKeyHeader)
+			if key == "" {
+				return next(c)
+			}
+			if len(key) > maxIdempotencyKeyLen || !validRequestID(key) {
+				return respondError(c, log, http.StatusBadRequest, "invalid Idempotency-Key")
+			}
+
+			req := c.Request()
+			body, err := io.ReadAll(http.MaxBytesReader(c.Response(), req.Body, maxIdempotentBodyLen))
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				return respondError(c, log, http.StatusRequestEntityTooLarge, "request body too large")
+			}
+			if err != nil {
+				return respondError(c, log, http.StatusBadRequest, "read request body: "+err.Error())
+			}
+			req.Body = io.NopCloser(bytes.NewReader(body))
+
+			ctx := req.Context()
+			key = idempotencyScope(c) + key
+			fingerprint := requestFingerprint(req, body)
+			rec, reserved, err := store.Reserve(ctx, key, fingerprint, lease)
+			if err != nil {
+				return respondError(c, log, errorStatus(err, http.StatusServiceUnavailable), err.Error())
+			}
+			if !reserved {
+				return replay(c, log, rec, fingerprint)
+			}
+
+			w := &recordingWriter{ResponseWriter: c.Response().Writer}
+			c.Response().Writer = w
+			err = next(c)
+			c.Response().Writer = w.ResponseWriter
+
+			// The outcome must be recorded even when the client is gone.
+			ctx = context.WithoutCancel(ctx)
+			status := c.Response().Status
+			if err != nil || !c.Response().Committed || status >= http.StatusInternalServerError {
+				if rerr := store.Release(ctx, key); rerr != nil {
+					log.WarnContext(ctx, "release idempotency key", slog.String("error", rerr.Error()))
+				}
+				return err
+			}
+			rec.Status = status
+			rec.ContentType = c.Response().Header().Get(echo.HeaderContentType)
+			rec.Body = w.body.Bytes()
+			if cerr := store.Complete(ctx, rec, ttl); cerr != nil {
+				log.WarnContext(ctx, "store idempotent response", slog.String("error", cerr.Error()))
+			}
+			return nil
+		}
+	}
+}
+
+// replay answers a retry with the stored response of the original request.
+func replay(c echo.Context, log *slog.Logger, rec domain.IdempotencyRecord, fingerprint string) error {
+	switch {
+	case rec.Fingerprint != fingerprint:
+		return respondError(c, log, http.StatusUnprocessableEntity, "Idempotency-Key was already used for a different request")
+	case !rec.Completed():
+		return respondError(c, log, http.StatusConflict, "a request with this Idempotency-Key is still being processed")
+	}
+	c.Response().Header().Set("Idempotent-Replayed", "true")
+	if len(rec.Body) == 0 {
+		return c.NoContent(rec.Status)
+	}
+	return c.Blob(rec.Status, rec.ContentType, rec.Body)
+}
+
+// idempotencyScope keeps the keys of different callers apart, so that one
+// cannot replay the response made for another.
+func idempotencyScope(c echo.Context) string {
+	if userID, err := extractUserID(c); err == nil {
+		return "user:" + strconv.Itoa(userID) + ":"
+	}
+	return "anonymous:"
+}
+
+// requestFingerprint identifies a request by its method, target and body.
+func requestFingerprint(req *http.Request, body []byte) string {
+	h := sha256.New()
+	io.WriteString(h, req.Method+" "+req.URL.RequestURI()+"\n")
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// recordingWriter keeps a copy of the response body written through it.
+type recordingWriter struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
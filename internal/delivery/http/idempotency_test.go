@@ -0,0 +1,91 @@
+package httpDelivery_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	httpDelivery "eve/internal/delivery/http"
+	"eve/internal/logging"
+	"eve/internal/repository/memory"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestIdempotentCreate(t *testing.T) {
+	e := newTestServer()
+	const review = `{"reviewable_type":"product","reviewable_id":42,"rating":5,"title":"Great"}`
+	first := map[string]string{"X-User-ID": "7", "Idempotency-Key": "4f9c2a"}
+
+	rec := do(e, http.MethodPost, "/reviews", review, first)
+	if rec.Code != http.StatusCreated || rec.Header().Get("Idempotent-Replayed") != "" {
+		t.Fatalf("create status = %d (body %s)", rec.Code, rec.Body)
+	}
+	created := rec.Body.String()
+
+	rec = do(e, http.MethodPost, "/reviews", review, first)
+	if rec.Code != http.StatusCreated || rec.Body.String() != created || rec.Header().Get("Idempotent-Replayed") != "true" {
+		t.Errorf("retry = %d %s (replayed %q), want the original response %s", rec.Code, rec.Body, rec.Header().Get("Idempotent-Replayed"), created)
+	}
+	if ct := rec.Header().Get(echo.HeaderContentType); ct != echo.MIMEApplicationJSON {
+		t.Errorf("retry Content-Type = %q", ct)
+	}
+
+	if rec := do(e, http.MethodPost, "/reviews", `{"reviewable_type":"product","reviewable_id":43,"rating":1}`, first); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("reuse for another review status = %d, want 422", rec.Code)
+	}
+	if rec := do(e, http.MethodPost, "/reviews/comments", review, first); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("reuse on another route status = %d, want 422", rec.Code)
+	}
+
+	// Keys are per caller: another user's request with the same key is new.
+	if rec := do(e, http.MethodPost, "/reviews", review, map[string]string{"X-User-ID": "8", "Idempotency-Key": "4f9c2a"}); rec.Code != http.StatusCreated || rec.Body.String() == created {
+		t.Errorf("other user create = %d %s, want a new review", rec.Code, rec.Body)
+	}
+
+	rec = do(e, http.MethodGet, "/reviews?reviewable_type=product&reviewable_id=42", "", nil)
+	if got := decode[[]map[string]any](t, rec); len(got) != 2 {
+		t.Errorf("reviews = %d, want one per user", len(got))
+	}
+
+	if rec := do(e, http.MethodPost, "/reviews", review, map[string]string{"X-User-ID": "7", "Idempotency-Key": "bad key"}); rec.Code != http.StatusBadRequest {
+		t.Errorf("invalid key status = %d, want 400", rec.Code)
+	}
+}
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	e := echo.New()
+	mw := httpDelivery.IdempotencyMiddleware(memory.NewIdempotencyStore(), time.Minute, time.Hour, logging.Nop())
+	calls := 0
+	e.POST("/things", func(c echo.Context) error {
+		calls++
+		switch calls {
+		case 1: // the server failed: the key is released
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "boom"})
+		case 2: // a retry arriving while this one runs is turned away
+			if rec := do(e, http.MethodPost, "/things", `{}`, map[string]string{"Idempotency-Key": "k"}); rec.Code != http.StatusConflict {
+				t.Errorf("concurrent retry status = %d, want 409", rec.Code)
+			}
+		}
+		return c.JSON(http.StatusCreated, map[string]int{"id": calls})
+	}, mw)
+
+	key := map[string]string{"Idempotency-Key": "k"}
+	if rec := do(e, http.MethodPost, "/things", `{}`, key); rec.Code != http.StatusInternalServerError {
+		t.Fatalf("first attempt status = %d, want 500", rec.Code)
+	}
+	if rec := do(e, http.MethodPost, "/things", `{}`, key); rec.Code != http.StatusCreated || rec.Body.String() != "{\"id\":2}\n" {
+		t.Fatalf("retry after failure = %d %s, want handled again", rec.Code, rec.Body)
+	}
+	if rec := do(e, http.MethodPost, "/things", `{}`, key); rec.Code != http.StatusCreated || rec.Body.String() != "{\"id\":2}\n" {
+		t.Errorf("retry after success = %d %s, want the stored response", rec.Code, rec.Body)
+	}
+	if rec := do(e, http.MethodPost, "/things", `{}`, nil); rec.Code != http.StatusCreated || calls != 3 {
+		t.Errorf("request without key = %d after %d calls, want handled", rec.Code, calls)
+	}
+	huge := `{"pad":"` + strings.Repeat("x", 1<<20) + `"}`
+	if rec := do(e, http.MethodPost, "/things", huge, map[string]string{"Idempotency-Key": "huge"}); rec.Code != http.StatusRequestEntityTooLarge || calls != 3 {
+		t.Errorf("oversized request = %d after %d calls, want 413 before the handler", rec.Code, calls)
+	}
+}
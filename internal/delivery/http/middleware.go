@@ -0,0 +1,186 @@
+package httpDelivery
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"eve/internal/logging"
+
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// HTTPMetrics records per-request measurements.
+type HTTPMetrics interface {
+	ObserveHTTPRequest(method, route string, status int, duration time.Duration)
+}
+
+// MetricsMiddleware reports method, matched route, status and latency of every request.
+func MetricsMiddleware(m HTTPMetrics) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+			err := next(c)
+			m.ObserveHTTPRequest(c.Request().Method, routeOf(c), statusOf(c, err), time.Since(start))
+			return err
+		}
+	}
+}
+
+// routeOf returns the registered route pattern (e.g. /reviews/:id) so that
+// label cardinality stays bounded.
+func routeOf(c echo.Context) string {
+	if p := c.Path(); p != "" {
+		return p
+	}
+	return "unmatched"
+}
+
+// statusOf returns the status that will be sent for the request. When the
+// handler returned an error the response is not written yet, so the status
+// is derived from the error the same way echo's error handler does.
+func statusOf(c echo.Context, err error) int {
+	if err == nil || c.Response().Committed {
+		return c.Response().Status
+	}
+	var he *echo.HTTPError
+	if errors.As(err, &he) {
+		return he.Code
+	}
+	return http.StatusInternalServerError
+}
+
+// TracingMiddleware starts a server span for every request, continuing any
+// trace propagated by the caller, and stores it in the request context so
+// use-case and repository spans become its children.
+func TracingMiddleware(tp trace.TracerProvider) echo.MiddlewareFunc {
+	tracer := tp.Tracer("eve/internal/delivery/http")
+	propagator := otel.GetTextMapPropagator()
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			ctx := propagator.Extract(req.Context(), propagation.HeaderCarrier(req.Header))
+
+			route := routeOf(c)
+			ctx, span := tracer.Start(ctx, req.Method+" "+route,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					attribute.String("http.request.method", req.Method),
+					attribute.String("http.route", route),
+					attribute.String("url.path", req.URL.Path),
+				),
+			)
+			defer span.End()
+
+			c.SetRequest(req.WithContext(ctx))
+
+			err := next(c)
+			status := statusOf(c, err)
+			span.SetAttributes(attribute.Int("http.response.status_code", status))
+			if status >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(status))
+			}
+			if err != nil {
+				span.RecordError(err)
+			}
+			return err
+		}
+	}
+}
+
+// TimeoutMiddleware bounds every request with a deadline of d. The deadline is
+// carried by the request context down to the repositories, so a slow query is
+// cancelled instead of outliving the request.
+func TimeoutMiddleware(d time.Duration) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			ctx, cancel := context.WithTimeout(c.Request().Context(), d)
+			defer cancel()
+
+			c.SetRequest(c.Request().WithContext(ctx))
+			return next(c)
+		}
+	}
+}
+
+// maxRequestIDLen caps the length of a client supplied X-Request-ID.
+const maxRequestIDLen = 128
+
+// RequestIDMiddleware honours an incoming X-Request-ID header (or generates a
+// new ID), echoes it back in the response and stores it in the request context
+// for loggers and error responses.
+func RequestIDMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			id := c.Request().Header.Get(echo.HeaderXRequestID)
+			if !validRequestID(id) {
+				id = newRequestID()
+			}
+
+			c.Response().Header().Set(echo.HeaderXRequestID, id)
+			c.SetRequest(c.Request().WithContext(logging.WithRequestID(c.Request().Context(), id)))
+			return next(c)
+		}
+	}
+}
+
+// validRequestID reports whether id is safe to propagate into logs and headers.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+	for _, r := range id {
+		if r < '!' || r > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// AccessLogMiddleware writes one structured line per request with the route,
+// status, latency and the authenticated user (if any).
+func AccessLogMiddleware(log *slog.Logger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+			err := next(c)
+			if err != nil {
+				// Let the error handler write the response first so the
+				// logged status and size match what the client received.
+				c.Error(err)
+			}
+
+			req := c.Request()
+			attrs := []slog.Attr{
+				slog.String("method", req.Method),
+				slog.String("route", routeOf(c)),
+				slog.String("path", req.URL.Path),
+				slog.Int("status", c.Response().Status),
+				slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+				slog.Int64("bytes_out", c.Response().Size),
+				slog.String("remote_ip", c.RealIP()),
+			}
+			if userID, uerr := extractUserID(c); uerr == nil {
+				attrs = append(attrs, slog.Int("user_id", userID))
+			}
+			log.LogAttrs(req.Context(), slog.LevelInfo, "http request", attrs...)
+			return nil
+		}
+	}
+}
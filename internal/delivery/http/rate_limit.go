@@ -0,0 +1,156 @@
+package httpDelivery
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"eve/internal/usecase"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RateLimitRule limits one route per client IP and, for authenticated
+// requests, also per user. A zero RateLimit does not limit.
+type RateLimitRule struct {
+	User usecase.RateLimit
+	IP   usecase.RateLimit
+}
+
+// RateLimitRules maps routes such as "POST /reviews/:id/comments" to their
+// limits; routes without a rule are not limited.
+type RateLimitRules map[string]RateLimitRule
+
+// ParseRateLimits parses rules separated by ";". A rule is a method, a route
+// pattern and one or both of "user=N/PERIOD" and "ip=N/PERIOD", where PERIOD
+// is a Go duration, e.g.
+//
+//	POST /user ip=5/1m; POST /reviews user=10/1m ip=30/1m
+func ParseRateLimits(spec string) (RateLimitRules, error) {
+	rules := make(RateLimitRules)
+	for _, entry := range strings.Split(spec, ";") {
+		fields := strings.Fields(entry)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) < 3 {
+			return nil, fmt.Errorf("rate limit %q: want METHOD /route user=N/PERIOD and/or ip=N/PERIOD", strings.TrimSpace(entry))
+		}
+		route := strings.ToUpper(fields[0]) + " " + fields[1]
+		var rule RateLimitRule
+		for _, f := range fields[2:] {
+			who, value, _ := strings.Cut(f, "=")
+			limit, err := parseRateLimit(value)
+			if err != nil {
+				return nil, fmt.Errorf("rate limit %q: %w", route, err)
+			}
+			switch who {
+			case "user":
+				rule.User = limit
+			case "ip":
+				rule.IP = limit
+			default:
+				return nil, fmt.Errorf("rate limit %q: unknown caller %q, want user or ip", route, who)
+			}
+		}
+		rules[route] = rule
+	}
+	return rules, nil
+}
+
+// parseRateLimit parses "N/PERIOD", e.g. "10/1m".
+func parseRateLimit(s string) (usecase.RateLimit, error) {
+	n, period, ok := strings.Cut(s, "/")
+	if !ok {
+		return usecase.RateLimit{}, fmt.Errorf("limit %q: want N/PERIOD", s)
+	}
+	burst, err := strconv.Atoi(n)
+	if err != nil || burst <= 0 {
+		return usecase.RateLimit{}, fmt.Errorf("limit %q: want a positive number of requests", s)
+	}
+	d, err := time.ParseDuration(period)
+	if err != nil || d <= 0 {
+		return usecase.RateLimit{}, fmt.Errorf("limit %q: want a positive period", s)
+	}
+	limit := usecase.RateLimit{Burst: burst, Period: d}
+	if limit.Interval() < time.Millisecond {
+		return usecase.RateLimit{}, fmt.Errorf("limit %q: more than one request per millisecond", s)
+	}
+	return limit, nil
+}
+
+// RateLimitMiddleware throttles the routes that have a rule with token
+// buckets per route: one per client IP, which every request takes from, and
+// one per user, which authenticated requests take from as well. Every limited
+// response carries RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset
+// (seconds until the bucket is full) of the bucket closest to running out;
+// refused requests get 429 with Retry-After. When the limiter fails requests
+// are let through, so that an outage of its store does not take the API down.
+func RateLimitMiddleware(limiter usecase.RateLimiter, rules RateLimitRules, log *slog.Logger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			route := c.Request().Method + " " + routeOf(c)
+			rule, ok := rules[route]
+			if !ok {
+				return next(c)
+			}
+
+			buckets := []rateLimitBucket{{key: route + " ip:" + c.RealIP(), limit: rule.IP}}
+			if userID, err := extractUserID(c); err == nil {
+				buckets = append(buckets, rateLimitBucket{key: route + " user:" + strconv.Itoa(userID), limit: rule.User})
+			}
+
+			ctx := c.Request().Context()
+			var shown *rateLimitBucket
+			for i := range buckets {
+				b := &buckets[i]
+				if b.limit.Burst == 0 {
+					continue
+				}
+				res, err := limiter.Take(ctx, b.key, b.limit)
+				if err != nil {
+					log.WarnContext(ctx, "rate limiter unavailable", slog.String("route", route), slog.String("error", err.Error()))
+					return next(c)
+				}
+				b.res = res
+				if shown == nil || !res.Allowed || res.Remaining < shown.res.Remaining {
+					shown = b
+				}
+				if !res.Allowed {
+					break
+				}
+			}
+			if shown == nil {
+				return next(c)
+			}
+
+			h := c.Response().Header()
+			h.Set("RateLimit-Limit", strconv.Itoa(shown.limit.Burst))
+			h.Set("RateLimit-Remaining", strconv.Itoa(shown.res.Remaining))
+			h.Set("RateLimit-Reset", ceilSeconds(shown.res.ResetAfter))
+			h.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%s", shown.limit.Burst, ceilSeconds(shown.limit.Period)))
+			if !shown.res.Allowed {
+				h.Set("Retry-After", ceilSeconds(shown.res.RetryAfter))
+				return respondError(c, log, http.StatusTooManyRequests, "rate limit exceeded; retry later")
+			}
+			return next(c)
+		}
+	}
+}
+
+// rateLimitBucket is a bucket a request takes a token from and the outcome.
+type rateLimitBucket struct {
+	key   string
+	limit usecase.RateLimit
+	res   usecase.RateLimitResult
+}
+
+// ceilSeconds renders d as whole seconds, rounded up so that clients waiting
+// that long are not refused again.
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
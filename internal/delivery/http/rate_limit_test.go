@@ -0,0 +1,137 @@
+package httpDelivery_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	httpDelivery "eve/internal/delivery/http"
+	"eve/internal/infrastructure"
+	"eve/internal/logging"
+	"eve/internal/repository/memory"
+	"eve/internal/usecase"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestParseRateLimits(t *testing.T) {
+	rules, err := httpDelivery.ParseRateLimits("POST /reviews user=10/1m ip=2/1m; post /user ip=5/1h;")
+	if err != nil {
+		t.Fatalf("ParseRateLimits() error = %v", err)
+	}
+	want := httpDelivery.RateLimitRules{
+		"POST /reviews": {User: usecase.RateLimit{Burst: 10, Period: time.Minute}, IP: usecase.RateLimit{Burst: 2, Period: time.Minute}},
+		"POST /user":    {IP: usecase.RateLimit{Burst: 5, Period: time.Hour}},
+	}
+	if len(rules) != len(want) || rules["POST /reviews"] != want["POST /reviews"] || rules["POST /user"] != want["POST /user"] {
+		t.Errorf("ParseRateLimits() = %+v, want %+v", rules, want)
+	}
+
+	for _, spec := range []string{
+		"POST /reviews",
+		"POST /reviews admin=1/1m",
+		"POST /reviews anonymous=1/1m",
+		"POST /reviews user=0/1m",
+		"POST /reviews user=10",
+		"POST /reviews user=10/soon",
+		"POST /reviews user=10000/1s",
+	} {
+		if _, err := httpDelivery.ParseRateLimits(spec); err == nil {
+			t.Errorf("ParseRateLimits(%q) succeeded, want an error", spec)
+		}
+	}
+}
+
+// failingLimiter is a RateLimiter whose store is down.
+type failingLimiter struct{}
+
+func (failingLimiter) Take(context.Context, string, usecase.RateLimit) (usecase.RateLimitResult, error) {
+	return usecase.RateLimitResult{}, errors.New("connection refused")
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	newServer := func(limiter usecase.RateLimiter, trustedProxies string) *echo.Echo {
+		rules, err := httpDelivery.ParseRateLimits("POST /things/:id user=2/1m ip=3/1m")
+		if err != nil {
+			t.Fatalf("ParseRateLimits() error = %v", err)
+		}
+		e := echo.New()
+		if e.IPExtractor, err = httpDelivery.IPExtractor(trustedProxies); err != nil {
+			t.Fatalf("IPExtractor() error = %v", err)
+		}
+		e.Use(httpDelivery.AuthMiddleware(
+			usecase.NewAuthenticateAPIKeyUseCase(memory.NewAPIKeyRepo(), usecase.NopMetrics{}, logging.Nop()),
+			usecase.NewAuthenticateSessionUseCase(testSessionPolicy, usecase.NopMetrics{}, logging.Nop()),
+			true,
+			logging.Nop(),
+		))
+		e.Use(httpDelivery.RateLimitMiddleware(limiter, rules, logging.Nop()))
+		ok := func(c echo.Context) error { return c.NoContent(http.StatusCreated) }
+		e.POST("/things/:id", ok)
+		e.GET("/things/:id", ok)
+		return e
+	}
+	// httptest requests come from 192.0.2.1, which plays the proxy.
+	e := newServer(infrastructure.NewMemoryRateLimiter(), "192.0.2.1/32")
+	alice := map[string]string{"X-User-ID": "1", "X-Forwarded-For": "198.51.100.1"}
+
+	// The user's bucket is closer to running out than the IP's, so its
+	// headers are shown.
+	rec := do(e, http.MethodPost, "/things/1", "", alice)
+	if rec.Code != http.StatusCreated || rec.Header().Get("RateLimit-Limit") != "2" || rec.Header().Get("RateLimit-Remaining") != "1" ||
+		rec.Header().Get("RateLimit-Reset") != "30" || rec.Header().Get("RateLimit-Policy") != "2;w=60" {
+		t.Errorf("first request = %d with headers %v", rec.Code, rec.Header())
+	}
+	// The bucket is per route pattern, not per path.
+	if rec := do(e, http.MethodPost, "/things/2", "", alice); rec.Code != http.StatusCreated || rec.Header().Get("RateLimit-Remaining") != "0" {
+		t.Errorf("second request = %d, remaining %q", rec.Code, rec.Header().Get("RateLimit-Remaining"))
+	}
+	rec = do(e, http.MethodPost, "/things/3", "", alice)
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") != "30" || rec.Header().Get("RateLimit-Remaining") != "0" {
+		t.Errorf("third request = %d, Retry-After %q", rec.Code, rec.Header().Get("Retry-After"))
+	}
+	if got := decode[map[string]string](t, rec); got["error"] == "" {
+		t.Errorf("429 body = %v, want an error", got)
+	}
+
+	// Other users from the same IP share its bucket, which alice emptied.
+	rec = do(e, http.MethodPost, "/things/1", "", map[string]string{"X-User-ID": "2", "X-Forwarded-For": "198.51.100.1"})
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("RateLimit-Limit") != "3" || rec.Header().Get("Retry-After") != "20" {
+		t.Errorf("other user from the same IP = %d with limit %q, Retry-After %q; want the IP's bucket", rec.Code, rec.Header().Get("RateLimit-Limit"), rec.Header().Get("Retry-After"))
+	}
+	if rec := do(e, http.MethodPost, "/things/1", "", map[string]string{"X-User-ID": "2", "X-Forwarded-For": "198.51.100.2"}); rec.Code != http.StatusCreated {
+		t.Errorf("other user from another IP status = %d, want its own buckets", rec.Code)
+	}
+	if rec := do(e, http.MethodGet, "/things/1", "", alice); rec.Code != http.StatusCreated || rec.Header().Get("RateLimit-Limit") != "" {
+		t.Errorf("unlimited route = %d with RateLimit-Limit %q", rec.Code, rec.Header().Get("RateLimit-Limit"))
+	}
+
+	anonymous := map[string]string{"X-Forwarded-For": "203.0.113.7"}
+	for i := range 3 {
+		if rec := do(e, http.MethodPost, "/things/1", "", anonymous); rec.Code != http.StatusCreated || rec.Header().Get("RateLimit-Limit") != "3" {
+			t.Errorf("anonymous request #%d = %d with RateLimit-Limit %q", i+1, rec.Code, rec.Header().Get("RateLimit-Limit"))
+		}
+	}
+	if rec := do(e, http.MethodPost, "/things/1", "", anonymous); rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") != "20" {
+		t.Errorf("fourth anonymous request = %d, Retry-After %q", rec.Code, rec.Header().Get("Retry-After"))
+	}
+
+	// Without trusted proxies X-Forwarded-For is ignored, so clients cannot
+	// pick a fresh IP for every request.
+	direct := newServer(infrastructure.NewMemoryRateLimiter(), "")
+	for i, ip := range []string{"203.0.113.1", "203.0.113.2", "203.0.113.3", "203.0.113.4"} {
+		want := http.StatusCreated
+		if i == 3 {
+			want = http.StatusTooManyRequests
+		}
+		if rec := do(direct, http.MethodPost, "/things/1", "", map[string]string{"X-Forwarded-For": ip}); rec.Code != want {
+			t.Errorf("request #%d claiming %s status = %d, want %d", i+1, ip, rec.Code, want)
+		}
+	}
+
+	if rec := do(newServer(failingLimiter{}, ""), http.MethodPost, "/things/1", "", alice); rec.Code != http.StatusCreated {
+		t.Errorf("limiter down status = %d, want the request let through", rec.Code)
+	}
+}
@@ -0,0 +1,81 @@
+package httpDelivery
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"eve/domain"
+	"eve/internal/usecase"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RatingHandler holds use-cases for rating criteria and rating summaries.
+type RatingHandler struct {
+	setCriteria  *usecase.SetCriteriaUseCase
+	listCriteria *usecase.ListCriteriaUseCase
+	summary      *usecase.GetReviewSummaryUseCase
+	log          *slog.Logger
+}
+
+// NewRatingHandler constructs a RatingHandler.
+func NewRatingHandler(
+	sc *usecase.SetCriteriaUseCase,
+	lc *usecase.ListCriteriaUseCase,
+	gs *usecase.GetReviewSummaryUseCase,
+	l *slog.Logger,
+) *RatingHandler {
+	return &RatingHandler{
+		setCriteria:  sc,
+		listCriteria: lc,
+		summary:      gs,
+		log:          l,
+	}
+}
+
+// ListCriteria handles GET /reviewable-types/:type/criteria
+func (h *RatingHandler) ListCriteria(c echo.Context) error {
+	criteria, err := h.listCriteria.Execute(c.Request().Context(), c.Param("type"))
+	if err != nil {
+		return respondError(c, h.log, errorStatus(err, http.StatusInternalServerError), err.Error())
+	}
+
+	return c.JSON(http.StatusOK, criteria)
+}
+
+// SetCriteria handles PUT /reviewable-types/:type/criteria
+// Expects JSON body matching domain.SetCriteriaRequest and the credentials of an admin.
+func (h *RatingHandler) SetCriteria(c echo.Context) error {
+	var req domain.SetCriteriaRequest
+	if err := c.Bind(&req); err != nil {
+		return respondError(c, h.log, http.StatusBadRequest, "invalid request body: "+err.Error())
+	}
+
+	userID, err := extractUserID(c)
+	if err != nil {
+		return respondError(c, h.log, http.StatusUnauthorized, err.Error())
+	}
+
+	criteria, err := h.setCriteria.Execute(c.Request().Context(), c.Param("type"), req, userID)
+	if err != nil {
+		return respondError(c, h.log, errorStatus(err, http.StatusInternalServerError), err.Error())
+	}
+
+	return c.JSON(http.StatusOK, criteria)
+}
+
+// GetSummary handles GET /reviewables/:type/:id/summary
+func (h *RatingHandler) GetSummary(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return respondError(c, h.log, http.StatusBadRequest, "invalid id")
+	}
+
+	summary, err := h.summary.Execute(c.Request().Context(), c.Param("type"), id)
+	if err != nil {
+		return respondError(c, h.log, errorStatus(err, http.StatusInternalServerError), err.Error())
+	}
+
+	return c.JSON(http.StatusOK, summary)
+}
@@ -0,0 +1,67 @@
+package httpDelivery_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"eve/domain"
+)
+
+func TestRatingHandlerFlow(t *testing.T) {
+	e, users := newTestServerWithUsers()
+	for _, email := range []string{"admin@example.com", "guest@example.com"} {
+		if rec := do(e, http.MethodPost, "/user", fmt.Sprintf(`{"email":%q,"password":"pw"}`, email), nil); rec.Code != http.StatusCreated {
+			t.Fatalf("create user status = %d (body %s)", rec.Code, rec.Body)
+		}
+	}
+	users.SetRole(1, domain.RoleAdmin)
+	admin := map[string]string{"X-User-ID": "1"}
+	guest := map[string]string{"X-User-ID": "2"}
+
+	criteria := `{"criteria":[{"key":"cleanliness","label":"Cleanliness","required":true},{"key":"location","label":"Location"}]}`
+	if rec := do(e, http.MethodPut, "/reviewable-types/hotel/criteria", criteria, guest); rec.Code != http.StatusForbidden {
+		t.Errorf("guest set criteria status = %d, want 403", rec.Code)
+	}
+	if rec := do(e, http.MethodPut, "/reviewable-types/hotel/criteria", criteria, admin); rec.Code != http.StatusOK {
+		t.Fatalf("set criteria status = %d (body %s)", rec.Code, rec.Body)
+	}
+	rec := do(e, http.MethodGet, "/reviewable-types/hotel/criteria", "", nil)
+	if got := decode[[]domain.RatingCriterion](t, rec); len(got) != 2 || got[0].Key != "cleanliness" || !got[0].Required {
+		t.Fatalf("criteria = %+v", got)
+	}
+
+	for _, tc := range []struct {
+		body string
+		want int
+	}{
+		{`{"reviewable_type":"hotel","reviewable_id":7,"rating":5,"sub_ratings":{"cleanliness":5,"location":4}}`, http.StatusCreated},
+		{`{"reviewable_type":"hotel","reviewable_id":7,"rating":3,"sub_ratings":{"cleanliness":2}}`, http.StatusCreated},
+		{`{"reviewable_type":"hotel","reviewable_id":7,"rating":3,"sub_ratings":{"location":2}}`, http.StatusBadRequest},
+		{`{"reviewable_type":"hotel","reviewable_id":7,"rating":3,"sub_ratings":{"cleanliness":2,"wifi":1}}`, http.StatusBadRequest},
+	} {
+		if rec := do(e, http.MethodPost, "/reviews", tc.body, guest); rec.Code != tc.want {
+			t.Errorf("create review %s status = %d, want %d (body %s)", tc.body, rec.Code, tc.want, rec.Body)
+		}
+	}
+
+	rec = do(e, http.MethodGet, "/reviews?reviewable_type=hotel&reviewable_id=7", "", nil)
+	if got := decode[[]domain.Review](t, rec); len(got) != 2 || got[1].SubRatings["location"] != 4 {
+		t.Errorf("listed reviews = %+v, want sub-ratings attached", got)
+	}
+
+	rec = do(e, http.MethodGet, "/reviewables/hotel/7/summary", "", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("summary status = %d (body %s)", rec.Code, rec.Body)
+	}
+	summary := decode[domain.ReviewSummary](t, rec)
+	if summary.Count != 2 || summary.AverageRating != 4 || len(summary.Criteria) != 2 {
+		t.Fatalf("summary = %+v", summary)
+	}
+	if c := summary.Criteria[0]; c.Key != "cleanliness" || c.Label != "Cleanliness" || c.Count != 2 || c.Average != 3.5 {
+		t.Errorf("cleanliness average = %+v", c)
+	}
+	if rec := do(e, http.MethodGet, "/reviewables/hotel/x/summary", "", nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("invalid id summary status = %d, want 400", rec.Code)
+	}
+}
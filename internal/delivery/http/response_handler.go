@@ -0,0 +1,158 @@
+package httpDelivery
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"eve/domain"
+	"eve/internal/usecase"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ResponseHandler holds use-cases for reviewable ownership and official responses.
+type ResponseHandler struct {
+	addOwner     *usecase.AddOwnerUseCase
+	removeOwner  *usecase.RemoveOwnerUseCase
+	listOwners   *usecase.ListOwnersUseCase
+	saveResponse *usecase.SaveResponseUseCase
+	listVersions *usecase.ListResponseVersionsUseCase
+	log          *slog.Logger
+}
+
+// NewResponseHandler constructs a ResponseHandler.
+func NewResponseHandler(
+	ao *usecase.AddOwnerUseCase,
+	ro *usecase.RemoveOwnerUseCase,
+	lo *usecase.ListOwnersUseCase,
+	sr *usecase.SaveResponseUseCase,
+	lv *usecase.ListResponseVersionsUseCase,
+	l *slog.Logger,
+) *ResponseHandler {
+	return &ResponseHandler{
+		addOwner:     ao,
+		removeOwner:  ro,
+		listOwners:   lo,
+		saveResponse: sr,
+		listVersions: lv,
+		log:          l,
+	}
+}
+
+// ListOwners handles GET /reviewables/:type/:id/owners
+func (h *ResponseHandler) ListOwners(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return respondError(c, h.log, http.StatusBadRequest, "invalid id")
+	}
+
+	owners, err := h.listOwners.Execute(c.Request().Context(), c.Param("type"), id)
+	if err != nil {
+		return respondError(c, h.log, errorStatus(err, http.StatusInternalServerError), err.Error())
+	}
+
+	return c.JSON(http.StatusOK, owners)
+}
+
+// AddOwner handles PUT /reviewables/:type/:id/owners/:user_id
+// Requires the credentials of an admin.
+func (h *ResponseHandler) AddOwner(c echo.Context) error {
+	owner, err := ownerFromPath(c)
+	if err != nil {
+		return respondError(c, h.log, http.StatusBadRequest, err.Error())
+	}
+
+	actorID, err := extractUserID(c)
+	if err != nil {
+		return respondError(c, h.log, http.StatusUnauthorized, err.Error())
+	}
+
+	if err := h.addOwner.Execute(c.Request().Context(), owner, actorID); err != nil {
+		return respondError(c, h.log, errorStatus(err, http.StatusInternalServerError), err.Error())
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// RemoveOwner handles DELETE /reviewables/:type/:id/owners/:user_id
+// Requires the credentials of an admin.
+func (h *ResponseHandler) RemoveOwner(c echo.Context) error {
+	owner, err := ownerFromPath(c)
+	if err != nil {
+		return respondError(c, h.log, http.StatusBadRequest, err.Error())
+	}
+
+	actorID, err := extractUserID(c)
+	if err != nil {
+		return respondError(c, h.log, http.StatusUnauthorized, err.Error())
+	}
+
+	err = h.removeOwner.Execute(c.Request().Context(), owner.ReviewableType, owner.ReviewableID, owner.UserID, actorID)
+	if err != nil {
+		return respondError(c, h.log, errorStatus(err, http.StatusInternalServerError), err.Error())
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// SaveResponse handles PUT /reviews/:id/response
+// Expects JSON body matching domain.SaveResponseRequest from an owner of
+// the reviewed entity. Creates the response or edits it.
+func (h *ResponseHandler) SaveResponse(c echo.Context) error {
+	reviewID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return respondError(c, h.log, http.StatusBadRequest, "invalid id")
+	}
+
+	var req domain.SaveResponseRequest
+	if err := c.Bind(&req); err != nil {
+		return respondError(c, h.log, http.StatusBadRequest, "invalid request body: "+err.Error())
+	}
+
+	userID, err := extractUserID(c)
+	if err != nil {
+		return respondError(c, h.log, http.StatusUnauthorized, err.Error())
+	}
+
+	resp, err := h.saveResponse.Execute(c.Request().Context(), reviewID, req, userID)
+	if err != nil {
+		return respondError(c, h.log, errorStatus(err, http.StatusInternalServerError), err.Error())
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// ListResponseVersions handles GET /reviews/:id/response/versions
+// Returns every saved version of the official response, oldest first.
+// Like GetReview, a review awaiting moderation is 404 unless the request is
+// authenticated as its author, a moderator or an admin.
+func (h *ResponseHandler) ListResponseVersions(c echo.Context) error {
+	reviewID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return respondError(c, h.log, http.StatusBadRequest, "invalid id")
+	}
+
+	// Anonymous callers see the responses to published reviews only.
+	viewerID, _ := extractUserID(c)
+	versions, err := h.listVersions.Execute(c.Request().Context(), reviewID, viewerID)
+	if err != nil {
+		return respondError(c, h.log, errorStatus(err, http.StatusInternalServerError), err.Error())
+	}
+
+	return c.JSON(http.StatusOK, versions)
+}
+
+// ownerFromPath reads the :type, :id and :user_id path parameters.
+func ownerFromPath(c echo.Context) (domain.ReviewableOwner, error) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return domain.ReviewableOwner{}, errors.New("invalid id")
+	}
+	userID, err := strconv.Atoi(c.Param("user_id"))
+	if err != nil {
+		return domain.ReviewableOwner{}, errors.New("invalid user_id")
+	}
+	return domain.ReviewableOwner{ReviewableType: c.Param("type"), ReviewableID: id, UserID: userID}, nil
+}
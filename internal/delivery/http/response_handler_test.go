@@ -0,0 +1,82 @@
+package httpDelivery_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"eve/domain"
+)
+
+func TestResponseHandlerFlow(t *testing.T) {
+	e, users := newTestServerWithUsers()
+	for _, email := range []string{"admin@example.com", "vendor@example.com", "author@example.com"} {
+		if rec := do(e, http.MethodPost, "/user", fmt.Sprintf(`{"email":%q,"password":"pw"}`, email), nil); rec.Code != http.StatusCreated {
+			t.Fatalf("create user status = %d (body %s)", rec.Code, rec.Body)
+		}
+	}
+	users.SetRole(1, domain.RoleAdmin)
+	admin := map[string]string{"X-User-ID": "1"}
+	vendor := map[string]string{"X-User-ID": "2"}
+
+	rec := do(e, http.MethodPost, "/reviews", `{"reviewable_type":"product","reviewable_id":42,"rating":2,"title":"Broke"}`,
+		map[string]string{"X-User-ID": "3"})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create review status = %d (body %s)", rec.Code, rec.Body)
+	}
+	reviewID := decode[map[string]int](t, rec)["id"]
+	respond := fmt.Sprintf("/reviews/%d/response", reviewID)
+
+	if rec := do(e, http.MethodPut, respond, `{"body":"Sorry!"}`, vendor); rec.Code != http.StatusForbidden {
+		t.Fatalf("respond before ownership status = %d, want 403", rec.Code)
+	}
+	if rec := do(e, http.MethodPut, "/reviewables/product/42/owners/2", "", vendor); rec.Code != http.StatusForbidden {
+		t.Fatalf("self-grant ownership status = %d, want 403", rec.Code)
+	}
+	if rec := do(e, http.MethodPut, "/reviewables/product/42/owners/2", "", admin); rec.Code != http.StatusNoContent {
+		t.Fatalf("grant ownership status = %d (body %s)", rec.Code, rec.Body)
+	}
+
+	rec = do(e, http.MethodGet, "/reviewables/product/42/owners", "", nil)
+	if owners := decode[[]domain.ReviewableOwner](t, rec); len(owners) != 1 || owners[0].UserID != 2 {
+		t.Fatalf("owners = %+v, want user 2", owners)
+	}
+
+	for _, body := range []string{"Sorry!", "Sorry, replacement sent."} {
+		rec := do(e, http.MethodPut, respond, fmt.Sprintf(`{"body":%q}`, body), vendor)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("respond status = %d (body %s)", rec.Code, rec.Body)
+		}
+		if got := decode[domain.ReviewResponse](t, rec); got.Body != body || got.UserID != 2 {
+			t.Errorf("response = %+v, want %q by 2", got, body)
+		}
+	}
+
+	rec = do(e, http.MethodGet, fmt.Sprintf("/reviews/%d", reviewID), "", nil)
+	got := decode[struct {
+		Review domain.Review `json:"review"`
+	}](t, rec)
+	if got.Review.Response == nil || got.Review.Response.Body != "Sorry, replacement sent." {
+		t.Errorf("GET review response = %+v", got.Review.Response)
+	}
+
+	rec = do(e, http.MethodGet, "/reviews?reviewable_type=product&reviewable_id=42", "", nil)
+	if list := decode[[]domain.Review](t, rec); len(list) != 1 || list[0].Response == nil {
+		t.Errorf("listed reviews = %+v, want response attached", list)
+	}
+
+	rec = do(e, http.MethodGet, respond+"/versions", "", nil)
+	if versions := decode[[]domain.ReviewResponseVersion](t, rec); len(versions) != 2 || versions[0].Body != "Sorry!" {
+		t.Errorf("versions = %+v, want both saves oldest first", versions)
+	}
+
+	if rec := do(e, http.MethodDelete, "/reviewables/product/42/owners/2", "", admin); rec.Code != http.StatusNoContent {
+		t.Fatalf("revoke ownership status = %d (body %s)", rec.Code, rec.Body)
+	}
+	if rec := do(e, http.MethodDelete, "/reviewables/product/42/owners/2", "", admin); rec.Code != http.StatusNotFound {
+		t.Errorf("revoke twice status = %d, want 404", rec.Code)
+	}
+	if rec := do(e, http.MethodPut, respond, `{"body":"again"}`, vendor); rec.Code != http.StatusForbidden {
+		t.Errorf("respond after revoke status = %d, want 403", rec.Code)
+	}
+}
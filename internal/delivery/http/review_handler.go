@@ -1,8 +1,10 @@
 package httpDelivery
 
 import (
+	"log/slog"
 	"net/http"
 	"strconv"
+	"time"
 
 	"eve/domain"
 	"eve/internal/usecase"
@@ -16,6 +18,8 @@ type ReviewHandler struct {
 	createComment *usecase.CreateCommentUseCase
 	listReviews   *usecase.ListReviewsUseCase
 	getReview     *usecase.GetReviewUseCase
+	search        *usecase.SearchReviewsUseCase
+	log           *slog.Logger
 }
 
 // NewReviewHandler constructs a ReviewHandler.
@@ -24,92 +28,147 @@ func NewReviewHandler(
 	cc *usecase.CreateCommentUseCase,
 	lr *usecase.ListReviewsUseCase,
 	gr *usecase.GetReviewUseCase,
+	sr *usecase.SearchReviewsUseCase,
+	l *slog.Logger,
 ) *ReviewHandler {
 	return &ReviewHandler{
 		createReview:  cr,
 		createComment: cc,
 		listReviews:   lr,
 		getReview:     gr,
+		search:        sr,
+		log:           l,
 	}
 }
 
 // CreateReview handles POST /reviews
-// Expects JSON body matching domain.CreateReviewRequest and an "X-User-ID" header.
+// Expects JSON body matching domain.CreateReviewRequest from an authenticated user.
 func (h *ReviewHandler) CreateReview(c echo.Context) error {
 	var req domain.CreateReviewRequest
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body: " + err.Error()})
+		return respondError(c, h.log, http.StatusBadRequest, "invalid request body: "+err.Error())
 	}
 
 	userID, err := extractUserID(c)
 	if err != nil {
-		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+		return respondError(c, h.log, http.StatusUnauthorized, err.Error())
 	}
 
-	id, err := h.createReview.Execute(req, userID)
+	id, err := h.createReview.Execute(c.Request().Context(), req, userID)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return respondError(c, h.log, errorStatus(err, http.StatusInternalServerError), err.Error())
 	}
 
 	return c.JSON(http.StatusCreated, map[string]int{"id": id})
 }
 
 // CreateComment handles POST /reviews/comments
-// Expects JSON body matching domain.CreateCommentRequest and an "X-User-ID" header.
+// Expects JSON body matching domain.CreateCommentRequest from an authenticated user.
 func (h *ReviewHandler) CreateComment(c echo.Context) error {
 	var req domain.CreateCommentRequest
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body: " + err.Error()})
+		return respondError(c, h.log, http.StatusBadRequest, "invalid request body: "+err.Error())
 	}
 
 	userID, err := extractUserID(c)
 	if err != nil {
-		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+		return respondError(c, h.log, http.StatusUnauthorized, err.Error())
 	}
 
-	id, err := h.createComment.Execute(req, userID)
+	id, err := h.createComment.Execute(c.Request().Context(), req, userID)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return respondError(c, h.log, errorStatus(err, http.StatusInternalServerError), err.Error())
 	}
 
 	return c.JSON(http.StatusCreated, map[string]int{"id": id})
 }
 
-// ListReviews handles GET /reviews?reviewable_type=...&reviewable_id=...
+// ReplyToComment handles POST /reviews/:id/comments/:cid/replies
+// Expects a JSON body with "body" from an authenticated user.
+func (h *ReviewHandler) ReplyToComment(c echo.Context) error {
+	reviewID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return respondError(c, h.log, http.StatusBadRequest, "invalid id")
+	}
+	parentID, err := strconv.Atoi(c.Param("cid"))
+	if err != nil {
+		return respondError(c, h.log, http.StatusBadRequest, "invalid cid")
+	}
+
+	var req domain.CreateCommentRequest
+	if err := c.Bind(&req); err != nil {
+		return respondError(c, h.log, http.StatusBadRequest, "invalid request body: "+err.Error())
+	}
+	req.ReviewID, req.ParentID = reviewID, parentID
+
+	userID, err := extractUserID(c)
+	if err != nil {
+		return respondError(c, h.log, http.StatusUnauthorized, err.Error())
+	}
+
+	id, err := h.createComment.Execute(c.Request().Context(), req, userID)
+	if err != nil {
+		return respondError(c, h.log, errorStatus(err, http.StatusInternalServerError), err.Error())
+	}
+
+	return c.JSON(http.StatusCreated, map[string]int{"id": id})
+}
+
+// ListReviews handles GET /reviews?reviewable_type=...&reviewable_id=...[&verified=true]
+// verified=true lists only reviews by verified buyers.
 func (h *ReviewHandler) ListReviews(c echo.Context) error {
 	rt := c.QueryParam("reviewable_type")
 	ridStr := c.QueryParam("reviewable_id")
 	if rt == "" || ridStr == "" {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "reviewable_type and reviewable_id query params are required"})
+		return respondError(c, h.log, http.StatusBadRequest, "reviewable_type and reviewable_id query params are required")
 	}
 	rid, err := strconv.Atoi(ridStr)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid reviewable_id"})
+		return respondError(c, h.log, http.StatusBadRequest, "invalid reviewable_id")
+	}
+	var verifiedOnly bool
+	if v := c.QueryParam("verified"); v != "" {
+		if verifiedOnly, err = strconv.ParseBool(v); err != nil {
+			return respondError(c, h.log, http.StatusBadRequest, "invalid verified")
+		}
 	}
 
-	reviews, err := h.listReviews.Execute(rt, rid)
+	reviews, err := h.listReviews.Execute(c.Request().Context(), rt, rid, verifiedOnly)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return respondError(c, h.log, errorStatus(err, http.StatusInternalServerError), err.Error())
+	}
+	// No Last-Modified: deleting a review changes the list without making
+	// any listed review more recent.
+	if notModified(c, listETag(reviews), time.Time{}) {
+		return c.NoContent(http.StatusNotModified)
 	}
 
 	return c.JSON(http.StatusOK, reviews)
 }
 
 // GetReview handles GET /reviews/:id
-// Returns the review and its comments.
+// Returns the review and its comments in thread order (see GetReviewUseCase),
+// or 304 when If-None-Match or If-Modified-Since show the client's copy is current.
+// A review awaiting moderation is 404 unless the request is
+// authenticated as its author, a moderator or an admin.
 func (h *ReviewHandler) GetReview(c echo.Context) error {
 	idStr := c.Param("id")
 	if idStr == "" {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "id path parameter is required"})
+		return respondError(c, h.log, http.StatusBadRequest, "id path parameter is required")
 	}
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+		return respondError(c, h.log, http.StatusBadRequest, "invalid id")
 	}
 
-	review, comments, err := h.getReview.Execute(id)
+	// Anonymous callers see published reviews only.
+	viewerID, _ := extractUserID(c)
+	review, comments, err := h.getReview.Execute(c.Request().Context(), id, viewerID)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return respondError(c, h.log, errorStatus(err, http.StatusInternalServerError), err.Error())
+	}
+	if notModified(c, reviewETag(review, comments), reviewLastModified(review, comments)) {
+		return c.NoContent(http.StatusNotModified)
 	}
 
 	return c.JSON(http.StatusOK, map[string]any{
@@ -118,16 +177,46 @@ func (h *ReviewHandler) GetReview(c echo.Context) error {
 	})
 }
 
-// extractUserID reads the X-User-ID header and returns it as an int.
-// If header missing or invalid, returns an error.
-func extractUserID(c echo.Context) (int, error) {
-	h := c.Request().Header.Get("X-User-ID")
-	if h == "" {
-		return 0, echo.NewHTTPError(http.StatusUnauthorized, "missing X-User-ID header")
+// SearchReviews handles GET /reviews/search?q=...&reviewable_type=...&reviewable_id=...&limit=...&offset=...
+// Returns ranked hits with highlighted title and body fragments.
+func (h *ReviewHandler) SearchReviews(c echo.Context) error {
+	q := domain.ReviewSearchQuery{
+		Query:          c.QueryParam("q"),
+		ReviewableType: c.QueryParam("reviewable_type"),
+	}
+
+	for _, p := range []struct {
+		name string
+		dst  *int
+	}{
+		{"reviewable_id", &q.ReviewableID},
+		{"limit", &q.Limit},
+		{"offset", &q.Offset},
+	} {
+		v := c.QueryParam(p.name)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return respondError(c, h.log, http.StatusBadRequest, "invalid "+p.name)
+		}
+		*p.dst = n
+	}
+
+	res, err := h.search.Execute(c.Request().Context(), q)
+	if err != nil {
+		return respondError(c, h.log, errorStatus(err, http.StatusInternalServerError), err.Error())
 	}
-	id, err := strconv.Atoi(h)
-	if err != nil || id <= 0 {
-		return 0, echo.NewHTTPError(http.StatusUnauthorized, "invalid X-User-ID header")
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// extractUserID returns the user AuthMiddleware authenticated the request
+// as. If there is none, returns an error.
+func extractUserID(c echo.Context) (int, error) {
+	if id, ok := c.Get(userKey).(int); ok {
+		return id, nil
 	}
-	return id, nil
+	return 0, echo.NewHTTPError(http.StatusUnauthorized, "authentication required")
 }
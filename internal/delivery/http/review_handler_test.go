@@ -0,0 +1,281 @@
+package httpDelivery_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestReviewHandlerFlow(t *testing.T) {
+	e := newTestServer()
+	author := map[string]string{"X-User-ID": "7"}
+
+	rec := do(e, http.MethodPost, "/reviews",
+		`{"reviewable_type":"product","reviewable_id":42,"rating":5,"title":"Great","photo_paths":["a.jpg"]}`, author)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create review status = %d (body %s)", rec.Code, rec.Body)
+	}
+	reviewID := decode[map[string]int](t, rec)["id"]
+
+	rec = do(e, http.MethodPost, fmt.Sprintf("/reviews/%d/comments", reviewID),
+		fmt.Sprintf(`{"review_id":%d,"body":"Agreed"}`, reviewID), map[string]string{"X-User-ID": "8"})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create comment status = %d (body %s)", rec.Code, rec.Body)
+	}
+
+	rec = do(e, http.MethodGet, "/reviews?reviewable_type=product&reviewable_id=42", "", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("list status = %d", rec.Code)
+	}
+	list := decode[[]map[string]any](t, rec)
+	if len(list) != 1 || list[0]["title"] != "Great" || list[0]["verified"] != false {
+		t.Errorf("list = %v", list)
+	}
+
+	// The test server verifies no purchases.
+	rec = do(e, http.MethodGet, "/reviews?reviewable_type=product&reviewable_id=42&verified=true", "", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("list verified status = %d", rec.Code)
+	}
+	if list := decode[[]map[string]any](t, rec); len(list) != 0 {
+		t.Errorf("list verified = %v, want none", list)
+	}
+
+	rec = do(e, http.MethodGet, fmt.Sprintf("/reviews/%d", reviewID), "", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("get status = %d", rec.Code)
+	}
+	got := decode[struct {
+		Review   map[string]any   `json:"review"`
+		Comments []map[string]any `json:"comments"`
+	}](t, rec)
+	if got.Review["user_id"] != float64(7) {
+		t.Errorf("review = %v, want author 7", got.Review)
+	}
+	if len(got.Comments) != 1 || got.Comments[0]["body"] != "Agreed" || got.Comments[0]["user_id"] != float64(8) {
+		t.Errorf("comments = %v", got.Comments)
+	}
+}
+
+func TestReviewHandlerErrors(t *testing.T) {
+	e := newTestServer()
+
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		body       string
+		headers    map[string]string
+		wantStatus int
+	}{
+		{
+			name:       "create without user header",
+			method:     http.MethodPost,
+			target:     "/reviews",
+			body:       `{"reviewable_type":"product","reviewable_id":1,"rating":5}`,
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "create with invalid user header",
+			method:     http.MethodPost,
+			target:     "/reviews",
+			body:       `{"reviewable_type":"product","reviewable_id":1,"rating":5}`,
+			headers:    map[string]string{"X-User-ID": "abc"},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "create with malformed body",
+			method:     http.MethodPost,
+			target:     "/reviews",
+			body:       `{"rating":`,
+			headers:    map[string]string{"X-User-ID": "1"},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "comment without user header",
+			method:     http.MethodPost,
+			target:     "/reviews/comments",
+			body:       `{"review_id":1,"body":"hi"}`,
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "list without params",
+			method:     http.MethodGet,
+			target:     "/reviews",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "list with invalid id",
+			method:     http.MethodGet,
+			target:     "/reviews?reviewable_type=product&reviewable_id=x",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "list with invalid verified",
+			method:     http.MethodGet,
+			target:     "/reviews?reviewable_type=product&reviewable_id=1&verified=maybe",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "get with invalid id",
+			method:     http.MethodGet,
+			target:     "/reviews/x",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := do(e, tt.method, tt.target, tt.body, tt.headers)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (body %s)", rec.Code, tt.wantStatus, rec.Body)
+			}
+		})
+	}
+}
+
+func TestReviewHandlerSearch(t *testing.T) {
+	e := newTestServer()
+	author := map[string]string{"X-User-ID": "7"}
+	for _, body := range []string{
+		`{"reviewable_type":"product","reviewable_id":1,"rating":5,"title":"Battery life","body":"Lasts long"}`,
+		`{"reviewable_type":"product","reviewable_id":1,"rating":3,"title":"Screen","body":"Dim"}`,
+	} {
+		if rec := do(e, http.MethodPost, "/reviews", body, author); rec.Code != http.StatusCreated {
+			t.Fatalf("create review status = %d (body %s)", rec.Code, rec.Body)
+		}
+	}
+
+	rec := do(e, http.MethodGet, "/reviews/search?q=battery&reviewable_type=product&reviewable_id=1", "", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("search status = %d (body %s)", rec.Code, rec.Body)
+	}
+	res := decode[struct {
+		Hits []struct {
+			TitleHighlight string `json:"title_highlight"`
+		} `json:"hits"`
+		Total int `json:"total"`
+		Limit int `json:"limit"`
+	}](t, rec)
+	if res.Total != 1 || len(res.Hits) != 1 || res.Hits[0].TitleHighlight != "<mark>Battery</mark> life" || res.Limit != 20 {
+		t.Errorf("search = %+v", res)
+	}
+
+	for _, target := range []string{
+		"/reviews/search",
+		"/reviews/search?q=battery&limit=x",
+		"/reviews/search?q=battery&limit=1000",
+	} {
+		if rec := do(e, http.MethodGet, target, "", nil); rec.Code != http.StatusBadRequest {
+			t.Errorf("GET %s status = %d, want %d", target, rec.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestReviewHandlerReplies(t *testing.T) {
+	e := newTestServer()
+	user := map[string]string{"X-User-ID": "7"}
+
+	rec := do(e, http.MethodPost, "/reviews", `{"reviewable_type":"product","reviewable_id":1,"rating":4}`, user)
+	reviewID := decode[map[string]int](t, rec)["id"]
+	rec = do(e, http.MethodPost, "/reviews/comments", fmt.Sprintf(`{"review_id":%d,"body":"root"}`, reviewID), user)
+	rootID := decode[map[string]int](t, rec)["id"]
+
+	rec = do(e, http.MethodPost, fmt.Sprintf("/reviews/%d/comments/%d/replies", reviewID, rootID), `{"body":"reply"}`, user)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("reply status = %d (body %s)", rec.Code, rec.Body)
+	}
+
+	rec = do(e, http.MethodGet, fmt.Sprintf("/reviews/%d", reviewID), "", nil)
+	got := decode[struct {
+		Comments []struct {
+			Body     string `json:"body"`
+			ParentID *int   `json:"parent_id"`
+			Depth    int    `json:"depth"`
+		} `json:"comments"`
+	}](t, rec)
+	if len(got.Comments) != 2 || got.Comments[1].Body != "reply" || got.Comments[1].Depth != 1 ||
+		got.Comments[1].ParentID == nil || *got.Comments[1].ParentID != rootID {
+		t.Errorf("comments = %+v", got.Comments)
+	}
+
+	rec = do(e, http.MethodPost, fmt.Sprintf("/reviews/%d/comments/999/replies", reviewID), `{"body":"reply"}`, user)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("reply to missing comment status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+	rec = do(e, http.MethodGet, "/reviews/999", "", nil)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("get missing review status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestReviewConditionalRequests(t *testing.T) {
+	e, _ := newTestServerWithUsers()
+	for _, email := range []string{"author@example.com", "stranger@example.com"} {
+		if rec := do(e, http.MethodPost, "/user", fmt.Sprintf(`{"email":%q,"password":"pw"}`, email), nil); rec.Code != http.StatusCreated {
+			t.Fatalf("create user status = %d (body %s)", rec.Code, rec.Body)
+		}
+	}
+	author := map[string]string{"X-User-ID": "1"}
+
+	rec := do(e, http.MethodPost, "/reviews", `{"reviewable_type":"product","reviewable_id":42,"rating":5,"title":"Great"}`, author)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create review status = %d (body %s)", rec.Code, rec.Body)
+	}
+	reviewID := decode[map[string]int](t, rec)["id"]
+	reviewURL := fmt.Sprintf("/reviews/%d", reviewID)
+
+	rec = do(e, http.MethodGet, reviewURL, "", nil)
+	etag, lastModified := rec.Header().Get("ETag"), rec.Header().Get("Last-Modified")
+	if rec.Code != http.StatusOK || etag == "" || lastModified == "" {
+		t.Fatalf("get review status = %d, ETag = %q, Last-Modified = %q", rec.Code, etag, lastModified)
+	}
+	if rec := do(e, http.MethodGet, reviewURL, "", map[string]string{"If-None-Match": etag}); rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+		t.Errorf("If-None-Match status = %d (body %s), want 304", rec.Code, rec.Body)
+	}
+	if rec := do(e, http.MethodGet, reviewURL, "", map[string]string{"If-None-Match": "W/" + etag}); rec.Code != http.StatusNotModified {
+		t.Errorf("weak If-None-Match status = %d, want 304", rec.Code)
+	}
+	if rec := do(e, http.MethodGet, reviewURL, "", map[string]string{"If-Modified-Since": lastModified}); rec.Code != http.StatusNotModified {
+		t.Errorf("If-Modified-Since status = %d, want 304", rec.Code)
+	}
+	if rec := do(e, http.MethodGet, reviewURL, "", map[string]string{"If-None-Match": `"other"`, "If-Modified-Since": lastModified}); rec.Code != http.StatusOK {
+		t.Errorf("If-None-Match precedence status = %d, want 200", rec.Code)
+	}
+
+	listURL := "/reviews?reviewable_type=product&reviewable_id=42"
+	rec = do(e, http.MethodGet, listURL, "", nil)
+	listTag := rec.Header().Get("ETag")
+	if rec.Code != http.StatusOK || listTag == "" {
+		t.Fatalf("list status = %d, ETag = %q", rec.Code, listTag)
+	}
+	if rec := do(e, http.MethodGet, listURL, "", map[string]string{"If-None-Match": listTag}); rec.Code != http.StatusNotModified {
+		t.Errorf("list If-None-Match status = %d, want 304", rec.Code)
+	}
+
+	rec = do(e, http.MethodPost, reviewURL+"/comments", fmt.Sprintf(`{"review_id":%d,"body":"Agreed"}`, reviewID), author)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create comment status = %d (body %s)", rec.Code, rec.Body)
+	}
+	if rec := do(e, http.MethodGet, reviewURL, "", map[string]string{"If-None-Match": etag}); rec.Code != http.StatusOK || rec.Header().Get("ETag") == etag {
+		t.Errorf("after comment status = %d, ETag = %q, want 200 and a new ETag", rec.Code, rec.Header().Get("ETag"))
+	}
+
+	if rec := do(e, http.MethodPatch, reviewURL, `{"title":"Fine"}`, map[string]string{"X-User-ID": "2"}); rec.Code != http.StatusForbidden {
+		t.Errorf("stranger edit without If-Match status = %d, want 403", rec.Code)
+	}
+	if rec := do(e, http.MethodPatch, reviewURL, `{"title":"Fine"}`, author); rec.Code != http.StatusPreconditionRequired {
+		t.Errorf("edit without If-Match status = %d, want 428", rec.Code)
+	}
+	stale := map[string]string{"X-User-ID": "1", "If-Match": etag}
+	if rec := do(e, http.MethodPatch, reviewURL, `{"title":"Fine"}`, stale); rec.Code != http.StatusPreconditionFailed {
+		t.Errorf("edit with stale If-Match status = %d, want 412", rec.Code)
+	}
+	rec = do(e, http.MethodPatch, reviewURL, `{"title":"Fine"}`, ifMatch(t, e, reviewURL, author))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("edit status = %d (body %s)", rec.Code, rec.Body)
+	}
+	if got, want := rec.Header().Get("ETag"), do(e, http.MethodGet, reviewURL, "", nil).Header().Get("ETag"); got == "" || got != want {
+		t.Errorf("edit ETag = %q, want the current ETag %q", got, want)
+	}
+}
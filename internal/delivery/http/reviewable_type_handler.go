@@ -0,0 +1,132 @@
+package httpDelivery
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"eve/domain"
+	"eve/internal/usecase"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ReviewableTypeHandler holds use-cases for the reviewable type registry and
+// for moderating pre-moderated reviews.
+type ReviewableTypeHandler struct {
+	saveType      *usecase.SaveReviewableTypeUseCase
+	listTypes     *usecase.ListReviewableTypesUseCase
+	approveReview *usecase.ApproveReviewUseCase
+	listPending   *usecase.ListPendingReviewsUseCase
+	log           *slog.Logger
+}
+
+// NewReviewableTypeHandler constructs a ReviewableTypeHandler.
+func NewReviewableTypeHandler(
+	st *usecase.SaveReviewableTypeUseCase,
+	lt *usecase.ListReviewableTypesUseCase,
+	ar *usecase.ApproveReviewUseCase,
+	lp *usecase.ListPendingReviewsUseCase,
+	l *slog.Logger,
+) *ReviewableTypeHandler {
+	return &ReviewableTypeHandler{
+		saveType:      st,
+		listTypes:     lt,
+		approveReview: ar,
+		listPending:   lp,
+		log:           l,
+	}
+}
+
+// ListTypes handles GET /reviewable-types
+func (h *ReviewableTypeHandler) ListTypes(c echo.Context) error {
+	types, err := h.listTypes.Execute(c.Request().Context())
+	if err != nil {
+		return respondError(c, h.log, errorStatus(err, http.StatusInternalServerError), err.Error())
+	}
+	if types == nil {
+		types = []domain.ReviewableType{}
+	}
+
+	return c.JSON(http.StatusOK, types)
+}
+
+// SaveType handles PUT /reviewable-types/:type
+// Expects JSON body matching domain.SaveReviewableTypeRequest and the credentials of an admin.
+func (h *ReviewableTypeHandler) SaveType(c echo.Context) error {
+	var req domain.SaveReviewableTypeRequest
+	if err := c.Bind(&req); err != nil {
+		return respondError(c, h.log, http.StatusBadRequest, "invalid request body: "+err.Error())
+	}
+
+	userID, err := extractUserID(c)
+	if err != nil {
+		return respondError(c, h.log, http.StatusUnauthorized, err.Error())
+	}
+
+	rt, err := h.saveType.Execute(c.Request().Context(), c.Param("type"), req, userID)
+	if err != nil {
+		return respondError(c, h.log, errorStatus(err, http.StatusInternalServerError), err.Error())
+	}
+
+	return c.JSON(http.StatusOK, rt)
+}
+
+// ApproveReview handles POST /reviews/:id/approve
+// Requires the credentials of a moderator or an admin.
+func (h *ReviewableTypeHandler) ApproveReview(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return respondError(c, h.log, http.StatusBadRequest, "invalid id")
+	}
+
+	userID, err := extractUserID(c)
+	if err != nil {
+		return respondError(c, h.log, http.StatusUnauthorized, err.Error())
+	}
+
+	if err := h.approveReview.Execute(c.Request().Context(), id, userID); err != nil {
+		return respondError(c, h.log, errorStatus(err, http.StatusInternalServerError), err.Error())
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ListPendingReviews handles GET /reviews/pending?limit=...&offset=...
+// Requires the credentials of a moderator or an admin. Returns the
+// reviews awaiting moderation, oldest first.
+func (h *ReviewableTypeHandler) ListPendingReviews(c echo.Context) error {
+	var limit, offset int
+	for _, p := range []struct {
+		name string
+		dst  *int
+	}{
+		{"limit", &limit},
+		{"offset", &offset},
+	} {
+		v := c.QueryParam(p.name)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return respondError(c, h.log, http.StatusBadRequest, "invalid "+p.name)
+		}
+		*p.dst = n
+	}
+
+	userID, err := extractUserID(c)
+	if err != nil {
+		return respondError(c, h.log, http.StatusUnauthorized, err.Error())
+	}
+
+	reviews, err := h.listPending.Execute(c.Request().Context(), limit, offset, userID)
+	if err != nil {
+		return respondError(c, h.log, errorStatus(err, http.StatusInternalServerError), err.Error())
+	}
+	if reviews == nil {
+		reviews = []domain.Review{}
+	}
+
+	return c.JSON(http.StatusOK, reviews)
+}
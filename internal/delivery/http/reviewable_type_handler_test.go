@@ -0,0 +1,102 @@
+package httpDelivery_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"eve/domain"
+)
+
+func TestReviewableTypeHandlerFlow(t *testing.T) {
+	e, users := newTestServerWithUsers()
+	for _, email := range []string{"admin@example.com", "guest@example.com"} {
+		if rec := do(e, http.MethodPost, "/user", fmt.Sprintf(`{"email":%q,"password":"pw"}`, email), nil); rec.Code != http.StatusCreated {
+			t.Fatalf("create user status = %d (body %s)", rec.Code, rec.Body)
+		}
+	}
+	users.SetRole(1, domain.RoleAdmin)
+	admin := map[string]string{"X-User-ID": "1"}
+	guest := map[string]string{"X-User-ID": "2"}
+
+	settings := `{"title_required":true,"max_body_length":20,"moderation_mode":"pre"}`
+	if rec := do(e, http.MethodPut, "/reviewable-types/book", settings, guest); rec.Code != http.StatusForbidden {
+		t.Errorf("guest save type status = %d, want 403", rec.Code)
+	}
+	if rec := do(e, http.MethodPut, "/reviewable-types/book", `{"moderation_mode":"later"}`, admin); rec.Code != http.StatusBadRequest {
+		t.Errorf("invalid mode status = %d, want 400", rec.Code)
+	}
+	if rec := do(e, http.MethodPut, "/reviewable-types/book", settings, admin); rec.Code != http.StatusOK {
+		t.Fatalf("save type status = %d (body %s)", rec.Code, rec.Body)
+	}
+
+	rec := do(e, http.MethodGet, "/reviewable-types", "", nil)
+	types := decode[[]domain.ReviewableType](t, rec)
+	if len(types) != 3 || types[0].Name != "book" || !types[0].TitleRequired || types[0].ModerationMode != domain.ModerationPre {
+		t.Fatalf("types = %+v", types)
+	}
+
+	for _, tc := range []struct {
+		body string
+		want int
+	}{
+		{`{"reviewable_type":"prodcut","reviewable_id":1,"rating":5}`, http.StatusBadRequest},
+		{`{"reviewable_type":"book","reviewable_id":1,"rating":5,"body":"no title"}`, http.StatusBadRequest},
+		{`{"reviewable_type":"book","reviewable_id":1,"rating":5,"title":"Long","body":"far too long for this type"}`, http.StatusBadRequest},
+		{`{"reviewable_type":"book","reviewable_id":1,"rating":5,"title":"Short","photo_paths":["a.jpg"]}`, http.StatusBadRequest},
+	} {
+		if rec := do(e, http.MethodPost, "/reviews", tc.body, guest); rec.Code != tc.want {
+			t.Errorf("create review %s status = %d, want %d (body %s)", tc.body, rec.Code, tc.want, rec.Body)
+		}
+	}
+
+	rec = do(e, http.MethodPost, "/reviews", `{"reviewable_type":"book","reviewable_id":1,"rating":5,"title":"Short","body":"Loved it"}`, guest)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create review status = %d (body %s)", rec.Code, rec.Body)
+	}
+	reviewURL := fmt.Sprintf("/reviews/%d", decode[map[string]int](t, rec)["id"])
+
+	if got := decode[[]domain.Review](t, do(e, http.MethodGet, "/reviews?reviewable_type=book&reviewable_id=1", "", nil)); len(got) != 0 {
+		t.Errorf("pending review listed: %+v", got)
+	}
+	for _, tc := range []struct {
+		name    string
+		headers map[string]string
+		want    int
+	}{
+		{"anonymous", nil, http.StatusNotFound},
+		{"author", guest, http.StatusOK},
+		{"admin", admin, http.StatusOK},
+	} {
+		if rec := do(e, http.MethodGet, reviewURL, "", tc.headers); rec.Code != tc.want {
+			t.Errorf("get pending review (%s) status = %d, want %d", tc.name, rec.Code, tc.want)
+		}
+	}
+	if rec := do(e, http.MethodPost, reviewURL+"/comments", `{"body":"first!"}`, guest); rec.Code != http.StatusBadRequest {
+		t.Errorf("comment on pending review status = %d, want 400 (body %s)", rec.Code, rec.Body)
+	}
+	if rec := do(e, http.MethodGet, "/reviews/pending", "", guest); rec.Code != http.StatusForbidden {
+		t.Errorf("guest moderation queue status = %d, want 403", rec.Code)
+	}
+	if rec := do(e, http.MethodGet, "/reviews/pending?limit=x", "", admin); rec.Code != http.StatusBadRequest {
+		t.Errorf("moderation queue with invalid limit status = %d, want 400", rec.Code)
+	}
+	if got := decode[[]domain.Review](t, do(e, http.MethodGet, "/reviews/pending", "", admin)); len(got) != 1 || got[0].Status != domain.ReviewStatusPending {
+		t.Errorf("moderation queue = %+v, want the pending review", got)
+	}
+	if rec := do(e, http.MethodPost, reviewURL+"/approve", "", guest); rec.Code != http.StatusForbidden {
+		t.Errorf("guest approve status = %d, want 403", rec.Code)
+	}
+	if rec := do(e, http.MethodPost, reviewURL+"/approve", "", admin); rec.Code != http.StatusNoContent {
+		t.Fatalf("approve status = %d (body %s)", rec.Code, rec.Body)
+	}
+	if got := decode[[]domain.Review](t, do(e, http.MethodGet, "/reviews?reviewable_type=book&reviewable_id=1", "", nil)); len(got) != 1 || got[0].Status != domain.ReviewStatusPublished {
+		t.Errorf("listed reviews after approval = %+v", got)
+	}
+	if rec := do(e, http.MethodGet, reviewURL, "", nil); rec.Code != http.StatusOK {
+		t.Errorf("get approved review status = %d, want 200", rec.Code)
+	}
+	if got := decode[[]domain.Review](t, do(e, http.MethodGet, "/reviews/pending", "", admin)); len(got) != 0 {
+		t.Errorf("moderation queue after approval = %+v, want empty", got)
+	}
+}
@@ -0,0 +1,118 @@
+package httpDelivery
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"eve/domain"
+	"eve/internal/usecase"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RevisionHandler holds use-cases for editing reviews and comments and for
+// reading the resulting audit trail.
+type RevisionHandler struct {
+	updateReview  *usecase.UpdateReviewUseCase
+	updateComment *usecase.UpdateCommentUseCase
+	listRevisions *usecase.ListRevisionsUseCase
+	getReview     *usecase.GetReviewUseCase
+	log           *slog.Logger
+}
+
+// NewRevisionHandler constructs a RevisionHandler.
+func NewRevisionHandler(
+	ur *usecase.UpdateReviewUseCase,
+	uc *usecase.UpdateCommentUseCase,
+	lr *usecase.ListRevisionsUseCase,
+	gr *usecase.GetReviewUseCase,
+	l *slog.Logger,
+) *RevisionHandler {
+	return &RevisionHandler{
+		updateReview:  ur,
+		updateComment: uc,
+		listRevisions: lr,
+		getReview:     gr,
+		log:           l,
+	}
+}
+
+// UpdateReview handles PATCH /reviews/:id
+// Expects JSON body matching domain.UpdateReviewRequest from an authenticated
+// user and an "If-Match" header with the ETag of GET /reviews/:id. The
+// response carries the ETag of the edited review.
+func (h *RevisionHandler) UpdateReview(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return respondError(c, h.log, http.StatusBadRequest, "invalid id")
+	}
+
+	var req domain.UpdateReviewRequest
+	if err := c.Bind(&req); err != nil {
+		return respondError(c, h.log, http.StatusBadRequest, "invalid request body: "+err.Error())
+	}
+
+	userID, err := extractUserID(c)
+	if err != nil {
+		return respondError(c, h.log, http.StatusUnauthorized, err.Error())
+	}
+	review, err := h.updateReview.Execute(c.Request().Context(), id, req, userID, ifMatch(c))
+	if err != nil {
+		return respondError(c, h.log, errorStatus(err, http.StatusInternalServerError), err.Error())
+	}
+	setReviewETag(c, h.getReview, id, userID)
+
+	return c.JSON(http.StatusOK, review)
+}
+
+// UpdateComment handles PATCH /reviews/:id/comments/:cid
+// Expects JSON body matching domain.UpdateCommentRequest from an authenticated user.
+func (h *RevisionHandler) UpdateComment(c echo.Context) error {
+	reviewID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return respondError(c, h.log, http.StatusBadRequest, "invalid id")
+	}
+	commentID, err := strconv.Atoi(c.Param("cid"))
+	if err != nil {
+		return respondError(c, h.log, http.StatusBadRequest, "invalid cid")
+	}
+
+	var req domain.UpdateCommentRequest
+	if err := c.Bind(&req); err != nil {
+		return respondError(c, h.log, http.StatusBadRequest, "invalid request body: "+err.Error())
+	}
+
+	userID, err := extractUserID(c)
+	if err != nil {
+		return respondError(c, h.log, http.StatusUnauthorized, err.Error())
+	}
+
+	comment, err := h.updateComment.Execute(c.Request().Context(), reviewID, commentID, req, userID)
+	if err != nil {
+		return respondError(c, h.log, errorStatus(err, http.StatusInternalServerError), err.Error())
+	}
+
+	return c.JSON(http.StatusOK, comment)
+}
+
+// ListRevisions handles GET /reviews/:id/revisions
+// Requires the credentials of the review's author, a moderator or an admin.
+func (h *RevisionHandler) ListRevisions(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return respondError(c, h.log, http.StatusBadRequest, "invalid id")
+	}
+
+	userID, err := extractUserID(c)
+	if err != nil {
+		return respondError(c, h.log, http.StatusUnauthorized, err.Error())
+	}
+
+	revisions, err := h.listRevisions.Execute(c.Request().Context(), id, userID)
+	if err != nil {
+		return respondError(c, h.log, errorStatus(err, http.StatusInternalServerError), err.Error())
+	}
+
+	return c.JSON(http.StatusOK, revisions)
+}
@@ -0,0 +1,73 @@
+package httpDelivery_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"eve/domain"
+)
+
+func TestRevisionHandlerFlow(t *testing.T) {
+	e, users := newTestServerWithUsers()
+	for _, email := range []string{"author@example.com", "stranger@example.com", "mod@example.com"} {
+		if rec := do(e, http.MethodPost, "/user", fmt.Sprintf(`{"email":%q,"password":"pw"}`, email), nil); rec.Code != http.StatusCreated {
+			t.Fatalf("create user status = %d (body %s)", rec.Code, rec.Body)
+		}
+	}
+	users.SetRole(3, domain.RoleModerator)
+	author := map[string]string{"X-User-ID": "1"}
+	stranger := map[string]string{"X-User-ID": "2"}
+	moderator := map[string]string{"X-User-ID": "3"}
+
+	rec := do(e, http.MethodPost, "/reviews", `{"reviewable_type":"product","reviewable_id":42,"rating":5,"title":"Great","body":"Love it"}`, author)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create review status = %d (body %s)", rec.Code, rec.Body)
+	}
+	reviewID := decode[map[string]int](t, rec)["id"]
+	rec = do(e, http.MethodPost, fmt.Sprintf("/reviews/%d/comments", reviewID), fmt.Sprintf(`{"review_id":%d,"body":"Same here"}`, reviewID), stranger)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create comment status = %d (body %s)", rec.Code, rec.Body)
+	}
+	commentID := decode[map[string]int](t, rec)["id"]
+
+	reviewURL := fmt.Sprintf("/reviews/%d", reviewID)
+	if rec := do(e, http.MethodPatch, reviewURL, `{"title":"Hacked"}`, ifMatch(t, e, reviewURL, stranger)); rec.Code != http.StatusForbidden {
+		t.Errorf("stranger edit status = %d, want 403", rec.Code)
+	}
+	if rec := do(e, http.MethodPatch, reviewURL, `{"rating":9}`, ifMatch(t, e, reviewURL, author)); rec.Code != http.StatusBadRequest {
+		t.Errorf("invalid rating status = %d, want 400", rec.Code)
+	}
+	rec = do(e, http.MethodPatch, reviewURL, `{"rating":2,"body":"Broke after a week"}`, ifMatch(t, e, reviewURL, author))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("edit review status = %d (body %s)", rec.Code, rec.Body)
+	}
+	if got := decode[domain.Review](t, rec); got.Rating != 2 || got.Title != "Great" || got.Body != "Broke after a week" {
+		t.Errorf("edited review = %+v", got)
+	}
+
+	rec = do(e, http.MethodPatch, fmt.Sprintf("%s/comments/%d", reviewURL, commentID), `{"body":"[removed]"}`, moderator)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("moderate comment status = %d (body %s)", rec.Code, rec.Body)
+	}
+
+	if rec := do(e, http.MethodGet, reviewURL+"/revisions", "", stranger); rec.Code != http.StatusForbidden {
+		t.Errorf("stranger revisions status = %d, want 403", rec.Code)
+	}
+	if rec := do(e, http.MethodGet, reviewURL+"/revisions", "", nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("anonymous revisions status = %d, want 401", rec.Code)
+	}
+	for _, who := range []map[string]string{author, moderator} {
+		rec := do(e, http.MethodGet, reviewURL+"/revisions", "", who)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("revisions status = %d (body %s)", rec.Code, rec.Body)
+		}
+		revisions := decode[[]domain.Revision](t, rec)
+		if len(revisions) != 2 || revisions[0].EntityType != domain.RevisionEntityReview || revisions[1].EntityID != commentID {
+			t.Fatalf("revisions = %+v", revisions)
+		}
+		if string(revisions[0].OldValues) != `{"body":"Love it","rating":5}` {
+			t.Errorf("old values = %s", revisions[0].OldValues)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package httpDelivery
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"eve/domain"
+	"eve/internal/usecase"
+
+	"github.com/labstack/echo/v4"
+)
+
+// WebhookHandler holds use-cases for managing webhook subscriptions and
+// inspecting their deliveries. Every endpoint requires an admin.
+type WebhookHandler struct {
+	createWebhook  *usecase.CreateWebhookUseCase
+	listWebhooks   *usecase.ListWebhooksUseCase
+	deleteWebhook  *usecase.DeleteWebhookUseCase
+	listDeliveries *usecase.ListWebhookDeliveriesUseCase
+	redeliver      *usecase.RedeliverWebhookUseCase
+	log            *slog.Logger
+}
+
+// NewWebhookHandler constructs a WebhookHandler.
+func NewWebhookHandler(
+	cw *usecase.CreateWebhookUseCase,
+	lw *usecase.ListWebhooksUseCase,
+	dw *usecase.DeleteWebhookUseCase,
+	ld *usecase.ListWebhookDeliveriesUseCase,
+	rd *usecase.RedeliverWebhookUseCase,
+	l *slog.Logger,
+) *WebhookHandler {
+	return &WebhookHandler{
+		createWebhook:  cw,
+		listWebhooks:   lw,
+		deleteWebhook:  dw,
+		listDeliveries: ld,
+		redeliver:      rd,
+		log:            l,
+	}
+}
+
+// CreateWebhook handles POST /webhooks
+// Expects JSON body matching domain.CreateWebhookRequest and the credentials of an admin.
+// The response is the only one that includes the secret.
+func (h *WebhookHandler) CreateWebhook(c echo.Context) error {
+	var req domain.CreateWebhookRequest
+	if err := c.Bind(&req); err != nil {
+		return respondError(c, h.log, http.StatusBadRequest, "invalid request body: "+err.Error())
+	}
+
+	userID, err := extractUserID(c)
+	if err != nil {
+		return respondError(c, h.log, http.StatusUnauthorized, err.Error())
+	}
+
+	sub, err := h.createWebhook.Execute(c.Request().Context(), req, userID)
+	if err != nil {
+		return respondError(c, h.log, errorStatus(err, http.StatusInternalServerError), err.Error())
+	}
+
+	return c.JSON(http.StatusCreated, sub)
+}
+
+// ListWebhooks handles GET /webhooks
+// Requires the credentials of an admin.
+func (h *WebhookHandler) ListWebhooks(c echo.Context) error {
+	userID, err := extractUserID(c)
+	if err != nil {
+		return respondError(c, h.log, http.StatusUnauthorized, err.Error())
+	}
+
+	subs, err := h.listWebhooks.Execute(c.Request().Context(), userID)
+	if err != nil {
+		return respondError(c, h.log, errorStatus(err, http.StatusInternalServerError), err.Error())
+	}
+	if subs == nil {
+		subs = []domain.WebhookSubscription{}
+	}
+
+	return c.JSON(http.StatusOK, subs)
+}
+
+// DeleteWebhook handles DELETE /webhooks/:id
+// Requires the credentials of an admin.
+func (h *WebhookHandler) DeleteWebhook(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return respondError(c, h.log, http.StatusBadRequest, "invalid id")
+	}
+
+	userID, err := extractUserID(c)
+	if err != nil {
+		return respondError(c, h.log, http.StatusUnauthorized, err.Error())
+	}
+
+	if err := h.deleteWebhook.Execute(c.Request().Context(), id, userID); err != nil {
+		return respondError(c, h.log, errorStatus(err, http.StatusInternalServerError), err.Error())
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ListDeliveries handles GET /webhooks/:id/deliveries
+// Accepts an optional "status" query param (pending, succeeded or dead) and
+// requires the credentials of an admin.
+func (h *WebhookHandler) ListDeliveries(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return respondError(c, h.log, http.StatusBadRequest, "invalid id")
+	}
+
+	userID, err := extractUserID(c)
+	if err != nil {
+		return respondError(c, h.log, http.StatusUnauthorized, err.Error())
+	}
+
+	deliveries, err := h.listDeliveries.Execute(c.Request().Context(), id, c.QueryParam("status"), userID)
+	if err != nil {
+		return respondError(c, h.log, errorStatus(err, http.StatusInternalServerError), err.Error())
+	}
+	if deliveries == nil {
+		deliveries = []domain.WebhookDelivery{}
+	}
+
+	return c.JSON(http.StatusOK, deliveries)
+}
+
+// Redeliver handles POST /webhooks/:id/deliveries/:delivery_id/redeliver
+// Requires the credentials of an admin.
+func (h *WebhookHandler) Redeliver(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return respondError(c, h.log, http.StatusBadRequest, "invalid id")
+	}
+	deliveryID, err := strconv.Atoi(c.Param("delivery_id"))
+	if err != nil {
+		return respondError(c, h.log, http.StatusBadRequest, "invalid delivery id")
+	}
+
+	userID, err := extractUserID(c)
+	if err != nil {
+		return respondError(c, h.log, http.StatusUnauthorized, err.Error())
+	}
+
+	if err := h.redeliver.Execute(c.Request().Context(), id, deliveryID, userID); err != nil {
+		return respondError(c, h.log, errorStatus(err, http.StatusInternalServerError), err.Error())
+	}
+
+	return c.NoContent(http.StatusAccepted)
+}
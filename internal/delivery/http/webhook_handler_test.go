@@ -0,0 +1,69 @@
+package httpDelivery_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"eve/domain"
+)
+
+func TestWebhookHandlerFlow(t *testing.T) {
+	e, users := newTestServerWithUsers()
+	for _, email := range []string{"admin@example.com", "guest@example.com"} {
+		if rec := do(e, http.MethodPost, "/user", fmt.Sprintf(`{"email":%q,"password":"pw"}`, email), nil); rec.Code != http.StatusCreated {
+			t.Fatalf("create user status = %d (body %s)", rec.Code, rec.Body)
+		}
+	}
+	users.SetRole(1, domain.RoleAdmin)
+	admin := map[string]string{"X-User-ID": "1"}
+	guest := map[string]string{"X-User-ID": "2"}
+
+	body := `{"url":"https://partner.example.com/hook","events":["review.created"],"reviewable_type":"product"}`
+	if rec := do(e, http.MethodPost, "/webhooks", body, guest); rec.Code != http.StatusForbidden {
+		t.Errorf("guest create status = %d, want 403", rec.Code)
+	}
+	if rec := do(e, http.MethodPost, "/webhooks", `{"url":"https://partner.example.com/hook","events":["review.liked"]}`, admin); rec.Code != http.StatusBadRequest {
+		t.Errorf("unknown event status = %d, want 400", rec.Code)
+	}
+	rec := do(e, http.MethodPost, "/webhooks", body, admin)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create status = %d (body %s)", rec.Code, rec.Body)
+	}
+	sub := decode[domain.WebhookSubscription](t, rec)
+	if sub.ID == 0 || sub.Secret == "" || sub.ReviewableType != "product" {
+		t.Fatalf("created webhook = %+v, want an ID and a secret", sub)
+	}
+	webhookURL := fmt.Sprintf("/webhooks/%d", sub.ID)
+
+	subs := decode[[]domain.WebhookSubscription](t, do(e, http.MethodGet, "/webhooks", "", admin))
+	if len(subs) != 1 || subs[0].ID != sub.ID || subs[0].Secret != "" {
+		t.Errorf("listed webhooks = %+v, want one without secret", subs)
+	}
+
+	rec = do(e, http.MethodGet, webhookURL+"/deliveries", "", admin)
+	if rec.Code != http.StatusOK || rec.Body.String() != "[]\n" {
+		t.Errorf("deliveries = %d %s, want an empty list", rec.Code, rec.Body)
+	}
+
+	for _, tc := range []struct {
+		method, url string
+		headers     map[string]string
+		want        int
+	}{
+		{http.MethodGet, "/webhooks", nil, http.StatusUnauthorized},
+		{http.MethodGet, webhookURL + "/deliveries?status=lost", admin, http.StatusBadRequest},
+		{http.MethodGet, "/webhooks/99/deliveries", admin, http.StatusNotFound},
+		{http.MethodGet, "/webhooks/x/deliveries", admin, http.StatusBadRequest},
+		{http.MethodGet, webhookURL + "/deliveries", guest, http.StatusForbidden},
+		{http.MethodPost, webhookURL + "/deliveries/x/redeliver", admin, http.StatusBadRequest},
+		{http.MethodPost, webhookURL + "/deliveries/1/redeliver", admin, http.StatusNotFound},
+		{http.MethodDelete, webhookURL, guest, http.StatusForbidden},
+		{http.MethodDelete, webhookURL, admin, http.StatusNoContent},
+		{http.MethodDelete, webhookURL, admin, http.StatusNotFound},
+	} {
+		if rec := do(e, tc.method, tc.url, "", tc.headers); rec.Code != tc.want {
+			t.Errorf("%s %s status = %d, want %d (body %s)", tc.method, tc.url, rec.Code, tc.want, rec.Body)
+		}
+	}
+}
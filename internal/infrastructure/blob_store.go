@@ -0,0 +1,41 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LocalBlobStore is a BlobStore backed by a directory on the local file
+// system. Photo paths are interpreted relative to that directory.
+type LocalBlobStore struct {
+	root string
+}
+
+func NewLocalBlobStore(root string) *LocalBlobStore {
+	return &LocalBlobStore{root: root}
+}
+
+// Delete removes root/path. URLs are left alone since they point at storage
+// this store does not own, and paths escaping root are rejected.
+func (s *LocalBlobStore) Delete(ctx context.Context, path string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if strings.Contains(path, "://") {
+		return nil
+	}
+	if !filepath.IsLocal(path) {
+		return fmt.Errorf("delete blob: path %q escapes the blob directory", path)
+	}
+
+	err := os.Remove(filepath.Join(s.root, path))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("delete blob: %w", err)
+	}
+	return nil
+}
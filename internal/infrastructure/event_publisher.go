@@ -0,0 +1,28 @@
+package infrastructure
+
+import (
+	"context"
+	"log/slog"
+
+	"eve/domain"
+)
+
+// LogEventPublisher is an EventPublisher that writes every event to the log.
+// It lets the outbox drain in deployments where no other service consumes
+// the events yet.
+type LogEventPublisher struct {
+	log *slog.Logger
+}
+
+func NewLogEventPublisher(l *slog.Logger) *LogEventPublisher {
+	return &LogEventPublisher{log: l}
+}
+
+func (p *LogEventPublisher) Publish(ctx context.Context, event domain.Event) error {
+	p.log.InfoContext(ctx, "event published",
+		slog.Int("event_id", event.ID),
+		slog.String("event_type", event.Type),
+		slog.Int("review_id", event.ReviewID),
+	)
+	return nil
+}
@@ -0,0 +1,35 @@
+package infrastructure
+
+import (
+	"errors"
+	"strings"
+)
+
+// FakeHasher is a PasswordHasher for tests and local tooling. It "hashes" by
+// adding a fixed prefix, so results are predictable and cheap to compute.
+type FakeHasher struct{}
+
+const fakeHashPrefix = "fake$"
+
+func NewFakeHasher() *FakeHasher {
+	return &FakeHasher{}
+}
+
+func (*FakeHasher) Hash(password string) (string, error) {
+	return fakeHashPrefix + password, nil
+}
+
+func (*FakeHasher) Compare(password, hash string) (bool, error) {
+	if hash != fakeHashPrefix+password {
+		return false, errors.New("password does not match")
+	}
+	return true, nil
+}
+
+func (*FakeHasher) NeedsRehash(string) bool {
+	return false
+}
+
+func (*FakeHasher) Recognizes(hash string) bool {
+	return strings.HasPrefix(hash, fakeHashPrefix)
+}
@@ -0,0 +1,102 @@
+package infrastructure
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"eve/domain"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisIdempotencyStore is a usecase.IdempotencyStore backed by Redis. Records
+// are JSON values that Redis expires on its own, so DeleteExpired has nothing
+// to do.
+type RedisIdempotencyStore struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+// NewRedisIdempotencyStore creates a store prefixing every key with prefix,
+// e.g. "eve:idempotency:".
+func NewRedisIdempotencyStore(client redis.UniversalClient, prefix string) *RedisIdempotencyStore {
+	return &RedisIdempotencyStore{client: client, prefix: prefix}
+}
+
+func (s *RedisIdempotencyStore) Reserve(ctx context.Context, key, fingerprint string, lease time.Duration) (domain.IdempotencyRecord, bool, error) {
+	rec := domain.IdempotencyRecord{Key: key, Fingerprint: fingerprint, CreatedAt: time.Now().UTC().Format(time.RFC3339Nano)}
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return domain.IdempotencyRecord{}, false, fmt.Errorf("marshal idempotency record: %w", err)
+	}
+
+	// The key can expire or be released between SETNX and GET; one more
+	// attempt then claims it.
+	for range 2 {
+		ok, err := s.client.SetNX(ctx, s.prefix+key, data, lease).Result()
+		if err != nil {
+			return domain.IdempotencyRecord{}, false, fmt.Errorf("redis setnx: %w", err)
+		}
+		if ok {
+			return rec, true, nil
+		}
+		existing, err := s.get(ctx, key)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		return existing, false, err
+	}
+	return domain.IdempotencyRecord{}, false, fmt.Errorf("reserve idempotency key %q: claimed and released concurrently", key)
+}
+
+func (s *RedisIdempotencyStore) Complete(ctx context.Context, rec domain.IdempotencyRecord, ttl time.Duration) error {
+	stored, err := s.get(ctx, rec.Key)
+	if err != nil {
+		return fmt.Errorf("complete idempotency key: %w", err)
+	}
+	stored.Status, stored.ContentType, stored.Body = rec.Status, rec.ContentType, rec.Body
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return fmt.Errorf("marshal idempotency record: %w", err)
+	}
+	ok, err := s.client.SetXX(ctx, s.prefix+rec.Key, data, ttl).Result()
+	if err != nil {
+		return fmt.Errorf("redis setxx: %w", err)
+	}
+	if !ok {
+		return fmt.Errorf("complete idempotency key: %w", sql.ErrNoRows)
+	}
+	return nil
+}
+
+func (s *RedisIdempotencyStore) Release(ctx context.Context, key string) error {
+	if err := s.client.Del(ctx, s.prefix+key).Err(); err != nil {
+		return fmt.Errorf("redis del: %w", err)
+	}
+	return nil
+}
+
+func (s *RedisIdempotencyStore) DeleteExpired(context.Context, time.Time) (int, error) {
+	return 0, nil
+}
+
+// get loads the record of key; a missing key yields an error wrapping
+// sql.ErrNoRows like the SQL stores.
+func (s *RedisIdempotencyStore) get(ctx context.Context, key string) (domain.IdempotencyRecord, error) {
+	data, err := s.client.Get(ctx, s.prefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return domain.IdempotencyRecord{}, fmt.Errorf("get idempotency key %q: %w", key, sql.ErrNoRows)
+	}
+	if err != nil {
+		return domain.IdempotencyRecord{}, fmt.Errorf("redis get: %w", err)
+	}
+	var rec domain.IdempotencyRecord
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return domain.IdempotencyRecord{}, fmt.Errorf("decode idempotency record %q: %w", key, err)
+	}
+	return rec, nil
+}
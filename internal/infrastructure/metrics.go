@@ -0,0 +1,126 @@
+package infrastructure
+
+import (
+	"database/sql"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+// PrometheusMetrics collects HTTP, use-case, database and business metrics.
+// It implements usecase.Metrics, httpDelivery.HTTPMetrics and cache.Metrics.
+type PrometheusMetrics struct {
+	httpRequests    *prometheus.CounterVec
+	httpDuration    *prometheus.HistogramVec
+	useCaseRuns     *prometheus.HistogramVec
+	useCaseErrors   *prometheus.CounterVec
+	reviewsCreated  prometheus.Counter
+	commentsCreated prometheus.Counter
+	photosAttached  prometheus.Counter
+	cacheLookups    *prometheus.CounterVec
+}
+
+// NewPrometheusMetrics creates the collectors and registers them in reg.
+func NewPrometheusMetrics(reg prometheus.Registerer) *PrometheusMetrics {
+	m := &PrometheusMetrics{
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "eve",
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "Number of HTTP requests by method, route and status.",
+		}, []string{"method", "route", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "eve",
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "HTTP request latency by method, route and status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		useCaseRuns: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "eve",
+			Subsystem: "usecase",
+			Name:      "duration_seconds",
+			Help:      "Use-case execution time.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"usecase"}),
+		useCaseErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "eve",
+			Subsystem: "usecase",
+			Name:      "errors_total",
+			Help:      "Number of use-case executions that returned an error.",
+		}, []string{"usecase"}),
+		reviewsCreated: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "eve",
+			Name:      "reviews_created_total",
+			Help:      "Number of reviews created.",
+		}),
+		commentsCreated: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "eve",
+			Name:      "comments_created_total",
+			Help:      "Number of review comments created.",
+		}),
+		photosAttached: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "eve",
+			Name:      "photos_attached_total",
+			Help:      "Number of photos attached to reviews.",
+		}),
+		cacheLookups: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "eve",
+			Subsystem: "cache",
+			Name:      "lookups_total",
+			Help:      "Number of review cache lookups by kind of entry and result (hit or miss).",
+		}, []string{"kind", "result"}),
+	}
+
+	reg.MustRegister(
+		m.httpRequests,
+		m.httpDuration,
+		m.useCaseRuns,
+		m.useCaseErrors,
+		m.reviewsCreated,
+		m.commentsCreated,
+		m.photosAttached,
+		m.cacheLookups,
+	)
+	return m
+}
+
+// RegisterDBStats exposes connection pool gauges from db.Stats() under the given name.
+func RegisterDBStats(reg prometheus.Registerer, db *sql.DB, dbName string) {
+	reg.MustRegister(collectors.NewDBStatsCollector(db, dbName))
+}
+
+func (m *PrometheusMetrics) ObserveHTTPRequest(method, route string, status int, duration time.Duration) {
+	s := strconv.Itoa(status)
+	m.httpRequests.WithLabelValues(method, route, s).Inc()
+	m.httpDuration.WithLabelValues(method, route, s).Observe(duration.Seconds())
+}
+
+func (m *PrometheusMetrics) ObserveUseCase(name string, duration time.Duration, err error) {
+	m.useCaseRuns.WithLabelValues(name).Observe(duration.Seconds())
+	if err != nil {
+		m.useCaseErrors.WithLabelValues(name).Inc()
+	}
+}
+
+func (m *PrometheusMetrics) ReviewCreated() {
+	m.reviewsCreated.Inc()
+}
+
+func (m *PrometheusMetrics) CommentCreated() {
+	m.commentsCreated.Inc()
+}
+
+func (m *PrometheusMetrics) PhotosAttached(n int) {
+	m.photosAttached.Add(float64(n))
+}
+
+func (m *PrometheusMetrics) CacheLookup(kind string, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	m.cacheLookups.WithLabelValues(kind, result).Inc()
+}
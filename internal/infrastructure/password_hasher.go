@@ -1,15 +1,65 @@
 package infrastructure
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"errors"
+	"strings"
 
-type BcryptHasher struct{}
+	"eve/internal/usecase"
 
-func NewBcryptHasher() *BcryptHasher {
-	return &BcryptHasher{}
+	"golang.org/x/crypto/bcrypt"
+)
+
+// RecognizingHasher is a PasswordHasher that tells its own hashes apart from
+// those made by other algorithms.
+type RecognizingHasher interface {
+	usecase.PasswordHasher
+	Recognizes(hash string) bool
+}
+
+// MultiHasher hashes new passwords with its current hasher and checks stored
+// hashes with whichever of its hashers recognizes them, so that switching the
+// algorithm or its costs keeps existing accounts working. Every hash not made
+// by the current hasher with its current costs needs a rehash.
+type MultiHasher struct {
+	current RecognizingHasher
+	hashers []RecognizingHasher
+}
+
+// NewMultiHasher hashes with current and also accepts the hashes of legacy.
+func NewMultiHasher(current RecognizingHasher, legacy ...RecognizingHasher) *MultiHasher {
+	return &MultiHasher{current: current, hashers: append([]RecognizingHasher{current}, legacy...)}
+}
+
+func (h *MultiHasher) Hash(password string) (string, error) {
+	return h.current.Hash(password)
+}
+
+func (h *MultiHasher) Compare(password, hash string) (bool, error) {
+	for _, hasher := range h.hashers {
+		if hasher.Recognizes(hash) {
+			return hasher.Compare(password, hash)
+		}
+	}
+	return false, errors.New("unrecognized password hash")
 }
 
-func (*BcryptHasher) Hash(password string) (string, error) {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+func (h *MultiHasher) NeedsRehash(hash string) bool {
+	return !h.current.Recognizes(hash) || h.current.NeedsRehash(hash)
+}
+
+// BcryptHasher hashes passwords with bcrypt at a fixed cost.
+type BcryptHasher struct {
+	cost int
+}
+
+// NewBcryptHasher hashes with the given cost, between bcrypt.MinCost and
+// bcrypt.MaxCost; bcrypt.DefaultCost is a sensible choice.
+func NewBcryptHasher(cost int) *BcryptHasher {
+	return &BcryptHasher{cost: cost}
+}
+
+func (h *BcryptHasher) Hash(password string) (string, error) {
+	bytes, err := bcrypt.GenerateFromPassword([]byte(password), h.cost)
 	return string(bytes), err
 }
 
@@ -20,3 +70,14 @@ func (*BcryptHasher) Compare(password, hash string) (bool, error) {
 	}
 	return true, nil
 }
+
+func (h *BcryptHasher) NeedsRehash(hash string) bool {
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err != nil || cost != h.cost
+}
+
+// Recognizes reports whether hash is in one of the modular crypt formats of
+// bcrypt: $2a$, $2b$ or $2y$.
+func (*BcryptHasher) Recognizes(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$")
+}
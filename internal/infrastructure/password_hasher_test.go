@@ -0,0 +1,93 @@
+package infrastructure
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// testArgon2idParams keep tests fast; they are far too cheap for production.
+var testArgon2idParams = Argon2idParams{Memory: 64, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+
+func TestArgon2idHasher(t *testing.T) {
+	h := NewArgon2idHasher(testArgon2idParams)
+	hash, err := h.Hash("secret")
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=64,t=1,p=1$") || !h.Recognizes(hash) {
+		t.Errorf("Hash() = %q, want a PHC string with the costs", hash)
+	}
+	if other, _ := h.Hash("secret"); other == hash {
+		t.Error("Hash() twice gave the same hash, want a random salt")
+	}
+
+	if ok, err := h.Compare("secret", hash); !ok || err != nil {
+		t.Errorf("Compare(right password) = %v, %v; want true", ok, err)
+	}
+	if ok, err := h.Compare("wrong", hash); ok || err == nil {
+		t.Errorf("Compare(wrong password) = %v, %v; want false with an error", ok, err)
+	}
+	for _, bad := range []string{"", "$argon2id$v=19$m=64,t=1,p=1$c2FsdA", "$argon2id$v=16$m=64,t=1,p=1$c2FsdA$a2V5", "$argon2id$v=19$m=64,t=0,p=1$c2FsdA$a2V5", "$argon2i$v=19$m=64,t=1,p=1$c2FsdA$a2V5"} {
+		if ok, err := h.Compare("secret", bad); ok || err == nil {
+			t.Errorf("Compare(%q) = %v, %v; want an error", bad, ok, err)
+		}
+	}
+
+	if h.NeedsRehash(hash) {
+		t.Error("NeedsRehash(current) = true, want false")
+	}
+	stronger := testArgon2idParams
+	stronger.Iterations = 2
+	if !NewArgon2idHasher(stronger).NeedsRehash(hash) {
+		t.Error("NeedsRehash() with more iterations = false, want true")
+	}
+	if ok, _ := NewArgon2idHasher(stronger).Compare("secret", hash); !ok {
+		t.Error("Compare() with other costs = false, want the costs of the hash used")
+	}
+}
+
+func TestBcryptHasherNeedsRehash(t *testing.T) {
+	h := NewBcryptHasher(bcrypt.MinCost)
+	hash, err := h.Hash("secret")
+	if err != nil || !h.Recognizes(hash) {
+		t.Fatalf("Hash() = %q, %v; want a bcrypt hash", hash, err)
+	}
+	if h.NeedsRehash(hash) {
+		t.Error("NeedsRehash(current) = true, want false")
+	}
+	if !NewBcryptHasher(bcrypt.MinCost + 1).NeedsRehash(hash) {
+		t.Error("NeedsRehash() with a higher cost = false, want true")
+	}
+}
+
+func TestMultiHasher(t *testing.T) {
+	argon2id := NewArgon2idHasher(testArgon2idParams)
+	legacy := NewBcryptHasher(bcrypt.MinCost)
+	h := NewMultiHasher(argon2id, legacy)
+
+	hash, err := h.Hash("secret")
+	if err != nil || !argon2id.Recognizes(hash) {
+		t.Fatalf("Hash() = %q, %v; want an argon2id hash", hash, err)
+	}
+	old, _ := legacy.Hash("secret")
+	for _, hash := range []string{hash, old} {
+		if ok, err := h.Compare("secret", hash); !ok || err != nil {
+			t.Errorf("Compare(%q) = %v, %v; want true", hash, ok, err)
+		}
+		if ok, _ := h.Compare("wrong", hash); ok {
+			t.Errorf("Compare(wrong password, %q) = true, want false", hash)
+		}
+	}
+	if ok, err := h.Compare("secret", "fake$secret"); ok || err == nil {
+		t.Errorf("Compare(unrecognized) = %v, %v; want an error", ok, err)
+	}
+
+	if h.NeedsRehash(hash) {
+		t.Error("NeedsRehash(current) = true, want false")
+	}
+	if !h.NeedsRehash(old) {
+		t.Error("NeedsRehash(bcrypt) = false, want true")
+	}
+}
@@ -0,0 +1,47 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// NoPurchaseVerifier is a PurchaseVerifier for deployments without an order
+// system: no review is ever marked verified.
+type NoPurchaseVerifier struct{}
+
+func (NoPurchaseVerifier) HasPurchased(context.Context, int, string, int) (bool, error) {
+	return false, nil
+}
+
+// HTTPPurchaseVerifier is a PurchaseVerifier that asks the order system
+// whether a user bought a reviewable. 2xx responses mean they did, 404 and 410
+// mean they did not; anything else is an error.
+type HTTPPurchaseVerifier struct {
+	client      *http.Client
+	urlTemplate string
+}
+
+// NewHTTPPurchaseVerifier creates a verifier issuing GET requests to
+// urlTemplate with "{user}", "{type}" and "{id}" replaced, e.g.
+// "http://orders:8080/users/{user}/purchases/{type}/{id}". A nil client uses
+// http.DefaultClient; the request context bounds every call.
+func NewHTTPPurchaseVerifier(urlTemplate string, client *http.Client) *HTTPPurchaseVerifier {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &HTTPPurchaseVerifier{client: client, urlTemplate: urlTemplate}
+}
+
+func (v *HTTPPurchaseVerifier) HasPurchased(ctx context.Context, userID int, reviewableType string, reviewableID int) (bool, error) {
+	target := strings.NewReplacer(
+		"{user}", strconv.Itoa(userID),
+		"{type}", url.PathEscape(reviewableType),
+		"{id}", strconv.Itoa(reviewableID),
+	).Replace(v.urlTemplate)
+
+	return httpExists(ctx, v.client, target, fmt.Sprintf("verify purchase of %s:%d by user %d", reviewableType, reviewableID, userID))
+}
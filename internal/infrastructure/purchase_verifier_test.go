@@ -0,0 +1,44 @@
+package infrastructure
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHTTPPurchaseVerifier(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/users/7/purchases/product/42":
+			w.WriteHeader(http.StatusNoContent)
+		case "/users/7/purchases/product/500":
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	v := NewHTTPPurchaseVerifier(srv.URL+"/users/{user}/purchases/{type}/{id}", srv.Client())
+	tests := []struct {
+		userID  int
+		id      int
+		want    bool
+		wantErr bool
+	}{
+		{userID: 7, id: 42, want: true},
+		{userID: 8, id: 42, want: false},
+		{userID: 7, id: 43, want: false},
+		{userID: 7, id: 500, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := v.HasPurchased(context.Background(), tt.userID, "product", tt.id)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("HasPurchased(%d, product, %d) error = %v, wantErr %v", tt.userID, tt.id, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Errorf("HasPurchased(%d, product, %d) = %v, want %v", tt.userID, tt.id, got, tt.want)
+		}
+	}
+}
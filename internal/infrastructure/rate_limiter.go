@@ -0,0 +1,137 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"eve/internal/usecase"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// tokenBucket is a token bucket as of last.
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+	full   time.Time // when it is full again and can be forgotten
+}
+
+// MemoryRateLimiter is a usecase.RateLimiter keeping its buckets in process,
+// so every instance of the service enforces the limits on its own.
+type MemoryRateLimiter struct {
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+
+	now func() time.Time
+}
+
+func NewMemoryRateLimiter() *MemoryRateLimiter {
+	return &MemoryRateLimiter{buckets: make(map[string]*tokenBucket), now: time.Now}
+}
+
+// sweepInterval is how often buckets that refilled completely are dropped;
+// a missing bucket is the same as a full one.
+const sweepInterval = time.Minute
+
+func (l *MemoryRateLimiter) Take(ctx context.Context, key string, limit usecase.RateLimit) (usecase.RateLimitResult, error) {
+	if err := ctx.Err(); err != nil {
+		return usecase.RateLimitResult{}, err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if now.Sub(l.lastSweep) >= sweepInterval {
+		for k, b := range l.buckets {
+			if !now.Before(b.full) {
+				delete(l.buckets, k)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	interval := limit.Interval()
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: float64(limit.Burst), last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = min(float64(limit.Burst), b.tokens+float64(now.Sub(b.last))/float64(interval))
+	b.last = now
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+	b.full = now.Add(time.Duration((float64(limit.Burst) - b.tokens) * float64(interval)))
+	return limit.Result(allowed, b.tokens), nil
+}
+
+// Len returns the number of buckets held, full ones not swept yet included.
+func (l *MemoryRateLimiter) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.buckets)
+}
+
+// takeToken refills the bucket in KEYS[1] for the time since it was last used,
+// takes a token if there is one and lets the bucket expire once it would be
+// full again. ARGV holds the burst and the microseconds per token. It returns
+// whether a token was taken and the tokens left, as a string because Redis
+// truncates Lua numbers to integers. Time comes from the Redis server, so
+// instances with skewed clocks agree.
+var takeToken = redis.NewScript(`
+local burst = tonumber(ARGV[1])
+local interval = tonumber(ARGV[2])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000000 + tonumber(t[2])
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1]) or burst
+local ts = tonumber(state[2]) or now
+tokens = math.min(burst, tokens + math.max(0, now - ts) / interval)
+
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+redis.call('HSET', KEYS[1], 'tokens', tokens, 'ts', now)
+redis.call('PEXPIRE', KEYS[1], math.ceil((burst - tokens) * interval / 1000) + 1)
+return {allowed, tostring(tokens)}
+`)
+
+// RedisRateLimiter is a usecase.RateLimiter keeping its buckets in Redis, so
+// that the limits hold across every instance of the service.
+type RedisRateLimiter struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+// NewRedisRateLimiter creates a limiter prefixing every key with prefix, e.g.
+// "eve:ratelimit:".
+func NewRedisRateLimiter(client redis.UniversalClient, prefix string) *RedisRateLimiter {
+	return &RedisRateLimiter{client: client, prefix: prefix}
+}
+
+func (l *RedisRateLimiter) Take(ctx context.Context, key string, limit usecase.RateLimit) (usecase.RateLimitResult, error) {
+	res, err := takeToken.Run(ctx, l.client, []string{l.prefix + key}, limit.Burst, limit.Interval().Microseconds()).Slice()
+	if err != nil {
+		return usecase.RateLimitResult{}, fmt.Errorf("redis take token: %w", err)
+	}
+	if len(res) != 2 {
+		return usecase.RateLimitResult{}, fmt.Errorf("redis take token: unexpected reply %v", res)
+	}
+	allowed, _ := res[0].(int64)
+	left, _ := res[1].(string)
+	tokens, err := strconv.ParseFloat(left, 64)
+	if err != nil {
+		return usecase.RateLimitResult{}, fmt.Errorf("redis take token: tokens %q: %w", left, err)
+	}
+	return limit.Result(allowed == 1, tokens), nil
+}
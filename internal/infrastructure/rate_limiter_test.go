@@ -0,0 +1,47 @@
+package infrastructure
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"eve/internal/repository/repotest"
+	"eve/internal/usecase"
+)
+
+func TestRateLimiterRefillsAndForgetsFullBuckets(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1700000000, 0)
+	l := NewMemoryRateLimiter()
+	l.now = func() time.Time { return now }
+	limit := usecase.RateLimit{Burst: 2, Period: time.Minute}
+
+	for range 2 {
+		if res, _ := l.Take(ctx, "a", limit); !res.Allowed {
+			t.Fatalf("Take() = %+v, want allowed", res)
+		}
+	}
+	res, _ := l.Take(ctx, "a", limit)
+	if res.Allowed || res.RetryAfter != 30*time.Second || res.ResetAfter != time.Minute {
+		t.Fatalf("Take(empty) = %+v, want retry in 30s and reset in 1m", res)
+	}
+
+	now = now.Add(45 * time.Second) // one and a half tokens
+	if res, _ := l.Take(ctx, "a", limit); !res.Allowed || res.Remaining != 0 || res.ResetAfter != 45*time.Second {
+		t.Errorf("Take() after 45s = %+v, want allowed with half a token left", res)
+	}
+	if res, _ := l.Take(ctx, "a", limit); res.Allowed || res.RetryAfter != 15*time.Second {
+		t.Errorf("Take() = %+v, want refused until the half token refills", res)
+	}
+
+	_, _ = l.Take(ctx, "b", limit)
+	now = now.Add(time.Hour)
+	_, _ = l.Take(ctx, "c", limit)
+	if l.Len() != 1 {
+		t.Errorf("Len() = %d, want the full buckets swept", l.Len())
+	}
+}
+
+func TestMemoryRateLimiterContract(t *testing.T) {
+	repotest.RunRateLimiter(t, func(t *testing.T) usecase.RateLimiter { return NewMemoryRateLimiter() })
+}
@@ -0,0 +1,59 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"eve/internal/repository/repotest"
+	"eve/internal/usecase"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// TestRedisIdempotencyStore runs the idempotency part of the repository
+// conformance suite against Redis. It is skipped unless EVE_TEST_REDIS_ADDR
+// points at a Redis server, e.g. EVE_TEST_REDIS_ADDR=localhost:6379. Every
+// subtest uses its own key prefix.
+func TestRedisIdempotencyStore(t *testing.T) {
+	addr := os.Getenv("EVE_TEST_REDIS_ADDR")
+	if addr == "" {
+		t.Skip("EVE_TEST_REDIS_ADDR not set")
+	}
+	client := redis.NewClient(&redis.Options{Addr: addr})
+	t.Cleanup(func() { _ = client.Close() })
+
+	run := time.Now().UnixNano()
+	n := 0
+	repotest.RunIdempotencyStore(t, func(t *testing.T) repotest.Repos {
+		n++
+		prefix := fmt.Sprintf("eve-test:idempotency:%d:%d:", run, n)
+		t.Cleanup(func() {
+			ctx := context.Background()
+			keys, _ := client.Keys(ctx, prefix+"*").Result()
+			if len(keys) > 0 {
+				_ = client.Del(ctx, keys...).Err()
+			}
+		})
+		return repotest.Repos{Idempotency: NewRedisIdempotencyStore(client, prefix)}
+	})
+}
+
+// TestRedisRateLimiter runs the rate limiter conformance checks against
+// Redis. It is skipped unless EVE_TEST_REDIS_ADDR is set.
+func TestRedisRateLimiter(t *testing.T) {
+	addr := os.Getenv("EVE_TEST_REDIS_ADDR")
+	if addr == "" {
+		t.Skip("EVE_TEST_REDIS_ADDR not set")
+	}
+	client := redis.NewClient(&redis.Options{Addr: addr})
+	t.Cleanup(func() { _ = client.Close() })
+
+	prefix := fmt.Sprintf("eve-test:ratelimit:%d:", time.Now().UnixNano())
+	t.Cleanup(func() { _ = client.Del(context.Background(), prefix+"a", prefix+"b").Err() })
+	repotest.RunRateLimiter(t, func(t *testing.T) usecase.RateLimiter {
+		return NewRedisRateLimiter(client, prefix)
+	})
+}
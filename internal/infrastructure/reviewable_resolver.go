@@ -0,0 +1,211 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"eve/internal/usecase"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// AllowAllResolver is a ReviewableResolver that accepts every reviewable. It
+// keeps the behaviour from before existence checks were introduced.
+type AllowAllResolver struct{}
+
+func (AllowAllResolver) Exists(context.Context, string, int) (bool, error) {
+	return true, nil
+}
+
+// StaticResolver is a ReviewableResolver backed by a fixed allow-list, for
+// deployments whose catalog rarely changes and for local development.
+type StaticResolver struct {
+	ids   map[string]map[int]bool
+	anyID map[string]bool
+}
+
+// NewStaticResolver parses a comma-separated allow-list of "type:id" entries.
+// "type:*" allows every ID of a type, e.g. "product:1,product:2,vendor:*".
+func NewStaticResolver(allowList string) (*StaticResolver, error) {
+	r := &StaticResolver{ids: make(map[string]map[int]bool), anyID: make(map[string]bool)}
+	for _, entry := range strings.Split(allowList, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		typ, id, ok := strings.Cut(entry, ":")
+		if !ok || typ == "" {
+			return nil, fmt.Errorf("allow-list entry %q: want type:id or type:*", entry)
+		}
+		if id == "*" {
+			r.anyID[typ] = true
+			continue
+		}
+		n, err := strconv.Atoi(id)
+		if err != nil {
+			return nil, fmt.Errorf("allow-list entry %q: %w", entry, err)
+		}
+		if r.ids[typ] == nil {
+			r.ids[typ] = make(map[int]bool)
+		}
+		r.ids[typ][n] = true
+	}
+	return r, nil
+}
+
+func (r *StaticResolver) Exists(ctx context.Context, reviewableType string, reviewableID int) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	return r.anyID[reviewableType] || r.ids[reviewableType][reviewableID], nil
+}
+
+// HTTPResolver is a ReviewableResolver that asks the service owning a
+// reviewable whether it exists. 2xx responses mean it exists, 404 and 410 mean
+// it does not; anything else is an error.
+type HTTPResolver struct {
+	client      *http.Client
+	urlTemplate string
+}
+
+// NewHTTPResolver creates a resolver issuing GET requests to urlTemplate with
+// "{type}" and "{id}" replaced, e.g. "http://catalog:8080/{type}s/{id}".
+// A nil client uses http.DefaultClient; the request context bounds every call.
+func NewHTTPResolver(urlTemplate string, client *http.Client) *HTTPResolver {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &HTTPResolver{client: client, urlTemplate: urlTemplate}
+}
+
+func (r *HTTPResolver) Exists(ctx context.Context, reviewableType string, reviewableID int) (bool, error) {
+	target := strings.NewReplacer(
+		"{type}", url.PathEscape(reviewableType),
+		"{id}", strconv.Itoa(reviewableID),
+	).Replace(r.urlTemplate)
+
+	return httpExists(ctx, r.client, target, fmt.Sprintf("look up %s:%d", reviewableType, reviewableID))
+}
+
+// httpExists issues a GET request to target and maps the response to an
+// answer: 2xx is yes, 404 and 410 are no, anything else is an error prefixed
+// with op.
+func httpExists(ctx context.Context, client *http.Client, target, op string) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10)) // lets the connection be reused
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return true, nil
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return false, nil
+	default:
+		return false, fmt.Errorf("%s: unexpected status %s", op, resp.Status)
+	}
+}
+
+// CachingResolver remembers the answers of another ReviewableResolver.
+// Positive and negative answers have separate lifetimes: a reviewable that
+// does not exist yet may be created soon, so misses are usually kept for a
+// shorter time. Errors are never cached.
+type CachingResolver struct {
+	next        usecase.ReviewableResolver
+	positiveTTL time.Duration
+	negativeTTL time.Duration
+	maxEntries  int
+	now         func() time.Time
+
+	mu      sync.Mutex
+	entries map[resolverKey]resolverEntry
+}
+
+type resolverKey struct {
+	reviewableType string
+	reviewableID   int
+}
+
+type resolverEntry struct {
+	exists  bool
+	expires time.Time
+}
+
+// NewCachingResolver wraps next. A zero TTL disables caching of that kind of
+// answer. At most maxEntries answers are kept.
+func NewCachingResolver(next usecase.ReviewableResolver, positiveTTL, negativeTTL time.Duration, maxEntries int) *CachingResolver {
+	return &CachingResolver{
+		next:        next,
+		positiveTTL: positiveTTL,
+		negativeTTL: negativeTTL,
+		maxEntries:  maxEntries,
+		now:         time.Now,
+		entries:     make(map[resolverKey]resolverEntry),
+	}
+}
+
+func (c *CachingResolver) Exists(ctx context.Context, reviewableType string, reviewableID int) (bool, error) {
+	key := resolverKey{reviewableType, reviewableID}
+
+	c.mu.Lock()
+	e, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok && c.now().Before(e.expires) {
+		return e.exists, nil
+	}
+
+	exists, err := c.next.Exists(ctx, reviewableType, reviewableID)
+	if err != nil {
+		return false, err
+	}
+
+	ttl := c.negativeTTL
+	if exists {
+		ttl = c.positiveTTL
+	}
+	if ttl > 0 {
+		c.store(key, resolverEntry{exists: exists, expires: c.now().Add(ttl)})
+	}
+	return exists, nil
+}
+
+// store adds an entry, making room by dropping expired entries first and then
+// arbitrary ones.
+func (c *CachingResolver) store(key resolverKey, e resolverEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= c.maxEntries {
+		now := c.now()
+		for k, old := range c.entries {
+			if !now.Before(old.expires) {
+				delete(c.entries, k)
+			}
+		}
+		for k := range c.entries {
+			if len(c.entries) < c.maxEntries {
+				break
+			}
+			delete(c.entries, k)
+		}
+	}
+	if c.maxEntries > 0 {
+		c.entries[key] = e
+	}
+}
@@ -0,0 +1,147 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHTTPResolver(t *testing.T) {
+	var gotPath string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		switch r.URL.Path {
+		case "/products/1":
+			_, _ = w.Write([]byte(`{"id":1}`))
+		case "/products/2":
+			w.WriteHeader(http.StatusGone)
+		case "/products/3":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	r := NewHTTPResolver(srv.URL+"/{type}s/{id}", srv.Client())
+	tests := []struct {
+		id      int
+		want    bool
+		wantErr bool
+	}{
+		{id: 1, want: true},
+		{id: 2, want: false},
+		{id: 3, wantErr: true},
+		{id: 4, want: false},
+	}
+	for _, tt := range tests {
+		got, err := r.Exists(context.Background(), "product", tt.id)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("Exists(product, %d) error = %v, wantErr %v", tt.id, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Errorf("Exists(product, %d) = %v, want %v", tt.id, got, tt.want)
+		}
+	}
+	if gotPath != "/products/4" {
+		t.Errorf("last request path = %q, want /products/4", gotPath)
+	}
+}
+
+func TestStaticResolver(t *testing.T) {
+	r, err := NewStaticResolver("product:1, product:2,vendor:*")
+	if err != nil {
+		t.Fatalf("NewStaticResolver() error = %v", err)
+	}
+	tests := []struct {
+		typ  string
+		id   int
+		want bool
+	}{
+		{"product", 1, true},
+		{"product", 3, false},
+		{"vendor", 99, true},
+		{"user", 1, false},
+	}
+	for _, tt := range tests {
+		if got, _ := r.Exists(context.Background(), tt.typ, tt.id); got != tt.want {
+			t.Errorf("Exists(%s, %d) = %v, want %v", tt.typ, tt.id, got, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"product", "product:x", ":1"} {
+		if _, err := NewStaticResolver(bad); err == nil {
+			t.Errorf("NewStaticResolver(%q) error = nil, want error", bad)
+		}
+	}
+}
+
+// countingResolver answers from a map and counts the lookups it serves.
+type countingResolver struct {
+	exists map[int]bool
+	err    error
+	calls  int
+}
+
+func (c *countingResolver) Exists(_ context.Context, _ string, id int) (bool, error) {
+	c.calls++
+	return c.exists[id], c.err
+}
+
+func TestCachingResolver(t *testing.T) {
+	ctx := context.Background()
+	clock := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	next := &countingResolver{exists: map[int]bool{1: true}}
+	c := NewCachingResolver(next, time.Minute, 10*time.Second, 10)
+	c.now = func() time.Time { return clock }
+
+	lookup := func(id int, want bool, wantCalls int) {
+		t.Helper()
+		got, err := c.Exists(ctx, "product", id)
+		if err != nil {
+			t.Fatalf("Exists(product, %d) error = %v", id, err)
+		}
+		if got != want {
+			t.Errorf("Exists(product, %d) = %v, want %v", id, got, want)
+		}
+		if next.calls != wantCalls {
+			t.Errorf("after Exists(product, %d) the wrapped resolver was called %d times, want %d", id, next.calls, wantCalls)
+		}
+	}
+
+	lookup(1, true, 1)
+	lookup(1, true, 1)
+	lookup(2, false, 2)
+	lookup(2, false, 2)
+
+	// The negative answer expires first.
+	clock = clock.Add(30 * time.Second)
+	next.exists[2] = true
+	lookup(2, true, 3)
+	lookup(1, true, 3)
+
+	clock = clock.Add(time.Minute)
+	lookup(1, true, 4)
+
+	// Errors are returned but not remembered.
+	next.err = errors.New("catalog unavailable")
+	if _, err := c.Exists(ctx, "product", 5); err == nil {
+		t.Fatal("Exists() error = nil, want the wrapped resolver's error")
+	}
+	next.err = nil
+	lookup(5, false, 6)
+}
+
+func TestCachingResolverEvicts(t *testing.T) {
+	next := &countingResolver{exists: map[int]bool{}}
+	c := NewCachingResolver(next, time.Minute, time.Minute, 2)
+	for id := 1; id <= 5; id++ {
+		_, _ = c.Exists(context.Background(), "product", id)
+	}
+	if n := len(c.entries); n > 2 {
+		t.Errorf("cache holds %d entries, want at most 2", n)
+	}
+}
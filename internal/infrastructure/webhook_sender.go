@@ -0,0 +1,76 @@
+package infrastructure
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"eve/domain"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// Headers of webhook requests.
+const (
+	WebhookEventHeader     = "X-Eve-Event"     // event type
+	WebhookDeliveryHeader  = "X-Eve-Delivery"  // delivery ID, stable across retries
+	WebhookTimestampHeader = "X-Eve-Timestamp" // Unix seconds when the request was signed
+	WebhookSignatureHeader = "X-Eve-Signature" // "sha256=" + SignWebhook(...)
+)
+
+// SignWebhook returns the hex-encoded HMAC-SHA256, keyed with secret, of
+// timestamp, a dot and body. Receivers recompute it to authenticate a
+// delivery and reject stale timestamps to prevent replays.
+func SignWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// HTTPWebhookSender is a WebhookSender that POSTs the payload of a delivery
+// as JSON to the subscription URL, signed with the subscription secret.
+type HTTPWebhookSender struct {
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewHTTPWebhookSender creates a sender. A nil client uses
+// http.DefaultClient; the request context bounds every call.
+func NewHTTPWebhookSender(client *http.Client) *HTTPWebhookSender {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &HTTPWebhookSender{client: client, now: time.Now}
+}
+
+func (s *HTTPWebhookSender) Send(ctx context.Context, sub domain.WebhookSubscription, d domain.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("send webhook delivery %d: %w", d.ID, err)
+	}
+	timestamp := strconv.FormatInt(s.now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, d.EventType)
+	req.Header.Set(WebhookDeliveryHeader, strconv.Itoa(d.ID))
+	req.Header.Set(WebhookTimestampHeader, timestamp)
+	req.Header.Set(WebhookSignatureHeader, "sha256="+SignWebhook(sub.Secret, timestamp, d.Payload))
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("send webhook delivery %d: %w", d.ID, err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10)) // lets the connection be reused
+	return resp.StatusCode, nil
+}
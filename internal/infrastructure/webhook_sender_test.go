@@ -0,0 +1,67 @@
+package infrastructure
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"eve/domain"
+)
+
+func TestHTTPWebhookSender(t *testing.T) {
+	type received struct {
+		header http.Header
+		body   []byte
+	}
+	got := make(chan received, 1)
+	status := http.StatusNoContent
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		code := status
+		body, _ := io.ReadAll(r.Body)
+		got <- received{header: r.Header.Clone(), body: body}
+		w.WriteHeader(code)
+	}))
+	defer srv.Close()
+
+	s := NewHTTPWebhookSender(srv.Client())
+	s.now = func() time.Time { return time.Unix(1700000000, 0) }
+	sub := domain.WebhookSubscription{URL: srv.URL + "/hook", Secret: "topsecret-topsecret"}
+	d := domain.WebhookDelivery{ID: 12, EventType: domain.EventReviewCreated, Payload: []byte(`{"id":5}`)}
+
+	code, err := s.Send(context.Background(), sub, d)
+	if err != nil || code != http.StatusNoContent {
+		t.Fatalf("Send() = %d, %v; want 204", code, err)
+	}
+	r := <-got
+	if string(r.body) != `{"id":5}` {
+		t.Errorf("body = %s, want the payload", r.body)
+	}
+	for header, want := range map[string]string{
+		"Content-Type":         "application/json",
+		WebhookEventHeader:     domain.EventReviewCreated,
+		WebhookDeliveryHeader:  "12",
+		WebhookTimestampHeader: "1700000000",
+		WebhookSignatureHeader: "sha256=" + SignWebhook("topsecret-topsecret", "1700000000", []byte(`{"id":5}`)),
+	} {
+		if v := r.header.Get(header); v != want {
+			t.Errorf("%s = %q, want %q", header, v, want)
+		}
+	}
+	if SignWebhook("other-secret", "1700000000", r.body) == SignWebhook("topsecret-topsecret", "1700000000", r.body) {
+		t.Error("signature does not depend on the secret")
+	}
+
+	status = http.StatusInternalServerError
+	if code, err := s.Send(context.Background(), sub, d); err != nil || code != http.StatusInternalServerError {
+		t.Errorf("Send() to failing receiver = %d, %v; want 500 and no error", code, err)
+	}
+	<-got
+
+	srv.Close()
+	if _, err := s.Send(context.Background(), sub, d); err == nil {
+		t.Error("Send() to closed receiver error = nil, want error")
+	}
+}
@@ -0,0 +1,31 @@
+package cache_test
+
+import (
+	"testing"
+	"time"
+
+	"eve/internal/logging"
+	"eve/internal/repository/cache"
+	"eve/internal/repository/memory"
+	"eve/internal/repository/repotest"
+)
+
+// TestContract runs the conformance suite through the cache, which must not
+// be observable: every write has to invalidate what it changes.
+func TestContract(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repotest.Repos {
+		reviews := memory.NewReviewRepo()
+		return repotest.Repos{
+			Users:         memory.NewUserRepo(),
+			Reviews:       cache.NewReviewRepo(reviews, cache.NewLRUStore(1000), time.Minute, cache.NopMetrics{}, logging.Nop()),
+			Owners:        memory.NewOwnerRepo(),
+			Criteria:      memory.NewCriteriaRepo(),
+			Types:         memory.NewReviewableTypeRepo(),
+			Outbox:        reviews,
+			Webhooks:      memory.NewWebhookRepo(),
+			Idempotency:   memory.NewIdempotencyStore(),
+			LoginAttempts: memory.NewLoginAttemptRepo(),
+			APIKeys:       memory.NewAPIKeyRepo(),
+		}
+	})
+}
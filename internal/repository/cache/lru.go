@@ -0,0 +1,115 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// LRUStore is an in-process Store holding at most maxEntries entries; the
+// least recently used one is evicted to make room. It suits single-instance
+// deployments: with several instances, one instance's writes do not
+// invalidate the others' entries, which then stay stale until they expire.
+type LRUStore struct {
+	maxEntries int
+	now        func() time.Time
+
+	mu      sync.Mutex
+	order   *list.List // of *lruEntry, most recently used first
+	entries map[string]*list.Element
+}
+
+type lruEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+func NewLRUStore(maxEntries int) *LRUStore {
+	return &LRUStore{
+		maxEntries: maxEntries,
+		now:        time.Now,
+		order:      list.New(),
+		entries:    make(map[string]*list.Element),
+	}
+}
+
+func (s *LRUStore) Get(ctx context.Context, keys ...string) (map[string][]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	found := make(map[string][]byte, len(keys))
+	now := s.now()
+	for _, key := range keys {
+		el, ok := s.entries[key]
+		if !ok {
+			continue
+		}
+		e := el.Value.(*lruEntry)
+		if !now.Before(e.expires) {
+			s.remove(el)
+			continue
+		}
+		s.order.MoveToFront(el)
+		found[key] = e.value
+	}
+	return found, nil
+}
+
+func (s *LRUStore) Set(ctx context.Context, entries map[string][]byte, ttl time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	expires := s.now().Add(ttl)
+	for key, value := range entries {
+		if el, ok := s.entries[key]; ok {
+			e := el.Value.(*lruEntry)
+			e.value, e.expires = value, expires
+			s.order.MoveToFront(el)
+			continue
+		}
+		s.entries[key] = s.order.PushFront(&lruEntry{key: key, value: value, expires: expires})
+		if s.order.Len() > s.maxEntries {
+			s.remove(s.order.Back())
+		}
+	}
+	return nil
+}
+
+func (s *LRUStore) Delete(ctx context.Context, keys ...string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, key := range keys {
+		if el, ok := s.entries[key]; ok {
+			s.remove(el)
+		}
+	}
+	return nil
+}
+
+// Len returns the number of stored entries, expired ones included.
+func (s *LRUStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.order.Len()
+}
+
+// remove drops an entry; the caller must hold the lock.
+func (s *LRUStore) remove(el *list.Element) {
+	s.order.Remove(el)
+	delete(s.entries, el.Value.(*lruEntry).key)
+}
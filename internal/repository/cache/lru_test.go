@@ -0,0 +1,40 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLRUStore(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1700000000, 0)
+	s := NewLRUStore(2)
+	s.now = func() time.Time { return now }
+
+	if err := s.Set(ctx, map[string][]byte{"a": []byte("1"), "b": []byte("2")}, time.Minute); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if got, _ := s.Get(ctx, "a"); string(got["a"]) != "1" { // a is now the most recently used
+		t.Fatalf("Get(a) = %v", got)
+	}
+	_ = s.Set(ctx, map[string][]byte{"c": []byte("3")}, time.Minute)
+
+	got, err := s.Get(ctx, "a", "b", "c")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if _, ok := got["b"]; ok || len(got) != 2 || s.Len() != 2 {
+		t.Errorf("Get() = %v, want b evicted as least recently used", got)
+	}
+
+	_ = s.Delete(ctx, "a", "missing")
+	if got, _ := s.Get(ctx, "a"); len(got) != 0 {
+		t.Errorf("Get(a) after Delete = %v", got)
+	}
+
+	now = now.Add(time.Minute)
+	if got, _ := s.Get(ctx, "c"); len(got) != 0 || s.Len() != 0 {
+		t.Errorf("Get(c) after expiry = %v (len %d), want it dropped", got, s.Len())
+	}
+}
@@ -0,0 +1,73 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisStore is a Store backed by Redis, shared by every instance of the
+// service. Keys are namespaced with a prefix so the database can be shared
+// with other data.
+type RedisStore struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+// NewRedisStore creates a store prefixing every key with prefix, e.g. "eve:".
+func NewRedisStore(client redis.UniversalClient, prefix string) *RedisStore {
+	return &RedisStore{client: client, prefix: prefix}
+}
+
+func (s *RedisStore) Get(ctx context.Context, keys ...string) (map[string][]byte, error) {
+	if len(keys) == 0 {
+		return map[string][]byte{}, nil
+	}
+	values, err := s.client.MGet(ctx, s.keys(keys)...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("redis mget: %w", err)
+	}
+	found := make(map[string][]byte, len(keys))
+	for i, v := range values {
+		if str, ok := v.(string); ok { // nil for missing keys
+			found[keys[i]] = []byte(str)
+		}
+	}
+	return found, nil
+}
+
+func (s *RedisStore) Set(ctx context.Context, entries map[string][]byte, ttl time.Duration) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	_, err := s.client.Pipelined(ctx, func(p redis.Pipeliner) error {
+		for key, value := range entries {
+			p.Set(ctx, s.prefix+key, value, ttl)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("redis set: %w", err)
+	}
+	return nil
+}
+
+func (s *RedisStore) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	if err := s.client.Del(ctx, s.keys(keys)...).Err(); err != nil {
+		return fmt.Errorf("redis del: %w", err)
+	}
+	return nil
+}
+
+func (s *RedisStore) keys(keys []string) []string {
+	prefixed := make([]string, len(keys))
+	for i, k := range keys {
+		prefixed[i] = s.prefix + k
+	}
+	return prefixed
+}
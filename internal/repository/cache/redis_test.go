@@ -0,0 +1,47 @@
+package cache_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"eve/internal/repository/cache"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// TestRedisStore is skipped unless EVE_TEST_REDIS_ADDR points at a Redis
+// server, e.g. EVE_TEST_REDIS_ADDR=localhost:6379. It only touches keys
+// under a test prefix.
+func TestRedisStore(t *testing.T) {
+	addr := os.Getenv("EVE_TEST_REDIS_ADDR")
+	if addr == "" {
+		t.Skip("EVE_TEST_REDIS_ADDR not set")
+	}
+	ctx := context.Background()
+	client := redis.NewClient(&redis.Options{Addr: addr})
+	t.Cleanup(func() { _ = client.Close() })
+	s := cache.NewRedisStore(client, "eve-test:")
+	t.Cleanup(func() { _ = s.Delete(ctx, "a", "b") })
+
+	if err := s.Set(ctx, map[string][]byte{"a": []byte("1"), "b": []byte("2")}, time.Minute); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	got, err := s.Get(ctx, "a", "b", "missing")
+	if err != nil || string(got["a"]) != "1" || string(got["b"]) != "2" || len(got) != 2 {
+		t.Fatalf("Get() = %v, %v; want a and b", got, err)
+	}
+	if err := s.Delete(ctx, "a"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if got, _ := s.Get(ctx, "a"); len(got) != 0 {
+		t.Errorf("Get(a) after Delete = %v", got)
+	}
+
+	_ = s.Set(ctx, map[string][]byte{"a": []byte("1")}, 50*time.Millisecond)
+	time.Sleep(100 * time.Millisecond)
+	if got, _ := s.Get(ctx, "a"); len(got) != 0 {
+		t.Errorf("Get(a) after expiry = %v", got)
+	}
+}
@@ -0,0 +1,385 @@
+package cache
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"eve/domain"
+	"eve/internal/usecase"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// Kinds of cached entries, as reported to Metrics.
+const (
+	KindReview     = "review"
+	KindList       = "review_list"
+	KindComments   = "comments"
+	KindSubRatings = "sub_ratings"
+	KindResponse   = "response"
+)
+
+// loadTimeout bounds a load shared by concurrent misses, which no single
+// caller's context does.
+const loadTimeout = 10 * time.Second
+
+// ReviewRepo is a usecase.ReviewRepository that serves the reads behind the
+// review pages (GetByID, ListByReviewable, ListComments, ListSubRatings,
+// GetResponse and ListResponses) from a Store, loading missing entries from
+// the wrapped repository. Every other method is passed through.
+//
+// Writes go to the wrapped repository first and then delete the entries they
+// affect. Concurrent misses of the same entries are collapsed into a single
+// load, and expiry is jittered so that entries filled together do not expire
+// together. The Store is best effort: when it fails, reads fall back to the
+// wrapped repository and a failed invalidation leaves a stale entry until it
+// expires. A read racing with a write may also cache the value from before
+// the write, so ttl bounds how stale a read can be.
+type ReviewRepo struct {
+	usecase.ReviewRepository
+
+	store   Store
+	ttl     time.Duration
+	metrics Metrics
+	log     *slog.Logger
+	loads   singleflight.Group
+}
+
+// NewReviewRepo wraps next with a cache keeping entries in store for about ttl.
+func NewReviewRepo(next usecase.ReviewRepository, store Store, ttl time.Duration, m Metrics, l *slog.Logger) *ReviewRepo {
+	return &ReviewRepo{ReviewRepository: next, store: store, ttl: ttl, metrics: m, log: l}
+}
+
+func reviewKey(id int) string     { return "review:" + strconv.Itoa(id) }
+func commentsKey(id int) string   { return "comments:" + strconv.Itoa(id) }
+func subRatingsKey(id int) string { return "sub_ratings:" + strconv.Itoa(id) }
+func responseKey(id int) string   { return "response:" + strconv.Itoa(id) }
+
+func listKey(reviewableType string, reviewableID int, verifiedOnly bool) string {
+	return fmt.Sprintf("reviews:%s:%d:%t", reviewableType, reviewableID, verifiedOnly)
+}
+
+// --- Reads ---
+
+func (r *ReviewRepo) GetByID(ctx context.Context, id int) (domain.Review, error) {
+	return cached(ctx, r, KindReview, reviewKey(id), func(ctx context.Context) (domain.Review, error) {
+		return r.ReviewRepository.GetByID(ctx, id)
+	})
+}
+
+func (r *ReviewRepo) ListByReviewable(ctx context.Context, reviewableType string, reviewableID int, verifiedOnly bool) ([]domain.Review, error) {
+	key := listKey(reviewableType, reviewableID, verifiedOnly)
+	return cached(ctx, r, KindList, key, func(ctx context.Context) ([]domain.Review, error) {
+		return r.ReviewRepository.ListByReviewable(ctx, reviewableType, reviewableID, verifiedOnly)
+	})
+}
+
+func (r *ReviewRepo) ListComments(ctx context.Context, reviewID int) ([]domain.ReviewComment, error) {
+	return cached(ctx, r, KindComments, commentsKey(reviewID), func(ctx context.Context) ([]domain.ReviewComment, error) {
+		return r.ReviewRepository.ListComments(ctx, reviewID)
+	})
+}
+
+func (r *ReviewRepo) GetResponse(ctx context.Context, reviewID int) (domain.ReviewResponse, error) {
+	responses, err := r.ListResponses(ctx, []int{reviewID})
+	if err != nil {
+		return domain.ReviewResponse{}, err
+	}
+	resp, ok := responses[reviewID]
+	if !ok {
+		return domain.ReviewResponse{}, fmt.Errorf("get response: %w", sql.ErrNoRows)
+	}
+	return resp, nil
+}
+
+func (r *ReviewRepo) ListResponses(ctx context.Context, reviewIDs []int) (map[int]domain.ReviewResponse, error) {
+	return cachedByID(ctx, r, KindResponse, responseKey, reviewIDs, r.ReviewRepository.ListResponses)
+}
+
+func (r *ReviewRepo) ListSubRatings(ctx context.Context, reviewIDs []int) (map[int]map[string]int, error) {
+	return cachedByID(ctx, r, KindSubRatings, subRatingsKey, reviewIDs, r.ReviewRepository.ListSubRatings)
+}
+
+// cached returns the entry stored under key, or loads, stores and returns it.
+// Errors are returned as they are and never cached. Every caller decodes its
+// own copy of the entry, so callers may modify what they get.
+func cached[T any](ctx context.Context, r *ReviewRepo, kind, key string, load func(context.Context) (T, error)) (T, error) {
+	var v T
+	if data, ok := r.lookup(ctx, key); ok && json.Unmarshal(data, &v) == nil {
+		r.metrics.CacheLookup(kind, true)
+		return v, nil
+	}
+	r.metrics.CacheLookup(kind, false)
+
+	data, err := r.load(ctx, key, func(ctx context.Context) (any, error) {
+		loaded, err := load(ctx)
+		if err != nil {
+			return nil, err
+		}
+		data, err := json.Marshal(loaded)
+		if err != nil {
+			return nil, fmt.Errorf("encode %s: %w", kind, err)
+		}
+		r.save(ctx, map[string][]byte{key: data})
+		return data, nil
+	})
+	if err != nil {
+		return v, err
+	}
+	if err := json.Unmarshal(data.([]byte), &v); err != nil {
+		return v, fmt.Errorf("decode %s: %w", kind, err)
+	}
+	return v, nil
+}
+
+// cachedByID is cached for batch reads keyed by review ID, whose results
+// have no entry for reviews without a value. Every review is cached
+// separately, including the absence of a value, and only the reviews
+// missing from the cache are loaded.
+func cachedByID[V any](ctx context.Context, r *ReviewRepo, kind string, key func(int) string, ids []int, load func(context.Context, []int) (map[int]V, error)) (map[int]V, error) {
+	result := make(map[int]V, len(ids))
+	if len(ids) == 0 {
+		return result, nil
+	}
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = key(id)
+	}
+	found, _ := r.lookupAll(ctx, keys)
+
+	var missing []int
+	for i, id := range ids {
+		var v *V
+		if data, ok := found[keys[i]]; ok && json.Unmarshal(data, &v) == nil {
+			r.metrics.CacheLookup(kind, true)
+			if v != nil {
+				result[id] = *v
+			}
+			continue
+		}
+		r.metrics.CacheLookup(kind, false)
+		missing = append(missing, id)
+	}
+	if len(missing) == 0 {
+		return result, nil
+	}
+
+	slices.Sort(missing)
+	missing = slices.Compact(missing)
+	flight := make([]string, len(missing))
+	for i, id := range missing {
+		flight[i] = strconv.Itoa(id)
+	}
+	data, err := r.load(ctx, kind+":"+strings.Join(flight, ","), func(ctx context.Context) (any, error) {
+		loaded, err := load(ctx, missing)
+		if err != nil {
+			return nil, err
+		}
+		entries := make(map[string][]byte, len(missing))
+		for _, id := range missing {
+			var v *V
+			if found, ok := loaded[id]; ok {
+				v = &found
+			}
+			if entries[key(id)], err = json.Marshal(v); err != nil {
+				return nil, fmt.Errorf("encode %s: %w", kind, err)
+			}
+		}
+		r.save(ctx, entries)
+		return entries, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	entries := data.(map[string][]byte)
+	for _, id := range missing {
+		var v *V
+		if err := json.Unmarshal(entries[key(id)], &v); err != nil {
+			return nil, fmt.Errorf("decode %s: %w", kind, err)
+		}
+		if v != nil {
+			result[id] = *v
+		}
+	}
+	return result, nil
+}
+
+// load calls f once for all concurrent callers sharing key. Since callers
+// join a load started by another, f runs detached from the context of the
+// first caller, bounded by loadTimeout, and every caller stops waiting when
+// its own ctx is done.
+func (r *ReviewRepo) load(ctx context.Context, key string, f func(context.Context) (any, error)) (any, error) {
+	ch := r.loads.DoChan(key, func() (any, error) {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), loadTimeout)
+		defer cancel()
+		return f(ctx)
+	})
+	select {
+	case res := <-ch:
+		return res.Val, res.Err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// lookup returns the entry stored under key. A store failure counts as a miss.
+func (r *ReviewRepo) lookup(ctx context.Context, key string) ([]byte, bool) {
+	found, _ := r.lookupAll(ctx, []string{key})
+	data, ok := found[key]
+	return data, ok
+}
+
+func (r *ReviewRepo) lookupAll(ctx context.Context, keys []string) (map[string][]byte, error) {
+	found, err := r.store.Get(ctx, keys...)
+	if err != nil {
+		r.warn(ctx, "read", err)
+	}
+	return found, err
+}
+
+// save stores entries with a jittered expiry of ttl plus up to 10%.
+func (r *ReviewRepo) save(ctx context.Context, entries map[string][]byte) {
+	ttl := r.ttl
+	if jitter := ttl / 10; jitter > 0 {
+		ttl += rand.N(jitter)
+	}
+	if err := r.store.Set(ctx, entries, ttl); err != nil {
+		r.warn(ctx, "write", err)
+	}
+}
+
+// --- Writes ---
+
+func (r *ReviewRepo) Create(ctx context.Context, review domain.Review) (int, error) {
+	id, err := r.ReviewRepository.Create(ctx, review)
+	if err != nil {
+		return 0, err
+	}
+	review.ID = id
+	r.invalidate(ctx, reviewKeys(review)...)
+	return id, nil
+}
+
+func (r *ReviewRepo) AddComment(ctx context.Context, comment domain.ReviewComment) (int, error) {
+	id, err := r.ReviewRepository.AddComment(ctx, comment)
+	if err != nil {
+		return 0, err
+	}
+	r.invalidate(ctx, commentsKey(comment.ReviewID))
+	return id, nil
+}
+
+func (r *ReviewRepo) UpdateReview(ctx context.Context, review domain.Review, actorID int, version string) (domain.Review, error) {
+	stored, err := r.ReviewRepository.UpdateReview(ctx, review, actorID, version)
+	if err != nil {
+		return domain.Review{}, err
+	}
+	r.invalidate(ctx, reviewKeys(stored)...)
+	return stored, nil
+}
+
+func (r *ReviewRepo) UpdateComment(ctx context.Context, comment domain.ReviewComment, actorID int) (domain.ReviewComment, error) {
+	stored, err := r.ReviewRepository.UpdateComment(ctx, comment, actorID)
+	if err != nil {
+		return domain.ReviewComment{}, err
+	}
+	r.invalidate(ctx, commentsKey(stored.ReviewID))
+	return stored, nil
+}
+
+func (r *ReviewRepo) SaveResponse(ctx context.Context, resp domain.ReviewResponse) (domain.ReviewResponse, error) {
+	stored, err := r.ReviewRepository.SaveResponse(ctx, resp)
+	if err != nil {
+		return domain.ReviewResponse{}, err
+	}
+	r.invalidate(ctx, responseKey(resp.ReviewID))
+	return stored, nil
+}
+
+// DeleteReview looks the review up first: once deleted it can no longer be
+// loaded to find the listing it must be removed from.
+func (r *ReviewRepo) DeleteReview(ctx context.Context, id int, version string) error {
+	review, lookupErr := r.ReviewRepository.GetByID(ctx, id)
+	if err := r.ReviewRepository.DeleteReview(ctx, id, version); err != nil {
+		return err
+	}
+	if lookupErr != nil {
+		review = domain.Review{ID: id} // already deleted or missing
+	}
+	r.invalidate(ctx, reviewKeys(review)...)
+	return nil
+}
+
+func (r *ReviewRepo) RestoreReview(ctx context.Context, id int) error {
+	if err := r.ReviewRepository.RestoreReview(ctx, id); err != nil {
+		return err
+	}
+	r.invalidateReview(ctx, id)
+	return nil
+}
+
+func (r *ReviewRepo) ApproveReview(ctx context.Context, id int) error {
+	if err := r.ReviewRepository.ApproveReview(ctx, id); err != nil {
+		return err
+	}
+	r.invalidateReview(ctx, id)
+	return nil
+}
+
+func (r *ReviewRepo) PurgeDeletedReviews(ctx context.Context, deletedBefore time.Time, limit int) (domain.PurgedReviews, error) {
+	purged, err := r.ReviewRepository.PurgeDeletedReviews(ctx, deletedBefore, limit)
+	if err != nil {
+		return domain.PurgedReviews{}, err
+	}
+	var keys []string
+	for _, id := range purged.ReviewIDs {
+		keys = append(keys, reviewKeys(domain.Review{ID: id})...)
+	}
+	r.invalidate(ctx, keys...)
+	return purged, nil
+}
+
+// reviewKeys returns the keys of every entry showing review. The listings
+// are only included when the reviewable of the review is set.
+func reviewKeys(review domain.Review) []string {
+	keys := []string{reviewKey(review.ID), commentsKey(review.ID), subRatingsKey(review.ID), responseKey(review.ID)}
+	if review.ReviewableType != "" {
+		keys = append(keys,
+			listKey(review.ReviewableType, review.ReviewableID, false),
+			listKey(review.ReviewableType, review.ReviewableID, true),
+		)
+	}
+	return keys
+}
+
+// invalidateReview invalidates the entries of review id after a write
+// that leaves it readable.
+func (r *ReviewRepo) invalidateReview(ctx context.Context, id int) {
+	review, err := r.ReviewRepository.GetByID(ctx, id)
+	if err != nil {
+		review = domain.Review{ID: id}
+		if !errors.Is(err, context.Canceled) {
+			r.warn(ctx, "load review "+strconv.Itoa(id)+" to invalidate its listing", err)
+		}
+	}
+	r.invalidate(ctx, reviewKeys(review)...)
+}
+
+func (r *ReviewRepo) invalidate(ctx context.Context, keys ...string) {
+	if err := r.store.Delete(ctx, keys...); err != nil {
+		r.warn(ctx, "invalidate", err)
+	}
+}
+
+func (r *ReviewRepo) warn(ctx context.Context, op string, err error) {
+	r.log.WarnContext(ctx, "review cache "+op+" failed", slog.String("error", err.Error()))
+}
@@ -0,0 +1,251 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"eve/domain"
+	"eve/internal/logging"
+	"eve/internal/repository/cache"
+	"eve/internal/repository/memory"
+	"eve/internal/usecase"
+)
+
+// countingRepo counts the reads reaching the wrapped repository and can
+// delay them.
+type countingRepo struct {
+	usecase.ReviewRepository
+	delay time.Duration
+	reads atomic.Int32
+}
+
+func (r *countingRepo) GetByID(ctx context.Context, id int) (domain.Review, error) {
+	r.reads.Add(1)
+	time.Sleep(r.delay)
+	return r.ReviewRepository.GetByID(ctx, id)
+}
+
+func (r *countingRepo) ListByReviewable(ctx context.Context, reviewableType string, reviewableID int, verifiedOnly bool) ([]domain.Review, error) {
+	r.reads.Add(1)
+	return r.ReviewRepository.ListByReviewable(ctx, reviewableType, reviewableID, verifiedOnly)
+}
+
+func (r *countingRepo) ListResponses(ctx context.Context, ids []int) (map[int]domain.ReviewResponse, error) {
+	r.reads.Add(1)
+	return r.ReviewRepository.ListResponses(ctx, ids)
+}
+
+// spyMetrics counts hits and misses.
+type spyMetrics struct {
+	mu     sync.Mutex
+	hits   map[string]int
+	misses map[string]int
+}
+
+func newSpyMetrics() *spyMetrics {
+	return &spyMetrics{hits: map[string]int{}, misses: map[string]int{}}
+}
+
+func (m *spyMetrics) CacheLookup(kind string, hit bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if hit {
+		m.hits[kind]++
+	} else {
+		m.misses[kind]++
+	}
+}
+
+// failingStore is a Store whose backend is down.
+type failingStore struct{}
+
+func (failingStore) Get(context.Context, ...string) (map[string][]byte, error) {
+	return nil, errors.New("connection refused")
+}
+
+func (failingStore) Set(context.Context, map[string][]byte, time.Duration) error {
+	return errors.New("connection refused")
+}
+
+func (failingStore) Delete(context.Context, ...string) error {
+	return errors.New("connection refused")
+}
+
+func seed(t *testing.T, repo usecase.ReviewRepository, reviewableID int) int {
+	t.Helper()
+	id, err := repo.Create(context.Background(), domain.Review{ReviewableType: "product", ReviewableID: reviewableID, UserID: 1, Rating: 4})
+	if err != nil {
+		t.Fatalf("seed review: %v", err)
+	}
+	return id
+}
+
+func TestReviewRepoServesRepeatedReads(t *testing.T) {
+	ctx := context.Background()
+	next := &countingRepo{ReviewRepository: memory.NewReviewRepo()}
+	metrics := newSpyMetrics()
+	repo := cache.NewReviewRepo(next, cache.NewLRUStore(100), time.Minute, metrics, logging.Nop())
+	id := seed(t, repo, 1)
+
+	for range 3 {
+		if got, err := repo.GetByID(ctx, id); err != nil || got.ID != id {
+			t.Fatalf("GetByID() = %+v, %v", got, err)
+		}
+		if got, err := repo.ListByReviewable(ctx, "product", 1, false); err != nil || len(got) != 1 {
+			t.Fatalf("ListByReviewable() = %+v, %v", got, err)
+		}
+		if _, err := repo.GetResponse(ctx, id); err == nil {
+			t.Fatal("GetResponse() of a review without response error = nil")
+		}
+	}
+	if n := next.reads.Load(); n != 3 {
+		t.Errorf("wrapped repository read %d times, want once per entry", n)
+	}
+	for _, kind := range []string{cache.KindReview, cache.KindList, cache.KindResponse} {
+		if metrics.hits[kind] != 2 || metrics.misses[kind] != 1 {
+			t.Errorf("%s: %d hits, %d misses; want 2 and 1", kind, metrics.hits[kind], metrics.misses[kind])
+		}
+	}
+
+	// Readers may modify what they get without affecting later reads.
+	list, _ := repo.ListByReviewable(ctx, "product", 1, false)
+	list[0].SubRatings = map[string]int{"quality": 1}
+	if again, _ := repo.ListByReviewable(ctx, "product", 1, false); again[0].SubRatings != nil {
+		t.Errorf("cached list was modified through a previous result: %+v", again[0])
+	}
+
+	if _, err := repo.GetByID(ctx, 999); err == nil {
+		t.Error("GetByID(missing) error = nil")
+	}
+}
+
+func TestReviewRepoInvalidatesOnWrites(t *testing.T) {
+	ctx := context.Background()
+	repo := cache.NewReviewRepo(memory.NewReviewRepo(), cache.NewLRUStore(100), time.Hour, cache.NopMetrics{}, logging.Nop())
+	id := seed(t, repo, 1)
+
+	prime := func() {
+		_, _ = repo.GetByID(ctx, id)
+		_, _ = repo.ListByReviewable(ctx, "product", 1, false)
+		_, _ = repo.ListComments(ctx, id)
+		_, _ = repo.ListResponses(ctx, []int{id})
+	}
+
+	prime()
+	if _, err := repo.AddComment(ctx, domain.ReviewComment{ReviewID: id, UserID: 2, Body: "first"}); err != nil {
+		t.Fatalf("AddComment() error = %v", err)
+	}
+	comments, _ := repo.ListComments(ctx, id)
+	if len(comments) != 1 {
+		t.Fatalf("comments after AddComment = %+v, want 1", comments)
+	}
+
+	prime()
+	comments[0].Body = "edited"
+	if _, err := repo.UpdateComment(ctx, comments[0], 2); err != nil {
+		t.Fatalf("UpdateComment() error = %v", err)
+	}
+	if got, _ := repo.ListComments(ctx, id); got[0].Body != "edited" {
+		t.Errorf("comment after UpdateComment = %+v", got[0])
+	}
+
+	prime()
+	if _, err := repo.SaveResponse(ctx, domain.ReviewResponse{ReviewID: id, UserID: 3, Body: "Thanks"}); err != nil {
+		t.Fatalf("SaveResponse() error = %v", err)
+	}
+	if got, err := repo.GetResponse(ctx, id); err != nil || got.Body != "Thanks" {
+		t.Errorf("GetResponse() after SaveResponse = %+v, %v", got, err)
+	}
+
+	prime()
+	seed(t, repo, 1)
+	if got, _ := repo.ListByReviewable(ctx, "product", 1, false); len(got) != 2 {
+		t.Errorf("listing after Create = %d reviews, want 2", len(got))
+	}
+
+	prime()
+	if err := repo.DeleteReview(ctx, id, ""); err != nil {
+		t.Fatalf("DeleteReview() error = %v", err)
+	}
+	if _, err := repo.GetByID(ctx, id); err == nil {
+		t.Error("GetByID() after DeleteReview error = nil")
+	}
+	if got, _ := repo.ListByReviewable(ctx, "product", 1, false); len(got) != 1 {
+		t.Errorf("listing after DeleteReview = %d reviews, want 1", len(got))
+	}
+
+	if err := repo.RestoreReview(ctx, id); err != nil {
+		t.Fatalf("RestoreReview() error = %v", err)
+	}
+	if got, _ := repo.ListByReviewable(ctx, "product", 1, false); len(got) != 2 {
+		t.Errorf("listing after RestoreReview = %d reviews, want 2", len(got))
+	}
+}
+
+func TestReviewRepoCollapsesConcurrentMisses(t *testing.T) {
+	ctx := context.Background()
+	next := &countingRepo{ReviewRepository: memory.NewReviewRepo(), delay: 50 * time.Millisecond}
+	repo := cache.NewReviewRepo(next, cache.NewLRUStore(100), time.Minute, cache.NopMetrics{}, logging.Nop())
+	id := seed(t, repo, 1)
+
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Go(func() {
+			if got, err := repo.GetByID(ctx, id); err != nil || got.ID != id {
+				t.Errorf("GetByID() = %+v, %v", got, err)
+			}
+		})
+	}
+	wg.Wait()
+	if n := next.reads.Load(); n != 1 {
+		t.Errorf("wrapped repository read %d times, want the concurrent misses collapsed into 1", n)
+	}
+}
+
+func TestReviewRepoSharedLoadOutlivesFirstCaller(t *testing.T) {
+	next := &countingRepo{ReviewRepository: memory.NewReviewRepo(), delay: 100 * time.Millisecond}
+	repo := cache.NewReviewRepo(next, cache.NewLRUStore(100), time.Minute, cache.NopMetrics{}, logging.Nop())
+	id := seed(t, repo, 1)
+
+	// The first caller starts the load and gives up before it is done; the
+	// second joins it and must still get the review.
+	first, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	var wg sync.WaitGroup
+	wg.Go(func() {
+		if _, err := repo.GetByID(first, id); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("GetByID(first) error = %v, want context.DeadlineExceeded", err)
+		}
+	})
+	time.Sleep(5 * time.Millisecond)
+	if got, err := repo.GetByID(context.Background(), id); err != nil || got.ID != id {
+		t.Errorf("GetByID(second) = %+v, %v; want the review", got, err)
+	}
+	wg.Wait()
+	if n := next.reads.Load(); n != 1 {
+		t.Errorf("wrapped repository read %d times, want one shared load", n)
+	}
+}
+
+func TestReviewRepoFallsBackWhenStoreFails(t *testing.T) {
+	ctx := context.Background()
+	next := &countingRepo{ReviewRepository: memory.NewReviewRepo()}
+	repo := cache.NewReviewRepo(next, failingStore{}, time.Minute, cache.NopMetrics{}, logging.Nop())
+	id := seed(t, repo, 1)
+
+	for range 2 {
+		if got, err := repo.GetByID(ctx, id); err != nil || got.ID != id {
+			t.Fatalf("GetByID() = %+v, %v; want the review from the wrapped repository", got, err)
+		}
+	}
+	if n := next.reads.Load(); n != 2 {
+		t.Errorf("wrapped repository read %d times, want every read", n)
+	}
+	if err := repo.DeleteReview(ctx, id, ""); err != nil {
+		t.Errorf("DeleteReview() error = %v, want the failed invalidation ignored", err)
+	}
+}
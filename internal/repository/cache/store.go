@@ -0,0 +1,34 @@
+// Package cache is a read-through caching decorator for
+// usecase.ReviewRepository, with Store adapters for Redis and for an
+// in-process LRU.
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Store is a byte-oriented key/value cache with per-entry expiry.
+type Store interface {
+	// Get returns the live entries among keys. Missing and expired keys have
+	// no entry in the result.
+	Get(ctx context.Context, keys ...string) (map[string][]byte, error)
+
+	// Set stores entries, each expiring after ttl.
+	Set(ctx context.Context, entries map[string][]byte, ttl time.Duration) error
+
+	// Delete removes keys. Missing keys are ignored.
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// Metrics records cache lookups, so that the hit ratio of every kind of
+// entry can be derived. Implementations live in internal/infrastructure.
+type Metrics interface {
+	// CacheLookup counts a lookup of an entry of the given kind.
+	CacheLookup(kind string, hit bool)
+}
+
+// NopMetrics is a Metrics that records nothing.
+type NopMetrics struct{}
+
+func (NopMetrics) CacheLookup(string, bool) {}
@@ -0,0 +1,46 @@
+// Package dbtrace records the statements the SQL repositories execute as
+// OpenTelemetry client spans.
+package dbtrace
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracer starts the spans of one repository adapter.
+type Tracer struct {
+	tracer trace.Tracer
+	prefix string
+	system string
+}
+
+// New returns a Tracer for the adapter in package pkg whose spans are named
+// prefix.operation and carry system as db.system.
+func New(pkg, prefix, system string) Tracer {
+	return Tracer{tracer: otel.Tracer(pkg), prefix: prefix, system: system}
+}
+
+// Start starts a client span for a repository operation executing query.
+func (t Tracer) Start(ctx context.Context, operation, query string) (context.Context, trace.Span) {
+	return t.tracer.Start(ctx, t.prefix+"."+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", t.system),
+			attribute.String("db.operation", operation),
+			attribute.String("db.statement", query),
+		),
+	)
+}
+
+// End records err on span (if any) and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,119 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"eve/domain"
+)
+
+// APIKeyRepo is a thread-safe in-memory implementation of usecase.APIKeyRepository.
+type APIKeyRepo struct {
+	mu     sync.RWMutex
+	nextID int
+	keys   []domain.APIKey // ordered by ID
+}
+
+func NewAPIKeyRepo() *APIKeyRepo {
+	return &APIKeyRepo{nextID: 1}
+}
+
+func (r *APIKeyRepo) CreateAPIKey(ctx context.Context, key domain.APIKey) (domain.APIKey, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.APIKey{}, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, k := range r.keys {
+		if k.Prefix == key.Prefix {
+			return domain.APIKey{}, fmt.Errorf("create api key: prefix %q already exists", key.Prefix)
+		}
+	}
+	key.ID = r.nextID
+	key.Key = ""
+	key.Permissions = slices.Clone(key.Permissions)
+	key.CreatedAt = now()
+	key.LastUsedAt = nil
+	r.keys = append(r.keys, key)
+	r.nextID++
+	return copyAPIKey(key), nil
+}
+
+func (r *APIKeyRepo) GetAPIKeyByPrefix(ctx context.Context, prefix string) (domain.APIKey, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.APIKey{}, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, k := range r.keys {
+		if k.Prefix == prefix {
+			return copyAPIKey(k), nil
+		}
+	}
+	return domain.APIKey{}, fmt.Errorf("get api key: %w", sql.ErrNoRows)
+}
+
+func (r *APIKeyRepo) ListAPIKeys(ctx context.Context) ([]domain.APIKey, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var keys []domain.APIKey
+	for _, k := range r.keys {
+		keys = append(keys, copyAPIKey(k))
+	}
+	return keys, nil
+}
+
+func (r *APIKeyRepo) DeleteAPIKey(ctx context.Context, id int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, k := range r.keys {
+		if k.ID == id {
+			r.keys = slices.Delete(r.keys, i, i+1)
+			return nil
+		}
+	}
+	return fmt.Errorf("delete api key: %w", sql.ErrNoRows)
+}
+
+func (r *APIKeyRepo) TouchAPIKey(ctx context.Context, id int, at time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.keys {
+		if r.keys[i].ID == id {
+			used := at.UTC().Format(timeLayout)
+			r.keys[i].LastUsedAt = &used
+			return nil
+		}
+	}
+	return fmt.Errorf("touch api key: %w", sql.ErrNoRows)
+}
+
+// copyAPIKey returns key with its own copy of the permissions so callers
+// cannot change the stored key.
+func copyAPIKey(key domain.APIKey) domain.APIKey {
+	key.Permissions = slices.Clone(key.Permissions)
+	return key
+}
@@ -0,0 +1,26 @@
+package memory_test
+
+import (
+	"testing"
+
+	"eve/internal/repository/memory"
+	"eve/internal/repository/repotest"
+)
+
+func TestContract(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repotest.Repos {
+		reviews := memory.NewReviewRepo()
+		return repotest.Repos{
+			Users:         memory.NewUserRepo(),
+			Reviews:       reviews,
+			Owners:        memory.NewOwnerRepo(),
+			Criteria:      memory.NewCriteriaRepo(),
+			Types:         memory.NewReviewableTypeRepo(),
+			Outbox:        reviews,
+			Webhooks:      memory.NewWebhookRepo(),
+			Idempotency:   memory.NewIdempotencyStore(),
+			LoginAttempts: memory.NewLoginAttemptRepo(),
+			APIKeys:       memory.NewAPIKeyRepo(),
+		}
+	})
+}
@@ -0,0 +1,52 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+	"sync"
+
+	"eve/domain"
+)
+
+// CriteriaRepo is a thread-safe in-memory implementation of usecase.CriteriaRepository.
+type CriteriaRepo struct {
+	mu       sync.RWMutex
+	criteria map[string][]domain.RatingCriterion // keyed by reviewable type
+}
+
+func NewCriteriaRepo() *CriteriaRepo {
+	return &CriteriaRepo{criteria: make(map[string][]domain.RatingCriterion)}
+}
+
+func (c *CriteriaRepo) SetCriteria(ctx context.Context, reviewableType string, criteria []domain.RatingCriterion) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stored := make([]domain.RatingCriterion, len(criteria))
+	for i, cr := range criteria {
+		cr.ReviewableType = reviewableType
+		stored[i] = cr
+	}
+	slices.SortFunc(stored, func(a, b domain.RatingCriterion) int {
+		return cmp.Or(cmp.Compare(a.SortOrder, b.SortOrder), strings.Compare(a.Key, b.Key))
+	})
+	c.criteria[reviewableType] = stored
+	return nil
+}
+
+func (c *CriteriaRepo) ListCriteria(ctx context.Context, reviewableType string) ([]domain.RatingCriterion, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return slices.Clone(c.criteria[reviewableType]), nil
+}
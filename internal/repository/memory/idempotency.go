@@ -0,0 +1,102 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"eve/domain"
+)
+
+// storedIdempotencyRecord is an idempotency record with its expiry.
+type storedIdempotencyRecord struct {
+	record    domain.IdempotencyRecord
+	expiresAt time.Time
+}
+
+// IdempotencyStore is a thread-safe in-memory implementation of
+// usecase.IdempotencyStore.
+type IdempotencyStore struct {
+	mu      sync.Mutex
+	records map[string]storedIdempotencyRecord
+}
+
+func NewIdempotencyStore() *IdempotencyStore {
+	return &IdempotencyStore{records: make(map[string]storedIdempotencyRecord)}
+}
+
+func (s *IdempotencyStore) Reserve(ctx context.Context, key, fingerprint string, lease time.Duration) (domain.IdempotencyRecord, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.IdempotencyRecord{}, false, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if stored, ok := s.records[key]; ok && time.Now().Before(stored.expiresAt) {
+		return copyIdempotencyRecord(stored.record), false, nil
+	}
+	rec := domain.IdempotencyRecord{Key: key, Fingerprint: fingerprint, CreatedAt: now()}
+	s.records[key] = storedIdempotencyRecord{record: rec, expiresAt: time.Now().Add(lease)}
+	return rec, true, nil
+}
+
+func (s *IdempotencyStore) Complete(ctx context.Context, rec domain.IdempotencyRecord, ttl time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.records[rec.Key]
+	if !ok {
+		return fmt.Errorf("complete idempotency key %q: %w", rec.Key, sql.ErrNoRows)
+	}
+	stored.record.Status = rec.Status
+	stored.record.ContentType = rec.ContentType
+	stored.record.Body = slices.Clone(rec.Body)
+	stored.expiresAt = time.Now().Add(ttl)
+	s.records[rec.Key] = stored
+	return nil
+}
+
+func (s *IdempotencyStore) Release(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.records, key)
+	return nil
+}
+
+func (s *IdempotencyStore) DeleteExpired(ctx context.Context, before time.Time) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := 0
+	for key, stored := range s.records {
+		if stored.expiresAt.Before(before) {
+			delete(s.records, key)
+			n++
+		}
+	}
+	return n, nil
+}
+
+// copyIdempotencyRecord returns rec with its own copy of the body so callers
+// cannot change the stored response.
+func copyIdempotencyRecord(rec domain.IdempotencyRecord) domain.IdempotencyRecord {
+	rec.Body = slices.Clone(rec.Body)
+	return rec
+}
@@ -0,0 +1,120 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"eve/domain"
+)
+
+// LoginAttemptRepo is a thread-safe in-memory implementation of
+// usecase.LoginAttemptRepository.
+type LoginAttemptRepo struct {
+	mu       sync.Mutex
+	attempts map[string]domain.LoginAttempts
+}
+
+func NewLoginAttemptRepo() *LoginAttemptRepo {
+	return &LoginAttemptRepo{attempts: make(map[string]domain.LoginAttempts)}
+}
+
+func (r *LoginAttemptRepo) GetAttempts(ctx context.Context, key string) (domain.LoginAttempts, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.LoginAttempts{}, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if a, ok := r.attempts[key]; ok {
+		return a, nil
+	}
+	return domain.LoginAttempts{Key: key}, nil
+}
+
+func (r *LoginAttemptRepo) ReserveAttempt(ctx context.Context, key string, seen int, at time.Time, window time.Duration) (domain.LoginAttempts, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.LoginAttempts{}, false, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	a, ok := r.attempts[key]
+	if !ok {
+		a = domain.LoginAttempts{Key: key}
+	} else if a.Failures != seen || a.Locked(at) {
+		return domain.LoginAttempts{}, false, nil
+	}
+	if !a.LastAttemptAt.After(at.Add(-window)) {
+		a.Failures = 0
+	}
+	a.Failures++
+	a.LastAttemptAt = at.UTC()
+	r.attempts[key] = a
+	return a, true, nil
+}
+
+func (r *LoginAttemptRepo) ReleaseAttempt(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if a, ok := r.attempts[key]; ok {
+		a.Failures = max(a.Failures-1, 0)
+		r.attempts[key] = a
+	}
+	return nil
+}
+
+func (r *LoginAttemptRepo) Lock(ctx context.Context, key string, until time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	a, ok := r.attempts[key]
+	if !ok {
+		a = domain.LoginAttempts{Key: key}
+	}
+	a.Failures = 0
+	a.LockedUntil = until.UTC()
+	r.attempts[key] = a
+	return nil
+}
+
+func (r *LoginAttemptRepo) Reset(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.attempts, key)
+	return nil
+}
+
+func (r *LoginAttemptRepo) DeleteStale(ctx context.Context, before time.Time) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	n := 0
+	for key, a := range r.attempts {
+		if a.LastAttemptAt.Before(before) && a.LockedUntil.Before(before) {
+			delete(r.attempts, key)
+			n++
+		}
+	}
+	return n, nil
+}
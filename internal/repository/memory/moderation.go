@@ -0,0 +1,59 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+
+	"eve/domain"
+)
+
+func (r *ReviewRepo) ApproveReview(ctx context.Context, id int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	review, ok := r.live(id)
+	if !ok || review.Status != domain.ReviewStatusPending {
+		return fmt.Errorf("approve review: %w", sql.ErrNoRows)
+	}
+	review.Status = domain.ReviewStatusPublished
+	review.UpdatedAt = now()
+	if err := r.addEvent(domain.EventReviewCreated, id, review.ReviewableType, review); err != nil {
+		return err
+	}
+	r.reviews[id] = review
+	return nil
+}
+
+func (r *ReviewRepo) ListPending(ctx context.Context, limit, offset int) ([]domain.Review, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var reviews []domain.Review
+	for _, review := range r.reviews {
+		if _, deleted := r.deleted[review.ID]; !deleted && review.Status == domain.ReviewStatusPending {
+			reviews = append(reviews, review)
+		}
+	}
+	// Oldest first; IDs break ties between reviews created in the same instant.
+	slices.SortFunc(reviews, func(a, b domain.Review) int {
+		if a.CreatedAt != b.CreatedAt {
+			if a.CreatedAt < b.CreatedAt {
+				return -1
+			}
+			return 1
+		}
+		return a.ID - b.ID
+	})
+	reviews = reviews[min(offset, len(reviews)):]
+	return reviews[:min(limit, len(reviews))], nil
+}
@@ -0,0 +1,102 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"eve/domain"
+)
+
+// outboxEvent is an event in the in-memory outbox with its delivery state.
+type outboxEvent struct {
+	event         domain.Event
+	published     bool
+	failed        bool // dead-lettered
+	lastError     string
+	nextAttemptAt time.Time
+}
+
+// addEvent appends a domain event to the outbox; the caller must hold the
+// write lock, which makes it atomic with the change it describes.
+func (r *ReviewRepo) addEvent(eventType string, reviewID int, reviewableType string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshal %s event: %w", eventType, err)
+	}
+	r.outbox = append(r.outbox, &outboxEvent{event: domain.Event{
+		ID:             r.nextEventID,
+		Type:           eventType,
+		ReviewID:       reviewID,
+		ReviewableType: reviewableType,
+		Payload:        data,
+		CreatedAt:      now(),
+	}})
+	r.nextEventID++
+	return nil
+}
+
+// ListUnpublished implements usecase.OutboxRepository for the events the
+// repository records.
+func (r *ReviewRepo) ListUnpublished(ctx context.Context, now time.Time, limit int) ([]domain.Event, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var events []domain.Event
+	waiting := make(map[int]bool) // review IDs with an event that is not due
+	for _, e := range r.outbox {
+		if len(events) == limit {
+			break
+		}
+		if e.published || e.failed || waiting[e.event.ReviewID] {
+			continue
+		}
+		if e.nextAttemptAt.After(now) {
+			waiting[e.event.ReviewID] = true
+			continue
+		}
+		events = append(events, e.event)
+	}
+	return events, nil
+}
+
+func (r *ReviewRepo) MarkPublished(ctx context.Context, ids []int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, e := range r.outbox {
+		if slices.Contains(ids, e.event.ID) {
+			e.published = true
+		}
+	}
+	return nil
+}
+
+func (r *ReviewRepo) RecordFailure(ctx context.Context, id int, reason string, nextAttemptAt time.Time, dead bool) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, e := range r.outbox {
+		if e.event.ID == id {
+			e.event.Attempts++
+			e.lastError = reason
+			e.nextAttemptAt = nextAttemptAt
+			e.failed = dead
+		}
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"sync"
+
+	"eve/domain"
+)
+
+// ownerKey identifies one ownership grant.
+type ownerKey struct {
+	reviewableType string
+	reviewableID   int
+	userID         int
+}
+
+// OwnerRepo is a thread-safe in-memory implementation of usecase.OwnerRepository.
+type OwnerRepo struct {
+	mu     sync.RWMutex
+	owners map[ownerKey]domain.ReviewableOwner
+}
+
+func NewOwnerRepo() *OwnerRepo {
+	return &OwnerRepo{owners: make(map[ownerKey]domain.ReviewableOwner)}
+}
+
+func (o *OwnerRepo) AddOwner(ctx context.Context, owner domain.ReviewableOwner) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	key := ownerKey{owner.ReviewableType, owner.ReviewableID, owner.UserID}
+	if _, ok := o.owners[key]; ok {
+		return nil
+	}
+	owner.CreatedAt = now()
+	o.owners[key] = owner
+	return nil
+}
+
+func (o *OwnerRepo) RemoveOwner(ctx context.Context, reviewableType string, reviewableID, userID int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	key := ownerKey{reviewableType, reviewableID, userID}
+	if _, ok := o.owners[key]; !ok {
+		return fmt.Errorf("remove owner: %w", sql.ErrNoRows)
+	}
+	delete(o.owners, key)
+	return nil
+}
+
+func (o *OwnerRepo) ListOwners(ctx context.Context, reviewableType string, reviewableID int) ([]domain.ReviewableOwner, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	var out []domain.ReviewableOwner
+	for key, owner := range o.owners {
+		if key.reviewableType == reviewableType && key.reviewableID == reviewableID {
+			out = append(out, owner)
+		}
+	}
+	slices.SortFunc(out, func(a, b domain.ReviewableOwner) int { return cmp.Compare(a.UserID, b.UserID) })
+	return out, nil
+}
+
+func (o *OwnerRepo) IsOwner(ctx context.Context, reviewableType string, reviewableID, userID int) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	_, ok := o.owners[ownerKey{reviewableType, reviewableID, userID}]
+	return ok, nil
+}
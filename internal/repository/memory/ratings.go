@@ -0,0 +1,71 @@
+package memory
+
+import (
+	"context"
+	"maps"
+	"slices"
+
+	"eve/domain"
+)
+
+func (r *ReviewRepo) ListSubRatings(ctx context.Context, reviewIDs []int) (map[int]map[string]int, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := make(map[int]map[string]int)
+	for _, id := range reviewIDs {
+		if _, live := r.live(id); !live {
+			continue
+		}
+		if ratings, ok := r.subRatings[id]; ok {
+			out[id] = maps.Clone(ratings)
+		}
+	}
+	return out, nil
+}
+
+func (r *ReviewRepo) Summarize(ctx context.Context, reviewableType string, reviewableID int) (domain.ReviewSummary, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.ReviewSummary{}, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	summary := domain.ReviewSummary{
+		ReviewableType: reviewableType,
+		ReviewableID:   reviewableID,
+		Criteria:       []domain.CriterionAverage{},
+	}
+	var total int
+	sums := make(map[string]int)
+	counts := make(map[string]int)
+	for id := range r.reviews {
+		review, live := r.live(id)
+		if !live || review.Status != domain.ReviewStatusPublished ||
+			review.ReviewableType != reviewableType || review.ReviewableID != reviewableID {
+			continue
+		}
+		summary.Count++
+		total += review.Rating
+		for k, v := range r.subRatings[id] {
+			sums[k] += v
+			counts[k]++
+		}
+	}
+	if summary.Count > 0 {
+		summary.AverageRating = float64(total) / float64(summary.Count)
+	}
+	for _, k := range slices.Sorted(maps.Keys(counts)) {
+		summary.Criteria = append(summary.Criteria, domain.CriterionAverage{
+			Key:     k,
+			Count:   counts[k],
+			Average: float64(sums[k]) / float64(counts[k]),
+		})
+	}
+	return summary, nil
+}
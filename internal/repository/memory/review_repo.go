@@ -0,0 +1,539 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"eve/domain"
+)
+
+// ReviewRepo is a thread-safe in-memory implementation of usecase.ReviewRepository.
+// It mirrors the Postgres adapter: lookups of missing rows wrap sql.ErrNoRows,
+// reviews are listed newest first, comments oldest first, photos by sort order,
+// deleted reviews are hidden from every read until restored or purged, and
+// pending reviews are left out of listings, searches and summaries. It also
+// implements usecase.OutboxRepository for the domain events its writes record.
+type ReviewRepo struct {
+	mu sync.RWMutex
+
+	nextReviewID   int
+	nextPhotoID    int
+	nextCommentID  int
+	nextResponseID int
+	nextVersionID  int
+	nextRevisionID int
+	nextEventID    int
+
+	reviews    map[int]domain.Review
+	photos     map[int][]domain.ReviewPhoto
+	comments   map[int][]domain.ReviewComment
+	responses  map[int]domain.ReviewResponse          // keyed by review ID
+	versions   map[int][]domain.ReviewResponseVersion // keyed by review ID
+	revisions  map[int][]domain.Revision              // keyed by review ID
+	subRatings map[int]map[string]int                 // keyed by review ID
+	deleted    map[int]time.Time                      // soft-deleted review IDs
+	outbox     []*outboxEvent                         // oldest first
+}
+
+func NewReviewRepo() *ReviewRepo {
+	return &ReviewRepo{
+		nextReviewID:   1,
+		nextPhotoID:    1,
+		nextCommentID:  1,
+		nextResponseID: 1,
+		nextVersionID:  1,
+		nextRevisionID: 1,
+		nextEventID:    1,
+		reviews:        make(map[int]domain.Review),
+		photos:         make(map[int][]domain.ReviewPhoto),
+		comments:       make(map[int][]domain.ReviewComment),
+		responses:      make(map[int]domain.ReviewResponse),
+		versions:       make(map[int][]domain.ReviewResponseVersion),
+		revisions:      make(map[int][]domain.Revision),
+		subRatings:     make(map[int]map[string]int),
+		deleted:        make(map[int]time.Time),
+	}
+}
+
+func (r *ReviewRepo) Create(ctx context.Context, review domain.Review) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	if review.Rating < 1 || review.Rating > 5 {
+		return 0, fmt.Errorf("insert review: rating %d out of range", review.Rating)
+	}
+	for k, v := range review.SubRatings {
+		if v < 1 || v > 5 {
+			return 0, fmt.Errorf("insert sub-rating: %s rating %d out of range", k, v)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	review.ID = r.nextReviewID
+	if review.Status == "" {
+		review.Status = domain.ReviewStatusPublished
+	}
+	review.CreatedAt = now()
+	review.UpdatedAt = review.CreatedAt
+	r.nextReviewID++
+	if len(review.SubRatings) > 0 {
+		r.subRatings[review.ID] = maps.Clone(review.SubRatings)
+	}
+	if err := r.addEvent(domain.EventReviewCreated, review.ID, review.ReviewableType, review); err != nil {
+		return 0, err
+	}
+	// Sub-ratings are kept apart and attached by the use-cases, as in the SQL adapters.
+	review.SubRatings = nil
+	r.reviews[review.ID] = review
+	return review.ID, nil
+}
+
+func (r *ReviewRepo) AddPhotos(ctx context.Context, reviewID int, photos []domain.ReviewPhoto) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.reviews[reviewID]; !ok {
+		return fmt.Errorf("insert photo: review %d does not exist", reviewID)
+	}
+
+	ts := now()
+	for _, p := range photos {
+		p.ID = r.nextPhotoID
+		p.ReviewID = reviewID
+		p.CreatedAt = ts
+		r.nextPhotoID++
+		r.photos[reviewID] = append(r.photos[reviewID], p)
+	}
+	return nil
+}
+
+func (r *ReviewRepo) AddComment(ctx context.Context, comment domain.ReviewComment) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.reviews[comment.ReviewID]; !ok {
+		return 0, fmt.Errorf("insert comment: review %d does not exist", comment.ReviewID)
+	}
+	if comment.ParentID != nil && r.findComment(*comment.ParentID) == nil {
+		return 0, fmt.Errorf("insert comment: parent comment %d does not exist", *comment.ParentID)
+	}
+
+	comment.ID = r.nextCommentID
+	comment.CreatedAt = now()
+	comment.UpdatedAt = comment.CreatedAt
+	if err := r.addEvent(domain.EventCommentAdded, comment.ReviewID, r.reviews[comment.ReviewID].ReviewableType, comment); err != nil {
+		return 0, err
+	}
+	r.nextCommentID++
+	r.comments[comment.ReviewID] = append(r.comments[comment.ReviewID], comment)
+	return comment.ID, nil
+}
+
+func (r *ReviewRepo) GetByID(ctx context.Context, id int) (domain.Review, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.Review{}, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	review, ok := r.live(id)
+	if !ok {
+		return domain.Review{}, fmt.Errorf("get review by id: %w", sql.ErrNoRows)
+	}
+	return review, nil
+}
+
+func (r *ReviewRepo) ListByReviewable(ctx context.Context, reviewableType string, reviewableID int, verifiedOnly bool) ([]domain.Review, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var reviews []domain.Review
+	for _, review := range r.reviews {
+		if _, deleted := r.deleted[review.ID]; deleted || review.Status != domain.ReviewStatusPublished {
+			continue
+		}
+		if verifiedOnly && !review.Verified {
+			continue
+		}
+		if review.ReviewableType == reviewableType && review.ReviewableID == reviewableID {
+			reviews = append(reviews, review)
+		}
+	}
+	// Newest first; IDs break ties between reviews created in the same instant.
+	slices.SortFunc(reviews, func(a, b domain.Review) int {
+		if a.CreatedAt != b.CreatedAt {
+			if a.CreatedAt > b.CreatedAt {
+				return -1
+			}
+			return 1
+		}
+		return b.ID - a.ID
+	})
+	return reviews, nil
+}
+
+func (r *ReviewRepo) GetComment(ctx context.Context, id int) (domain.ReviewComment, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.ReviewComment{}, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	c := r.findComment(id)
+	if c == nil {
+		return domain.ReviewComment{}, fmt.Errorf("get comment by id: %w", sql.ErrNoRows)
+	}
+	if _, ok := r.live(c.ReviewID); !ok {
+		return domain.ReviewComment{}, fmt.Errorf("get comment by id: %w", sql.ErrNoRows)
+	}
+	return *c, nil
+}
+
+// live returns the review with the given ID unless it is missing or
+// soft-deleted. r.mu must be held.
+func (r *ReviewRepo) live(id int) (domain.Review, bool) {
+	review, ok := r.reviews[id]
+	if !ok {
+		return domain.Review{}, false
+	}
+	if _, deleted := r.deleted[id]; deleted {
+		return domain.Review{}, false
+	}
+	return review, true
+}
+
+// findComment returns the comment with the given ID or nil. r.mu must be held.
+func (r *ReviewRepo) findComment(id int) *domain.ReviewComment {
+	for _, comments := range r.comments {
+		for i := range comments {
+			if comments[i].ID == id {
+				return &comments[i]
+			}
+		}
+	}
+	return nil
+}
+
+func (r *ReviewRepo) ListComments(ctx context.Context, reviewID int) ([]domain.ReviewComment, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if _, ok := r.live(reviewID); !ok {
+		return nil, nil
+	}
+	// Comments are appended in creation order, so the slice is already oldest first.
+	return slices.Clone(r.comments[reviewID]), nil
+}
+
+func (r *ReviewRepo) ListPhotos(ctx context.Context, reviewID int) ([]domain.ReviewPhoto, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if _, ok := r.live(reviewID); !ok {
+		return nil, nil
+	}
+	photos := slices.Clone(r.photos[reviewID])
+	slices.SortStableFunc(photos, func(a, b domain.ReviewPhoto) int {
+		return a.SortOrder - b.SortOrder
+	})
+	return photos, nil
+}
+
+func (r *ReviewRepo) DeleteReview(ctx context.Context, id int, version string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if stored, ok := r.reviews[id]; ok && version != "" && stored.UpdatedAt != version {
+		return fmt.Errorf("delete review: %w", domain.ErrReviewChanged)
+	}
+	review, ok := r.live(id)
+	if !ok {
+		return nil
+	}
+	review.UpdatedAt = now()
+	if err := r.addEvent(domain.EventReviewDeleted, id, review.ReviewableType, review); err != nil {
+		return err
+	}
+	r.reviews[id] = review
+	r.deleted[id] = time.Now()
+	return nil
+}
+
+func (r *ReviewRepo) RestoreReview(ctx context.Context, id int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.deleted[id]; !ok {
+		return fmt.Errorf("restore review: %w", sql.ErrNoRows)
+	}
+	review := r.reviews[id]
+	review.UpdatedAt = now()
+	if err := r.addEvent(domain.EventReviewUpdated, id, review.ReviewableType, review); err != nil {
+		return err
+	}
+	r.reviews[id] = review
+	delete(r.deleted, id)
+	return nil
+}
+
+func (r *ReviewRepo) PurgeDeletedReviews(ctx context.Context, deletedBefore time.Time, limit int) (domain.PurgedReviews, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.PurgedReviews{}, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var purged domain.PurgedReviews
+	for id, at := range r.deleted {
+		if at.Before(deletedBefore) {
+			purged.ReviewIDs = append(purged.ReviewIDs, id)
+		}
+	}
+	// Oldest deletions first, like the SQL adapters.
+	slices.SortFunc(purged.ReviewIDs, func(a, b int) int { return r.deleted[a].Compare(r.deleted[b]) })
+	if len(purged.ReviewIDs) > limit {
+		purged.ReviewIDs = purged.ReviewIDs[:limit]
+	}
+
+	for _, id := range purged.ReviewIDs {
+		for _, p := range r.photos[id] {
+			if !slices.Contains(purged.FilePaths, p.FilePath) {
+				purged.FilePaths = append(purged.FilePaths, p.FilePath)
+			}
+		}
+	}
+	// Files still used by a photo of a surviving review stay, like in the SQL
+	// adapters.
+	for id, photos := range r.photos {
+		if slices.Contains(purged.ReviewIDs, id) {
+			continue
+		}
+		for _, p := range photos {
+			purged.FilePaths = slices.DeleteFunc(purged.FilePaths, func(path string) bool { return path == p.FilePath })
+		}
+	}
+	for _, id := range purged.ReviewIDs {
+		delete(r.reviews, id)
+		delete(r.photos, id)
+		delete(r.comments, id)
+		delete(r.responses, id)
+		delete(r.versions, id)
+		delete(r.revisions, id)
+		delete(r.subRatings, id)
+		delete(r.deleted, id)
+	}
+	return purged, nil
+}
+
+func (r *ReviewRepo) SaveResponse(ctx context.Context, resp domain.ReviewResponse) (domain.ReviewResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.ReviewResponse{}, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.reviews[resp.ReviewID]; !ok {
+		return domain.ReviewResponse{}, fmt.Errorf("save response: review %d does not exist", resp.ReviewID)
+	}
+
+	ts := now()
+	if existing, ok := r.responses[resp.ReviewID]; ok {
+		resp.ID = existing.ID
+		resp.CreatedAt = existing.CreatedAt
+	} else {
+		resp.ID = r.nextResponseID
+		resp.CreatedAt = ts
+		r.nextResponseID++
+	}
+	resp.UpdatedAt = ts
+	r.responses[resp.ReviewID] = resp
+
+	r.versions[resp.ReviewID] = append(r.versions[resp.ReviewID], domain.ReviewResponseVersion{
+		ID:         r.nextVersionID,
+		ResponseID: resp.ID,
+		UserID:     resp.UserID,
+		Body:       resp.Body,
+		CreatedAt:  ts,
+	})
+	r.nextVersionID++
+	return resp, nil
+}
+
+func (r *ReviewRepo) GetResponse(ctx context.Context, reviewID int) (domain.ReviewResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.ReviewResponse{}, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	resp, ok := r.responses[reviewID]
+	if _, live := r.live(reviewID); !ok || !live {
+		return domain.ReviewResponse{}, fmt.Errorf("get response: %w", sql.ErrNoRows)
+	}
+	return resp, nil
+}
+
+func (r *ReviewRepo) ListResponses(ctx context.Context, reviewIDs []int) (map[int]domain.ReviewResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := make(map[int]domain.ReviewResponse)
+	for _, id := range reviewIDs {
+		if _, live := r.live(id); !live {
+			continue
+		}
+		if resp, ok := r.responses[id]; ok {
+			out[id] = resp
+		}
+	}
+	return out, nil
+}
+
+func (r *ReviewRepo) ListResponseVersions(ctx context.Context, reviewID int) ([]domain.ReviewResponseVersion, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if _, ok := r.live(reviewID); !ok {
+		return nil, nil
+	}
+	return slices.Clone(r.versions[reviewID]), nil
+}
+
+func (r *ReviewRepo) UpdateReview(ctx context.Context, review domain.Review, actorID int, version string) (domain.Review, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.Review{}, err
+	}
+	if review.Rating < 1 || review.Rating > 5 {
+		return domain.Review{}, fmt.Errorf("update review: rating %d out of range", review.Rating)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.live(review.ID)
+	if !ok {
+		return domain.Review{}, fmt.Errorf("get review by id: %w", sql.ErrNoRows)
+	}
+	if version != "" && stored.UpdatedAt != version {
+		return domain.Review{}, fmt.Errorf("update review: %w", domain.ErrReviewChanged)
+	}
+	updated := stored
+	updated.Rating, updated.Title, updated.Body = review.Rating, review.Title, review.Body
+	if review.Status != "" {
+		updated.Status = review.Status
+	}
+
+	oldValues, newValues, changed := domain.ReviewChanges(stored, updated)
+	if !changed {
+		return stored, nil
+	}
+	updated.UpdatedAt = now()
+	if err := r.addEvent(domain.EventReviewUpdated, review.ID, updated.ReviewableType, updated); err != nil {
+		return domain.Review{}, err
+	}
+	r.reviews[review.ID] = updated
+	r.addRevision(review.ID, domain.RevisionEntityReview, review.ID, actorID, oldValues, newValues)
+	return updated, nil
+}
+
+func (r *ReviewRepo) UpdateComment(ctx context.Context, comment domain.ReviewComment, actorID int) (domain.ReviewComment, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.ReviewComment{}, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := r.findComment(comment.ID)
+	if stored == nil {
+		return domain.ReviewComment{}, fmt.Errorf("get comment by id: %w", sql.ErrNoRows)
+	}
+	updated := *stored
+	updated.Body = comment.Body
+
+	oldValues, newValues, changed := domain.CommentChanges(*stored, updated)
+	if !changed {
+		return updated, nil
+	}
+	updated.UpdatedAt = now()
+	*stored = updated
+	r.addRevision(updated.ReviewID, domain.RevisionEntityComment, updated.ID, actorID, oldValues, newValues)
+	return updated, nil
+}
+
+// addRevision appends a revision; the caller must hold the write lock.
+func (r *ReviewRepo) addRevision(reviewID int, entityType string, entityID, actorID int, oldValues, newValues []byte) {
+	r.revisions[reviewID] = append(r.revisions[reviewID], domain.Revision{
+		ID:         r.nextRevisionID,
+		ReviewID:   reviewID,
+		EntityType: entityType,
+		EntityID:   entityID,
+		ActorID:    actorID,
+		OldValues:  oldValues,
+		NewValues:  newValues,
+		CreatedAt:  now(),
+	})
+	r.nextRevisionID++
+}
+
+func (r *ReviewRepo) ListRevisions(ctx context.Context, reviewID int) ([]domain.Revision, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if _, ok := r.live(reviewID); !ok {
+		return nil, nil
+	}
+	return slices.Clone(r.revisions[reviewID]), nil
+}
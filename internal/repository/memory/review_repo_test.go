@@ -0,0 +1,48 @@
+package memory_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"eve/domain"
+	"eve/internal/repository/memory"
+)
+
+func TestReviewRepoConcurrentWrites(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewReviewRepo()
+
+	const writers = 20
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			id, err := repo.Create(ctx, domain.Review{ReviewableType: "product", ReviewableID: 1, UserID: 1, Rating: 3})
+			if err != nil {
+				t.Errorf("Create() error = %v", err)
+				return
+			}
+			if _, err := repo.AddComment(ctx, domain.ReviewComment{ReviewID: id, UserID: 2, Body: "x"}); err != nil {
+				t.Errorf("AddComment() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	reviews, err := repo.ListByReviewable(ctx, "product", 1, false)
+	if err != nil {
+		t.Fatalf("ListByReviewable() error = %v", err)
+	}
+	seen := make(map[int]bool)
+	for _, r := range reviews {
+		if seen[r.ID] {
+			t.Fatalf("duplicate review ID %d", r.ID)
+		}
+		seen[r.ID] = true
+	}
+	if len(reviews) != writers {
+		t.Errorf("got %d reviews, want %d", len(reviews), writers)
+	}
+}
@@ -0,0 +1,73 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+
+	"eve/domain"
+)
+
+// ReviewableTypeRepo is a thread-safe in-memory implementation of usecase.ReviewableTypeRepository.
+type ReviewableTypeRepo struct {
+	mu    sync.RWMutex
+	types map[string]domain.ReviewableType
+}
+
+func NewReviewableTypeRepo() *ReviewableTypeRepo {
+	return &ReviewableTypeRepo{types: make(map[string]domain.ReviewableType)}
+}
+
+func (t *ReviewableTypeRepo) SaveType(ctx context.Context, rt domain.ReviewableType) (domain.ReviewableType, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.ReviewableType{}, err
+	}
+	if rt.MaxBodyLength < 0 {
+		return domain.ReviewableType{}, fmt.Errorf("save reviewable type: negative max_body_length %d", rt.MaxBodyLength)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	ts := now()
+	if existing, ok := t.types[rt.Name]; ok {
+		rt.CreatedAt = existing.CreatedAt
+	} else {
+		rt.CreatedAt = ts
+	}
+	rt.UpdatedAt = ts
+	t.types[rt.Name] = rt
+	return rt, nil
+}
+
+func (t *ReviewableTypeRepo) GetType(ctx context.Context, name string) (domain.ReviewableType, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.ReviewableType{}, err
+	}
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	rt, ok := t.types[name]
+	if !ok {
+		return domain.ReviewableType{}, fmt.Errorf("get reviewable type: %w", sql.ErrNoRows)
+	}
+	return rt, nil
+}
+
+func (t *ReviewableTypeRepo) ListTypes(ctx context.Context) ([]domain.ReviewableType, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return slices.SortedFunc(maps.Values(t.types), func(a, b domain.ReviewableType) int {
+		return cmp.Compare(a.Name, b.Name)
+	}), nil
+}
@@ -0,0 +1,129 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"unicode"
+
+	"eve/domain"
+)
+
+// titleWeight makes title matches rank above body matches, like the SQL adapters.
+const titleWeight = 10
+
+// SearchReviews implements usecase.ReviewSearcher with a case-insensitive scan:
+// a review matches when every query term appears as a word in its title or
+// body. There is no stemming, so it is meant for tests and tooling only.
+func (r *ReviewRepo) SearchReviews(ctx context.Context, q domain.ReviewSearchQuery) (domain.ReviewSearchResult, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.ReviewSearchResult{}, err
+	}
+
+	terms := words(q.Query)
+	if len(terms) == 0 {
+		return domain.ReviewSearchResult{}, nil
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var hits []domain.ReviewSearchHit
+	for _, review := range r.reviews {
+		if _, deleted := r.deleted[review.ID]; deleted || review.Status != domain.ReviewStatusPublished {
+			continue
+		}
+		if q.ReviewableType != "" && review.ReviewableType != q.ReviewableType {
+			continue
+		}
+		if q.ReviewableID != 0 && review.ReviewableID != q.ReviewableID {
+			continue
+		}
+
+		titleWords, bodyWords := words(review.Title), words(review.Body)
+		rank, matched := 0, true
+		for _, t := range terms {
+			n := titleWeight*count(titleWords, t) + count(bodyWords, t)
+			if n == 0 {
+				matched = false
+				break
+			}
+			rank += n
+		}
+		if !matched {
+			continue
+		}
+
+		hits = append(hits, domain.ReviewSearchHit{
+			Review:         review,
+			Rank:           float64(rank),
+			TitleHighlight: highlight(review.Title, terms),
+			BodyHighlight:  highlight(review.Body, terms),
+		})
+	}
+
+	slices.SortFunc(hits, func(a, b domain.ReviewSearchHit) int {
+		if a.Rank != b.Rank {
+			if a.Rank > b.Rank {
+				return -1
+			}
+			return 1
+		}
+		return b.Review.ID - a.Review.ID
+	})
+
+	res := domain.ReviewSearchResult{Total: len(hits)}
+	if q.Offset < len(hits) {
+		hits = hits[q.Offset:]
+		if q.Limit > 0 && q.Limit < len(hits) {
+			hits = hits[:q.Limit]
+		}
+		res.Hits = hits
+	}
+	return res, nil
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// words splits s into lower-cased words.
+func words(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool { return !isWordRune(r) })
+}
+
+func count(words []string, term string) int {
+	n := 0
+	for _, w := range words {
+		if w == term {
+			n++
+		}
+	}
+	return n
+}
+
+// highlight escapes s and wraps every word that equals one of terms in
+// <mark>…</mark>.
+func highlight(s string, terms []string) string {
+	var b strings.Builder
+	runes := []rune(s)
+	for i := 0; i < len(runes); {
+		if !isWordRune(runes[i]) {
+			b.WriteRune(runes[i])
+			i++
+			continue
+		}
+		j := i
+		for j < len(runes) && isWordRune(runes[j]) {
+			j++
+		}
+		word := string(runes[i:j])
+		if slices.Contains(terms, strings.ToLower(word)) {
+			b.WriteString(domain.HighlightStart + word + domain.HighlightStop)
+		} else {
+			b.WriteString(word)
+		}
+		i = j
+	}
+	return domain.HighlightHTML(b.String())
+}
@@ -0,0 +1,129 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"eve/domain"
+)
+
+// UserRepo is a thread-safe in-memory implementation of usecase.UserRepository.
+type UserRepo struct {
+	mu     sync.RWMutex
+	nextID int
+	users  []domain.User
+}
+
+func NewUserRepo() *UserRepo {
+	return &UserRepo{nextID: 1}
+}
+
+func (u *UserRepo) Save(ctx context.Context, user domain.User) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	for _, existing := range u.users {
+		if existing.Email == user.Email {
+			return fmt.Errorf("insert user: email %q already exists", user.Email)
+		}
+	}
+
+	user.ID = u.nextID
+	user.CreatedAt = now()
+	if user.Role == "" {
+		user.Role = domain.RoleUser
+	}
+	u.nextID++
+	u.users = append(u.users, user)
+	return nil
+}
+
+func (u *UserRepo) GetAll(ctx context.Context) ([]domain.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+
+	users := make([]domain.User, len(u.users))
+	copy(users, u.users)
+	return users, nil
+}
+
+func (u *UserRepo) GetByID(ctx context.Context, id int) (domain.User, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.User{}, err
+	}
+
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+
+	for _, user := range u.users {
+		if user.ID == id {
+			return user, nil
+		}
+	}
+	return domain.User{}, fmt.Errorf("get user by id: %w", sql.ErrNoRows)
+}
+
+func (u *UserRepo) GetByEmail(ctx context.Context, email string) (domain.User, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.User{}, err
+	}
+
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+
+	for _, user := range u.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return domain.User{}, fmt.Errorf("get user by email: %w", sql.ErrNoRows)
+}
+
+func (u *UserRepo) UpdatePassword(ctx context.Context, id int, hash string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	for i := range u.users {
+		if u.users[i].ID == id {
+			u.users[i].Password = hash
+			return nil
+		}
+	}
+	return fmt.Errorf("update password: %w", sql.ErrNoRows)
+}
+
+// SetRole changes the role of an existing user. Roles have no API of their
+// own; this stands in for granting them directly in the database.
+func (u *UserRepo) SetRole(id int, role string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	for i := range u.users {
+		if u.users[i].ID == id {
+			u.users[i].Role = role
+		}
+	}
+}
+
+// timeLayout has microsecond precision like Postgres timestamps and a fixed
+// width, so formatted values sort chronologically as strings.
+const timeLayout = "2006-01-02T15:04:05.000000Z07:00"
+
+func now() string {
+	return time.Now().UTC().Format(timeLayout)
+}
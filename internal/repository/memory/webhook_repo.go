@@ -0,0 +1,234 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"eve/domain"
+)
+
+// storedDelivery is a webhook delivery with the time of its next attempt.
+type storedDelivery struct {
+	delivery    domain.WebhookDelivery
+	nextAttempt time.Time
+}
+
+// WebhookRepo is a thread-safe in-memory implementation of usecase.WebhookRepository.
+type WebhookRepo struct {
+	mu sync.RWMutex
+
+	nextSubscriptionID int
+	nextDeliveryID     int
+
+	subscriptions map[int]domain.WebhookSubscription
+	deliveries    []*storedDelivery // by ID
+}
+
+func NewWebhookRepo() *WebhookRepo {
+	return &WebhookRepo{
+		nextSubscriptionID: 1,
+		nextDeliveryID:     1,
+		subscriptions:      make(map[int]domain.WebhookSubscription),
+	}
+}
+
+func (w *WebhookRepo) CreateSubscription(ctx context.Context, sub domain.WebhookSubscription) (domain.WebhookSubscription, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.WebhookSubscription{}, err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	sub.ID = w.nextSubscriptionID
+	sub.Events = slices.Clone(sub.Events)
+	sub.CreatedAt = now()
+	w.subscriptions[sub.ID] = sub
+	w.nextSubscriptionID++
+	return sub, nil
+}
+
+func (w *WebhookRepo) GetSubscription(ctx context.Context, id int) (domain.WebhookSubscription, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.WebhookSubscription{}, err
+	}
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	sub, ok := w.subscriptions[id]
+	if !ok {
+		return domain.WebhookSubscription{}, fmt.Errorf("get webhook subscription: %w", sql.ErrNoRows)
+	}
+	sub.Events = slices.Clone(sub.Events)
+	return sub, nil
+}
+
+func (w *WebhookRepo) ListSubscriptions(ctx context.Context) ([]domain.WebhookSubscription, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	var subs []domain.WebhookSubscription
+	for _, id := range slices.Sorted(maps.Keys(w.subscriptions)) {
+		sub := w.subscriptions[id]
+		sub.Events = slices.Clone(sub.Events)
+		subs = append(subs, sub)
+	}
+	return subs, nil
+}
+
+func (w *WebhookRepo) DeleteSubscription(ctx context.Context, id int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, ok := w.subscriptions[id]; !ok {
+		return fmt.Errorf("delete webhook subscription: %w", sql.ErrNoRows)
+	}
+	delete(w.subscriptions, id)
+	w.deliveries = slices.DeleteFunc(w.deliveries, func(d *storedDelivery) bool {
+		return d.delivery.SubscriptionID == id
+	})
+	return nil
+}
+
+func (w *WebhookRepo) EnqueueDeliveries(ctx context.Context, event domain.Event, subscriptionIDs []int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("marshal event %d: %w", event.ID, err)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, subID := range subscriptionIDs {
+		if _, ok := w.subscriptions[subID]; !ok {
+			return fmt.Errorf("enqueue webhook deliveries: subscription %d does not exist", subID)
+		}
+		if slices.ContainsFunc(w.deliveries, func(d *storedDelivery) bool {
+			return d.delivery.SubscriptionID == subID && d.delivery.EventID == event.ID
+		}) {
+			continue
+		}
+		ts := now()
+		w.deliveries = append(w.deliveries, &storedDelivery{
+			delivery: domain.WebhookDelivery{
+				ID:             w.nextDeliveryID,
+				SubscriptionID: subID,
+				EventID:        event.ID,
+				EventType:      event.Type,
+				Payload:        data,
+				Status:         domain.DeliveryPending,
+				NextAttemptAt:  ts,
+				CreatedAt:      ts,
+				UpdatedAt:      ts,
+			},
+			nextAttempt: time.Now(),
+		})
+		w.nextDeliveryID++
+	}
+	return nil
+}
+
+func (w *WebhookRepo) ClaimDueDeliveries(ctx context.Context, now, until time.Time, limit int) ([]domain.WebhookDelivery, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var due []*storedDelivery
+	for _, d := range w.deliveries {
+		if d.delivery.Status == domain.DeliveryPending && !d.nextAttempt.After(now) {
+			due = append(due, d)
+		}
+	}
+	slices.SortStableFunc(due, func(a, b *storedDelivery) int {
+		return a.nextAttempt.Compare(b.nextAttempt)
+	})
+
+	var deliveries []domain.WebhookDelivery
+	for _, d := range due[:min(limit, len(due))] {
+		d.delivery.NextAttemptAt = until.UTC().Format(timeLayout)
+		d.nextAttempt = until
+		deliveries = append(deliveries, d.delivery)
+	}
+	return deliveries, nil
+}
+
+func (w *WebhookRepo) ListDeliveries(ctx context.Context, subscriptionID int, status string, limit int) ([]domain.WebhookDelivery, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	var deliveries []domain.WebhookDelivery
+	for _, d := range slices.Backward(w.deliveries) {
+		if len(deliveries) == limit {
+			break
+		}
+		if d.delivery.SubscriptionID == subscriptionID && (status == "" || d.delivery.Status == status) {
+			deliveries = append(deliveries, d.delivery)
+		}
+	}
+	return deliveries, nil
+}
+
+func (w *WebhookRepo) GetDelivery(ctx context.Context, id int) (domain.WebhookDelivery, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.WebhookDelivery{}, err
+	}
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	for _, d := range w.deliveries {
+		if d.delivery.ID == id {
+			return d.delivery, nil
+		}
+	}
+	return domain.WebhookDelivery{}, fmt.Errorf("get webhook delivery: %w", sql.ErrNoRows)
+}
+
+func (w *WebhookRepo) UpdateDelivery(ctx context.Context, d domain.WebhookDelivery, nextAttemptAt time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, stored := range w.deliveries {
+		if stored.delivery.ID != d.ID {
+			continue
+		}
+		stored.delivery.Status = d.Status
+		stored.delivery.Attempts = d.Attempts
+		stored.delivery.LastStatusCode = d.LastStatusCode
+		stored.delivery.LastError = d.LastError
+		stored.delivery.NextAttemptAt = nextAttemptAt.UTC().Format(timeLayout)
+		stored.delivery.UpdatedAt = now()
+		stored.nextAttempt = nextAttemptAt
+		return nil
+	}
+	return fmt.Errorf("update webhook delivery: %w", sql.ErrNoRows)
+}
@@ -0,0 +1,115 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"eve/domain"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// APIKeyRepo is a Postgres implementation of usecase.APIKeyRepository.
+type APIKeyRepo struct {
+	db *sqlx.DB
+}
+
+func NewAPIKeyRepo(db *sqlx.DB) *APIKeyRepo {
+	return &APIKeyRepo{db: db}
+}
+
+const apiKeyColumns = "id, name, prefix, hash, user_id, permissions, reviewable_type, created_by, created_at, last_used_at"
+
+// apiKeyRow scans the permissions array of a key.
+type apiKeyRow struct {
+	domain.APIKey
+	Permissions pq.StringArray `db:"permissions"`
+}
+
+func (row apiKeyRow) apiKey() domain.APIKey {
+	key := row.APIKey
+	key.Permissions = []string(row.Permissions)
+	return key
+}
+
+func (r *APIKeyRepo) CreateAPIKey(ctx context.Context, key domain.APIKey) (_ domain.APIKey, err error) {
+	query := `
+		INSERT INTO api_keys (name, prefix, hash, user_id, permissions, reviewable_type, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING ` + apiKeyColumns
+	ctx, span := startSpan(ctx, "api_keys.create", query)
+	defer func() { err = endSpan(ctx, span, err) }()
+
+	permissions := pq.StringArray(key.Permissions)
+	if permissions == nil {
+		permissions = pq.StringArray{} // a nil array is NULL
+	}
+	var row apiKeyRow
+	if err := r.db.GetContext(ctx, &row, query, key.Name, key.Prefix, key.Hash, key.UserID, permissions, key.ReviewableType, key.CreatedBy); err != nil {
+		return domain.APIKey{}, fmt.Errorf("create api key: %w", err)
+	}
+	return row.apiKey(), nil
+}
+
+func (r *APIKeyRepo) GetAPIKeyByPrefix(ctx context.Context, prefix string) (_ domain.APIKey, err error) {
+	query := "SELECT " + apiKeyColumns + " FROM api_keys WHERE prefix = $1"
+	ctx, span := startSpan(ctx, "api_keys.get_by_prefix", query)
+	defer func() { err = endSpan(ctx, span, err) }()
+
+	var row apiKeyRow
+	if err := r.db.GetContext(ctx, &row, query, prefix); err != nil {
+		return domain.APIKey{}, fmt.Errorf("get api key: %w", err)
+	}
+	return row.apiKey(), nil
+}
+
+func (r *APIKeyRepo) ListAPIKeys(ctx context.Context) (keys []domain.APIKey, err error) {
+	query := "SELECT " + apiKeyColumns + " FROM api_keys ORDER BY id"
+	ctx, span := startSpan(ctx, "api_keys.list", query)
+	defer func() { err = endSpan(ctx, span, err) }()
+
+	var rows []apiKeyRow
+	if err := r.db.SelectContext(ctx, &rows, query); err != nil {
+		return nil, fmt.Errorf("list api keys: %w", err)
+	}
+	for _, row := range rows {
+		keys = append(keys, row.apiKey())
+	}
+	return keys, nil
+}
+
+func (r *APIKeyRepo) DeleteAPIKey(ctx context.Context, id int) (err error) {
+	query := "DELETE FROM api_keys WHERE id = $1"
+	ctx, span := startSpan(ctx, "api_keys.delete", query)
+	defer func() { err = endSpan(ctx, span, err) }()
+
+	res, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("delete api key: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("delete api key: %w", err)
+	} else if n == 0 {
+		return fmt.Errorf("delete api key: %w", sql.ErrNoRows)
+	}
+	return nil
+}
+
+func (r *APIKeyRepo) TouchAPIKey(ctx context.Context, id int, at time.Time) (err error) {
+	query := "UPDATE api_keys SET last_used_at = $1 WHERE id = $2"
+	ctx, span := startSpan(ctx, "api_keys.touch", query)
+	defer func() { err = endSpan(ctx, span, err) }()
+
+	res, err := r.db.ExecContext(ctx, query, at.UTC(), id)
+	if err != nil {
+		return fmt.Errorf("touch api key: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("touch api key: %w", err)
+	} else if n == 0 {
+		return fmt.Errorf("touch api key: %w", sql.ErrNoRows)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"eve/domain"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// liveReview restricts rows of a table with a review_id column to reviews
+// that are not soft-deleted.
+const liveReview = "review_id IN (SELECT id FROM reviews WHERE deleted_at IS NULL)"
+
+// DeleteReview soft-deletes a review: it disappears from every read path but
+// keeps its photos, comments and response until PurgeDeletedReviews removes it.
+func (r *ReviewRepo) DeleteReview(ctx context.Context, id int, version string) (err error) {
+	query := "UPDATE reviews SET deleted_at = now() WHERE id = $1 AND deleted_at IS NULL"
+	ctx, span := startSpan(ctx, "reviews.delete", query)
+	defer func() { err = endSpan(ctx, span, err) }()
+
+	if err := r.changeReview(ctx, query, id, version, domain.EventReviewDeleted); err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("delete review: %w", err)
+	}
+	return nil
+}
+
+func (r *ReviewRepo) RestoreReview(ctx context.Context, id int) (err error) {
+	query := "UPDATE reviews SET deleted_at = NULL WHERE id = $1 AND deleted_at IS NOT NULL"
+	ctx, span := startSpan(ctx, "reviews.restore", query)
+	defer func() { err = endSpan(ctx, span, err) }()
+
+	if err := r.changeReview(ctx, query, id, "", domain.EventReviewUpdated); err != nil {
+		return fmt.Errorf("restore review: %w", err)
+	}
+	return nil
+}
+
+// purgedPhotos selects the files of the photos of the reviews in $1 that no
+// other review's photo points at. Clients choose photo paths, so two reviews
+// may share one, and removing it would break the review that survives.
+const purgedPhotos = `
+	SELECT file_path FROM review_photos
+	WHERE review_id = ANY($1)
+	  AND file_path NOT IN (SELECT file_path FROM review_photos WHERE NOT review_id = ANY($1))
+	GROUP BY file_path
+	ORDER BY min(id)
+`
+
+func (r *ReviewRepo) PurgeDeletedReviews(ctx context.Context, deletedBefore time.Time, limit int) (purged domain.PurgedReviews, err error) {
+	// SKIP LOCKED lets several instances purge concurrently without blocking
+	// each other or a restore in progress.
+	query := `
+		SELECT id FROM reviews
+		WHERE deleted_at < $1
+		ORDER BY deleted_at, id
+		LIMIT $2
+		FOR UPDATE SKIP LOCKED
+	`
+	ctx, span := startSpan(ctx, "reviews.purge", query)
+	defer func() { err = endSpan(ctx, span, err) }()
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return domain.PurgedReviews{}, fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if err := tx.SelectContext(ctx, &purged.ReviewIDs, query, deletedBefore.UTC(), limit); err != nil {
+		return domain.PurgedReviews{}, fmt.Errorf("select purgeable reviews: %w", err)
+	}
+	if len(purged.ReviewIDs) == 0 {
+		return domain.PurgedReviews{}, nil
+	}
+
+	ids := pq.Array(purged.ReviewIDs)
+	if err := tx.SelectContext(ctx, &purged.FilePaths, purgedPhotos, ids); err != nil {
+		return domain.PurgedReviews{}, fmt.Errorf("select purged photos: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM reviews WHERE id = ANY($1)", ids); err != nil {
+		return domain.PurgedReviews{}, fmt.Errorf("purge reviews: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return domain.PurgedReviews{}, fmt.Errorf("commit purge tx: %w", err)
+	}
+	return purged, nil
+}
@@ -0,0 +1,96 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"eve/domain"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// IdempotencyStore is a Postgres implementation of usecase.IdempotencyStore.
+type IdempotencyStore struct {
+	db *sqlx.DB
+}
+
+func NewIdempotencyStore(db *sqlx.DB) *IdempotencyStore {
+	return &IdempotencyStore{db: db}
+}
+
+const idempotencyColumns = "key, fingerprint, status, content_type, body, created_at"
+
+func (s *IdempotencyStore) Reserve(ctx context.Context, key, fingerprint string, lease time.Duration) (rec domain.IdempotencyRecord, reserved bool, err error) {
+	// An expired record is taken over as if it did not exist; a live one is
+	// left alone and the statement returns no row.
+	query := `
+		INSERT INTO idempotency_keys (key, fingerprint, created_at, expires_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (key) DO UPDATE
+		SET fingerprint = EXCLUDED.fingerprint, status = 0, content_type = '', body = NULL,
+		    created_at = EXCLUDED.created_at, expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at <= EXCLUDED.created_at
+		RETURNING ` + idempotencyColumns
+	ctx, span := startSpan(ctx, "idempotency_keys.reserve", query)
+	defer func() { err = endSpan(ctx, span, err) }()
+
+	now := time.Now().UTC()
+	err = s.db.GetContext(ctx, &rec, query, key, fingerprint, now, now.Add(lease))
+	if err == nil {
+		return rec, true, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return domain.IdempotencyRecord{}, false, fmt.Errorf("reserve idempotency key: %w", err)
+	}
+
+	if err := s.db.GetContext(ctx, &rec, "SELECT "+idempotencyColumns+" FROM idempotency_keys WHERE key = $1", key); err != nil {
+		return domain.IdempotencyRecord{}, false, fmt.Errorf("get idempotency key: %w", err)
+	}
+	return rec, false, nil
+}
+
+func (s *IdempotencyStore) Complete(ctx context.Context, rec domain.IdempotencyRecord, ttl time.Duration) (err error) {
+	query := "UPDATE idempotency_keys SET status = $2, content_type = $3, body = $4, expires_at = $5 WHERE key = $1"
+	ctx, span := startSpan(ctx, "idempotency_keys.complete", query)
+	defer func() { err = endSpan(ctx, span, err) }()
+
+	res, err := s.db.ExecContext(ctx, query, rec.Key, rec.Status, rec.ContentType, rec.Body, time.Now().UTC().Add(ttl))
+	if err != nil {
+		return fmt.Errorf("complete idempotency key: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("complete idempotency key: %w", err)
+	} else if n == 0 {
+		return fmt.Errorf("complete idempotency key: %w", sql.ErrNoRows)
+	}
+	return nil
+}
+
+func (s *IdempotencyStore) Release(ctx context.Context, key string) (err error) {
+	query := "DELETE FROM idempotency_keys WHERE key = $1"
+	ctx, span := startSpan(ctx, "idempotency_keys.release", query)
+	defer func() { err = endSpan(ctx, span, err) }()
+
+	if _, err := s.db.ExecContext(ctx, query, key); err != nil {
+		return fmt.Errorf("release idempotency key: %w", err)
+	}
+	return nil
+}
+
+func (s *IdempotencyStore) DeleteExpired(ctx context.Context, before time.Time) (_ int, err error) {
+	query := "DELETE FROM idempotency_keys WHERE expires_at < $1"
+	ctx, span := startSpan(ctx, "idempotency_keys.delete_expired", query)
+	defer func() { err = endSpan(ctx, span, err) }()
+
+	res, err := s.db.ExecContext(ctx, query, before.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("delete expired idempotency keys: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("delete expired idempotency keys: %w", err)
+	}
+	return int(n), nil
+}
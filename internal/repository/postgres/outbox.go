@@ -0,0 +1,125 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"eve/domain"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// reviewColumns are the columns of a review as returned to the use-cases.
+const reviewColumns = "id, reviewable_type, reviewable_id, user_id, rating, title, body, status, verified, created_at, updated_at"
+
+// addEvent records a domain event inside the transaction of the change it describes.
+func addEvent(ctx context.Context, tx *sqlx.Tx, eventType string, reviewID int, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshal %s event: %w", eventType, err)
+	}
+	// JSON is passed as text: lib/pq would send []byte as bytea.
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO outbox_events (event_type, review_id, reviewable_type, payload)
+		SELECT $1::text, id, reviewable_type, $3::jsonb FROM reviews WHERE id = $2
+	`, eventType, reviewID, string(data))
+	if err != nil {
+		return fmt.Errorf("insert %s event: %w", eventType, err)
+	}
+	return nil
+}
+
+// changeReview runs stmt, an UPDATE of the review with ID $1, and records
+// eventType with the updated review in the same transaction. A non-empty
+// version must match the review's updated_at, locked for the transaction, or
+// domain.ErrReviewChanged is reported. It reports sql.ErrNoRows when stmt, or
+// the version check, matched no review.
+func (r *ReviewRepo) changeReview(ctx context.Context, stmt string, id int, version, eventType string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if version != "" {
+		var current string
+		if err := tx.GetContext(ctx, &current, "SELECT updated_at FROM reviews WHERE id = $1 FOR UPDATE", id); err != nil {
+			return err
+		}
+		if current != version {
+			return domain.ErrReviewChanged
+		}
+	}
+
+	var changed domain.Review
+	if err := tx.GetContext(ctx, &changed, stmt+" RETURNING "+reviewColumns, id); err != nil {
+		return err
+	}
+	if err := addEvent(ctx, tx, eventType, id, changed); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// OutboxRepo is a Postgres implementation of usecase.OutboxRepository.
+type OutboxRepo struct {
+	db *sqlx.DB
+}
+
+func NewOutboxRepo(db *sqlx.DB) *OutboxRepo {
+	return &OutboxRepo{db: db}
+}
+
+// ListUnpublished leaves out the events behind an event of the same review
+// that waits for a later attempt.
+func (o *OutboxRepo) ListUnpublished(ctx context.Context, now time.Time, limit int) (events []domain.Event, err error) {
+	query := `
+		SELECT e.id, e.event_type, e.review_id, e.reviewable_type, e.payload, e.created_at, e.attempts
+		FROM outbox_events e
+		WHERE e.published_at IS NULL AND e.failed_at IS NULL
+			AND (e.next_attempt_at IS NULL OR e.next_attempt_at <= $1)
+			AND NOT EXISTS (
+				SELECT 1 FROM outbox_events w
+				WHERE w.review_id = e.review_id AND w.id < e.id
+					AND w.published_at IS NULL AND w.failed_at IS NULL AND w.next_attempt_at > $1
+			)
+		ORDER BY e.id
+		LIMIT $2
+	`
+	ctx, span := startSpan(ctx, "outbox_events.list_unpublished", query)
+	defer func() { err = endSpan(ctx, span, err) }()
+
+	if err := o.db.SelectContext(ctx, &events, query, now.UTC(), limit); err != nil {
+		return nil, fmt.Errorf("list unpublished events: %w", err)
+	}
+	return events, nil
+}
+
+func (o *OutboxRepo) MarkPublished(ctx context.Context, ids []int) (err error) {
+	query := "UPDATE outbox_events SET published_at = now() WHERE id = ANY($1)"
+	ctx, span := startSpan(ctx, "outbox_events.mark_published", query)
+	defer func() { err = endSpan(ctx, span, err) }()
+
+	if _, err := o.db.ExecContext(ctx, query, pq.Array(ids)); err != nil {
+		return fmt.Errorf("mark events published: %w", err)
+	}
+	return nil
+}
+
+func (o *OutboxRepo) RecordFailure(ctx context.Context, id int, reason string, nextAttemptAt time.Time, dead bool) (err error) {
+	query := `
+		UPDATE outbox_events
+		SET attempts = attempts + 1, last_error = $2, next_attempt_at = $3,
+			failed_at = CASE WHEN $4 THEN now() END
+		WHERE id = $1
+	`
+	ctx, span := startSpan(ctx, "outbox_events.record_failure", query)
+	defer func() { err = endSpan(ctx, span, err) }()
+
+	if _, err := o.db.ExecContext(ctx, query, id, reason, nextAttemptAt.UTC(), dead); err != nil {
+		return fmt.Errorf("record event failure: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"eve/domain"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// OwnerRepo is a Postgres implementation of usecase.OwnerRepository.
+type OwnerRepo struct {
+	db *sqlx.DB
+}
+
+func NewOwnerRepo(db *sqlx.DB) *OwnerRepo {
+	return &OwnerRepo{db: db}
+}
+
+func (o *OwnerRepo) AddOwner(ctx context.Context, owner domain.ReviewableOwner) (err error) {
+	query := `
+		INSERT INTO reviewable_owners (reviewable_type, reviewable_id, user_id)
+		VALUES ($1, $2, $3)
+		ON CONFLICT DO NOTHING
+	`
+	ctx, span := startSpan(ctx, "reviewable_owners.add", query)
+	defer func() { err = endSpan(ctx, span, err) }()
+
+	if _, err := o.db.ExecContext(ctx, query, owner.ReviewableType, owner.ReviewableID, owner.UserID); err != nil {
+		return fmt.Errorf("insert owner: %w", err)
+	}
+	return nil
+}
+
+func (o *OwnerRepo) RemoveOwner(ctx context.Context, reviewableType string, reviewableID, userID int) (err error) {
+	query := "DELETE FROM reviewable_owners WHERE reviewable_type = $1 AND reviewable_id = $2 AND user_id = $3"
+	ctx, span := startSpan(ctx, "reviewable_owners.remove", query)
+	defer func() { err = endSpan(ctx, span, err) }()
+
+	res, err := o.db.ExecContext(ctx, query, reviewableType, reviewableID, userID)
+	if err != nil {
+		return fmt.Errorf("remove owner: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("remove owner: %w", err)
+	} else if n == 0 {
+		return fmt.Errorf("remove owner: %w", sql.ErrNoRows)
+	}
+	return nil
+}
+
+func (o *OwnerRepo) ListOwners(ctx context.Context, reviewableType string, reviewableID int) (owners []domain.ReviewableOwner, err error) {
+	query := `
+		SELECT reviewable_type, reviewable_id, user_id, created_at
+		FROM reviewable_owners
+		WHERE reviewable_type = $1 AND reviewable_id = $2
+		ORDER BY user_id
+	`
+	ctx, span := startSpan(ctx, "reviewable_owners.list", query)
+	defer func() { err = endSpan(ctx, span, err) }()
+
+	if err := o.db.SelectContext(ctx, &owners, query, reviewableType, reviewableID); err != nil {
+		return nil, fmt.Errorf("list owners: %w", err)
+	}
+	return owners, nil
+}
+
+func (o *OwnerRepo) IsOwner(ctx context.Context, reviewableType string, reviewableID, userID int) (owner bool, err error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM reviewable_owners
+			WHERE reviewable_type = $1 AND reviewable_id = $2 AND user_id = $3
+		)
+	`
+	ctx, span := startSpan(ctx, "reviewable_owners.is_owner", query)
+	defer func() { err = endSpan(ctx, span, err) }()
+
+	if err := o.db.GetContext(ctx, &owner, query, reviewableType, reviewableID, userID); err != nil {
+		return false, fmt.Errorf("check owner: %w", err)
+	}
+	return owner, nil
+}
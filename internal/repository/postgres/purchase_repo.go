@@ -0,0 +1,34 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// PurchaseRepo is a Postgres implementation of usecase.PurchaseVerifier backed
+// by the purchases table, which the order system writes into.
+type PurchaseRepo struct {
+	db *sqlx.DB
+}
+
+func NewPurchaseRepo(db *sqlx.DB) *PurchaseRepo {
+	return &PurchaseRepo{db: db}
+}
+
+func (p *PurchaseRepo) HasPurchased(ctx context.Context, userID int, reviewableType string, reviewableID int) (purchased bool, err error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM purchases
+			WHERE user_id = $1 AND reviewable_type = $2 AND reviewable_id = $3
+		)
+	`
+	ctx, span := startSpan(ctx, "purchases.exists", query)
+	defer func() { err = endSpan(ctx, span, err) }()
+
+	if err := p.db.GetContext(ctx, &purchased, query, userID, reviewableType, reviewableID); err != nil {
+		return false, fmt.Errorf("check purchase: %w", err)
+	}
+	return purchased, nil
+}
@@ -0,0 +1,91 @@
+package postgres
+
+import (
+	"context"
+	"eve/domain"
+	"fmt"
+	"slices"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// insertSubRatings stores the sub-ratings of a new review within tx, in key order.
+func insertSubRatings(ctx context.Context, tx *sqlx.Tx, reviewID int, subRatings map[string]int) error {
+	keys := make([]string, 0, len(subRatings))
+	for k := range subRatings {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	for _, k := range keys {
+		_, err := tx.ExecContext(ctx,
+			"INSERT INTO review_sub_ratings (review_id, criterion, rating) VALUES ($1, $2, $3)",
+			reviewID, k, subRatings[k],
+		)
+		if err != nil {
+			return fmt.Errorf("insert sub-rating: %w", err)
+		}
+	}
+	return nil
+}
+
+func (r *ReviewRepo) ListSubRatings(ctx context.Context, reviewIDs []int) (_ map[int]map[string]int, err error) {
+	query := "SELECT review_id, criterion, rating FROM review_sub_ratings WHERE review_id = ANY($1) AND " + liveReview
+	ctx, span := startSpan(ctx, "review_sub_ratings.list", query)
+	defer func() { err = endSpan(ctx, span, err) }()
+
+	var rows []struct {
+		ReviewID  int    `db:"review_id"`
+		Criterion string `db:"criterion"`
+		Rating    int    `db:"rating"`
+	}
+	if err := r.db.SelectContext(ctx, &rows, query, pq.Array(reviewIDs)); err != nil {
+		return nil, fmt.Errorf("list sub-ratings: %w", err)
+	}
+	out := make(map[int]map[string]int)
+	for _, row := range rows {
+		if out[row.ReviewID] == nil {
+			out[row.ReviewID] = make(map[string]int)
+		}
+		out[row.ReviewID][row.Criterion] = row.Rating
+	}
+	return out, nil
+}
+
+func (r *ReviewRepo) Summarize(ctx context.Context, reviewableType string, reviewableID int) (summary domain.ReviewSummary, err error) {
+	totalsQuery := `
+		SELECT COUNT(*) AS count, COALESCE(AVG(rating), 0)::float8 AS average
+		FROM reviews
+		WHERE reviewable_type = $1 AND reviewable_id = $2 AND deleted_at IS NULL AND status = 'published'
+	`
+	criteriaQuery := `
+		SELECT s.criterion, COUNT(*) AS count, AVG(s.rating)::float8 AS average
+		FROM review_sub_ratings s
+		JOIN reviews r ON r.id = s.review_id
+		WHERE r.reviewable_type = $1 AND r.reviewable_id = $2 AND r.deleted_at IS NULL AND r.status = 'published'
+		GROUP BY s.criterion
+		ORDER BY s.criterion
+	`
+	ctx, span := startSpan(ctx, "reviews.summarize", totalsQuery)
+	defer func() { err = endSpan(ctx, span, err) }()
+
+	var totals struct {
+		Count   int     `db:"count"`
+		Average float64 `db:"average"`
+	}
+	if err := r.db.GetContext(ctx, &totals, totalsQuery, reviewableType, reviewableID); err != nil {
+		return domain.ReviewSummary{}, fmt.Errorf("summarize ratings: %w", err)
+	}
+	summary = domain.ReviewSummary{
+		ReviewableType: reviewableType,
+		ReviewableID:   reviewableID,
+		Count:          totals.Count,
+		AverageRating:  totals.Average,
+		Criteria:       []domain.CriterionAverage{},
+	}
+	if err := r.db.SelectContext(ctx, &summary.Criteria, criteriaQuery, reviewableType, reviewableID); err != nil {
+		return domain.ReviewSummary{}, fmt.Errorf("summarize sub-ratings: %w", err)
+	}
+	return summary, nil
+}
@@ -0,0 +1,90 @@
+package postgres
+
+import (
+	"context"
+	"eve/domain"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+const responseColumns = "id, review_id, user_id, body, created_at, updated_at"
+
+func (r *ReviewRepo) SaveResponse(ctx context.Context, resp domain.ReviewResponse) (saved domain.ReviewResponse, err error) {
+	upsert := `
+		INSERT INTO review_responses (review_id, user_id, body)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (review_id) DO UPDATE SET user_id = EXCLUDED.user_id, body = EXCLUDED.body
+		RETURNING ` + responseColumns
+	version := `
+		INSERT INTO review_response_versions (response_id, user_id, body)
+		VALUES ($1, $2, $3)
+	`
+	ctx, span := startSpan(ctx, "review_responses.save", upsert)
+	defer func() { err = endSpan(ctx, span, err) }()
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return domain.ReviewResponse{}, fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if err := tx.GetContext(ctx, &saved, upsert, resp.ReviewID, resp.UserID, resp.Body); err != nil {
+		return domain.ReviewResponse{}, fmt.Errorf("upsert response: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, version, saved.ID, saved.UserID, saved.Body); err != nil {
+		return domain.ReviewResponse{}, fmt.Errorf("insert response version: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return domain.ReviewResponse{}, fmt.Errorf("commit response tx: %w", err)
+	}
+	return saved, nil
+}
+
+func (r *ReviewRepo) GetResponse(ctx context.Context, reviewID int) (resp domain.ReviewResponse, err error) {
+	query := "SELECT " + responseColumns + " FROM review_responses WHERE review_id = $1 AND " + liveReview
+	ctx, span := startSpan(ctx, "review_responses.get", query)
+	defer func() { err = endSpan(ctx, span, err) }()
+
+	if err := r.db.GetContext(ctx, &resp, query, reviewID); err != nil {
+		return domain.ReviewResponse{}, fmt.Errorf("get response: %w", err)
+	}
+	return resp, nil
+}
+
+func (r *ReviewRepo) ListResponses(ctx context.Context, reviewIDs []int) (_ map[int]domain.ReviewResponse, err error) {
+	query := "SELECT " + responseColumns + " FROM review_responses WHERE review_id = ANY($1) AND " + liveReview
+	ctx, span := startSpan(ctx, "review_responses.list", query)
+	defer func() { err = endSpan(ctx, span, err) }()
+
+	var rows []domain.ReviewResponse
+	if err := r.db.SelectContext(ctx, &rows, query, pq.Array(reviewIDs)); err != nil {
+		return nil, fmt.Errorf("list responses: %w", err)
+	}
+	out := make(map[int]domain.ReviewResponse, len(rows))
+	for _, resp := range rows {
+		out[resp.ReviewID] = resp
+	}
+	return out, nil
+}
+
+func (r *ReviewRepo) ListResponseVersions(ctx context.Context, reviewID int) (versions []domain.ReviewResponseVersion, err error) {
+	query := `
+		SELECT v.id, v.response_id, v.user_id, v.body, v.created_at
+		FROM review_response_versions v
+		JOIN review_responses r ON r.id = v.response_id
+		WHERE r.review_id = $1
+		  AND r.review_id IN (SELECT id FROM reviews WHERE deleted_at IS NULL)
+		ORDER BY v.created_at ASC, v.id ASC
+	`
+	ctx, span := startSpan(ctx, "review_response_versions.list", query)
+	defer func() { err = endSpan(ctx, span, err) }()
+
+	if err := r.db.SelectContext(ctx, &versions, query, reviewID); err != nil {
+		return nil, fmt.Errorf("list response versions: %w", err)
+	}
+	return versions, nil
+}
@@ -0,0 +1,62 @@
+package postgres
+
+import (
+	"context"
+	"eve/domain"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ReviewableTypeRepo is a Postgres implementation of usecase.ReviewableTypeRepository.
+type ReviewableTypeRepo struct {
+	db *sqlx.DB
+}
+
+func NewReviewableTypeRepo(db *sqlx.DB) *ReviewableTypeRepo {
+	return &ReviewableTypeRepo{db: db}
+}
+
+const reviewableTypeColumns = "name, photos_allowed, max_body_length, title_required, moderation_mode, created_at, updated_at"
+
+func (t *ReviewableTypeRepo) SaveType(ctx context.Context, rt domain.ReviewableType) (saved domain.ReviewableType, err error) {
+	query := `
+		INSERT INTO reviewable_types (name, photos_allowed, max_body_length, title_required, moderation_mode)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (name) DO UPDATE SET
+			photos_allowed = EXCLUDED.photos_allowed,
+			max_body_length = EXCLUDED.max_body_length,
+			title_required = EXCLUDED.title_required,
+			moderation_mode = EXCLUDED.moderation_mode
+		RETURNING ` + reviewableTypeColumns
+	ctx, span := startSpan(ctx, "reviewable_types.save", query)
+	defer func() { err = endSpan(ctx, span, err) }()
+
+	err = t.db.GetContext(ctx, &saved, query, rt.Name, rt.PhotosAllowed, rt.MaxBodyLength, rt.TitleRequired, rt.ModerationMode)
+	if err != nil {
+		return domain.ReviewableType{}, fmt.Errorf("save reviewable type: %w", err)
+	}
+	return saved, nil
+}
+
+func (t *ReviewableTypeRepo) GetType(ctx context.Context, name string) (rt domain.ReviewableType, err error) {
+	query := "SELECT " + reviewableTypeColumns + " FROM reviewable_types WHERE name = $1"
+	ctx, span := startSpan(ctx, "reviewable_types.get", query)
+	defer func() { err = endSpan(ctx, span, err) }()
+
+	if err := t.db.GetContext(ctx, &rt, query, name); err != nil {
+		return domain.ReviewableType{}, fmt.Errorf("get reviewable type: %w", err)
+	}
+	return rt, nil
+}
+
+func (t *ReviewableTypeRepo) ListTypes(ctx context.Context) (types []domain.ReviewableType, err error) {
+	query := "SELECT " + reviewableTypeColumns + " FROM reviewable_types ORDER BY name"
+	ctx, span := startSpan(ctx, "reviewable_types.list", query)
+	defer func() { err = endSpan(ctx, span, err) }()
+
+	if err := t.db.SelectContext(ctx, &types, query); err != nil {
+		return nil, fmt.Errorf("list reviewable types: %w", err)
+	}
+	return types, nil
+}
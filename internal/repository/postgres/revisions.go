@@ -0,0 +1,145 @@
+package postgres
+
+import (
+	"context"
+	"eve/domain"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const insertRevision = `
+	INSERT INTO revisions (review_id, entity_type, entity_id, actor_id, old_values, new_values)
+	VALUES ($1, $2, $3, $4, $5, $6)
+`
+
+func (r *ReviewRepo) UpdateReview(ctx context.Context, review domain.Review, actorID int, version string) (updated domain.Review, err error) {
+	query := `
+		UPDATE reviews SET rating = $2, title = $3, body = $4, status = $5
+		WHERE id = $1
+		RETURNING id, reviewable_type, reviewable_id, user_id, rating, title, body, status, verified, created_at, updated_at
+	`
+	ctx, span := startSpan(ctx, "reviews.update", query)
+	defer func() { err = endSpan(ctx, span, err) }()
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return domain.Review{}, fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	var stored domain.Review
+	err = tx.GetContext(ctx, &stored, `
+		SELECT id, reviewable_type, reviewable_id, user_id, rating, title, body, status, verified, created_at, updated_at
+		FROM reviews
+		WHERE id = $1 AND deleted_at IS NULL
+		FOR UPDATE
+	`, review.ID)
+	if err != nil {
+		return domain.Review{}, fmt.Errorf("get review by id: %w", err)
+	}
+	if version != "" && stored.UpdatedAt != version {
+		return domain.Review{}, fmt.Errorf("update review: %w", domain.ErrReviewChanged)
+	}
+
+	next := stored
+	next.Rating, next.Title, next.Body = review.Rating, review.Title, review.Body
+	if review.Status != "" {
+		next.Status = review.Status
+	}
+	oldValues, newValues, changed := domain.ReviewChanges(stored, next)
+	if !changed {
+		return stored, nil
+	}
+
+	if err := tx.GetContext(ctx, &updated, query, review.ID, next.Rating, next.Title, next.Body, next.Status); err != nil {
+		return domain.Review{}, fmt.Errorf("update review: %w", err)
+	}
+	if err := addRevision(ctx, tx, review.ID, domain.RevisionEntityReview, review.ID, actorID, oldValues, newValues); err != nil {
+		return domain.Review{}, err
+	}
+	if err := addEvent(ctx, tx, domain.EventReviewUpdated, review.ID, updated); err != nil {
+		return domain.Review{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return domain.Review{}, fmt.Errorf("commit review update tx: %w", err)
+	}
+	return updated, nil
+}
+
+func (r *ReviewRepo) UpdateComment(ctx context.Context, comment domain.ReviewComment, actorID int) (updated domain.ReviewComment, err error) {
+	query := `
+		UPDATE review_comments SET body = $2
+		WHERE id = $1
+		RETURNING id, review_id, parent_id, depth, user_id, body, created_at, updated_at
+	`
+	ctx, span := startSpan(ctx, "review_comments.update", query)
+	defer func() { err = endSpan(ctx, span, err) }()
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return domain.ReviewComment{}, fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	var stored domain.ReviewComment
+	err = tx.GetContext(ctx, &stored, `
+		SELECT id, review_id, parent_id, depth, user_id, body, created_at, updated_at
+		FROM review_comments
+		WHERE id = $1
+		FOR UPDATE
+	`, comment.ID)
+	if err != nil {
+		return domain.ReviewComment{}, fmt.Errorf("get comment by id: %w", err)
+	}
+
+	next := stored
+	next.Body = comment.Body
+	oldValues, newValues, changed := domain.CommentChanges(stored, next)
+	if !changed {
+		return stored, nil
+	}
+
+	if err := tx.GetContext(ctx, &updated, query, comment.ID, comment.Body); err != nil {
+		return domain.ReviewComment{}, fmt.Errorf("update comment: %w", err)
+	}
+	if err := addRevision(ctx, tx, stored.ReviewID, domain.RevisionEntityComment, comment.ID, actorID, oldValues, newValues); err != nil {
+		return domain.ReviewComment{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return domain.ReviewComment{}, fmt.Errorf("commit comment update tx: %w", err)
+	}
+	return updated, nil
+}
+
+// addRevision records a revision inside the transaction of the edit it describes.
+func addRevision(ctx context.Context, tx *sqlx.Tx, reviewID int, entityType string, entityID, actorID int, oldValues, newValues []byte) error {
+	// JSON is passed as text: lib/pq would send []byte as bytea.
+	_, err := tx.ExecContext(ctx, insertRevision, reviewID, entityType, entityID, actorID, string(oldValues), string(newValues))
+	if err != nil {
+		return fmt.Errorf("insert revision: %w", err)
+	}
+	return nil
+}
+
+func (r *ReviewRepo) ListRevisions(ctx context.Context, reviewID int) (revisions []domain.Revision, err error) {
+	query := `
+		SELECT id, review_id, entity_type, entity_id, actor_id, old_values, new_values, created_at
+		FROM revisions
+		WHERE review_id = $1 AND ` + liveReview + `
+		ORDER BY created_at ASC, id ASC
+	`
+	ctx, span := startSpan(ctx, "revisions.list", query)
+	defer func() { err = endSpan(ctx, span, err) }()
+
+	if err := r.db.SelectContext(ctx, &revisions, query, reviewID); err != nil {
+		return nil, fmt.Errorf("list revisions: %w", err)
+	}
+	return revisions, nil
+}
@@ -0,0 +1,65 @@
+package postgres
+
+import (
+	"context"
+	"eve/domain"
+	"fmt"
+)
+
+// searchFilter restricts matches to live, published reviews and the optional reviewable filter.
+// Arguments: $1 language, $2 query text, $3 reviewable_type (empty = any),
+// $4 reviewable_id (0 = any).
+const searchFilter = `
+		FROM reviews, websearch_to_tsquery($1::regconfig, $2) AS q
+		WHERE search_vector @@ q
+		  AND deleted_at IS NULL AND status = 'published'
+		  AND ($3 = '' OR reviewable_type = $3)
+		  AND ($4 = 0 OR reviewable_id = $4)
+`
+
+// searchRow is a review together with its rank and highlighted fragments.
+type searchRow struct {
+	domain.Review
+	Rank           float64 `db:"rank"`
+	TitleHighlight string  `db:"title_highlight"`
+	BodyHighlight  string  `db:"body_highlight"`
+}
+
+// SearchReviews implements usecase.ReviewSearcher with Postgres full-text
+// search. Title matches weigh more than body matches.
+func (r *ReviewRepo) SearchReviews(ctx context.Context, q domain.ReviewSearchQuery) (res domain.ReviewSearchResult, err error) {
+	marks := "StartSel=" + domain.HighlightStart + ", StopSel=" + domain.HighlightStop
+	query := `
+		SELECT id, reviewable_type, reviewable_id, user_id, rating, title, body, status, verified, created_at, updated_at,
+			ts_rank(search_vector, q) AS rank,
+			ts_headline(search_language, coalesce(title, ''), q,
+				'` + marks + `, HighlightAll=true') AS title_highlight,
+			ts_headline(search_language, coalesce(body, ''), q,
+				'` + marks + `, MaxFragments=2, MaxWords=30, MinWords=10') AS body_highlight
+	` + searchFilter + `
+		ORDER BY rank DESC, id DESC
+		LIMIT $5 OFFSET $6
+	`
+	ctx, span := startSpan(ctx, "reviews.search", query)
+	defer func() { err = endSpan(ctx, span, err) }()
+
+	args := []any{r.searchLanguage, q.Query, q.ReviewableType, q.ReviewableID}
+
+	if err := r.db.GetContext(ctx, &res.Total, "SELECT count(*)"+searchFilter, args...); err != nil {
+		return domain.ReviewSearchResult{}, fmt.Errorf("count search results: %w", err)
+	}
+
+	var rows []searchRow
+	if err := r.db.SelectContext(ctx, &rows, query, append(args, q.Limit, q.Offset)...); err != nil {
+		return domain.ReviewSearchResult{}, fmt.Errorf("search reviews: %w", err)
+	}
+	for _, row := range rows {
+		res.Hits = append(res.Hits, domain.ReviewSearchHit{
+			Review:         row.Review,
+			Rank:           row.Rank,
+			TitleHighlight: domain.HighlightHTML(row.TitleHighlight),
+			BodyHighlight:  domain.HighlightHTML(row.BodyHighlight),
+		})
+	}
+	return res, nil
+}
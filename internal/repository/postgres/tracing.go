@@ -0,0 +1,45 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"eve/internal/repository/dbtrace"
+	"fmt"
+
+	"github.com/lib/pq"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = dbtrace.New("eve/internal/repository/postgres", "postgres", "postgresql")
+
+// queryCanceled is the SQLSTATE Postgres reports for a cancelled statement,
+// which is how lib/pq surfaces a context that ended mid-query.
+const queryCanceled = "57014"
+
+// startSpan starts a client span for a repository operation executing query.
+func startSpan(ctx context.Context, operation, query string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, operation, query)
+}
+
+// endSpan records err on span (if any), ends it and returns err as the
+// operation reports it, see contextError.
+func endSpan(ctx context.Context, span trace.Span, err error) error {
+	err = contextError(ctx, err)
+	dbtrace.End(span, err)
+	return err
+}
+
+// contextError makes a statement Postgres cancelled match the error of ctx,
+// so that callers can tell a timeout from a failure without knowing the
+// driver. A statement cancelled while ctx is still live hit the server's
+// statement_timeout and matches context.DeadlineExceeded.
+func contextError(ctx context.Context, err error) error {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) || pqErr.Code != queryCanceled {
+		return err
+	}
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return fmt.Errorf("%w: %w", err, ctxErr)
+	}
+	return fmt.Errorf("%w: %w", err, context.DeadlineExceeded)
+}
@@ -0,0 +1,42 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+func TestContextError(t *testing.T) {
+	canceled := fmt.Errorf("get review: %w", &pq.Error{Code: queryCanceled, Message: "canceling statement due to user request"})
+	gone, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for _, tt := range []struct {
+		name string
+		ctx  context.Context
+		err  error
+		want error
+	}{
+		{"client gone", gone, canceled, context.Canceled},
+		{"statement timeout", context.Background(), canceled, context.DeadlineExceeded},
+		{"other error", gone, &pq.Error{Code: "23505"}, nil},
+	} {
+		got := contextError(tt.ctx, tt.err)
+		if tt.want == nil {
+			if got != tt.err {
+				t.Errorf("contextError(%s) = %v, want it unchanged", tt.name, got)
+			}
+			continue
+		}
+		var pqErr *pq.Error
+		if !errors.Is(got, tt.want) || !errors.As(got, &pqErr) {
+			t.Errorf("contextError(%s) = %v, want it to match %v and keep the driver error", tt.name, got, tt.want)
+		}
+	}
+	if contextError(context.Background(), nil) != nil {
+		t.Error("contextError(nil) != nil")
+	}
+}
@@ -1,7 +1,10 @@
 package postgres
 
 import (
+	"context"
+	"database/sql"
 	"eve/domain"
+	"fmt"
 
 	"github.com/jmoiron/sqlx"
 )
@@ -14,16 +17,63 @@ func NewUserRepo(db *sqlx.DB) *UserRepo {
 	return &UserRepo{db}
 }
 
-func (u *UserRepo) Save(user domain.User) error {
-	_, err := u.db.Exec("INSERT INTO users (email, password) VALUES ($1, $2)", user.Email, user.Password)
+func (u *UserRepo) Save(ctx context.Context, user domain.User) (err error) {
+	// An empty role falls back to the column default.
+	query := "INSERT INTO users (email, password, role) VALUES ($1, $2, COALESCE(NULLIF($3, ''), 'user'))"
+	ctx, span := startSpan(ctx, "users.save", query)
+	defer func() { err = endSpan(ctx, span, err) }()
+
+	_, err = u.db.ExecContext(ctx, query, user.Email, user.Password, user.Role)
 	return err
 }
 
-func (u *UserRepo) GetAll() ([]domain.User, error) {
-	var users []domain.User
-	err := u.db.Select(&users, `
-		SELECT id,email,password,created_at FROM users
-	`)
+func (u *UserRepo) GetAll(ctx context.Context) (users []domain.User, err error) {
+	query := `
+		SELECT id,email,password,role,created_at FROM users ORDER BY id
+	`
+	ctx, span := startSpan(ctx, "users.get_all", query)
+	defer func() { err = endSpan(ctx, span, err) }()
+
+	err = u.db.SelectContext(ctx, &users, query)
 
 	return users, err
 }
+
+func (u *UserRepo) GetByID(ctx context.Context, id int) (user domain.User, err error) {
+	query := "SELECT id, email, password, role, created_at FROM users WHERE id = $1"
+	ctx, span := startSpan(ctx, "users.get_by_id", query)
+	defer func() { err = endSpan(ctx, span, err) }()
+
+	if err := u.db.GetContext(ctx, &user, query, id); err != nil {
+		return domain.User{}, fmt.Errorf("get user by id: %w", err)
+	}
+	return user, nil
+}
+
+func (u *UserRepo) GetByEmail(ctx context.Context, email string) (user domain.User, err error) {
+	query := "SELECT id, email, password, role, created_at FROM users WHERE email = $1"
+	ctx, span := startSpan(ctx, "users.get_by_email", query)
+	defer func() { err = endSpan(ctx, span, err) }()
+
+	if err := u.db.GetContext(ctx, &user, query, email); err != nil {
+		return domain.User{}, fmt.Errorf("get user by email: %w", err)
+	}
+	return user, nil
+}
+
+func (u *UserRepo) UpdatePassword(ctx context.Context, id int, hash string) (err error) {
+	query := "UPDATE users SET password = $1 WHERE id = $2"
+	ctx, span := startSpan(ctx, "users.update_password", query)
+	defer func() { err = endSpan(ctx, span, err) }()
+
+	res, err := u.db.ExecContext(ctx, query, hash, id)
+	if err != nil {
+		return fmt.Errorf("update password: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("update password: %w", err)
+	} else if n == 0 {
+		return fmt.Errorf("update password: %w", sql.ErrNoRows)
+	}
+	return nil
+}
@@ -0,0 +1,201 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"eve/domain"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// WebhookRepo is a Postgres implementation of usecase.WebhookRepository.
+type WebhookRepo struct {
+	db *sqlx.DB
+}
+
+func NewWebhookRepo(db *sqlx.DB) *WebhookRepo {
+	return &WebhookRepo{db: db}
+}
+
+const (
+	webhookColumns  = "id, url, events, reviewable_type, secret, created_at"
+	deliveryColumns = "id, subscription_id, event_id, event_type, payload, status, attempts, next_attempt_at, last_status_code, last_error, created_at, updated_at"
+)
+
+// subscriptionRow scans the events array of a subscription.
+type subscriptionRow struct {
+	domain.WebhookSubscription
+	Events pq.StringArray `db:"events"`
+}
+
+func (row subscriptionRow) subscription() domain.WebhookSubscription {
+	sub := row.WebhookSubscription
+	sub.Events = []string(row.Events)
+	return sub
+}
+
+func (w *WebhookRepo) CreateSubscription(ctx context.Context, sub domain.WebhookSubscription) (_ domain.WebhookSubscription, err error) {
+	query := `
+		INSERT INTO webhook_subscriptions (url, events, reviewable_type, secret)
+		VALUES ($1, $2, $3, $4)
+		RETURNING ` + webhookColumns
+	ctx, span := startSpan(ctx, "webhook_subscriptions.create", query)
+	defer func() { err = endSpan(ctx, span, err) }()
+
+	events := pq.StringArray(sub.Events)
+	if events == nil {
+		events = pq.StringArray{} // a nil array is NULL
+	}
+	var row subscriptionRow
+	if err := w.db.GetContext(ctx, &row, query, sub.URL, events, sub.ReviewableType, sub.Secret); err != nil {
+		return domain.WebhookSubscription{}, fmt.Errorf("create webhook subscription: %w", err)
+	}
+	return row.subscription(), nil
+}
+
+func (w *WebhookRepo) GetSubscription(ctx context.Context, id int) (_ domain.WebhookSubscription, err error) {
+	query := "SELECT " + webhookColumns + " FROM webhook_subscriptions WHERE id = $1"
+	ctx, span := startSpan(ctx, "webhook_subscriptions.get", query)
+	defer func() { err = endSpan(ctx, span, err) }()
+
+	var row subscriptionRow
+	if err := w.db.GetContext(ctx, &row, query, id); err != nil {
+		return domain.WebhookSubscription{}, fmt.Errorf("get webhook subscription: %w", err)
+	}
+	return row.subscription(), nil
+}
+
+func (w *WebhookRepo) ListSubscriptions(ctx context.Context) (subs []domain.WebhookSubscription, err error) {
+	query := "SELECT " + webhookColumns + " FROM webhook_subscriptions ORDER BY id"
+	ctx, span := startSpan(ctx, "webhook_subscriptions.list", query)
+	defer func() { err = endSpan(ctx, span, err) }()
+
+	var rows []subscriptionRow
+	if err := w.db.SelectContext(ctx, &rows, query); err != nil {
+		return nil, fmt.Errorf("list webhook subscriptions: %w", err)
+	}
+	for _, row := range rows {
+		subs = append(subs, row.subscription())
+	}
+	return subs, nil
+}
+
+func (w *WebhookRepo) DeleteSubscription(ctx context.Context, id int) (err error) {
+	query := "DELETE FROM webhook_subscriptions WHERE id = $1"
+	ctx, span := startSpan(ctx, "webhook_subscriptions.delete", query)
+	defer func() { err = endSpan(ctx, span, err) }()
+
+	res, err := w.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("delete webhook subscription: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("delete webhook subscription: %w", err)
+	} else if n == 0 {
+		return fmt.Errorf("delete webhook subscription: %w", sql.ErrNoRows)
+	}
+	return nil
+}
+
+func (w *WebhookRepo) EnqueueDeliveries(ctx context.Context, event domain.Event, subscriptionIDs []int) (err error) {
+	query := `
+		INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, payload)
+		SELECT unnest($1::int[]), $2, $3, $4::jsonb
+		ON CONFLICT (subscription_id, event_id) DO NOTHING
+	`
+	ctx, span := startSpan(ctx, "webhook_deliveries.enqueue", query)
+	defer func() { err = endSpan(ctx, span, err) }()
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("marshal event %d: %w", event.ID, err)
+	}
+	// JSON is passed as text: lib/pq would send []byte as bytea.
+	if _, err := w.db.ExecContext(ctx, query, pq.Array(subscriptionIDs), event.ID, event.Type, string(data)); err != nil {
+		return fmt.Errorf("enqueue webhook deliveries: %w", err)
+	}
+	return nil
+}
+
+// ClaimDueDeliveries skips the rows another worker is claiming, so that
+// concurrent claims never block each other or return the same delivery.
+func (w *WebhookRepo) ClaimDueDeliveries(ctx context.Context, now, until time.Time, limit int) (deliveries []domain.WebhookDelivery, err error) {
+	query := `
+		WITH due AS (
+			SELECT id, next_attempt_at AS due_at
+			FROM webhook_deliveries
+			WHERE status = 'pending' AND next_attempt_at <= $1
+			ORDER BY next_attempt_at, id
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		), claimed AS (
+			UPDATE webhook_deliveries d SET next_attempt_at = $2
+			FROM due
+			WHERE d.id = due.id
+			RETURNING d.*, due.due_at
+		)
+		SELECT ` + deliveryColumns + `
+		FROM claimed
+		ORDER BY due_at, id
+	`
+	ctx, span := startSpan(ctx, "webhook_deliveries.claim_due", query)
+	defer func() { err = endSpan(ctx, span, err) }()
+
+	if err := w.db.SelectContext(ctx, &deliveries, query, now.UTC(), until.UTC(), limit); err != nil {
+		return nil, fmt.Errorf("claim due webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+func (w *WebhookRepo) ListDeliveries(ctx context.Context, subscriptionID int, status string, limit int) (deliveries []domain.WebhookDelivery, err error) {
+	query := `
+		SELECT ` + deliveryColumns + `
+		FROM webhook_deliveries
+		WHERE subscription_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY id DESC
+		LIMIT $3
+	`
+	ctx, span := startSpan(ctx, "webhook_deliveries.list", query)
+	defer func() { err = endSpan(ctx, span, err) }()
+
+	if err := w.db.SelectContext(ctx, &deliveries, query, subscriptionID, status, limit); err != nil {
+		return nil, fmt.Errorf("list webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+func (w *WebhookRepo) GetDelivery(ctx context.Context, id int) (d domain.WebhookDelivery, err error) {
+	query := "SELECT " + deliveryColumns + " FROM webhook_deliveries WHERE id = $1"
+	ctx, span := startSpan(ctx, "webhook_deliveries.get", query)
+	defer func() { err = endSpan(ctx, span, err) }()
+
+	if err := w.db.GetContext(ctx, &d, query, id); err != nil {
+		return domain.WebhookDelivery{}, fmt.Errorf("get webhook delivery: %w", err)
+	}
+	return d, nil
+}
+
+func (w *WebhookRepo) UpdateDelivery(ctx context.Context, d domain.WebhookDelivery, nextAttemptAt time.Time) (err error) {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = $2, attempts = $3, last_status_code = $4, last_error = $5, next_attempt_at = $6, updated_at = now()
+		WHERE id = $1
+	`
+	ctx, span := startSpan(ctx, "webhook_deliveries.update", query)
+	defer func() { err = endSpan(ctx, span, err) }()
+
+	res, err := w.db.ExecContext(ctx, query, d.ID, d.Status, d.Attempts, d.LastStatusCode, d.LastError, nextAttemptAt.UTC())
+	if err != nil {
+		return fmt.Errorf("update webhook delivery: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("update webhook delivery: %w", err)
+	} else if n == 0 {
+		return fmt.Errorf("update webhook delivery: %w", sql.ErrNoRows)
+	}
+	return nil
+}
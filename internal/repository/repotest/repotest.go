@@ -0,0 +1,1950 @@
+// Package repotest is a conformance suite for the storage ports of package
+// usecase (UserRepository, ReviewRepository, OwnerRepository, ...). Every storage adapter runs it from
+// its own tests so that behaviour (ordering, cascades, error semantics) cannot
+// drift between adapters.
+package repotest
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"eve/domain"
+	"eve/internal/usecase"
+)
+
+// Repos is the set of repositories an adapter provides, all backed by the same
+// store so that reviews can reference users saved through Users.
+type Repos struct {
+	Users         usecase.UserRepository
+	Reviews       usecase.ReviewRepository
+	Owners        usecase.OwnerRepository
+	Criteria      usecase.CriteriaRepository
+	Types         usecase.ReviewableTypeRepository
+	Outbox        usecase.OutboxRepository // reads the events written through Reviews
+	Webhooks      usecase.WebhookRepository
+	Idempotency   usecase.IdempotencyStore
+	LoginAttempts usecase.LoginAttemptRepository
+	APIKeys       usecase.APIKeyRepository
+}
+
+// Factory returns empty repositories. It is called once per subtest; use
+// t.Cleanup to release resources.
+type Factory func(t *testing.T) Repos
+
+// Run runs the whole suite against the repositories produced by newRepos.
+// Search checks run when the review repository also implements
+// usecase.ReviewSearcher.
+func Run(t *testing.T, newRepos Factory) {
+	t.Run("UserRepository", func(t *testing.T) { RunUserRepository(t, newRepos) })
+	t.Run("ReviewRepository", func(t *testing.T) { RunReviewRepository(t, newRepos) })
+	t.Run("ReviewSearcher", func(t *testing.T) { RunReviewSearcher(t, newRepos) })
+	t.Run("OwnerRepository", func(t *testing.T) { RunOwnerRepository(t, newRepos) })
+	t.Run("CriteriaRepository", func(t *testing.T) { RunCriteriaRepository(t, newRepos) })
+	t.Run("ReviewableTypeRepository", func(t *testing.T) { RunReviewableTypeRepository(t, newRepos) })
+	t.Run("OutboxRepository", func(t *testing.T) { RunOutboxRepository(t, newRepos) })
+	t.Run("WebhookRepository", func(t *testing.T) { RunWebhookRepository(t, newRepos) })
+	t.Run("IdempotencyStore", func(t *testing.T) { RunIdempotencyStore(t, newRepos) })
+	t.Run("LoginAttemptRepository", func(t *testing.T) { RunLoginAttemptRepository(t, newRepos) })
+	t.Run("APIKeyRepository", func(t *testing.T) { RunAPIKeyRepository(t, newRepos) })
+}
+
+// RunUserRepository checks the usecase.UserRepository contract.
+func RunUserRepository(t *testing.T, newRepos Factory) {
+	t.Run("SaveAndGetAll", func(t *testing.T) {
+		ctx := context.Background()
+		users := newRepos(t).Users
+
+		if all, err := users.GetAll(ctx); err != nil || len(all) != 0 {
+			t.Fatalf("GetAll() on empty store = %v, %v; want no users", all, err)
+		}
+
+		for _, email := range []string{"a@example.com", "b@example.com"} {
+			if err := users.Save(ctx, domain.User{Email: email, Password: "hash-" + email}); err != nil {
+				t.Fatalf("Save(%s) error = %v", email, err)
+			}
+		}
+
+		all, err := users.GetAll(ctx)
+		if err != nil {
+			t.Fatalf("GetAll() error = %v", err)
+		}
+		if len(all) != 2 {
+			t.Fatalf("GetAll() returned %d users, want 2", len(all))
+		}
+		for i, email := range []string{"a@example.com", "b@example.com"} {
+			u := all[i]
+			if u.Email != email || u.Password != "hash-"+email {
+				t.Errorf("users[%d] = %+v, want email %s", i, u, email)
+			}
+			if u.ID == 0 || u.CreatedAt == "" {
+				t.Errorf("users[%d] = %+v, want generated id and created_at", i, u)
+			}
+		}
+		if all[0].ID >= all[1].ID {
+			t.Errorf("users not ordered by id: %d, %d", all[0].ID, all[1].ID)
+		}
+	})
+
+	t.Run("DuplicateEmail", func(t *testing.T) {
+		ctx := context.Background()
+		users := newRepos(t).Users
+
+		if err := users.Save(ctx, domain.User{Email: "dup@example.com", Password: "x"}); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+		if err := users.Save(ctx, domain.User{Email: "dup@example.com", Password: "y"}); err == nil {
+			t.Fatal("Save() with duplicate email succeeded, want error")
+		}
+	})
+
+	t.Run("GetByID", func(t *testing.T) {
+		ctx := context.Background()
+		users := newRepos(t).Users
+		id := createUser(t, users, "a@example.com")
+
+		got, err := users.GetByID(ctx, id)
+		if err != nil {
+			t.Fatalf("GetByID() error = %v", err)
+		}
+		if got.ID != id || got.Email != "a@example.com" || got.CreatedAt == "" {
+			t.Errorf("GetByID() = %+v, want saved user %d", got, id)
+		}
+		if got.Role != domain.RoleUser {
+			t.Errorf("GetByID().Role = %q, want default %q", got.Role, domain.RoleUser)
+		}
+
+		if _, err := users.GetByID(ctx, id+1000); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("GetByID(missing) error = %v, want sql.ErrNoRows", err)
+		}
+	})
+
+	t.Run("GetByEmail", func(t *testing.T) {
+		ctx := context.Background()
+		users := newRepos(t).Users
+		id := createUser(t, users, "a@example.com")
+		createUser(t, users, "b@example.com")
+
+		got, err := users.GetByEmail(ctx, "a@example.com")
+		if err != nil {
+			t.Fatalf("GetByEmail() error = %v", err)
+		}
+		if got.ID != id || got.Email != "a@example.com" || got.Password == "" || got.Role != domain.RoleUser {
+			t.Errorf("GetByEmail() = %+v, want saved user %d", got, id)
+		}
+		if _, err := users.GetByEmail(ctx, "missing@example.com"); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("GetByEmail(missing) error = %v, want sql.ErrNoRows", err)
+		}
+	})
+
+	t.Run("UpdatePassword", func(t *testing.T) {
+		ctx := context.Background()
+		users := newRepos(t).Users
+		id := createUser(t, users, "a@example.com")
+		other := createUser(t, users, "b@example.com")
+
+		if err := users.UpdatePassword(ctx, id, "new-hash"); err != nil {
+			t.Fatalf("UpdatePassword() error = %v", err)
+		}
+		if got, _ := users.GetByID(ctx, id); got.Password != "new-hash" {
+			t.Errorf("password = %q, want new-hash", got.Password)
+		}
+		if got, _ := users.GetByID(ctx, other); got.Password == "new-hash" {
+			t.Errorf("password of another user = %q, want it unchanged", got.Password)
+		}
+		if err := users.UpdatePassword(ctx, 999, "x"); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("UpdatePassword(missing) error = %v, want sql.ErrNoRows", err)
+		}
+	})
+
+	t.Run("CancelledContext", func(t *testing.T) {
+		users := newRepos(t).Users
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		if err := users.Save(ctx, domain.User{Email: "c@example.com", Password: "x"}); err == nil {
+			t.Error("Save() with cancelled context succeeded, want error")
+		}
+		if _, err := users.GetAll(ctx); err == nil {
+			t.Error("GetAll() with cancelled context succeeded, want error")
+		}
+	})
+}
+
+// RunReviewRepository checks the usecase.ReviewRepository contract.
+func RunReviewRepository(t *testing.T, newRepos Factory) {
+	t.Run("CreateAndGetByID", func(t *testing.T) {
+		ctx := context.Background()
+		s := newRepos(t)
+		users, reviews := s.Users, s.Reviews
+		author := createUser(t, users, "author@example.com")
+
+		want := domain.Review{
+			ReviewableType: "product",
+			ReviewableID:   42,
+			UserID:         author,
+			Rating:         4,
+			Title:          "Solid",
+			Body:           "Does the job",
+		}
+		id, err := reviews.Create(ctx, want)
+		if err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		if id == 0 {
+			t.Fatal("Create() returned id 0")
+		}
+
+		got, err := reviews.GetByID(ctx, id)
+		if err != nil {
+			t.Fatalf("GetByID() error = %v", err)
+		}
+		want.ID = id
+		want.Status = domain.ReviewStatusPublished // the default for an empty Status
+		want.CreatedAt, want.UpdatedAt = got.CreatedAt, got.UpdatedAt
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("GetByID() = %+v, want %+v", got, want)
+		}
+		if got.CreatedAt == "" || got.UpdatedAt == "" {
+			t.Errorf("GetByID() = %+v, want timestamps", got)
+		}
+	})
+
+	t.Run("GetByIDMissing", func(t *testing.T) {
+		reviews := newRepos(t).Reviews
+		if _, err := reviews.GetByID(context.Background(), 999999); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("GetByID(missing) error = %v, want sql.ErrNoRows", err)
+		}
+	})
+
+	t.Run("CreateRejectsInvalidRating", func(t *testing.T) {
+		s := newRepos(t)
+		users, reviews := s.Users, s.Reviews
+		author := createUser(t, users, "author@example.com")
+		for _, rating := range []int{0, 6} {
+			_, err := reviews.Create(context.Background(), domain.Review{
+				ReviewableType: "product", ReviewableID: 1, UserID: author, Rating: rating,
+			})
+			if err == nil {
+				t.Errorf("Create() with rating %d succeeded, want error", rating)
+			}
+		}
+	})
+
+	t.Run("ListByReviewableNewestFirst", func(t *testing.T) {
+		ctx := context.Background()
+		s := newRepos(t)
+		users, reviews := s.Users, s.Reviews
+		author := createUser(t, users, "author@example.com")
+
+		var ids []int
+		for i := 0; i < 3; i++ {
+			ids = append(ids, createReview(t, reviews, author, "product", 1))
+		}
+		createReview(t, reviews, author, "product", 2)
+		createReview(t, reviews, author, "vendor", 1)
+
+		got, err := reviews.ListByReviewable(ctx, "product", 1, false)
+		if err != nil {
+			t.Fatalf("ListByReviewable() error = %v", err)
+		}
+		want := []int{ids[2], ids[1], ids[0]}
+		if gotIDs := reviewIDs(got); fmt.Sprint(gotIDs) != fmt.Sprint(want) {
+			t.Errorf("ListByReviewable() ids = %v, want %v", gotIDs, want)
+		}
+
+		empty, err := reviews.ListByReviewable(ctx, "product", 99, false)
+		if err != nil || len(empty) != 0 {
+			t.Errorf("ListByReviewable(unknown) = %v, %v; want no reviews", empty, err)
+		}
+	})
+
+	t.Run("ListCommentsOldestFirst", func(t *testing.T) {
+		ctx := context.Background()
+		s := newRepos(t)
+		users, reviews := s.Users, s.Reviews
+		author := createUser(t, users, "author@example.com")
+		commenter := createUser(t, users, "commenter@example.com")
+		reviewID := createReview(t, reviews, author, "product", 1)
+		otherID := createReview(t, reviews, author, "product", 1)
+
+		var want []int
+		for _, body := range []string{"first", "second", "third"} {
+			id, err := reviews.AddComment(ctx, domain.ReviewComment{ReviewID: reviewID, UserID: commenter, Body: body})
+			if err != nil {
+				t.Fatalf("AddComment() error = %v", err)
+			}
+			want = append(want, id)
+		}
+		if _, err := reviews.AddComment(ctx, domain.ReviewComment{ReviewID: otherID, UserID: commenter, Body: "other"}); err != nil {
+			t.Fatalf("AddComment() error = %v", err)
+		}
+
+		got, err := reviews.ListComments(ctx, reviewID)
+		if err != nil {
+			t.Fatalf("ListComments() error = %v", err)
+		}
+		var gotIDs []int
+		for _, c := range got {
+			gotIDs = append(gotIDs, c.ID)
+			if c.ReviewID != reviewID || c.UserID != commenter || c.CreatedAt == "" {
+				t.Errorf("comment = %+v", c)
+			}
+		}
+		if fmt.Sprint(gotIDs) != fmt.Sprint(want) {
+			t.Errorf("ListComments() ids = %v, want %v", gotIDs, want)
+		}
+	})
+
+	t.Run("Replies", func(t *testing.T) {
+		ctx := context.Background()
+		s := newRepos(t)
+		users, reviews := s.Users, s.Reviews
+		author := createUser(t, users, "author@example.com")
+		reviewID := createReview(t, reviews, author, "product", 1)
+
+		rootID, err := reviews.AddComment(ctx, domain.ReviewComment{ReviewID: reviewID, UserID: author, Body: "root"})
+		if err != nil {
+			t.Fatalf("AddComment(root) error = %v", err)
+		}
+		replyID, err := reviews.AddComment(ctx, domain.ReviewComment{
+			ReviewID: reviewID, ParentID: &rootID, Depth: 1, UserID: author, Body: "reply",
+		})
+		if err != nil {
+			t.Fatalf("AddComment(reply) error = %v", err)
+		}
+
+		root, err := reviews.GetComment(ctx, rootID)
+		if err != nil {
+			t.Fatalf("GetComment(root) error = %v", err)
+		}
+		if root.ParentID != nil || root.Depth != 0 || root.Body != "root" || root.ReviewID != reviewID {
+			t.Errorf("GetComment(root) = %+v", root)
+		}
+		reply, err := reviews.GetComment(ctx, replyID)
+		if err != nil {
+			t.Fatalf("GetComment(reply) error = %v", err)
+		}
+		if reply.ParentID == nil || *reply.ParentID != rootID || reply.Depth != 1 {
+			t.Errorf("GetComment(reply) = %+v, want parent %d at depth 1", reply, rootID)
+		}
+
+		listed, err := reviews.ListComments(ctx, reviewID)
+		if err != nil || len(listed) != 2 || listed[1].ParentID == nil || *listed[1].ParentID != rootID {
+			t.Errorf("ListComments() = %+v, %v; want reply to carry parent %d", listed, err, rootID)
+		}
+
+		if _, err := reviews.GetComment(ctx, 999999); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("GetComment(missing) error = %v, want sql.ErrNoRows", err)
+		}
+		missing := 999999
+		if _, err := reviews.AddComment(ctx, domain.ReviewComment{ReviewID: reviewID, ParentID: &missing, Depth: 1, UserID: author, Body: "x"}); err == nil {
+			t.Error("AddComment() with missing parent succeeded, want error")
+		}
+	})
+
+	t.Run("AddCommentToMissingReview", func(t *testing.T) {
+		s := newRepos(t)
+		users, reviews := s.Users, s.Reviews
+		commenter := createUser(t, users, "commenter@example.com")
+		_, err := reviews.AddComment(context.Background(), domain.ReviewComment{ReviewID: 999999, UserID: commenter, Body: "x"})
+		if err == nil {
+			t.Error("AddComment() on missing review succeeded, want error")
+		}
+	})
+
+	t.Run("ListPhotosBySortOrder", func(t *testing.T) {
+		ctx := context.Background()
+		s := newRepos(t)
+		users, reviews := s.Users, s.Reviews
+		author := createUser(t, users, "author@example.com")
+		reviewID := createReview(t, reviews, author, "product", 1)
+
+		photos := []domain.ReviewPhoto{
+			{FilePath: "c.jpg", SortOrder: 2},
+			{FilePath: "a.jpg", SortOrder: 0, Metadata: json.RawMessage(`{"width": 640}`)},
+			{FilePath: "b.jpg", SortOrder: 1},
+		}
+		if err := reviews.AddPhotos(ctx, reviewID, photos); err != nil {
+			t.Fatalf("AddPhotos() error = %v", err)
+		}
+
+		got, err := reviews.ListPhotos(ctx, reviewID)
+		if err != nil {
+			t.Fatalf("ListPhotos() error = %v", err)
+		}
+		var paths []string
+		for _, p := range got {
+			paths = append(paths, p.FilePath)
+			if p.ReviewID != reviewID || p.ID == 0 {
+				t.Errorf("photo = %+v", p)
+			}
+		}
+		if fmt.Sprint(paths) != "[a.jpg b.jpg c.jpg]" {
+			t.Errorf("ListPhotos() paths = %v, want [a.jpg b.jpg c.jpg]", paths)
+		}
+		var meta map[string]int
+		if err := json.Unmarshal(got[0].Metadata, &meta); err != nil || meta["width"] != 640 {
+			t.Errorf("photo metadata = %s, want width 640", got[0].Metadata)
+		}
+		if len(got[1].Metadata) != 0 {
+			t.Errorf("photo without metadata = %s, want empty", got[1].Metadata)
+		}
+	})
+
+	t.Run("Responses", func(t *testing.T) {
+		ctx := context.Background()
+		s := newRepos(t)
+		users, reviews := s.Users, s.Reviews
+		author := createUser(t, users, "author@example.com")
+		owner := createUser(t, users, "owner@example.com")
+		coOwner := createUser(t, users, "co-owner@example.com")
+		reviewID := createReview(t, reviews, author, "product", 1)
+		otherID := createReview(t, reviews, author, "product", 1)
+
+		if _, err := reviews.GetResponse(ctx, reviewID); !errors.Is(err, sql.ErrNoRows) {
+			t.Fatalf("GetResponse() before save error = %v, want sql.ErrNoRows", err)
+		}
+
+		first, err := reviews.SaveResponse(ctx, domain.ReviewResponse{ReviewID: reviewID, UserID: owner, Body: "thanks"})
+		if err != nil {
+			t.Fatalf("SaveResponse() error = %v", err)
+		}
+		if first.ID == 0 || first.ReviewID != reviewID || first.UserID != owner || first.Body != "thanks" || first.CreatedAt == "" {
+			t.Fatalf("SaveResponse() = %+v, want stored response", first)
+		}
+
+		edited, err := reviews.SaveResponse(ctx, domain.ReviewResponse{ReviewID: reviewID, UserID: coOwner, Body: "thanks, fixed"})
+		if err != nil {
+			t.Fatalf("SaveResponse(edit) error = %v", err)
+		}
+		if edited.ID != first.ID || edited.CreatedAt != first.CreatedAt {
+			t.Errorf("edit = %+v, want same response as %+v", edited, first)
+		}
+		if edited.UserID != coOwner || edited.Body != "thanks, fixed" {
+			t.Errorf("edit = %+v, want new author and body", edited)
+		}
+
+		got, err := reviews.GetResponse(ctx, reviewID)
+		if err != nil || got != edited {
+			t.Errorf("GetResponse() = %+v, %v; want %+v", got, err, edited)
+		}
+
+		versions, err := reviews.ListResponseVersions(ctx, reviewID)
+		if err != nil {
+			t.Fatalf("ListResponseVersions() error = %v", err)
+		}
+		if len(versions) != 2 {
+			t.Fatalf("ListResponseVersions() returned %d versions, want 2", len(versions))
+		}
+		for i, want := range []struct {
+			user int
+			body string
+		}{{owner, "thanks"}, {coOwner, "thanks, fixed"}} {
+			v := versions[i]
+			if v.ResponseID != first.ID || v.UserID != want.user || v.Body != want.body || v.CreatedAt == "" {
+				t.Errorf("versions[%d] = %+v, want user %d body %q", i, v, want.user, want.body)
+			}
+		}
+
+		byReview, err := reviews.ListResponses(ctx, []int{reviewID, otherID})
+		if err != nil {
+			t.Fatalf("ListResponses() error = %v", err)
+		}
+		if len(byReview) != 1 || byReview[reviewID] != edited {
+			t.Errorf("ListResponses() = %+v, want only review %d", byReview, reviewID)
+		}
+		if empty, err := reviews.ListResponses(ctx, nil); err != nil || len(empty) != 0 {
+			t.Errorf("ListResponses(nil) = %v, %v; want none", empty, err)
+		}
+	})
+
+	t.Run("UpdatesRecordRevisions", func(t *testing.T) {
+		ctx := context.Background()
+		s := newRepos(t)
+		users, reviews := s.Users, s.Reviews
+		author := createUser(t, users, "author@example.com")
+		moderator := createUser(t, users, "moderator@example.com")
+		reviewID := createReview(t, reviews, author, "product", 1)
+		commentID, err := reviews.AddComment(ctx, domain.ReviewComment{ReviewID: reviewID, UserID: author, Body: "first"})
+		if err != nil {
+			t.Fatalf("AddComment() error = %v", err)
+		}
+
+		before, _ := reviews.GetByID(ctx, reviewID)
+		edit := before
+		edit.Rating, edit.Title = 1, "Changed my mind"
+		updated, err := reviews.UpdateReview(ctx, edit, author, "")
+		if err != nil {
+			t.Fatalf("UpdateReview() error = %v", err)
+		}
+		if updated.Rating != 1 || updated.Title != "Changed my mind" || updated.Body != before.Body || updated.UserID != author {
+			t.Errorf("UpdateReview() = %+v, want rating and title changed", updated)
+		}
+		if got, _ := reviews.GetByID(ctx, reviewID); !reflect.DeepEqual(got, updated) {
+			t.Errorf("GetByID() after update = %+v, want %+v", got, updated)
+		}
+
+		// Saving identical values is not a change.
+		if _, err := reviews.UpdateReview(ctx, updated, author, ""); err != nil {
+			t.Fatalf("UpdateReview(unchanged) error = %v", err)
+		}
+
+		c, _ := reviews.GetComment(ctx, commentID)
+		c.Body = "edited by moderator"
+		if got, err := reviews.UpdateComment(ctx, c, moderator); err != nil || got.Body != c.Body {
+			t.Fatalf("UpdateComment() = %+v, %v", got, err)
+		}
+		if got, _ := reviews.GetComment(ctx, commentID); got.Body != c.Body {
+			t.Errorf("GetComment() after update = %+v", got)
+		}
+
+		revisions, err := reviews.ListRevisions(ctx, reviewID)
+		if err != nil {
+			t.Fatalf("ListRevisions() error = %v", err)
+		}
+		if len(revisions) != 2 {
+			t.Fatalf("ListRevisions() returned %d revisions, want 2: %+v", len(revisions), revisions)
+		}
+		for i, want := range []struct {
+			entityType string
+			entityID   int
+			actor      int
+			old, new   map[string]any
+		}{
+			{domain.RevisionEntityReview, reviewID, author,
+				map[string]any{"rating": 3.0, "title": ""}, map[string]any{"rating": 1.0, "title": "Changed my mind"}},
+			{domain.RevisionEntityComment, commentID, moderator,
+				map[string]any{"body": "first"}, map[string]any{"body": "edited by moderator"}},
+		} {
+			r := revisions[i]
+			if r.ReviewID != reviewID || r.EntityType != want.entityType || r.EntityID != want.entityID || r.ActorID != want.actor || r.CreatedAt == "" {
+				t.Errorf("revisions[%d] = %+v, want %s %d by %d", i, r, want.entityType, want.entityID, want.actor)
+			}
+			var oldValues, newValues map[string]any
+			if err := json.Unmarshal(r.OldValues, &oldValues); err != nil || fmt.Sprint(oldValues) != fmt.Sprint(want.old) {
+				t.Errorf("revisions[%d].OldValues = %s, want %v", i, r.OldValues, want.old)
+			}
+			if err := json.Unmarshal(r.NewValues, &newValues); err != nil || fmt.Sprint(newValues) != fmt.Sprint(want.new) {
+				t.Errorf("revisions[%d].NewValues = %s, want %v", i, r.NewValues, want.new)
+			}
+		}
+
+		// The status is stored along with an edit, never on its own.
+		pending := updated
+		pending.Status = domain.ReviewStatusPending
+		if got, err := reviews.UpdateReview(ctx, pending, author, ""); err != nil || got.Status != domain.ReviewStatusPublished {
+			t.Errorf("UpdateReview(status only) = %+v, %v; want the review still published", got, err)
+		}
+		pending.Body = "Needs another look"
+		if got, err := reviews.UpdateReview(ctx, pending, author, ""); err != nil || got.Status != domain.ReviewStatusPending {
+			t.Errorf("UpdateReview(edit to pending) = %+v, %v; want the review pending", got, err)
+		}
+
+		if _, err := reviews.UpdateReview(ctx, domain.Review{ID: reviewID + 1000, Rating: 2}, author, ""); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("UpdateReview(missing) error = %v, want sql.ErrNoRows", err)
+		}
+		if _, err := reviews.UpdateComment(ctx, domain.ReviewComment{ID: commentID + 1000, Body: "x"}, author); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("UpdateComment(missing) error = %v, want sql.ErrNoRows", err)
+		}
+	})
+
+	t.Run("ConditionalWrites", func(t *testing.T) {
+		ctx := context.Background()
+		s := newRepos(t)
+		users, reviews := s.Users, s.Reviews
+		author := createUser(t, users, "author@example.com")
+		reviewID := createReview(t, reviews, author, "product", 1)
+		const stale = "2000-01-01T00:00:00Z"
+
+		current, _ := reviews.GetByID(ctx, reviewID)
+		edit := current
+		edit.Title = "Edited"
+		if _, err := reviews.UpdateReview(ctx, edit, author, stale); !errors.Is(err, domain.ErrReviewChanged) {
+			t.Errorf("UpdateReview(stale) error = %v, want domain.ErrReviewChanged", err)
+		}
+		if got, _ := reviews.GetByID(ctx, reviewID); got.Title != current.Title {
+			t.Errorf("stale UpdateReview() changed the title to %q", got.Title)
+		}
+		updated, err := reviews.UpdateReview(ctx, edit, author, current.UpdatedAt)
+		if err != nil || updated.Title != "Edited" {
+			t.Fatalf("UpdateReview(current) = %+v, %v", updated, err)
+		}
+
+		if err := reviews.DeleteReview(ctx, reviewID, stale); !errors.Is(err, domain.ErrReviewChanged) {
+			t.Errorf("DeleteReview(stale) error = %v, want domain.ErrReviewChanged", err)
+		}
+		if _, err := reviews.GetByID(ctx, reviewID); err != nil {
+			t.Errorf("GetByID() after stale DeleteReview error = %v, want the review kept", err)
+		}
+		if err := reviews.DeleteReview(ctx, reviewID, updated.UpdatedAt); err != nil {
+			t.Fatalf("DeleteReview(current) error = %v", err)
+		}
+		if _, err := reviews.GetByID(ctx, reviewID); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("GetByID() after DeleteReview error = %v, want sql.ErrNoRows", err)
+		}
+	})
+
+	// seedDeletable creates two reviews with a photo, a comment and a response
+	// each, and soft-deletes the first one.
+	seedDeletable := func(t *testing.T, reviews usecase.ReviewRepository, author int) (reviewID, keptID, commentID int) {
+		t.Helper()
+		ctx := context.Background()
+		reviewID = createReview(t, reviews, author, "product", 1)
+		keptID = createReview(t, reviews, author, "product", 1)
+
+		for _, id := range []int{reviewID, keptID} {
+			if err := reviews.AddPhotos(ctx, id, []domain.ReviewPhoto{{FilePath: fmt.Sprintf("p%d.jpg", id)}}); err != nil {
+				t.Fatalf("AddPhotos() error = %v", err)
+			}
+			c, err := reviews.AddComment(ctx, domain.ReviewComment{ReviewID: id, UserID: author, Body: "c"})
+			if err != nil {
+				t.Fatalf("AddComment() error = %v", err)
+			}
+			if id == reviewID {
+				commentID = c
+			}
+			if _, err := reviews.SaveResponse(ctx, domain.ReviewResponse{ReviewID: id, UserID: author, Body: "r"}); err != nil {
+				t.Fatalf("SaveResponse() error = %v", err)
+			}
+		}
+
+		if err := reviews.DeleteReview(ctx, reviewID, ""); err != nil {
+			t.Fatalf("DeleteReview() error = %v", err)
+		}
+		return reviewID, keptID, commentID
+	}
+
+	t.Run("DeleteReviewHidesEverything", func(t *testing.T) {
+		ctx := context.Background()
+		s := newRepos(t)
+		users, reviews := s.Users, s.Reviews
+		author := createUser(t, users, "author@example.com")
+		reviewID, keptID, commentID := seedDeletable(t, reviews, author)
+
+		if _, err := reviews.GetByID(ctx, reviewID); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("GetByID(deleted) error = %v, want sql.ErrNoRows", err)
+		}
+		if got, err := reviews.ListByReviewable(ctx, "product", 1, false); err != nil || !slices.Equal(reviewIDs(got), []int{keptID}) {
+			t.Errorf("ListByReviewable() = %v, %v; want only %d", reviewIDs(got), err, keptID)
+		}
+		if _, err := reviews.GetComment(ctx, commentID); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("GetComment(on deleted) error = %v, want sql.ErrNoRows", err)
+		}
+		if r, err := reviews.ListResponses(ctx, []int{reviewID, keptID}); err != nil || len(r) != 1 {
+			t.Errorf("ListResponses() = %v, %v; want only the kept review", r, err)
+		}
+		if _, err := reviews.UpdateReview(ctx, domain.Review{ID: reviewID, Rating: 1}, author, ""); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("UpdateReview(deleted) error = %v, want sql.ErrNoRows", err)
+		}
+		if c, err := reviews.ListComments(ctx, reviewID); err != nil || len(c) != 0 {
+			t.Errorf("ListComments(deleted) = %v, %v; want none", c, err)
+		}
+		if p, err := reviews.ListPhotos(ctx, reviewID); err != nil || len(p) != 0 {
+			t.Errorf("ListPhotos(deleted) = %v, %v; want none", p, err)
+		}
+		if _, err := reviews.GetResponse(ctx, reviewID); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("GetResponse(deleted) error = %v, want sql.ErrNoRows", err)
+		}
+		if v, err := reviews.ListResponseVersions(ctx, reviewID); err != nil || len(v) != 0 {
+			t.Errorf("ListResponseVersions(deleted) = %v, %v; want none", v, err)
+		}
+
+		if _, err := reviews.GetByID(ctx, keptID); err != nil {
+			t.Errorf("GetByID(kept) error = %v", err)
+		}
+		if c, _ := reviews.ListComments(ctx, keptID); len(c) != 1 {
+			t.Errorf("ListComments(kept) = %v, want 1 comment", c)
+		}
+		if p, _ := reviews.ListPhotos(ctx, keptID); len(p) != 1 {
+			t.Errorf("ListPhotos(kept) = %v, want 1 photo", p)
+		}
+
+		if err := reviews.DeleteReview(ctx, reviewID, ""); err != nil {
+			t.Errorf("DeleteReview() twice error = %v, want nil", err)
+		}
+	})
+
+	t.Run("RestoreReview", func(t *testing.T) {
+		ctx := context.Background()
+		s := newRepos(t)
+		users, reviews := s.Users, s.Reviews
+		author := createUser(t, users, "author@example.com")
+		reviewID, keptID, commentID := seedDeletable(t, reviews, author)
+
+		if err := reviews.RestoreReview(ctx, reviewID); err != nil {
+			t.Fatalf("RestoreReview() error = %v", err)
+		}
+		if _, err := reviews.GetByID(ctx, reviewID); err != nil {
+			t.Errorf("GetByID(restored) error = %v", err)
+		}
+		if _, err := reviews.GetComment(ctx, commentID); err != nil {
+			t.Errorf("GetComment(restored) error = %v", err)
+		}
+		if p, _ := reviews.ListPhotos(ctx, reviewID); len(p) != 1 {
+			t.Errorf("ListPhotos(restored) = %v, want 1 photo", p)
+		}
+		if _, err := reviews.GetResponse(ctx, reviewID); err != nil {
+			t.Errorf("GetResponse(restored) error = %v", err)
+		}
+
+		if err := reviews.RestoreReview(ctx, reviewID); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("RestoreReview(live) error = %v, want sql.ErrNoRows", err)
+		}
+		if err := reviews.RestoreReview(ctx, keptID+1000); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("RestoreReview(missing) error = %v, want sql.ErrNoRows", err)
+		}
+	})
+
+	t.Run("PurgeDeletedReviews", func(t *testing.T) {
+		ctx := context.Background()
+		s := newRepos(t)
+		users, reviews := s.Users, s.Reviews
+		author := createUser(t, users, "author@example.com")
+		reviewID, keptID, _ := seedDeletable(t, reviews, author)
+		otherID := createReview(t, reviews, author, "product", 2)
+		// Photo paths come from clients, so other reviews may point at the
+		// same files; a file is only reported once nothing uses it anymore.
+		shared := []domain.ReviewPhoto{{FilePath: fmt.Sprintf("p%d.jpg", reviewID)}, {FilePath: fmt.Sprintf("p%d.jpg", keptID)}}
+		if err := reviews.AddPhotos(ctx, otherID, shared); err != nil {
+			t.Fatalf("AddPhotos() error = %v", err)
+		}
+		if err := reviews.DeleteReview(ctx, otherID, ""); err != nil {
+			t.Fatalf("DeleteReview() error = %v", err)
+		}
+
+		if got, err := reviews.PurgeDeletedReviews(ctx, time.Now().Add(-time.Hour), 10); err != nil || len(got.ReviewIDs) != 0 {
+			t.Fatalf("PurgeDeletedReviews(before deletion) = %+v, %v; want nothing", got, err)
+		}
+
+		cutoff := time.Now().Add(time.Minute)
+		got, err := reviews.PurgeDeletedReviews(ctx, cutoff, 1)
+		if err != nil {
+			t.Fatalf("PurgeDeletedReviews() error = %v", err)
+		}
+		if !slices.Equal(got.ReviewIDs, []int{reviewID}) || len(got.FilePaths) != 0 {
+			t.Errorf("PurgeDeletedReviews(limit 1) = %+v, want review %d without its photo, which review %d shares", got, reviewID, otherID)
+		}
+		got, err = reviews.PurgeDeletedReviews(ctx, cutoff, 10)
+		if err != nil || !slices.Equal(got.ReviewIDs, []int{otherID}) || !slices.Equal(got.FilePaths, []string{fmt.Sprintf("p%d.jpg", reviewID)}) {
+			t.Errorf("PurgeDeletedReviews() second batch = %+v, %v; want review %d and the photo nothing else uses", got, err, otherID)
+		}
+
+		if err := reviews.RestoreReview(ctx, reviewID); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("RestoreReview(purged) error = %v, want sql.ErrNoRows", err)
+		}
+		if _, err := reviews.GetByID(ctx, keptID); err != nil {
+			t.Errorf("GetByID(kept) error = %v", err)
+		}
+		if p, _ := reviews.ListPhotos(ctx, keptID); len(p) != 1 {
+			t.Errorf("ListPhotos(kept) = %v, want 1 photo", p)
+		}
+	})
+
+	t.Run("PendingReviewsAwaitApproval", func(t *testing.T) {
+		ctx := context.Background()
+		s := newRepos(t)
+		users, reviews := s.Users, s.Reviews
+		author := createUser(t, users, "author@example.com")
+		publishedID := createReview(t, reviews, author, "product", 1)
+		pendingID, err := reviews.Create(ctx, domain.Review{
+			ReviewableType: "product", ReviewableID: 1, UserID: author, Rating: 1, Title: "pending words",
+			Status: domain.ReviewStatusPending,
+		})
+		if err != nil {
+			t.Fatalf("Create(pending) error = %v", err)
+		}
+		laterID, err := reviews.Create(ctx, domain.Review{
+			ReviewableType: "vendor", ReviewableID: 2, UserID: author, Rating: 2,
+			Status: domain.ReviewStatusPending,
+		})
+		if err != nil {
+			t.Fatalf("Create(pending) error = %v", err)
+		}
+
+		// Pending reviews are loaded by ID, so that use-cases can show them to
+		// their author and moderators, and queued for moderation, but left
+		// out of listings, summaries and search.
+		if got, err := reviews.GetByID(ctx, pendingID); err != nil || got.Status != domain.ReviewStatusPending {
+			t.Fatalf("GetByID(pending) = %+v, %v; want pending review", got, err)
+		}
+		if got, err := reviews.ListPending(ctx, 10, 0); err != nil || !slices.Equal(reviewIDs(got), []int{pendingID, laterID}) {
+			t.Errorf("ListPending() = %v, %v; want %d then %d", reviewIDs(got), err, pendingID, laterID)
+		}
+		if got, _ := reviews.ListPending(ctx, 1, 1); !slices.Equal(reviewIDs(got), []int{laterID}) {
+			t.Errorf("ListPending(limit 1, offset 1) = %v, want %d", reviewIDs(got), laterID)
+		}
+		if got, _ := reviews.ListByReviewable(ctx, "product", 1, false); !slices.Equal(reviewIDs(got), []int{publishedID}) {
+			t.Errorf("ListByReviewable() = %v, want only %d", reviewIDs(got), publishedID)
+		}
+		if got, _ := reviews.Summarize(ctx, "product", 1); got.Count != 1 {
+			t.Errorf("Summarize().Count = %d, want 1", got.Count)
+		}
+		if searcher, ok := reviews.(usecase.ReviewSearcher); ok {
+			if got, err := searcher.SearchReviews(ctx, domain.ReviewSearchQuery{Query: "pending", Limit: 10}); err != nil || got.Total != 0 {
+				t.Errorf("SearchReviews(pending) = %+v, %v; want no hits", got, err)
+			}
+		}
+
+		if err := reviews.ApproveReview(ctx, publishedID); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("ApproveReview(published) error = %v, want sql.ErrNoRows", err)
+		}
+		if err := reviews.ApproveReview(ctx, pendingID); err != nil {
+			t.Fatalf("ApproveReview() error = %v", err)
+		}
+		if got, _ := reviews.ListByReviewable(ctx, "product", 1, false); len(got) != 2 {
+			t.Errorf("ListByReviewable() after approval = %v, want both reviews", reviewIDs(got))
+		}
+		if err := reviews.DeleteReview(ctx, laterID, ""); err != nil {
+			t.Fatalf("DeleteReview() error = %v", err)
+		}
+		if got, err := reviews.ListPending(ctx, 10, 0); err != nil || len(got) != 0 {
+			t.Errorf("ListPending() after approval and deletion = %v, %v; want none", reviewIDs(got), err)
+		}
+		if err := reviews.ApproveReview(ctx, pendingID); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("ApproveReview() twice error = %v, want sql.ErrNoRows", err)
+		}
+	})
+
+	t.Run("VerifiedOnlyListing", func(t *testing.T) {
+		ctx := context.Background()
+		s := newRepos(t)
+		users, reviews := s.Users, s.Reviews
+		author := createUser(t, users, "author@example.com")
+		unverifiedID := createReview(t, reviews, author, "product", 1)
+		verifiedID, err := reviews.Create(ctx, domain.Review{
+			ReviewableType: "product", ReviewableID: 1, UserID: author, Rating: 5, Verified: true,
+		})
+		if err != nil {
+			t.Fatalf("Create(verified) error = %v", err)
+		}
+
+		if got, err := reviews.GetByID(ctx, verifiedID); err != nil || !got.Verified {
+			t.Errorf("GetByID(verified) = %+v, %v; want Verified", got, err)
+		}
+		if got, err := reviews.GetByID(ctx, unverifiedID); err != nil || got.Verified {
+			t.Errorf("GetByID(unverified) = %+v, %v; want not Verified", got, err)
+		}
+		if got, err := reviews.ListByReviewable(ctx, "product", 1, true); err != nil || !slices.Equal(reviewIDs(got), []int{verifiedID}) {
+			t.Errorf("ListByReviewable(verified only) = %v, %v; want only %d", reviewIDs(got), err, verifiedID)
+		}
+		if got, _ := reviews.ListByReviewable(ctx, "product", 1, false); len(got) != 2 {
+			t.Errorf("ListByReviewable() = %v, want both reviews", reviewIDs(got))
+		}
+	})
+
+	t.Run("SubRatingsAndSummary", func(t *testing.T) {
+		ctx := context.Background()
+		s := newRepos(t)
+		users, reviews := s.Users, s.Reviews
+		author := createUser(t, users, "author@example.com")
+
+		if got, err := reviews.Summarize(ctx, "hotel", 7); err != nil || got.Count != 0 || got.AverageRating != 0 || len(got.Criteria) != 0 {
+			t.Fatalf("Summarize(no reviews) = %+v, %v; want zero summary", got, err)
+		}
+
+		var ids []int
+		for _, r := range []domain.Review{
+			{Rating: 5, SubRatings: map[string]int{"cleanliness": 4, "location": 5}},
+			{Rating: 2, SubRatings: map[string]int{"cleanliness": 1}},
+			{Rating: 4},
+			{Rating: 1, SubRatings: map[string]int{"cleanliness": 1, "location": 1}}, // deleted below
+		} {
+			r.ReviewableType, r.ReviewableID, r.UserID = "hotel", 7, author
+			id, err := reviews.Create(ctx, r)
+			if err != nil {
+				t.Fatalf("Create() error = %v", err)
+			}
+			ids = append(ids, id)
+		}
+		createReview(t, reviews, author, "hotel", 8)
+		if err := reviews.DeleteReview(ctx, ids[3], ""); err != nil {
+			t.Fatalf("DeleteReview() error = %v", err)
+		}
+		if _, err := reviews.Create(ctx, domain.Review{
+			ReviewableType: "hotel", ReviewableID: 7, UserID: author, Rating: 3,
+			SubRatings: map[string]int{"cleanliness": 9},
+		}); err == nil {
+			t.Error("Create() with sub-rating 9 succeeded, want error")
+		}
+
+		got, err := reviews.ListSubRatings(ctx, ids)
+		if err != nil {
+			t.Fatalf("ListSubRatings() error = %v", err)
+		}
+		want := map[int]map[string]int{
+			ids[0]: {"cleanliness": 4, "location": 5},
+			ids[1]: {"cleanliness": 1},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("ListSubRatings() = %v, want %v", got, want)
+		}
+
+		summary, err := reviews.Summarize(ctx, "hotel", 7)
+		if err != nil {
+			t.Fatalf("Summarize() error = %v", err)
+		}
+		wantSummary := domain.ReviewSummary{
+			ReviewableType: "hotel",
+			ReviewableID:   7,
+			Count:          3,
+			AverageRating:  11.0 / 3,
+			Criteria: []domain.CriterionAverage{
+				{Key: "cleanliness", Count: 2, Average: 2.5},
+				{Key: "location", Count: 1, Average: 5},
+			},
+		}
+		if summary.Count != wantSummary.Count || math.Abs(summary.AverageRating-wantSummary.AverageRating) > 1e-9 ||
+			!slices.Equal(summary.Criteria, wantSummary.Criteria) {
+			t.Errorf("Summarize() = %+v, want %+v", summary, wantSummary)
+		}
+	})
+
+	t.Run("CancelledContext", func(t *testing.T) {
+		reviews := newRepos(t).Reviews
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		if _, err := reviews.ListByReviewable(ctx, "product", 1, false); err == nil {
+			t.Error("ListByReviewable() with cancelled context succeeded, want error")
+		}
+		if _, err := reviews.GetByID(ctx, 1); err == nil {
+			t.Error("GetByID() with cancelled context succeeded, want error")
+		}
+	})
+}
+
+// RunOwnerRepository checks the usecase.OwnerRepository contract.
+func RunOwnerRepository(t *testing.T, newRepos Factory) {
+	t.Run("AddListRemove", func(t *testing.T) {
+		ctx := context.Background()
+		s := newRepos(t)
+		owners := s.Owners
+		first := createUser(t, s.Users, "first@example.com")
+		second := createUser(t, s.Users, "second@example.com")
+
+		for _, uid := range []int{second, first, second} {
+			if err := owners.AddOwner(ctx, domain.ReviewableOwner{ReviewableType: "product", ReviewableID: 42, UserID: uid}); err != nil {
+				t.Fatalf("AddOwner(%d) error = %v", uid, err)
+			}
+		}
+		if err := owners.AddOwner(ctx, domain.ReviewableOwner{ReviewableType: "vendor", ReviewableID: 42, UserID: first}); err != nil {
+			t.Fatalf("AddOwner(vendor) error = %v", err)
+		}
+
+		got, err := owners.ListOwners(ctx, "product", 42)
+		if err != nil {
+			t.Fatalf("ListOwners() error = %v", err)
+		}
+		if len(got) != 2 || got[0].UserID != first || got[1].UserID != second {
+			t.Fatalf("ListOwners() = %+v, want users %d and %d", got, first, second)
+		}
+		if got[0].ReviewableType != "product" || got[0].ReviewableID != 42 || got[0].CreatedAt == "" {
+			t.Errorf("owner = %+v, want product:42 with created_at", got[0])
+		}
+
+		for _, tc := range []struct {
+			typ  string
+			id   int
+			user int
+			want bool
+		}{
+			{"product", 42, first, true},
+			{"product", 42, second, true},
+			{"vendor", 42, second, false},
+			{"product", 43, first, false},
+		} {
+			if ok, err := owners.IsOwner(ctx, tc.typ, tc.id, tc.user); err != nil || ok != tc.want {
+				t.Errorf("IsOwner(%s:%d, %d) = %v, %v; want %v", tc.typ, tc.id, tc.user, ok, err, tc.want)
+			}
+		}
+
+		if err := owners.RemoveOwner(ctx, "product", 42, first); err != nil {
+			t.Fatalf("RemoveOwner() error = %v", err)
+		}
+		if ok, _ := owners.IsOwner(ctx, "product", 42, first); ok {
+			t.Error("IsOwner() after RemoveOwner = true")
+		}
+		if err := owners.RemoveOwner(ctx, "product", 42, first); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("RemoveOwner() twice error = %v, want sql.ErrNoRows", err)
+		}
+		if got, _ := owners.ListOwners(ctx, "product", 42); len(got) != 1 || got[0].UserID != second {
+			t.Errorf("ListOwners() after remove = %+v, want only %d", got, second)
+		}
+	})
+
+	t.Run("CancelledContext", func(t *testing.T) {
+		owners := newRepos(t).Owners
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		if _, err := owners.IsOwner(ctx, "product", 1, 1); err == nil {
+			t.Error("IsOwner() with cancelled context succeeded, want error")
+		}
+	})
+}
+
+// RunCriteriaRepository checks the usecase.CriteriaRepository contract.
+func RunCriteriaRepository(t *testing.T, newRepos Factory) {
+	t.Run("SetAndList", func(t *testing.T) {
+		ctx := context.Background()
+		criteria := newRepos(t).Criteria
+
+		if got, err := criteria.ListCriteria(ctx, "hotel"); err != nil || len(got) != 0 {
+			t.Fatalf("ListCriteria() on empty store = %v, %v; want none", got, err)
+		}
+
+		hotel := []domain.RatingCriterion{
+			{ReviewableType: "hotel", Key: "value", Label: "Value", SortOrder: 2},
+			{ReviewableType: "hotel", Key: "cleanliness", Label: "Cleanliness", Required: true, SortOrder: 0},
+			{ReviewableType: "hotel", Key: "location", Label: "Location", SortOrder: 1},
+		}
+		if err := criteria.SetCriteria(ctx, "hotel", hotel); err != nil {
+			t.Fatalf("SetCriteria(hotel) error = %v", err)
+		}
+		if err := criteria.SetCriteria(ctx, "vendor", []domain.RatingCriterion{{ReviewableType: "vendor", Key: "shipping", Label: "Shipping"}}); err != nil {
+			t.Fatalf("SetCriteria(vendor) error = %v", err)
+		}
+
+		got, err := criteria.ListCriteria(ctx, "hotel")
+		if err != nil {
+			t.Fatalf("ListCriteria() error = %v", err)
+		}
+		want := []domain.RatingCriterion{hotel[1], hotel[2], hotel[0]}
+		if !slices.Equal(got, want) {
+			t.Errorf("ListCriteria() = %+v, want %+v", got, want)
+		}
+
+		// Setting replaces the whole set.
+		if err := criteria.SetCriteria(ctx, "hotel", hotel[2:]); err != nil {
+			t.Fatalf("SetCriteria(replace) error = %v", err)
+		}
+		if got, _ := criteria.ListCriteria(ctx, "hotel"); !slices.Equal(got, hotel[2:]) {
+			t.Errorf("ListCriteria() after replace = %+v, want %+v", got, hotel[2:])
+		}
+		if err := criteria.SetCriteria(ctx, "hotel", nil); err != nil {
+			t.Fatalf("SetCriteria(empty) error = %v", err)
+		}
+		if got, _ := criteria.ListCriteria(ctx, "hotel"); len(got) != 0 {
+			t.Errorf("ListCriteria() after clearing = %+v, want none", got)
+		}
+		if got, _ := criteria.ListCriteria(ctx, "vendor"); len(got) != 1 {
+			t.Errorf("ListCriteria(vendor) = %+v, want untouched", got)
+		}
+	})
+
+	t.Run("CancelledContext", func(t *testing.T) {
+		criteria := newRepos(t).Criteria
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		if _, err := criteria.ListCriteria(ctx, "hotel"); err == nil {
+			t.Error("ListCriteria() with cancelled context succeeded, want error")
+		}
+	})
+}
+
+// RunReviewableTypeRepository checks the usecase.ReviewableTypeRepository contract.
+func RunReviewableTypeRepository(t *testing.T, newRepos Factory) {
+	t.Run("SaveGetList", func(t *testing.T) {
+		ctx := context.Background()
+		types := newRepos(t).Types
+
+		book := domain.ReviewableType{Name: "book", MaxBodyLength: 500, TitleRequired: true, ModerationMode: domain.ModerationPre}
+		saved, err := types.SaveType(ctx, book)
+		if err != nil {
+			t.Fatalf("SaveType() error = %v", err)
+		}
+		if saved.CreatedAt == "" || saved.UpdatedAt == "" {
+			t.Errorf("SaveType() = %+v, want timestamps", saved)
+		}
+		book.CreatedAt, book.UpdatedAt = saved.CreatedAt, saved.UpdatedAt
+		if saved != book {
+			t.Errorf("SaveType() = %+v, want %+v", saved, book)
+		}
+
+		// Saving again replaces the settings.
+		book.PhotosAllowed, book.MaxBodyLength, book.ModerationMode = true, 0, domain.ModerationPost
+		if _, err := types.SaveType(ctx, book); err != nil {
+			t.Fatalf("SaveType(update) error = %v", err)
+		}
+		got, err := types.GetType(ctx, "book")
+		if err != nil {
+			t.Fatalf("GetType() error = %v", err)
+		}
+		if !got.PhotosAllowed || got.MaxBodyLength != 0 || !got.TitleRequired || got.ModerationMode != domain.ModerationPost || got.CreatedAt != saved.CreatedAt {
+			t.Errorf("GetType() after update = %+v", got)
+		}
+		if _, err := types.GetType(ctx, "boko"); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("GetType(missing) error = %v, want sql.ErrNoRows", err)
+		}
+
+		if _, err := types.SaveType(ctx, domain.ReviewableType{Name: "album", ModerationMode: domain.ModerationPost}); err != nil {
+			t.Fatalf("SaveType(album) error = %v", err)
+		}
+		all, err := types.ListTypes(ctx)
+		if err != nil {
+			t.Fatalf("ListTypes() error = %v", err)
+		}
+		var names []string
+		for _, rt := range all {
+			names = append(names, rt.Name)
+		}
+		// Adapters with migrations also list the seeded default types.
+		if i, j := slices.Index(names, "album"), slices.Index(names, "book"); i < 0 || j < 0 || i > j || !slices.IsSorted(names) {
+			t.Errorf("ListTypes() names = %v, want sorted and containing album and book", names)
+		}
+	})
+
+	t.Run("CancelledContext", func(t *testing.T) {
+		types := newRepos(t).Types
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		if _, err := types.GetType(ctx, "product"); err == nil {
+			t.Error("GetType() with cancelled context succeeded, want error")
+		}
+	})
+}
+
+// RunReviewSearcher checks the usecase.ReviewSearcher contract. Only plain
+// words are searched so that adapters with and without stemming agree.
+func RunReviewSearcher(t *testing.T, newRepos Factory) {
+	setup := func(t *testing.T) (usecase.ReviewSearcher, map[string]int) {
+		s := newRepos(t)
+		users, reviews := s.Users, s.Reviews
+		searcher, ok := reviews.(usecase.ReviewSearcher)
+		if !ok {
+			t.Skip("review repository does not implement usecase.ReviewSearcher")
+		}
+		author := createUser(t, users, "author@example.com")
+
+		ids := make(map[string]int)
+		for _, r := range []struct {
+			key, reviewableType string
+			reviewableID        int
+			title, body         string
+		}{
+			{"both", "product", 1, "Battery life", "Great battery, lasts two days"},
+			{"body", "product", 1, "Screen", "The battery is fine, the screen is dim"},
+			{"none", "product", 1, "Camera", "Sharp photos"},
+			{"vendor", "vendor", 1, "Shipping", "Replacement battery arrived late"},
+		} {
+			id, err := reviews.Create(context.Background(), domain.Review{
+				ReviewableType: r.reviewableType,
+				ReviewableID:   r.reviewableID,
+				UserID:         author,
+				Rating:         4,
+				Title:          r.title,
+				Body:           r.body,
+			})
+			if err != nil {
+				t.Fatalf("create review: %v", err)
+			}
+			ids[r.key] = id
+		}
+		return searcher, ids
+	}
+
+	search := func(t *testing.T, s usecase.ReviewSearcher, q domain.ReviewSearchQuery) domain.ReviewSearchResult {
+		t.Helper()
+		if q.Limit == 0 {
+			q.Limit = 10
+		}
+		res, err := s.SearchReviews(context.Background(), q)
+		if err != nil {
+			t.Fatalf("SearchReviews(%+v) error = %v", q, err)
+		}
+		return res
+	}
+
+	t.Run("RanksTitleMatchesFirst", func(t *testing.T) {
+		s, ids := setup(t)
+		res := search(t, s, domain.ReviewSearchQuery{Query: "battery"})
+		if res.Total != 3 || len(res.Hits) != 3 {
+			t.Fatalf("SearchReviews() total = %d, hits = %d; want 3", res.Total, len(res.Hits))
+		}
+		if res.Hits[0].Review.ID != ids["both"] {
+			t.Errorf("best hit = %d, want %d", res.Hits[0].Review.ID, ids["both"])
+		}
+		for i := 1; i < len(res.Hits); i++ {
+			if res.Hits[i].Rank > res.Hits[i-1].Rank {
+				t.Errorf("hits not ordered by rank: %v > %v", res.Hits[i].Rank, res.Hits[i-1].Rank)
+			}
+		}
+		if h := res.Hits[0].TitleHighlight; h != "<mark>Battery</mark> life" {
+			t.Errorf("TitleHighlight = %q", h)
+		}
+		if h := res.Hits[0].BodyHighlight; !strings.Contains(h, "<mark>battery</mark>") {
+			t.Errorf("BodyHighlight = %q, want marked term", h)
+		}
+	})
+
+	t.Run("EscapesHighlights", func(t *testing.T) {
+		s, _ := setup(t)
+		reviews := s.(usecase.ReviewRepository)
+		if _, err := reviews.Create(context.Background(), domain.Review{
+			ReviewableType: "product",
+			ReviewableID:   2,
+			UserID:         1,
+			Rating:         1,
+			Title:          "Charger <b>broke</b>",
+			Body:           `The charger <script>alert("x")</script> melted`,
+		}); err != nil {
+			t.Fatalf("create review: %v", err)
+		}
+		res := search(t, s, domain.ReviewSearchQuery{Query: "charger", ReviewableType: "product", ReviewableID: 2})
+		if len(res.Hits) != 1 {
+			t.Fatalf("SearchReviews() hits = %d, want 1", len(res.Hits))
+		}
+		hit := res.Hits[0]
+		if hit.TitleHighlight != "<mark>Charger</mark> &lt;b&gt;broke&lt;/b&gt;" {
+			t.Errorf("TitleHighlight = %q, want the markup escaped", hit.TitleHighlight)
+		}
+		if strings.Contains(hit.BodyHighlight, "<script>") || !strings.Contains(hit.BodyHighlight, "&lt;script&gt;") || !strings.Contains(hit.BodyHighlight, "<mark>charger</mark>") {
+			t.Errorf("BodyHighlight = %q, want the script escaped and the term marked", hit.BodyHighlight)
+		}
+		if hit.Review.Body != `The charger <script>alert("x")</script> melted` {
+			t.Errorf("Review.Body = %q, want it unchanged", hit.Review.Body)
+		}
+	})
+
+	t.Run("SkipsDeletedReviews", func(t *testing.T) {
+		s, ids := setup(t)
+		if err := s.(usecase.ReviewRepository).DeleteReview(context.Background(), ids["both"], ""); err != nil {
+			t.Fatalf("DeleteReview() error = %v", err)
+		}
+		res := search(t, s, domain.ReviewSearchQuery{Query: "battery"})
+		if res.Total != 2 || len(res.Hits) != 2 {
+			t.Fatalf("SearchReviews() total = %d, hits = %d; want 2", res.Total, len(res.Hits))
+		}
+		for _, h := range res.Hits {
+			if h.Review.ID == ids["both"] {
+				t.Errorf("deleted review %d returned by search", h.Review.ID)
+			}
+		}
+	})
+
+	t.Run("RequiresEveryTerm", func(t *testing.T) {
+		s, ids := setup(t)
+		res := search(t, s, domain.ReviewSearchQuery{Query: "battery screen"})
+		if res.Total != 1 || len(res.Hits) != 1 || res.Hits[0].Review.ID != ids["body"] {
+			t.Errorf("SearchReviews() = %+v, want only review %d", res, ids["body"])
+		}
+	})
+
+	t.Run("FiltersByReviewable", func(t *testing.T) {
+		s, ids := setup(t)
+		res := search(t, s, domain.ReviewSearchQuery{Query: "battery", ReviewableType: "vendor", ReviewableID: 1})
+		if res.Total != 1 || len(res.Hits) != 1 || res.Hits[0].Review.ID != ids["vendor"] {
+			t.Errorf("SearchReviews() = %+v, want only review %d", res, ids["vendor"])
+		}
+	})
+
+	t.Run("Paginates", func(t *testing.T) {
+		s, _ := setup(t)
+		all := search(t, s, domain.ReviewSearchQuery{Query: "battery"})
+		page := search(t, s, domain.ReviewSearchQuery{Query: "battery", Limit: 1, Offset: 1})
+		if page.Total != 3 || len(page.Hits) != 1 || page.Hits[0].Review.ID != all.Hits[1].Review.ID {
+			t.Errorf("page = %+v, want total 3 and hit %d", page, all.Hits[1].Review.ID)
+		}
+		past := search(t, s, domain.ReviewSearchQuery{Query: "battery", Offset: 10})
+		if len(past.Hits) != 0 {
+			t.Errorf("page past the end = %+v, want no hits", past)
+		}
+	})
+
+	t.Run("NoMatches", func(t *testing.T) {
+		s, _ := setup(t)
+		res := search(t, s, domain.ReviewSearchQuery{Query: "zebra"})
+		if res.Total != 0 || len(res.Hits) != 0 {
+			t.Errorf("SearchReviews() = %+v, want no hits", res)
+		}
+	})
+}
+
+// RunOutboxRepository checks the usecase.OutboxRepository contract together
+// with the events the review repository records.
+func RunOutboxRepository(t *testing.T, newRepos Factory) {
+	t.Run("RecordsEventsOfWrites", func(t *testing.T) {
+		ctx := context.Background()
+		s := newRepos(t)
+		users, reviews, outbox := s.Users, s.Reviews, s.Outbox
+		author := createUser(t, users, "author@example.com")
+
+		reviewID, err := reviews.Create(ctx, domain.Review{
+			ReviewableType: "product", ReviewableID: 1, UserID: author, Rating: 4, Title: "Solid",
+			SubRatings: map[string]int{"quality": 5},
+		})
+		if err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		if _, err := reviews.AddComment(ctx, domain.ReviewComment{ReviewID: reviewID, UserID: author, Body: "Agreed"}); err != nil {
+			t.Fatalf("AddComment() error = %v", err)
+		}
+		edit := domain.Review{ID: reviewID, Rating: 2, Title: "Solid"}
+		for range 2 { // the second edit changes nothing
+			if _, err := reviews.UpdateReview(ctx, edit, author, ""); err != nil {
+				t.Fatalf("UpdateReview() error = %v", err)
+			}
+		}
+		if err := reviews.RestoreReview(ctx, reviewID); !errors.Is(err, sql.ErrNoRows) {
+			t.Fatalf("RestoreReview(live) error = %v, want sql.ErrNoRows", err)
+		}
+		for range 2 { // deleting twice is a no-op
+			if err := reviews.DeleteReview(ctx, reviewID, ""); err != nil {
+				t.Fatalf("DeleteReview() error = %v", err)
+			}
+		}
+		if err := reviews.RestoreReview(ctx, reviewID); err != nil {
+			t.Fatalf("RestoreReview() error = %v", err)
+		}
+
+		events, err := outbox.ListUnpublished(ctx, time.Now(), 10)
+		if err != nil {
+			t.Fatalf("ListUnpublished() error = %v", err)
+		}
+		var types []string
+		for i, e := range events {
+			types = append(types, e.Type)
+			if e.ReviewID != reviewID || e.ReviewableType != "product" || e.CreatedAt == "" {
+				t.Errorf("events[%d] = %+v, want product review %d and created_at", i, e, reviewID)
+			}
+			if i > 0 && e.ID <= events[i-1].ID {
+				t.Errorf("event IDs %d, %d not increasing", events[i-1].ID, e.ID)
+			}
+		}
+		want := []string{
+			domain.EventReviewCreated, domain.EventCommentAdded, domain.EventReviewUpdated,
+			domain.EventReviewDeleted, domain.EventReviewUpdated,
+		}
+		if !slices.Equal(types, want) {
+			t.Fatalf("event types = %v, want %v", types, want)
+		}
+
+		var created domain.Review
+		if err := json.Unmarshal(events[0].Payload, &created); err != nil {
+			t.Fatalf("unmarshal created payload: %v", err)
+		}
+		if created.ID != reviewID || created.Title != "Solid" || created.SubRatings["quality"] != 5 || created.CreatedAt == "" {
+			t.Errorf("created payload = %+v", created)
+		}
+		var comment domain.ReviewComment
+		if err := json.Unmarshal(events[1].Payload, &comment); err != nil || comment.Body != "Agreed" || comment.ID == 0 {
+			t.Errorf("comment payload = %+v, %v", comment, err)
+		}
+		var updated domain.Review
+		if err := json.Unmarshal(events[2].Payload, &updated); err != nil || updated.Rating != 2 {
+			t.Errorf("updated payload = %+v, %v; want rating 2", updated, err)
+		}
+	})
+
+	t.Run("MarkPublished", func(t *testing.T) {
+		ctx := context.Background()
+		s := newRepos(t)
+		users, reviews, outbox := s.Users, s.Reviews, s.Outbox
+		author := createUser(t, users, "author@example.com")
+		for i := 1; i <= 3; i++ {
+			createReview(t, reviews, author, "product", i)
+		}
+
+		first, err := outbox.ListUnpublished(ctx, time.Now(), 2)
+		if err != nil || len(first) != 2 {
+			t.Fatalf("ListUnpublished(2) = %v, %v; want 2 events", first, err)
+		}
+		if err := outbox.RecordFailure(ctx, first[0].ID, "broker unavailable", time.Now(), false); err != nil {
+			t.Fatalf("RecordFailure() error = %v", err)
+		}
+		if err := outbox.MarkPublished(ctx, nil); err != nil {
+			t.Fatalf("MarkPublished(none) error = %v", err)
+		}
+		if err := outbox.MarkPublished(ctx, []int{first[0].ID, first[1].ID}); err != nil {
+			t.Fatalf("MarkPublished() error = %v", err)
+		}
+
+		rest, err := outbox.ListUnpublished(ctx, time.Now(), 10)
+		if err != nil || len(rest) != 1 || rest[0].ID <= first[1].ID {
+			t.Errorf("ListUnpublished() after MarkPublished = %v, %v; want only the third event", rest, err)
+		}
+	})
+
+	t.Run("RetriesAndDeadLetters", func(t *testing.T) {
+		ctx := context.Background()
+		s := newRepos(t)
+		users, reviews, outbox := s.Users, s.Reviews, s.Outbox
+		author := createUser(t, users, "author@example.com")
+		failing := createReview(t, reviews, author, "product", 1)
+		createReview(t, reviews, author, "product", 2)
+		if err := reviews.DeleteReview(ctx, failing, ""); err != nil {
+			t.Fatalf("DeleteReview() error = %v", err)
+		}
+		all, err := outbox.ListUnpublished(ctx, time.Now(), 10)
+		if err != nil || len(all) != 3 {
+			t.Fatalf("ListUnpublished() = %v, %v; want 3 events", all, err)
+		}
+		ids := func(events []domain.Event) []int {
+			var ids []int
+			for _, e := range events {
+				ids = append(ids, e.ID)
+			}
+			return ids
+		}
+
+		// While the failed event waits, the later event of its review waits too.
+		now := time.Now()
+		if err := outbox.RecordFailure(ctx, all[0].ID, "broker unavailable", now.Add(time.Hour), false); err != nil {
+			t.Fatalf("RecordFailure() error = %v", err)
+		}
+		if due, err := outbox.ListUnpublished(ctx, now, 10); err != nil || !slices.Equal(ids(due), []int{all[1].ID}) {
+			t.Errorf("ListUnpublished() during backoff = %v, %v; want only event %d", ids(due), err, all[1].ID)
+		}
+		due, err := outbox.ListUnpublished(ctx, now.Add(2*time.Hour), 10)
+		if err != nil || !slices.Equal(ids(due), ids(all)) {
+			t.Fatalf("ListUnpublished() after backoff = %v, %v; want %v", ids(due), err, ids(all))
+		}
+		if due[0].Attempts != 1 || due[1].Attempts != 0 {
+			t.Errorf("attempts = %d, %d; want 1, 0", due[0].Attempts, due[1].Attempts)
+		}
+
+		// A dead-lettered event is never listed again and no longer holds back its review.
+		if err := outbox.RecordFailure(ctx, all[0].ID, "broker unavailable", now.Add(time.Hour), true); err != nil {
+			t.Fatalf("RecordFailure(dead) error = %v", err)
+		}
+		if due, err := outbox.ListUnpublished(ctx, now.Add(2*time.Hour), 10); err != nil || !slices.Equal(ids(due), []int{all[1].ID, all[2].ID}) {
+			t.Errorf("ListUnpublished() after dead-lettering = %v, %v; want %v", ids(due), err, []int{all[1].ID, all[2].ID})
+		}
+	})
+}
+
+// RunWebhookRepository checks the usecase.WebhookRepository contract.
+func RunWebhookRepository(t *testing.T, newRepos Factory) {
+	t.Run("Subscriptions", func(t *testing.T) {
+		ctx := context.Background()
+		webhooks := newRepos(t).Webhooks
+
+		first, err := webhooks.CreateSubscription(ctx, domain.WebhookSubscription{
+			URL: "https://a.example.com/hook", Events: []string{domain.EventReviewCreated}, ReviewableType: "product", Secret: "s3cret-s3cret-s3cret",
+		})
+		if err != nil {
+			t.Fatalf("CreateSubscription() error = %v", err)
+		}
+		if first.ID == 0 || first.CreatedAt == "" || first.Secret != "s3cret-s3cret-s3cret" {
+			t.Errorf("CreateSubscription() = %+v, want ID, created_at and secret", first)
+		}
+		second, err := webhooks.CreateSubscription(ctx, domain.WebhookSubscription{URL: "https://b.example.com/hook", Secret: "x"})
+		if err != nil {
+			t.Fatalf("CreateSubscription(all events) error = %v", err)
+		}
+
+		got, err := webhooks.GetSubscription(ctx, first.ID)
+		if err != nil {
+			t.Fatalf("GetSubscription() error = %v", err)
+		}
+		if got.URL != first.URL || !slices.Equal(got.Events, []string{domain.EventReviewCreated}) || got.ReviewableType != "product" {
+			t.Errorf("GetSubscription() = %+v, want %+v", got, first)
+		}
+		if _, err := webhooks.GetSubscription(ctx, 999); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("GetSubscription(missing) error = %v, want sql.ErrNoRows", err)
+		}
+
+		all, err := webhooks.ListSubscriptions(ctx)
+		if err != nil || len(all) != 2 || all[0].ID != first.ID || all[1].ID != second.ID || len(all[1].Events) != 0 {
+			t.Fatalf("ListSubscriptions() = %+v, %v; want both by ID", all, err)
+		}
+
+		if err := webhooks.DeleteSubscription(ctx, first.ID); err != nil {
+			t.Fatalf("DeleteSubscription() error = %v", err)
+		}
+		if err := webhooks.DeleteSubscription(ctx, first.ID); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("DeleteSubscription(deleted) error = %v, want sql.ErrNoRows", err)
+		}
+		if all, _ := webhooks.ListSubscriptions(ctx); len(all) != 1 {
+			t.Errorf("ListSubscriptions() after delete = %+v, want one", all)
+		}
+	})
+
+	t.Run("Deliveries", func(t *testing.T) {
+		ctx := context.Background()
+		webhooks := newRepos(t).Webhooks
+		a, err := webhooks.CreateSubscription(ctx, domain.WebhookSubscription{URL: "https://a.example.com/hook", Secret: "x"})
+		if err != nil {
+			t.Fatalf("CreateSubscription() error = %v", err)
+		}
+		b, err := webhooks.CreateSubscription(ctx, domain.WebhookSubscription{URL: "https://b.example.com/hook", Secret: "y"})
+		if err != nil {
+			t.Fatalf("CreateSubscription() error = %v", err)
+		}
+
+		event := domain.Event{ID: 7, Type: domain.EventReviewCreated, ReviewID: 3, ReviewableType: "product", Payload: json.RawMessage(`{"id":3}`)}
+		for range 2 { // enqueueing an event twice is a no-op
+			if err := webhooks.EnqueueDeliveries(ctx, event, []int{a.ID, b.ID}); err != nil {
+				t.Fatalf("EnqueueDeliveries() error = %v", err)
+			}
+		}
+		if err := webhooks.EnqueueDeliveries(ctx, domain.Event{ID: 8, Type: domain.EventReviewDeleted}, []int{a.ID}); err != nil {
+			t.Fatalf("EnqueueDeliveries(second event) error = %v", err)
+		}
+
+		claim := func(at time.Time, limit int) []domain.WebhookDelivery {
+			t.Helper()
+			due, err := webhooks.ClaimDueDeliveries(ctx, at, at.Add(time.Minute), limit)
+			if err != nil {
+				t.Fatalf("ClaimDueDeliveries() error = %v", err)
+			}
+			return due
+		}
+		now := time.Now().Add(time.Second)
+
+		due := claim(now, 1)
+		if len(due) != 1 {
+			t.Fatalf("ClaimDueDeliveries(limit 1) = %+v; want one", due)
+		}
+		d := due[0]
+		if d.ID == 0 || d.EventID != 7 || d.EventType != domain.EventReviewCreated || d.Status != domain.DeliveryPending ||
+			d.Attempts != 0 || d.LastStatusCode != nil || d.NextAttemptAt == "" || d.CreatedAt == "" {
+			t.Errorf("due[0] = %+v, want a pending delivery of event 7", d)
+		}
+		var sent domain.Event
+		if err := json.Unmarshal(d.Payload, &sent); err != nil || sent.ID != 7 || string(sent.Payload) != `{"id":3}` {
+			t.Errorf("payload = %s (%v), want the encoded event", d.Payload, err)
+		}
+		if rest := claim(now, 10); len(rest) != 2 || rest[0].ID == d.ID || rest[1].ID == d.ID {
+			t.Errorf("ClaimDueDeliveries() = %+v, want the two unclaimed deliveries", rest)
+		}
+		if again := claim(now, 10); len(again) != 0 {
+			t.Errorf("ClaimDueDeliveries() during the lease = %+v, want none", again)
+		}
+
+		code := 503
+		d.Attempts, d.LastStatusCode, d.LastError = 1, &code, "unexpected status 503"
+		if err := webhooks.UpdateDelivery(ctx, d, now.Add(time.Hour)); err != nil {
+			t.Fatalf("UpdateDelivery() error = %v", err)
+		}
+		got, err := webhooks.GetDelivery(ctx, d.ID)
+		if err != nil || got.Attempts != 1 || got.LastStatusCode == nil || *got.LastStatusCode != 503 || got.LastError != d.LastError {
+			t.Errorf("GetDelivery() = %+v, %v; want the recorded failure", got, err)
+		}
+
+		// Expired leases make the other deliveries due again before the retry.
+		if due := claim(now.Add(2*time.Hour), 2); len(due) != 2 || due[0].ID == d.ID || due[1].ID == d.ID {
+			t.Errorf("ClaimDueDeliveries(later, limit 2) = %+v, want the longest due first", due)
+		}
+		if due := claim(now.Add(2*time.Hour), 10); len(due) != 1 || due[0].ID != d.ID {
+			t.Errorf("ClaimDueDeliveries(later) = %+v, want the retried one", due)
+		}
+
+		d.Status, d.Attempts = domain.DeliveryDead, 2
+		if err := webhooks.UpdateDelivery(ctx, d, now); err != nil {
+			t.Fatalf("UpdateDelivery(dead) error = %v", err)
+		}
+		if due := claim(now.Add(3*time.Hour), 10); len(due) != 2 {
+			t.Errorf("ClaimDueDeliveries() claims dead deliveries: %+v", due)
+		}
+		if err := webhooks.UpdateDelivery(ctx, domain.WebhookDelivery{ID: 999}, time.Now()); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("UpdateDelivery(missing) error = %v, want sql.ErrNoRows", err)
+		}
+		if _, err := webhooks.GetDelivery(ctx, 999); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("GetDelivery(missing) error = %v, want sql.ErrNoRows", err)
+		}
+
+		log, err := webhooks.ListDeliveries(ctx, a.ID, "", 10)
+		if err != nil || len(log) != 2 || log[0].EventID != 8 || log[1].EventID != 7 {
+			t.Fatalf("ListDeliveries() = %+v, %v; want both of a, newest first", log, err)
+		}
+		if dead, _ := webhooks.ListDeliveries(ctx, a.ID, domain.DeliveryDead, 10); len(dead) != 1 || dead[0].ID != d.ID {
+			t.Errorf("ListDeliveries(dead) = %+v, want the dead one", dead)
+		}
+		if log, _ := webhooks.ListDeliveries(ctx, a.ID, "", 1); len(log) != 1 || log[0].EventID != 8 {
+			t.Errorf("ListDeliveries(limit 1) = %+v, want the newest", log)
+		}
+
+		if err := webhooks.DeleteSubscription(ctx, a.ID); err != nil {
+			t.Fatalf("DeleteSubscription() error = %v", err)
+		}
+		if _, err := webhooks.GetDelivery(ctx, d.ID); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("GetDelivery() after subscription deleted error = %v, want sql.ErrNoRows", err)
+		}
+		if log, _ := webhooks.ListDeliveries(ctx, b.ID, "", 10); len(log) != 1 {
+			t.Errorf("ListDeliveries(b) = %+v, want its delivery kept", log)
+		}
+	})
+}
+
+// RunIdempotencyStore checks the usecase.IdempotencyStore contract.
+func RunIdempotencyStore(t *testing.T, newRepos Factory) {
+	t.Run("ReserveCompleteRelease", func(t *testing.T) {
+		ctx := context.Background()
+		store := newRepos(t).Idempotency
+
+		rec, reserved, err := store.Reserve(ctx, "user:1:a", "fp-a", time.Hour)
+		if err != nil || !reserved {
+			t.Fatalf("Reserve() = %+v, %v, %v; want reserved", rec, reserved, err)
+		}
+		if rec.Key != "user:1:a" || rec.Fingerprint != "fp-a" || rec.Completed() || rec.CreatedAt == "" {
+			t.Errorf("Reserve() = %+v, want a pending record", rec)
+		}
+
+		rec, reserved, err = store.Reserve(ctx, "user:1:a", "fp-other", time.Hour)
+		if err != nil || reserved || rec.Fingerprint != "fp-a" || rec.Completed() {
+			t.Errorf("Reserve(in progress) = %+v, %v, %v; want the pending record", rec, reserved, err)
+		}
+
+		body := []byte(`{"id":1}`)
+		if err := store.Complete(ctx, domain.IdempotencyRecord{Key: "user:1:a", Status: 201, ContentType: "application/json", Body: body}, time.Hour); err != nil {
+			t.Fatalf("Complete() error = %v", err)
+		}
+		body[0] = 'x' // the store keeps its own copy
+		rec, reserved, err = store.Reserve(ctx, "user:1:a", "fp-a", time.Hour)
+		if err != nil || reserved {
+			t.Fatalf("Reserve(completed) = %+v, %v, %v; want the stored response", rec, reserved, err)
+		}
+		if rec.Fingerprint != "fp-a" || rec.Status != 201 || rec.ContentType != "application/json" || string(rec.Body) != `{"id":1}` {
+			t.Errorf("Reserve(completed) = %+v, want the stored response", rec)
+		}
+		if err := store.Complete(ctx, domain.IdempotencyRecord{Key: "user:1:missing", Status: 201}, time.Hour); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("Complete(missing) error = %v, want sql.ErrNoRows", err)
+		}
+
+		if _, reserved, _ := store.Reserve(ctx, "user:2:a", "fp-a", time.Hour); !reserved {
+			t.Errorf("Reserve(other key) not reserved")
+		}
+
+		if err := store.Release(ctx, "user:1:a"); err != nil {
+			t.Fatalf("Release() error = %v", err)
+		}
+		if err := store.Release(ctx, "user:1:a"); err != nil {
+			t.Errorf("Release(released) error = %v", err)
+		}
+		if rec, reserved, err := store.Reserve(ctx, "user:1:a", "fp-b", time.Hour); err != nil || !reserved || rec.Fingerprint != "fp-b" {
+			t.Errorf("Reserve() after Release = %+v, %v, %v; want reserved again", rec, reserved, err)
+		}
+	})
+
+	t.Run("Expiry", func(t *testing.T) {
+		ctx := context.Background()
+		store := newRepos(t).Idempotency
+
+		if _, reserved, err := store.Reserve(ctx, "short", "fp", 10*time.Millisecond); err != nil || !reserved {
+			t.Fatalf("Reserve(short) = %v, %v; want reserved", reserved, err)
+		}
+		if _, reserved, err := store.Reserve(ctx, "long", "fp", time.Hour); err != nil || !reserved {
+			t.Fatalf("Reserve(long) = %v, %v; want reserved", reserved, err)
+		}
+		// A completed response is kept for its ttl, not the lease it was
+		// reserved with.
+		if _, reserved, err := store.Reserve(ctx, "completed", "fp", 10*time.Millisecond); err != nil || !reserved {
+			t.Fatalf("Reserve(completed) = %v, %v; want reserved", reserved, err)
+		}
+		if err := store.Complete(ctx, domain.IdempotencyRecord{Key: "completed", Status: 201}, time.Hour); err != nil {
+			t.Fatalf("Complete() error = %v", err)
+		}
+		time.Sleep(50 * time.Millisecond)
+
+		if n, err := store.DeleteExpired(ctx, time.Now()); err != nil || n > 1 {
+			t.Errorf("DeleteExpired() = %d, %v; want at most the expired record", n, err)
+		}
+		if _, reserved, err := store.Reserve(ctx, "long", "fp", time.Hour); err != nil || reserved {
+			t.Errorf("Reserve(long) = %v, %v; want it still held", reserved, err)
+		}
+		if rec, reserved, err := store.Reserve(ctx, "completed", "fp", time.Hour); err != nil || reserved || rec.Status != 201 {
+			t.Errorf("Reserve(completed) = %+v, %v, %v; want the stored response", rec, reserved, err)
+		}
+		if rec, reserved, err := store.Reserve(ctx, "short", "fp-new", time.Hour); err != nil || !reserved || rec.Fingerprint != "fp-new" {
+			t.Errorf("Reserve(expired) = %+v, %v, %v; want reserved again", rec, reserved, err)
+		}
+	})
+}
+
+// RunLoginAttemptRepository checks the usecase.LoginAttemptRepository contract.
+func RunLoginAttemptRepository(t *testing.T, newRepos Factory) {
+	t.Run("FailuresAndLockout", func(t *testing.T) {
+		ctx := context.Background()
+		attempts := newRepos(t).LoginAttempts
+		now := time.Now().Truncate(time.Millisecond)
+
+		a, err := attempts.GetAttempts(ctx, "account:a@example.com")
+		if err != nil || a.Key != "account:a@example.com" || a.Failures != 0 || a.Locked(now) {
+			t.Fatalf("GetAttempts(none) = %+v, %v; want no failures", a, err)
+		}
+
+		for i, at := range []time.Time{now.Add(-time.Minute), now} {
+			a, ok, err := attempts.ReserveAttempt(ctx, "account:a@example.com", i, at, time.Hour)
+			if err != nil || !ok || a.Failures != i+1 || !a.LastAttemptAt.Equal(at) {
+				t.Fatalf("ReserveAttempt(#%d) = %+v, %v, %v; want %d failures, the last at %v", i+1, a, ok, err, i+1, at)
+			}
+		}
+		if a, _ := attempts.GetAttempts(ctx, "account:a@example.com"); a.Failures != 2 || a.Locked(now) {
+			t.Errorf("GetAttempts() = %+v, want 2 failures", a)
+		}
+		if a, _ := attempts.GetAttempts(ctx, "ip:192.0.2.1"); a.Failures != 0 {
+			t.Errorf("GetAttempts(other key) = %+v, want none", a)
+		}
+		if a, ok, err := attempts.ReserveAttempt(ctx, "account:a@example.com", 1, now, time.Hour); err != nil || ok {
+			t.Errorf("ReserveAttempt(stale count) = %+v, %v, %v; want nothing counted", a, ok, err)
+		}
+		if err := attempts.ReleaseAttempt(ctx, "account:a@example.com"); err != nil {
+			t.Fatalf("ReleaseAttempt() error = %v", err)
+		}
+		if a, _ := attempts.GetAttempts(ctx, "account:a@example.com"); a.Failures != 1 || !a.LastAttemptAt.Equal(now) {
+			t.Errorf("GetAttempts() after ReleaseAttempt = %+v, want 1 failure, the last at %v", a, now)
+		}
+		if err := attempts.ReleaseAttempt(ctx, "ip:192.0.2.1"); err != nil {
+			t.Errorf("ReleaseAttempt(none) error = %v", err)
+		}
+		if a, ok, err := attempts.ReserveAttempt(ctx, "account:a@example.com", 1, now.Add(2*time.Hour), time.Hour); err != nil || !ok || a.Failures != 1 {
+			t.Errorf("ReserveAttempt(after the window) = %+v, %v, %v; want the count started over", a, ok, err)
+		}
+
+		until := now.Add(15 * time.Minute)
+		if err := attempts.Lock(ctx, "account:a@example.com", until); err != nil {
+			t.Fatalf("Lock() error = %v", err)
+		}
+		a, err = attempts.GetAttempts(ctx, "account:a@example.com")
+		if err != nil || !a.Locked(now) || a.Locked(until) || !a.LockedUntil.Equal(until) || a.Failures != 0 {
+			t.Errorf("GetAttempts(locked) = %+v, %v; want locked until %v with the count started over", a, err, until)
+		}
+		if err := attempts.Lock(ctx, "ip:192.0.2.9", until); err != nil {
+			t.Fatalf("Lock(without failures) error = %v", err)
+		}
+		if a, _ := attempts.GetAttempts(ctx, "ip:192.0.2.9"); !a.Locked(now) {
+			t.Errorf("GetAttempts(locked without failures) = %+v, want locked", a)
+		}
+		if a, ok, err := attempts.ReserveAttempt(ctx, "ip:192.0.2.9", 0, now, time.Hour); err != nil || ok {
+			t.Errorf("ReserveAttempt(locked) = %+v, %v, %v; want nothing counted", a, ok, err)
+		}
+
+		if err := attempts.Reset(ctx, "account:a@example.com"); err != nil {
+			t.Fatalf("Reset() error = %v", err)
+		}
+		if a, _ := attempts.GetAttempts(ctx, "account:a@example.com"); a.Failures != 0 || a.Locked(now) {
+			t.Errorf("GetAttempts() after Reset = %+v, want nothing recorded", a)
+		}
+		if err := attempts.Reset(ctx, "account:a@example.com"); err != nil {
+			t.Errorf("Reset(none) error = %v", err)
+		}
+	})
+
+	t.Run("ConcurrentReservations", func(t *testing.T) {
+		ctx := context.Background()
+		attempts := newRepos(t).LoginAttempts
+		now := time.Now()
+
+		var (
+			wg       sync.WaitGroup
+			reserved atomic.Int32
+		)
+		for range 8 {
+			wg.Go(func() {
+				_, ok, err := attempts.ReserveAttempt(ctx, "account:a@example.com", 0, now, time.Hour)
+				if err != nil {
+					t.Errorf("ReserveAttempt() error = %v", err)
+				}
+				if ok {
+					reserved.Add(1)
+				}
+			})
+		}
+		wg.Wait()
+		if n := reserved.Load(); n != 1 {
+			t.Errorf("%d of 8 concurrent reservations of the same count succeeded, want 1", n)
+		}
+		if a, _ := attempts.GetAttempts(ctx, "account:a@example.com"); a.Failures != 1 {
+			t.Errorf("GetAttempts() = %+v, want 1 failure", a)
+		}
+	})
+
+	t.Run("DeleteStale", func(t *testing.T) {
+		ctx := context.Background()
+		attempts := newRepos(t).LoginAttempts
+		now := time.Now()
+
+		_, _, _ = attempts.ReserveAttempt(ctx, "old", 0, now.Add(-2*time.Hour), time.Hour)
+		_, _, _ = attempts.ReserveAttempt(ctx, "locked", 0, now.Add(-2*time.Hour), time.Hour)
+		_ = attempts.Lock(ctx, "locked", now.Add(time.Hour))
+		_, _, _ = attempts.ReserveAttempt(ctx, "recent", 0, now, time.Hour)
+
+		if n, err := attempts.DeleteStale(ctx, now.Add(-time.Hour)); err != nil || n != 1 {
+			t.Fatalf("DeleteStale() = %d, %v; want 1", n, err)
+		}
+		if a, _ := attempts.GetAttempts(ctx, "old"); a.Failures != 0 {
+			t.Errorf("GetAttempts(old) = %+v, want deleted", a)
+		}
+		if a, _ := attempts.GetAttempts(ctx, "locked"); !a.Locked(now) {
+			t.Errorf("GetAttempts(locked) = %+v, want kept while locked", a)
+		}
+		if a, _ := attempts.GetAttempts(ctx, "recent"); a.Failures != 1 {
+			t.Errorf("GetAttempts(recent) = %+v, want kept", a)
+		}
+	})
+}
+
+// RunAPIKeyRepository checks the usecase.APIKeyRepository contract.
+func RunAPIKeyRepository(t *testing.T, newRepos Factory) {
+	t.Run("CreateGetAndList", func(t *testing.T) {
+		ctx := context.Background()
+		s := newRepos(t)
+		admin := createUser(t, s.Users, "admin@example.com")
+		service := createUser(t, s.Users, "service@example.com")
+
+		want := domain.APIKey{
+			Name:           "catalog sync",
+			Prefix:         "eve_000000000001",
+			Hash:           "digest-1",
+			UserID:         service,
+			Permissions:    []string{domain.PermissionReviewsRead, domain.PermissionReviewsWrite},
+			ReviewableType: "product",
+			CreatedBy:      admin,
+		}
+		created, err := s.APIKeys.CreateAPIKey(ctx, want)
+		if err != nil {
+			t.Fatalf("CreateAPIKey() error = %v", err)
+		}
+		if created.ID == 0 || created.CreatedAt == "" || created.LastUsedAt != nil {
+			t.Fatalf("CreateAPIKey() = %+v, want an ID and CreatedAt and no use", created)
+		}
+		want.ID, want.CreatedAt = created.ID, created.CreatedAt
+		if !reflect.DeepEqual(created, want) {
+			t.Errorf("CreateAPIKey() = %+v, want %+v", created, want)
+		}
+		if _, err := s.APIKeys.CreateAPIKey(ctx, want); err == nil {
+			t.Error("CreateAPIKey(same prefix) succeeded, want error")
+		}
+		other, err := s.APIKeys.CreateAPIKey(ctx, domain.APIKey{Name: "reports", Prefix: "eve_000000000002", Hash: "digest-2", UserID: admin, CreatedBy: admin})
+		if err != nil || other.Permissions == nil && len(other.Permissions) != 0 {
+			t.Fatalf("CreateAPIKey(no permissions) = %+v, %v", other, err)
+		}
+
+		got, err := s.APIKeys.GetAPIKeyByPrefix(ctx, "eve_000000000001")
+		if err != nil || !reflect.DeepEqual(got, want) {
+			t.Errorf("GetAPIKeyByPrefix() = %+v, %v; want %+v", got, err, want)
+		}
+		if _, err := s.APIKeys.GetAPIKeyByPrefix(ctx, "eve_ffffffffffff"); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("GetAPIKeyByPrefix(missing) error = %v, want sql.ErrNoRows", err)
+		}
+
+		keys, err := s.APIKeys.ListAPIKeys(ctx)
+		if err != nil || len(keys) != 2 || keys[0].ID != created.ID || keys[1].ID != other.ID || len(keys[1].Permissions) != 0 {
+			t.Errorf("ListAPIKeys() = %+v, %v; want both keys by ID", keys, err)
+		}
+	})
+
+	t.Run("TouchAndDelete", func(t *testing.T) {
+		ctx := context.Background()
+		s := newRepos(t)
+		user := createUser(t, s.Users, "service@example.com")
+		key, err := s.APIKeys.CreateAPIKey(ctx, domain.APIKey{Name: "sync", Prefix: "eve_000000000001", Hash: "digest", UserID: user, Permissions: []string{domain.PermissionReviewsRead}, CreatedBy: user})
+		if err != nil {
+			t.Fatalf("CreateAPIKey() error = %v", err)
+		}
+
+		at := time.Now().Add(-time.Hour).Truncate(time.Millisecond)
+		if err := s.APIKeys.TouchAPIKey(ctx, key.ID, at); err != nil {
+			t.Fatalf("TouchAPIKey() error = %v", err)
+		}
+		got, _ := s.APIKeys.GetAPIKeyByPrefix(ctx, key.Prefix)
+		if got.LastUsedAt == nil {
+			t.Fatalf("LastUsedAt = nil, want %v", at)
+		}
+		if used, err := time.Parse(time.RFC3339, *got.LastUsedAt); err != nil || !used.Equal(at) {
+			t.Errorf("LastUsedAt = %q, want %v", *got.LastUsedAt, at)
+		}
+		if err := s.APIKeys.TouchAPIKey(ctx, 999, at); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("TouchAPIKey(missing) error = %v, want sql.ErrNoRows", err)
+		}
+
+		if err := s.APIKeys.DeleteAPIKey(ctx, key.ID); err != nil {
+			t.Fatalf("DeleteAPIKey() error = %v", err)
+		}
+		if _, err := s.APIKeys.GetAPIKeyByPrefix(ctx, key.Prefix); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("GetAPIKeyByPrefix(deleted) error = %v, want sql.ErrNoRows", err)
+		}
+		if err := s.APIKeys.DeleteAPIKey(ctx, key.ID); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("DeleteAPIKey(missing) error = %v, want sql.ErrNoRows", err)
+		}
+		if keys, err := s.APIKeys.ListAPIKeys(ctx); err != nil || len(keys) != 0 {
+			t.Errorf("ListAPIKeys() = %+v, %v; want none", keys, err)
+		}
+	})
+}
+
+// RunRateLimiter checks the usecase.RateLimiter contract. It is not part of
+// Run: limiters are not backed by the database the other ports share.
+func RunRateLimiter(t *testing.T, newLimiter func(t *testing.T) usecase.RateLimiter) {
+	ctx := context.Background()
+	limiter := newLimiter(t)
+	limit := usecase.RateLimit{Burst: 3, Period: 300 * time.Millisecond}
+
+	for want := 2; want >= 0; want-- {
+		res, err := limiter.Take(ctx, "a", limit)
+		if err != nil || !res.Allowed || res.Remaining != want || res.RetryAfter != 0 {
+			t.Fatalf("Take() = %+v, %v; want allowed with %d left", res, err, want)
+		}
+	}
+	res, err := limiter.Take(ctx, "a", limit)
+	if err != nil || res.Allowed || res.Remaining != 0 {
+		t.Fatalf("Take(empty) = %+v, %v; want refused", res, err)
+	}
+	if res.RetryAfter <= 0 || res.RetryAfter > 100*time.Millisecond || res.ResetAfter <= 200*time.Millisecond || res.ResetAfter > 300*time.Millisecond {
+		t.Errorf("Take(empty) = %+v, want a retry within one token and a reset within the period", res)
+	}
+
+	if res, err := limiter.Take(ctx, "b", limit); err != nil || !res.Allowed || res.Remaining != 2 {
+		t.Errorf("Take(other key) = %+v, %v; want its own full bucket", res, err)
+	}
+
+	time.Sleep(150 * time.Millisecond)
+	if res, err := limiter.Take(ctx, "a", limit); err != nil || !res.Allowed || res.Remaining != 0 {
+		t.Errorf("Take() after a refill = %+v, %v; want allowed with 0 left", res, err)
+	}
+}
+
+func createUser(t *testing.T, users usecase.UserRepository, email string) int {
+	t.Helper()
+	ctx := context.Background()
+	if err := users.Save(ctx, domain.User{Email: email, Password: "x"}); err != nil {
+		t.Fatalf("save user %s: %v", email, err)
+	}
+	all, err := users.GetAll(ctx)
+	if err != nil {
+		t.Fatalf("get users: %v", err)
+	}
+	for _, u := range all {
+		if u.Email == email {
+			return u.ID
+		}
+	}
+	t.Fatalf("user %s not found after save", email)
+	return 0
+}
+
+func createReview(t *testing.T, reviews usecase.ReviewRepository, author int, reviewableType string, reviewableID int) int {
+	t.Helper()
+	id, err := reviews.Create(context.Background(), domain.Review{
+		ReviewableType: reviewableType,
+		ReviewableID:   reviewableID,
+		UserID:         author,
+		Rating:         3,
+	})
+	if err != nil {
+		t.Fatalf("create review: %v", err)
+	}
+	return id
+}
+
+func reviewIDs(reviews []domain.Review) []int {
+	ids := make([]int, 0, len(reviews))
+	for _, r := range reviews {
+		ids = append(ids, r.ID)
+	}
+	return ids
+}
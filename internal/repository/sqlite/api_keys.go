@@ -0,0 +1,124 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"eve/domain"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// APIKeyRepo is a SQLite implementation of usecase.APIKeyRepository.
+type APIKeyRepo struct {
+	db *sqlx.DB
+}
+
+func NewAPIKeyRepo(db *sqlx.DB) *APIKeyRepo {
+	return &APIKeyRepo{db: db}
+}
+
+const apiKeyColumns = "id, name, prefix, hash, user_id, permissions, reviewable_type, created_by, created_at, last_used_at"
+
+// apiKeyRow scans the permissions of a key, stored as a JSON array.
+type apiKeyRow struct {
+	domain.APIKey
+	Permissions string `db:"permissions"`
+}
+
+func (row apiKeyRow) apiKey() (domain.APIKey, error) {
+	key := row.APIKey
+	if err := json.Unmarshal([]byte(row.Permissions), &key.Permissions); err != nil {
+		return domain.APIKey{}, fmt.Errorf("decode permissions of api key %d: %w", key.ID, err)
+	}
+	return key, nil
+}
+
+func (r *APIKeyRepo) CreateAPIKey(ctx context.Context, key domain.APIKey) (_ domain.APIKey, err error) {
+	query := `
+		INSERT INTO api_keys (name, prefix, hash, user_id, permissions, reviewable_type, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING ` + apiKeyColumns
+	ctx, span := startSpan(ctx, "api_keys.create", query)
+	defer func() { endSpan(span, err) }()
+
+	permissions, err := json.Marshal(key.Permissions)
+	if err != nil {
+		return domain.APIKey{}, fmt.Errorf("marshal api key permissions: %w", err)
+	}
+	if key.Permissions == nil {
+		permissions = []byte("[]")
+	}
+	var row apiKeyRow
+	if err := r.db.GetContext(ctx, &row, query, key.Name, key.Prefix, key.Hash, key.UserID, string(permissions), key.ReviewableType, key.CreatedBy); err != nil {
+		return domain.APIKey{}, fmt.Errorf("create api key: %w", err)
+	}
+	return row.apiKey()
+}
+
+func (r *APIKeyRepo) GetAPIKeyByPrefix(ctx context.Context, prefix string) (_ domain.APIKey, err error) {
+	query := "SELECT " + apiKeyColumns + " FROM api_keys WHERE prefix = $1"
+	ctx, span := startSpan(ctx, "api_keys.get_by_prefix", query)
+	defer func() { endSpan(span, err) }()
+
+	var row apiKeyRow
+	if err := r.db.GetContext(ctx, &row, query, prefix); err != nil {
+		return domain.APIKey{}, fmt.Errorf("get api key: %w", err)
+	}
+	return row.apiKey()
+}
+
+func (r *APIKeyRepo) ListAPIKeys(ctx context.Context) (keys []domain.APIKey, err error) {
+	query := "SELECT " + apiKeyColumns + " FROM api_keys ORDER BY id"
+	ctx, span := startSpan(ctx, "api_keys.list", query)
+	defer func() { endSpan(span, err) }()
+
+	var rows []apiKeyRow
+	if err := r.db.SelectContext(ctx, &rows, query); err != nil {
+		return nil, fmt.Errorf("list api keys: %w", err)
+	}
+	for _, row := range rows {
+		key, err := row.apiKey()
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func (r *APIKeyRepo) DeleteAPIKey(ctx context.Context, id int) (err error) {
+	query := "DELETE FROM api_keys WHERE id = $1"
+	ctx, span := startSpan(ctx, "api_keys.delete", query)
+	defer func() { endSpan(span, err) }()
+
+	res, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("delete api key: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("delete api key: %w", err)
+	} else if n == 0 {
+		return fmt.Errorf("delete api key: %w", sql.ErrNoRows)
+	}
+	return nil
+}
+
+func (r *APIKeyRepo) TouchAPIKey(ctx context.Context, id int, at time.Time) (err error) {
+	query := "UPDATE api_keys SET last_used_at = $1 WHERE id = $2"
+	ctx, span := startSpan(ctx, "api_keys.touch", query)
+	defer func() { endSpan(span, err) }()
+
+	res, err := r.db.ExecContext(ctx, query, at.UTC().Format(sqliteTimeLayout), id)
+	if err != nil {
+		return fmt.Errorf("touch api key: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("touch api key: %w", err)
+	} else if n == 0 {
+		return fmt.Errorf("touch api key: %w", sql.ErrNoRows)
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// CatalogRepo is a SQLite implementation of usecase.ReviewableResolver backed
+// by the catalog_items table.
+type CatalogRepo struct {
+	db *sqlx.DB
+}
+
+func NewCatalogRepo(db *sqlx.DB) *CatalogRepo {
+	return &CatalogRepo{db: db}
+}
+
+func (c *CatalogRepo) Exists(ctx context.Context, reviewableType string, reviewableID int) (exists bool, err error) {
+	query := `SELECT EXISTS (SELECT 1 FROM catalog_items WHERE reviewable_type = $1 AND reviewable_id = $2)`
+	ctx, span := startSpan(ctx, "catalog_items.exists", query)
+	defer func() { endSpan(span, err) }()
+
+	if err := c.db.GetContext(ctx, &exists, query, reviewableType, reviewableID); err != nil {
+		return false, fmt.Errorf("check catalog item: %w", err)
+	}
+	return exists, nil
+}
@@ -0,0 +1,32 @@
+package sqlite_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"eve/internal/repository/repotest"
+	"eve/internal/repository/sqlite"
+)
+
+func TestContract(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repotest.Repos {
+		db, err := sqlite.Open(context.Background(), filepath.Join(t.TempDir(), "eve.db"))
+		if err != nil {
+			t.Fatalf("open: %v", err)
+		}
+		t.Cleanup(func() { _ = db.Close() })
+		return repotest.Repos{
+			Users:         sqlite.NewUserRepo(db),
+			Reviews:       sqlite.NewReviewRepo(db),
+			Owners:        sqlite.NewOwnerRepo(db),
+			Criteria:      sqlite.NewCriteriaRepo(db),
+			Types:         sqlite.NewReviewableTypeRepo(db),
+			Outbox:        sqlite.NewOutboxRepo(db),
+			Webhooks:      sqlite.NewWebhookRepo(db),
+			Idempotency:   sqlite.NewIdempotencyStore(db),
+			LoginAttempts: sqlite.NewLoginAttemptRepo(db),
+			APIKeys:       sqlite.NewAPIKeyRepo(db),
+		}
+	})
+}
@@ -0,0 +1,63 @@
+package sqlite
+
+import (
+	"context"
+	"eve/domain"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// CriteriaRepo is a SQLite implementation of usecase.CriteriaRepository.
+type CriteriaRepo struct {
+	db *sqlx.DB
+}
+
+func NewCriteriaRepo(db *sqlx.DB) *CriteriaRepo {
+	return &CriteriaRepo{db: db}
+}
+
+func (c *CriteriaRepo) SetCriteria(ctx context.Context, reviewableType string, criteria []domain.RatingCriterion) (err error) {
+	stmt := `
+		INSERT INTO rating_criteria (reviewable_type, criterion, label, required, sort_order)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+	ctx, span := startSpan(ctx, "rating_criteria.set", stmt)
+	defer func() { endSpan(span, err) }()
+
+	tx, err := c.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM rating_criteria WHERE reviewable_type = $1", reviewableType); err != nil {
+		return fmt.Errorf("clear criteria: %w", err)
+	}
+	for _, cr := range criteria {
+		if _, err := tx.ExecContext(ctx, stmt, reviewableType, cr.Key, cr.Label, cr.Required, cr.SortOrder); err != nil {
+			return fmt.Errorf("insert criterion: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit criteria tx: %w", err)
+	}
+	return nil
+}
+
+func (c *CriteriaRepo) ListCriteria(ctx context.Context, reviewableType string) (criteria []domain.RatingCriterion, err error) {
+	query := `
+		SELECT reviewable_type, criterion, label, required, sort_order
+		FROM rating_criteria
+		WHERE reviewable_type = $1
+		ORDER BY sort_order, criterion
+	`
+	ctx, span := startSpan(ctx, "rating_criteria.list", query)
+	defer func() { endSpan(span, err) }()
+
+	if err := c.db.SelectContext(ctx, &criteria, query, reviewableType); err != nil {
+		return nil, fmt.Errorf("list criteria: %w", err)
+	}
+	return criteria, nil
+}
@@ -0,0 +1,111 @@
+// Package sqlite implements the repositories on top of an embedded SQLite
+// database, for local development and single-binary deployments.
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"sort"
+	"strings"
+
+	"eve/migrations"
+
+	"github.com/jmoiron/sqlx"
+	_ "modernc.org/sqlite"
+)
+
+// Open opens (creating if needed) the SQLite database at path and applies any
+// pending migrations. Foreign keys are enforced so that deletes cascade the
+// same way they do in Postgres.
+func Open(ctx context.Context, path string) (*sqlx.DB, error) {
+	q := url.Values{}
+	q.Add("_pragma", "foreign_keys(1)")
+	q.Add("_pragma", "busy_timeout(5000)")
+	q.Add("_pragma", "journal_mode(WAL)")
+	dsn := "file:" + path + "?" + q.Encode()
+
+	db, err := sqlx.ConnectContext(ctx, "sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("open sqlite %s: %w", path, err)
+	}
+	if err := Migrate(ctx, db); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// Migrate applies the "Up" section of every embedded migration that has not
+// been applied yet, each in its own transaction.
+func Migrate(ctx context.Context, db *sqlx.DB) error {
+	if _, err := db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version TEXT PRIMARY KEY,
+			applied_at TEXT DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now'))
+		)
+	`); err != nil {
+		return fmt.Errorf("create schema_migrations: %w", err)
+	}
+
+	files, err := fs.Glob(migrations.SQLite, "sqlite/*.sql")
+	if err != nil {
+		return fmt.Errorf("list migrations: %w", err)
+	}
+	sort.Strings(files)
+
+	for _, name := range files {
+		version := strings.TrimSuffix(strings.TrimPrefix(name, "sqlite/"), ".sql")
+
+		var applied int
+		if err := db.GetContext(ctx, &applied, "SELECT COUNT(*) FROM schema_migrations WHERE version = $1", version); err != nil {
+			return fmt.Errorf("check migration %s: %w", version, err)
+		}
+		if applied > 0 {
+			continue
+		}
+
+		src, err := fs.ReadFile(migrations.SQLite, name)
+		if err != nil {
+			return fmt.Errorf("read migration %s: %w", version, err)
+		}
+		if err := applyMigration(ctx, db, version, upSection(string(src))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func applyMigration(ctx context.Context, db *sqlx.DB, version, script string) error {
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin migration %s: %w", version, err)
+	}
+	defer func() {
+		// If still in transaction and not committed, rollback.
+		_ = tx.Rollback()
+	}()
+
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		return fmt.Errorf("apply migration %s: %w", version, err)
+	}
+	if _, err := tx.ExecContext(ctx, "INSERT INTO schema_migrations (version) VALUES ($1)", version); err != nil {
+		return fmt.Errorf("record migration %s: %w", version, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit migration %s: %w", version, err)
+	}
+	return nil
+}
+
+// upSection returns the statements between "-- +goose Up" and "-- +goose Down".
+func upSection(src string) string {
+	if i := strings.Index(src, "-- +goose Up"); i >= 0 {
+		src = src[i+len("-- +goose Up"):]
+	}
+	if i := strings.Index(src, "-- +goose Down"); i >= 0 {
+		src = src[:i]
+	}
+	return src
+}
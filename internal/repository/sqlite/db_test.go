@@ -0,0 +1,113 @@
+package sqlite_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"eve/internal/repository/sqlite"
+)
+
+func TestOpenIsIdempotent(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "eve.db")
+
+	for i := 0; i < 2; i++ {
+		db, err := sqlite.Open(ctx, path)
+		if err != nil {
+			t.Fatalf("Open() #%d error = %v", i+1, err)
+		}
+		_ = db.Close()
+	}
+}
+
+func TestUpdatedAtTrigger(t *testing.T) {
+	ctx := context.Background()
+	db, err := sqlite.Open(ctx, filepath.Join(t.TempDir(), "eve.db"))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer db.Close()
+
+	db.MustExecContext(ctx, "INSERT INTO users (email, password) VALUES ('a@example.com', 'x')")
+	db.MustExecContext(ctx, "INSERT INTO reviews (reviewable_type, reviewable_id, user_id, rating) VALUES ('product', 1, 1, 3)")
+
+	var before string
+	if err := db.GetContext(ctx, &before, "SELECT updated_at FROM reviews WHERE id = 1"); err != nil {
+		t.Fatalf("select updated_at: %v", err)
+	}
+
+	time.Sleep(5 * time.Millisecond)
+	db.MustExecContext(ctx, "UPDATE reviews SET rating = 4 WHERE id = 1")
+
+	var after string
+	if err := db.GetContext(ctx, &after, "SELECT updated_at FROM reviews WHERE id = 1"); err != nil {
+		t.Fatalf("select updated_at: %v", err)
+	}
+	if after <= before {
+		t.Errorf("updated_at = %s after update, want later than %s", after, before)
+	}
+}
+
+func TestCatalogRepoExists(t *testing.T) {
+	ctx := context.Background()
+	db, err := sqlite.Open(ctx, filepath.Join(t.TempDir(), "eve.db"))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer db.Close()
+
+	db.MustExecContext(ctx, "INSERT INTO catalog_items (reviewable_type, reviewable_id) VALUES ('product', 42)")
+	catalog := sqlite.NewCatalogRepo(db)
+
+	tests := []struct {
+		reviewableType string
+		reviewableID   int
+		want           bool
+	}{
+		{"product", 42, true},
+		{"product", 43, false},
+		{"vendor", 42, false},
+	}
+	for _, tt := range tests {
+		got, err := catalog.Exists(ctx, tt.reviewableType, tt.reviewableID)
+		if err != nil {
+			t.Fatalf("Exists(%s, %d) error = %v", tt.reviewableType, tt.reviewableID, err)
+		}
+		if got != tt.want {
+			t.Errorf("Exists(%s, %d) = %v, want %v", tt.reviewableType, tt.reviewableID, got, tt.want)
+		}
+	}
+}
+
+func TestPurchaseRepoHasPurchased(t *testing.T) {
+	ctx := context.Background()
+	db, err := sqlite.Open(ctx, filepath.Join(t.TempDir(), "eve.db"))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer db.Close()
+
+	db.MustExecContext(ctx, "INSERT INTO purchases (user_id, reviewable_type, reviewable_id, order_ref) VALUES (7, 'product', 42, 'A-1'), (7, 'product', 42, 'A-2')")
+	purchases := sqlite.NewPurchaseRepo(db)
+
+	tests := []struct {
+		userID       int
+		reviewableID int
+		want         bool
+	}{
+		{7, 42, true},
+		{7, 43, false},
+		{8, 42, false},
+	}
+	for _, tt := range tests {
+		got, err := purchases.HasPurchased(ctx, tt.userID, "product", tt.reviewableID)
+		if err != nil {
+			t.Fatalf("HasPurchased(%d, product, %d) error = %v", tt.userID, tt.reviewableID, err)
+		}
+		if got != tt.want {
+			t.Errorf("HasPurchased(%d, product, %d) = %v, want %v", tt.userID, tt.reviewableID, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"eve/domain"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// LoginAttemptRepo is a SQLite implementation of usecase.LoginAttemptRepository.
+type LoginAttemptRepo struct {
+	db *sqlx.DB
+}
+
+func NewLoginAttemptRepo(db *sqlx.DB) *LoginAttemptRepo {
+	return &LoginAttemptRepo{db: db}
+}
+
+const loginAttemptColumns = "key, failures, last_attempt_at, locked_until"
+
+// loginAttemptsRow scans the text timestamps of login attempts.
+type loginAttemptsRow struct {
+	Key           string `db:"key"`
+	Failures      int    `db:"failures"`
+	LastAttemptAt string `db:"last_attempt_at"`
+	LockedUntil   string `db:"locked_until"`
+}
+
+func (row loginAttemptsRow) attempts() (domain.LoginAttempts, error) {
+	a := domain.LoginAttempts{Key: row.Key, Failures: row.Failures}
+	var err error
+	if a.LastAttemptAt, err = time.Parse(sqliteTimeLayout, row.LastAttemptAt); err != nil {
+		return domain.LoginAttempts{}, fmt.Errorf("parse last_attempt_at: %w", err)
+	}
+	if a.LockedUntil, err = time.Parse(sqliteTimeLayout, row.LockedUntil); err != nil {
+		return domain.LoginAttempts{}, fmt.Errorf("parse locked_until: %w", err)
+	}
+	return a, nil
+}
+
+func (r *LoginAttemptRepo) GetAttempts(ctx context.Context, key string) (_ domain.LoginAttempts, err error) {
+	query := "SELECT " + loginAttemptColumns + " FROM login_attempts WHERE key = $1"
+	ctx, span := startSpan(ctx, "login_attempts.get", query)
+	defer func() { endSpan(span, err) }()
+
+	var row loginAttemptsRow
+	err = r.db.GetContext(ctx, &row, query, key)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.LoginAttempts{Key: key}, nil
+	}
+	if err != nil {
+		return domain.LoginAttempts{}, fmt.Errorf("get login attempts: %w", err)
+	}
+	return row.attempts()
+}
+
+func (r *LoginAttemptRepo) ReserveAttempt(ctx context.Context, key string, seen int, at time.Time, window time.Duration) (_ domain.LoginAttempts, reserved bool, err error) {
+	// Writes are serialized, so that of concurrent reservations with the same
+	// seen count only one succeeds.
+	query := `
+		INSERT INTO login_attempts (key, failures, last_attempt_at)
+		VALUES ($1, 1, $2)
+		ON CONFLICT (key) DO UPDATE
+		SET failures = CASE WHEN login_attempts.last_attempt_at <= $3 THEN 1 ELSE login_attempts.failures + 1 END,
+		    last_attempt_at = excluded.last_attempt_at
+		WHERE login_attempts.failures = $4 AND login_attempts.locked_until <= $2
+		RETURNING ` + loginAttemptColumns
+	ctx, span := startSpan(ctx, "login_attempts.reserve", query)
+	defer func() { endSpan(span, err) }()
+
+	var row loginAttemptsRow
+	err = r.db.GetContext(ctx, &row, query, key, at.UTC().Format(sqliteTimeLayout), at.Add(-window).UTC().Format(sqliteTimeLayout), seen)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.LoginAttempts{}, false, nil
+	}
+	if err != nil {
+		return domain.LoginAttempts{}, false, fmt.Errorf("reserve login attempt: %w", err)
+	}
+	a, err := row.attempts()
+	return a, err == nil, err
+}
+
+func (r *LoginAttemptRepo) ReleaseAttempt(ctx context.Context, key string) (err error) {
+	query := "UPDATE login_attempts SET failures = MAX(failures - 1, 0) WHERE key = $1"
+	ctx, span := startSpan(ctx, "login_attempts.release", query)
+	defer func() { endSpan(span, err) }()
+
+	if _, err := r.db.ExecContext(ctx, query, key); err != nil {
+		return fmt.Errorf("release login attempt: %w", err)
+	}
+	return nil
+}
+
+func (r *LoginAttemptRepo) Lock(ctx context.Context, key string, until time.Time) (err error) {
+	query := `
+		INSERT INTO login_attempts (key, locked_until)
+		VALUES ($1, $2)
+		ON CONFLICT (key) DO UPDATE SET failures = 0, locked_until = excluded.locked_until
+	`
+	ctx, span := startSpan(ctx, "login_attempts.lock", query)
+	defer func() { endSpan(span, err) }()
+
+	if _, err := r.db.ExecContext(ctx, query, key, until.UTC().Format(sqliteTimeLayout)); err != nil {
+		return fmt.Errorf("lock logins: %w", err)
+	}
+	return nil
+}
+
+func (r *LoginAttemptRepo) Reset(ctx context.Context, key string) (err error) {
+	query := "DELETE FROM login_attempts WHERE key = $1"
+	ctx, span := startSpan(ctx, "login_attempts.reset", query)
+	defer func() { endSpan(span, err) }()
+
+	if _, err := r.db.ExecContext(ctx, query, key); err != nil {
+		return fmt.Errorf("reset login attempts: %w", err)
+	}
+	return nil
+}
+
+func (r *LoginAttemptRepo) DeleteStale(ctx context.Context, before time.Time) (_ int, err error) {
+	query := "DELETE FROM login_attempts WHERE last_attempt_at < $1 AND locked_until < $1"
+	ctx, span := startSpan(ctx, "login_attempts.delete_stale", query)
+	defer func() { endSpan(span, err) }()
+
+	res, err := r.db.ExecContext(ctx, query, before.UTC().Format(sqliteTimeLayout))
+	if err != nil {
+		return 0, fmt.Errorf("delete stale login attempts: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("delete stale login attempts: %w", err)
+	}
+	return int(n), nil
+}
@@ -0,0 +1,66 @@
+package sqlite
+
+import (
+	"context"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"eve/migrations"
+)
+
+// downSection returns the statements after "-- +goose Down".
+func downSection(src string) string {
+	if i := strings.Index(src, "-- +goose Down"); i >= 0 {
+		return src[i+len("-- +goose Down"):]
+	}
+	return ""
+}
+
+func TestMigrationsRollBack(t *testing.T) {
+	ctx := context.Background()
+	db, err := Open(ctx, filepath.Join(t.TempDir(), "eve.db"))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer db.Close()
+
+	db.MustExecContext(ctx, "INSERT INTO users (email, password) VALUES ('a@example.com', 'x')")
+	db.MustExecContext(ctx, "INSERT INTO reviews (reviewable_type, reviewable_id, user_id, rating) VALUES ('product', 1, 1, 3)")
+	db.MustExecContext(ctx, "INSERT INTO review_comments (review_id, user_id, body) VALUES (1, 1, 'first')")
+	db.MustExecContext(ctx, "INSERT INTO review_comments (review_id, user_id, body, parent_id, depth) VALUES (1, 1, 'reply', 1, 1)")
+
+	files, err := fs.Glob(migrations.SQLite, "sqlite/*.sql")
+	if err != nil {
+		t.Fatalf("list migrations: %v", err)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(files)))
+	for _, name := range files {
+		version := strings.TrimSuffix(strings.TrimPrefix(name, "sqlite/"), ".sql")
+		src, err := fs.ReadFile(migrations.SQLite, name)
+		if err != nil {
+			t.Fatalf("read migration %s: %v", version, err)
+		}
+		if _, err := db.ExecContext(ctx, downSection(string(src))); err != nil {
+			t.Fatalf("roll back %s: %v", version, err)
+		}
+		db.MustExecContext(ctx, "DELETE FROM schema_migrations WHERE version = $1", version)
+
+		if version == "20260112100000_add_comment_replies" {
+			var bodies []string
+			if err := db.SelectContext(ctx, &bodies, "SELECT body FROM review_comments ORDER BY id"); err != nil {
+				t.Fatalf("comments after rolling back %s: %v", version, err)
+			}
+			if strings.Join(bodies, ",") != "first,reply" {
+				t.Errorf("comments after rolling back %s = %v, want both kept", version, bodies)
+			}
+		}
+	}
+
+	// Everything rolled back can be applied again.
+	if err := Migrate(ctx, db); err != nil {
+		t.Fatalf("Migrate() after rolling back error = %v", err)
+	}
+}
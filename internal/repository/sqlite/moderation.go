@@ -0,0 +1,39 @@
+package sqlite
+
+import (
+	"context"
+	"eve/domain"
+	"fmt"
+)
+
+func (r *ReviewRepo) ApproveReview(ctx context.Context, id int) (err error) {
+	query := `
+		UPDATE reviews SET status = 'published', updated_at = strftime('%Y-%m-%dT%H:%M:%fZ', 'now')
+		WHERE id = $1 AND status = 'pending' AND deleted_at IS NULL
+	`
+	ctx, span := startSpan(ctx, "reviews.approve", query)
+	defer func() { endSpan(span, err) }()
+
+	if err := r.changeReview(ctx, query, id, "", domain.EventReviewCreated); err != nil {
+		return fmt.Errorf("approve review: %w", err)
+	}
+	return nil
+}
+
+func (r *ReviewRepo) ListPending(ctx context.Context, limit, offset int) (reviews []domain.Review, err error) {
+	// Served by idx_reviews_pending.
+	query := `
+		SELECT ` + reviewColumns + `
+		FROM reviews
+		WHERE status = 'pending' AND deleted_at IS NULL
+		ORDER BY created_at, id
+		LIMIT $1 OFFSET $2
+	`
+	ctx, span := startSpan(ctx, "reviews.list_pending", query)
+	defer func() { endSpan(span, err) }()
+
+	if err := r.db.SelectContext(ctx, &reviews, query, limit, offset); err != nil {
+		return nil, fmt.Errorf("list pending reviews: %w", err)
+	}
+	return reviews, nil
+}
@@ -0,0 +1,233 @@
+package sqlite
+
+import (
+	"context"
+	"eve/domain"
+	"eve/internal/usecase"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ReviewRepo is a SQLite implementation of usecase.ReviewRepository.
+type ReviewRepo struct {
+	db *sqlx.DB
+}
+
+var _ usecase.ReviewRepository = (*ReviewRepo)(nil)
+
+func NewReviewRepo(db *sqlx.DB) *ReviewRepo {
+	return &ReviewRepo{db: db}
+}
+
+func (r *ReviewRepo) Create(ctx context.Context, review domain.Review) (id int, err error) {
+	query := `
+		INSERT INTO reviews (reviewable_type, reviewable_id, user_id, rating, title, body, status, verified)
+		VALUES ($1, $2, $3, $4, $5, $6, COALESCE(NULLIF($7, ''), 'published'), $8)
+		RETURNING ` + reviewColumns
+	ctx, span := startSpan(ctx, "reviews.create", query)
+	defer func() { endSpan(span, err) }()
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var stored domain.Review
+	err = tx.GetContext(ctx, &stored, query,
+		review.ReviewableType,
+		review.ReviewableID,
+		review.UserID,
+		review.Rating,
+		review.Title,
+		review.Body,
+		review.Status,
+		review.Verified,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("insert review: %w", err)
+	}
+	id = stored.ID
+	if err := insertSubRatings(ctx, tx, id, review.SubRatings); err != nil {
+		return 0, err
+	}
+	stored.SubRatings = review.SubRatings
+	if err := addEvent(ctx, tx, domain.EventReviewCreated, id, stored); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit review tx: %w", err)
+	}
+	return id, nil
+}
+
+func (r *ReviewRepo) AddPhotos(ctx context.Context, reviewID int, photos []domain.ReviewPhoto) (err error) {
+	stmt := `
+		INSERT INTO review_photos (review_id, file_path, metadata, sort_order)
+		VALUES ($1, $2, $3, $4)
+	`
+	ctx, span := startSpan(ctx, "review_photos.add", stmt)
+	defer func() { endSpan(span, err) }()
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() {
+		// If still in transaction and not committed, rollback.
+		_ = tx.Rollback()
+	}()
+
+	for _, p := range photos {
+		var metadata interface{}
+		if len(p.Metadata) == 0 {
+			metadata = nil
+		} else {
+			metadata = []byte(p.Metadata)
+		}
+		if _, err := tx.ExecContext(ctx, stmt, reviewID, p.FilePath, metadata, p.SortOrder); err != nil {
+			return fmt.Errorf("insert photo: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit photos tx: %w", err)
+	}
+	return nil
+}
+
+func (r *ReviewRepo) AddComment(ctx context.Context, comment domain.ReviewComment) (id int, err error) {
+	query := `
+		INSERT INTO review_comments (review_id, parent_id, depth, user_id, body)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, review_id, parent_id, depth, user_id, body, created_at, updated_at
+	`
+	ctx, span := startSpan(ctx, "review_comments.add", query)
+	defer func() { endSpan(span, err) }()
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var stored domain.ReviewComment
+	err = tx.GetContext(ctx, &stored, query, comment.ReviewID, comment.ParentID, comment.Depth, comment.UserID, comment.Body)
+	if err != nil {
+		return 0, fmt.Errorf("insert comment: %w", err)
+	}
+	if err := addEvent(ctx, tx, domain.EventCommentAdded, stored.ReviewID, stored); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit comment tx: %w", err)
+	}
+	return stored.ID, nil
+}
+
+func (r *ReviewRepo) GetByID(ctx context.Context, id int) (review domain.Review, err error) {
+	query := `
+		SELECT id, reviewable_type, reviewable_id, user_id, rating, title, body, status, verified, created_at, updated_at
+		FROM reviews
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+	ctx, span := startSpan(ctx, "reviews.get_by_id", query)
+	defer func() { endSpan(span, err) }()
+
+	if err := r.db.GetContext(ctx, &review, query, id); err != nil {
+		return domain.Review{}, fmt.Errorf("get review by id: %w", err)
+	}
+	return review, nil
+}
+
+func (r *ReviewRepo) ListByReviewable(ctx context.Context, reviewableType string, reviewableID int, verifiedOnly bool) (reviews []domain.Review, err error) {
+	query := `
+		SELECT id, reviewable_type, reviewable_id, user_id, rating, title, body, status, verified, created_at, updated_at
+		FROM reviews
+		WHERE reviewable_type = $1 AND reviewable_id = $2 AND deleted_at IS NULL AND status = 'published'
+		  AND (verified OR NOT $3)
+		ORDER BY created_at DESC, id DESC
+	`
+	ctx, span := startSpan(ctx, "reviews.list_by_reviewable", query)
+	defer func() { endSpan(span, err) }()
+
+	if err := r.db.SelectContext(ctx, &reviews, query, reviewableType, reviewableID, verifiedOnly); err != nil {
+		return nil, fmt.Errorf("list reviews by reviewable: %w", err)
+	}
+	return reviews, nil
+}
+
+func (r *ReviewRepo) GetComment(ctx context.Context, id int) (comment domain.ReviewComment, err error) {
+	query := `
+		SELECT c.id, c.review_id, c.parent_id, c.depth, c.user_id, c.body, c.created_at, c.updated_at
+		FROM review_comments c
+		JOIN reviews r ON r.id = c.review_id AND r.deleted_at IS NULL
+		WHERE c.id = $1
+	`
+	ctx, span := startSpan(ctx, "review_comments.get_by_id", query)
+	defer func() { endSpan(span, err) }()
+
+	if err := r.db.GetContext(ctx, &comment, query, id); err != nil {
+		return domain.ReviewComment{}, fmt.Errorf("get comment by id: %w", err)
+	}
+	return comment, nil
+}
+
+func (r *ReviewRepo) ListComments(ctx context.Context, reviewID int) (comments []domain.ReviewComment, err error) {
+	query := `
+		SELECT c.id, c.review_id, c.parent_id, c.depth, c.user_id, c.body, c.created_at, c.updated_at
+		FROM review_comments c
+		JOIN reviews r ON r.id = c.review_id AND r.deleted_at IS NULL
+		WHERE c.review_id = $1
+		ORDER BY c.created_at ASC, c.id ASC
+	`
+	ctx, span := startSpan(ctx, "review_comments.list", query)
+	defer func() { endSpan(span, err) }()
+
+	if err := r.db.SelectContext(ctx, &comments, query, reviewID); err != nil {
+		return nil, fmt.Errorf("list comments: %w", err)
+	}
+	return comments, nil
+}
+
+// photoRow mirrors domain.ReviewPhoto with a nullable metadata column, which
+// cannot be scanned into json.RawMessage directly.
+type photoRow struct {
+	ID        int    `db:"id"`
+	ReviewID  int    `db:"review_id"`
+	FilePath  string `db:"file_path"`
+	Metadata  []byte `db:"metadata"`
+	SortOrder int    `db:"sort_order"`
+	CreatedAt string `db:"created_at"`
+}
+
+func (r *ReviewRepo) ListPhotos(ctx context.Context, reviewID int) (photos []domain.ReviewPhoto, err error) {
+	query := `
+		SELECT p.id, p.review_id, p.file_path, p.metadata, p.sort_order, p.created_at
+		FROM review_photos p
+		JOIN reviews r ON r.id = p.review_id AND r.deleted_at IS NULL
+		WHERE p.review_id = $1
+		ORDER BY p.sort_order ASC, p.id ASC
+	`
+	ctx, span := startSpan(ctx, "review_photos.list", query)
+	defer func() { endSpan(span, err) }()
+
+	var rows []photoRow
+	if err := r.db.SelectContext(ctx, &rows, query, reviewID); err != nil {
+		return nil, fmt.Errorf("list photos: %w", err)
+	}
+	for _, row := range rows {
+		photos = append(photos, domain.ReviewPhoto{
+			ID:        row.ID,
+			ReviewID:  row.ReviewID,
+			FilePath:  row.FilePath,
+			Metadata:  row.Metadata,
+			SortOrder: row.SortOrder,
+			CreatedAt: row.CreatedAt,
+		})
+	}
+	return photos, nil
+}
@@ -0,0 +1,74 @@
+package sqlite
+
+import (
+	"context"
+	"eve/domain"
+	"fmt"
+	"strings"
+)
+
+// searchFilter restricts matches to live, published reviews and the optional reviewable filter.
+// Arguments: $1 FTS5 match expression, $2 reviewable_type (empty = any),
+// $3 reviewable_id (0 = any).
+const searchFilter = `
+		FROM reviews_fts
+		JOIN reviews r ON r.id = reviews_fts.rowid
+		WHERE reviews_fts MATCH $1
+		  AND r.deleted_at IS NULL AND r.status = 'published'
+		  AND ($2 = '' OR r.reviewable_type = $2)
+		  AND ($3 = 0 OR r.reviewable_id = $3)
+`
+
+// searchRow is a review together with its rank and highlighted fragments.
+type searchRow struct {
+	domain.Review
+	Rank           float64 `db:"rank"`
+	TitleHighlight string  `db:"title_highlight"`
+	BodyHighlight  string  `db:"body_highlight"`
+}
+
+// SearchReviews implements usecase.ReviewSearcher with an FTS5 index. Title
+// matches weigh more than body matches.
+func (r *ReviewRepo) SearchReviews(ctx context.Context, q domain.ReviewSearchQuery) (res domain.ReviewSearchResult, err error) {
+	query := `
+		SELECT r.id, r.reviewable_type, r.reviewable_id, r.user_id, r.rating, r.title, r.body, r.status, r.verified, r.created_at, r.updated_at,
+			-bm25(reviews_fts, 10.0, 1.0) AS rank,
+			highlight(reviews_fts, 0, '` + domain.HighlightStart + `', '` + domain.HighlightStop + `') AS title_highlight,
+			snippet(reviews_fts, 1, '` + domain.HighlightStart + `', '` + domain.HighlightStop + `', '…', 30) AS body_highlight
+	` + searchFilter + `
+		ORDER BY rank DESC, r.id DESC
+		LIMIT $4 OFFSET $5
+	`
+	ctx, span := startSpan(ctx, "reviews.search", query)
+	defer func() { endSpan(span, err) }()
+
+	args := []any{matchExpr(q.Query), q.ReviewableType, q.ReviewableID}
+
+	if err := r.db.GetContext(ctx, &res.Total, "SELECT count(*)"+searchFilter, args...); err != nil {
+		return domain.ReviewSearchResult{}, fmt.Errorf("count search results: %w", err)
+	}
+
+	var rows []searchRow
+	if err := r.db.SelectContext(ctx, &rows, query, append(args, q.Limit, q.Offset)...); err != nil {
+		return domain.ReviewSearchResult{}, fmt.Errorf("search reviews: %w", err)
+	}
+	for _, row := range rows {
+		res.Hits = append(res.Hits, domain.ReviewSearchHit{
+			Review:         row.Review,
+			Rank:           row.Rank,
+			TitleHighlight: domain.HighlightHTML(row.TitleHighlight),
+			BodyHighlight:  domain.HighlightHTML(row.BodyHighlight),
+		})
+	}
+	return res, nil
+}
+
+// matchExpr turns free text into an FTS5 expression requiring every term.
+// Terms are quoted so that FTS5 operators in user input are matched literally.
+func matchExpr(text string) string {
+	terms := strings.Fields(text)
+	for i, t := range terms {
+		terms[i] = `"` + strings.ReplaceAll(t, `"`, `""`) + `"`
+	}
+	return strings.Join(terms, " ")
+}
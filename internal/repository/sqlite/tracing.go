@@ -0,0 +1,20 @@
+package sqlite
+
+import (
+	"context"
+	"eve/internal/repository/dbtrace"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = dbtrace.New("eve/internal/repository/sqlite", "sqlite", "sqlite")
+
+// startSpan starts a client span for a repository operation executing query.
+func startSpan(ctx context.Context, operation, query string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, operation, query)
+}
+
+// endSpan records err on span (if any) and ends it.
+func endSpan(span trace.Span, err error) {
+	dbtrace.End(span, err)
+}
@@ -0,0 +1,211 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"eve/domain"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// WebhookRepo is a SQLite implementation of usecase.WebhookRepository.
+type WebhookRepo struct {
+	db *sqlx.DB
+}
+
+func NewWebhookRepo(db *sqlx.DB) *WebhookRepo {
+	return &WebhookRepo{db: db}
+}
+
+const (
+	webhookColumns  = "id, url, events, reviewable_type, secret, created_at"
+	deliveryColumns = "id, subscription_id, event_id, event_type, CAST(payload AS BLOB) AS payload, status, attempts, next_attempt_at, last_status_code, last_error, created_at, updated_at"
+)
+
+// subscriptionRow scans the events of a subscription, stored as a JSON array.
+type subscriptionRow struct {
+	domain.WebhookSubscription
+	Events string `db:"events"`
+}
+
+func (row subscriptionRow) subscription() (domain.WebhookSubscription, error) {
+	sub := row.WebhookSubscription
+	if err := json.Unmarshal([]byte(row.Events), &sub.Events); err != nil {
+		return domain.WebhookSubscription{}, fmt.Errorf("decode events of webhook subscription %d: %w", sub.ID, err)
+	}
+	return sub, nil
+}
+
+func (w *WebhookRepo) CreateSubscription(ctx context.Context, sub domain.WebhookSubscription) (_ domain.WebhookSubscription, err error) {
+	query := `
+		INSERT INTO webhook_subscriptions (url, events, reviewable_type, secret)
+		VALUES ($1, $2, $3, $4)
+		RETURNING ` + webhookColumns
+	ctx, span := startSpan(ctx, "webhook_subscriptions.create", query)
+	defer func() { endSpan(span, err) }()
+
+	events, err := json.Marshal(sub.Events)
+	if err != nil {
+		return domain.WebhookSubscription{}, fmt.Errorf("marshal webhook events: %w", err)
+	}
+	if sub.Events == nil {
+		events = []byte("[]")
+	}
+	var row subscriptionRow
+	if err := w.db.GetContext(ctx, &row, query, sub.URL, string(events), sub.ReviewableType, sub.Secret); err != nil {
+		return domain.WebhookSubscription{}, fmt.Errorf("create webhook subscription: %w", err)
+	}
+	return row.subscription()
+}
+
+func (w *WebhookRepo) GetSubscription(ctx context.Context, id int) (_ domain.WebhookSubscription, err error) {
+	query := "SELECT " + webhookColumns + " FROM webhook_subscriptions WHERE id = $1"
+	ctx, span := startSpan(ctx, "webhook_subscriptions.get", query)
+	defer func() { endSpan(span, err) }()
+
+	var row subscriptionRow
+	if err := w.db.GetContext(ctx, &row, query, id); err != nil {
+		return domain.WebhookSubscription{}, fmt.Errorf("get webhook subscription: %w", err)
+	}
+	return row.subscription()
+}
+
+func (w *WebhookRepo) ListSubscriptions(ctx context.Context) (subs []domain.WebhookSubscription, err error) {
+	query := "SELECT " + webhookColumns + " FROM webhook_subscriptions ORDER BY id"
+	ctx, span := startSpan(ctx, "webhook_subscriptions.list", query)
+	defer func() { endSpan(span, err) }()
+
+	var rows []subscriptionRow
+	if err := w.db.SelectContext(ctx, &rows, query); err != nil {
+		return nil, fmt.Errorf("list webhook subscriptions: %w", err)
+	}
+	for _, row := range rows {
+		sub, err := row.subscription()
+		if err != nil {
+			return nil, err
+		}
+		subs = append(subs, sub)
+	}
+	return subs, nil
+}
+
+func (w *WebhookRepo) DeleteSubscription(ctx context.Context, id int) (err error) {
+	query := "DELETE FROM webhook_subscriptions WHERE id = $1"
+	ctx, span := startSpan(ctx, "webhook_subscriptions.delete", query)
+	defer func() { endSpan(span, err) }()
+
+	res, err := w.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("delete webhook subscription: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("delete webhook subscription: %w", err)
+	} else if n == 0 {
+		return fmt.Errorf("delete webhook subscription: %w", sql.ErrNoRows)
+	}
+	return nil
+}
+
+func (w *WebhookRepo) EnqueueDeliveries(ctx context.Context, event domain.Event, subscriptionIDs []int) (err error) {
+	if len(subscriptionIDs) == 0 {
+		return nil
+	}
+	query := `
+		INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, payload)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (subscription_id, event_id) DO NOTHING
+	`
+	ctx, span := startSpan(ctx, "webhook_deliveries.enqueue", query)
+	defer func() { endSpan(span, err) }()
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("marshal event %d: %w", event.ID, err)
+	}
+	tx, err := w.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, id := range subscriptionIDs {
+		if _, err := tx.ExecContext(ctx, query, id, event.ID, event.Type, string(data)); err != nil {
+			return fmt.Errorf("enqueue webhook deliveries: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// ClaimDueDeliveries claims in a single statement; SQLite serializes writers,
+// so concurrent claims never return the same delivery.
+func (w *WebhookRepo) ClaimDueDeliveries(ctx context.Context, now, until time.Time, limit int) (deliveries []domain.WebhookDelivery, err error) {
+	query := `
+		UPDATE webhook_deliveries SET next_attempt_at = $2
+		WHERE id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status = 'pending' AND next_attempt_at <= $1
+			ORDER BY next_attempt_at, id
+			LIMIT $3
+		)
+		RETURNING ` + deliveryColumns
+	ctx, span := startSpan(ctx, "webhook_deliveries.claim_due", query)
+	defer func() { endSpan(span, err) }()
+
+	if err := w.db.SelectContext(ctx, &deliveries, query, now.UTC().Format(sqliteTimeLayout), until.UTC().Format(sqliteTimeLayout), limit); err != nil {
+		return nil, fmt.Errorf("claim due webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+func (w *WebhookRepo) ListDeliveries(ctx context.Context, subscriptionID int, status string, limit int) (deliveries []domain.WebhookDelivery, err error) {
+	query := `
+		SELECT ` + deliveryColumns + `
+		FROM webhook_deliveries
+		WHERE subscription_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY id DESC
+		LIMIT $3
+	`
+	ctx, span := startSpan(ctx, "webhook_deliveries.list", query)
+	defer func() { endSpan(span, err) }()
+
+	if err := w.db.SelectContext(ctx, &deliveries, query, subscriptionID, status, limit); err != nil {
+		return nil, fmt.Errorf("list webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+func (w *WebhookRepo) GetDelivery(ctx context.Context, id int) (d domain.WebhookDelivery, err error) {
+	query := "SELECT " + deliveryColumns + " FROM webhook_deliveries WHERE id = $1"
+	ctx, span := startSpan(ctx, "webhook_deliveries.get", query)
+	defer func() { endSpan(span, err) }()
+
+	if err := w.db.GetContext(ctx, &d, query, id); err != nil {
+		return domain.WebhookDelivery{}, fmt.Errorf("get webhook delivery: %w", err)
+	}
+	return d, nil
+}
+
+func (w *WebhookRepo) UpdateDelivery(ctx context.Context, d domain.WebhookDelivery, nextAttemptAt time.Time) (err error) {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = $2, attempts = $3, last_status_code = $4, last_error = $5, next_attempt_at = $6,
+			updated_at = strftime('%Y-%m-%dT%H:%M:%fZ', 'now')
+		WHERE id = $1
+	`
+	ctx, span := startSpan(ctx, "webhook_deliveries.update", query)
+	defer func() { endSpan(span, err) }()
+
+	res, err := w.db.ExecContext(ctx, query, d.ID, d.Status, d.Attempts, d.LastStatusCode, d.LastError, nextAttemptAt.UTC().Format(sqliteTimeLayout))
+	if err != nil {
+		return fmt.Errorf("update webhook delivery: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("update webhook delivery: %w", err)
+	} else if n == 0 {
+		return fmt.Errorf("update webhook delivery: %w", sql.ErrNoRows)
+	}
+	return nil
+}
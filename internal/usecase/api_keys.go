@@ -0,0 +1,268 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"eve/domain"
+)
+
+// APIKeyRepository stores the API keys of backend services.
+type APIKeyRepository interface {
+	// CreateAPIKey stores a key and returns it with its ID and CreatedAt.
+	CreateAPIKey(ctx context.Context, key domain.APIKey) (domain.APIKey, error)
+
+	// GetAPIKeyByPrefix loads the key with the given prefix. A missing one
+	// yields an error wrapping sql.ErrNoRows.
+	GetAPIKeyByPrefix(ctx context.Context, prefix string) (domain.APIKey, error)
+
+	// ListAPIKeys returns every key ordered by ID.
+	ListAPIKeys(ctx context.Context) ([]domain.APIKey, error)
+
+	// DeleteAPIKey removes a key. A missing one yields an error wrapping
+	// sql.ErrNoRows.
+	DeleteAPIKey(ctx context.Context, id int) error
+
+	// TouchAPIKey records that key id was used at the given time. A missing
+	// key yields an error wrapping sql.ErrNoRows.
+	TouchAPIKey(ctx context.Context, id int, at time.Time) error
+}
+
+// apiKeyPermissions are the permissions keys may be granted.
+var apiKeyPermissions = []string{
+	domain.PermissionReviewsRead,
+	domain.PermissionReviewsWrite,
+	domain.PermissionResponsesWrite,
+}
+
+// API keys look like "eve_<prefix>_<secret>": 6 random bytes identify the
+// key and 32 more make it unguessable, both hex-encoded.
+const (
+	apiKeyScheme       = "eve_"
+	apiKeyPrefixBytes  = 6
+	apiKeySecretBytes  = 32
+	apiKeyPrefixLength = len(apiKeyScheme) + 2*apiKeyPrefixBytes
+)
+
+// apiKeyTouchInterval is how stale LastUsedAt may get, so that busy keys do
+// not write on every request.
+const apiKeyTouchInterval = time.Minute
+
+// hashAPIKey returns the digest a key is stored as. Keys are long random
+// strings, so a fast hash is enough.
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// apiKeyContextKey is the context key of the API key a request was
+// authenticated with.
+type apiKeyContextKey struct{}
+
+// WithAPIKey returns a copy of ctx carrying the API key the request was
+// authenticated with. Use-cases keep a key restricted to a reviewable type
+// away from reviews of other types.
+func WithAPIKey(ctx context.Context, key domain.APIKey) context.Context {
+	return context.WithValue(ctx, apiKeyContextKey{}, key)
+}
+
+// APIKey returns the API key stored in ctx by WithAPIKey.
+func APIKey(ctx context.Context) (domain.APIKey, bool) {
+	key, ok := ctx.Value(apiKeyContextKey{}).(domain.APIKey)
+	return key, ok
+}
+
+// CreateAPIKeyUseCase issues an API key. Only admins may call it.
+type CreateAPIKeyUseCase struct {
+	users   UserRepository
+	types   ReviewableTypeRepository
+	keys    APIKeyRepository
+	metrics Metrics
+	log     *slog.Logger
+}
+
+// NewCreateAPIKeyUseCase constructs a new CreateAPIKeyUseCase.
+func NewCreateAPIKeyUseCase(u UserRepository, t ReviewableTypeRepository, k APIKeyRepository, m Metrics, l *slog.Logger) *CreateAPIKeyUseCase {
+	return &CreateAPIKeyUseCase{users: u, types: t, keys: k, metrics: m, log: l}
+}
+
+// Execute stores a new key on behalf of actorID and returns it, including the
+// key itself, which is not shown again.
+func (uc *CreateAPIKeyUseCase) Execute(ctx context.Context, req domain.CreateAPIKeyRequest, actorID int) (_ domain.APIKey, err error) {
+	ctx, end := track(ctx, uc.metrics, uc.log, "create_api_key")
+	defer end(&err)
+
+	if err := requireRole(ctx, uc.users, actorID, domain.RoleAdmin); err != nil {
+		return domain.APIKey{}, err
+	}
+	if strings.TrimSpace(req.Name) == "" {
+		return domain.APIKey{}, invalidInput("name is required")
+	}
+	if len(req.Permissions) == 0 {
+		return domain.APIKey{}, invalidInput("permissions are required, want some of %v", apiKeyPermissions)
+	}
+	for _, p := range req.Permissions {
+		if !slices.Contains(apiKeyPermissions, p) {
+			return domain.APIKey{}, invalidInput("unknown permission %q, want one of %v", p, apiKeyPermissions)
+		}
+	}
+	if req.UserID == 0 {
+		req.UserID = actorID
+	}
+	if _, err := uc.users.GetByID(ctx, req.UserID); err != nil {
+		return domain.APIKey{}, notFound(err, "get user", "user %d not found", req.UserID)
+	}
+	if req.ReviewableType != "" {
+		if _, err := lookupType(ctx, uc.types, req.ReviewableType); err != nil {
+			return domain.APIKey{}, err
+		}
+	}
+
+	b := make([]byte, apiKeyPrefixBytes+apiKeySecretBytes)
+	if _, err := rand.Read(b); err != nil {
+		return domain.APIKey{}, fmt.Errorf("generate api key: %w", err)
+	}
+	prefix := apiKeyScheme + hex.EncodeToString(b[:apiKeyPrefixBytes])
+	secret := prefix + "_" + hex.EncodeToString(b[apiKeyPrefixBytes:])
+
+	permissions := slices.Clone(req.Permissions)
+	slices.Sort(permissions)
+	key, err := uc.keys.CreateAPIKey(ctx, domain.APIKey{
+		Name:           req.Name,
+		Prefix:         prefix,
+		Hash:           hashAPIKey(secret),
+		UserID:         req.UserID,
+		Permissions:    slices.Compact(permissions),
+		ReviewableType: req.ReviewableType,
+		CreatedBy:      actorID,
+	})
+	if err != nil {
+		return domain.APIKey{}, fmt.Errorf("create api key: %w", err)
+	}
+	key.Key = secret
+	uc.log.InfoContext(ctx, "api key created",
+		slog.Int("api_key_id", key.ID),
+		slog.String("prefix", key.Prefix),
+		slog.Int("user_id", key.UserID),
+		slog.Int("actor_id", actorID),
+	)
+	return key, nil
+}
+
+// ListAPIKeysUseCase returns the API keys, without the keys themselves. Only
+// admins may call it.
+type ListAPIKeysUseCase struct {
+	users   UserRepository
+	keys    APIKeyRepository
+	metrics Metrics
+	log     *slog.Logger
+}
+
+// NewListAPIKeysUseCase constructs a new ListAPIKeysUseCase.
+func NewListAPIKeysUseCase(u UserRepository, k APIKeyRepository, m Metrics, l *slog.Logger) *ListAPIKeysUseCase {
+	return &ListAPIKeysUseCase{users: u, keys: k, metrics: m, log: l}
+}
+
+// Execute returns every key ordered by ID.
+func (uc *ListAPIKeysUseCase) Execute(ctx context.Context, actorID int) (_ []domain.APIKey, err error) {
+	ctx, end := track(ctx, uc.metrics, uc.log, "list_api_keys")
+	defer end(&err)
+
+	if err := requireRole(ctx, uc.users, actorID, domain.RoleAdmin); err != nil {
+		return nil, err
+	}
+	keys, err := uc.keys.ListAPIKeys(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list api keys: %w", err)
+	}
+	return keys, nil
+}
+
+// DeleteAPIKeyUseCase revokes an API key. Only admins may call it.
+type DeleteAPIKeyUseCase struct {
+	users   UserRepository
+	keys    APIKeyRepository
+	metrics Metrics
+	log     *slog.Logger
+}
+
+// NewDeleteAPIKeyUseCase constructs a new DeleteAPIKeyUseCase.
+func NewDeleteAPIKeyUseCase(u UserRepository, k APIKeyRepository, m Metrics, l *slog.Logger) *DeleteAPIKeyUseCase {
+	return &DeleteAPIKeyUseCase{users: u, keys: k, metrics: m, log: l}
+}
+
+// Execute deletes key id on behalf of actorID; requests made with it are
+// refused from then on.
+func (uc *DeleteAPIKeyUseCase) Execute(ctx context.Context, id, actorID int) (err error) {
+	ctx, end := track(ctx, uc.metrics, uc.log, "delete_api_key")
+	defer end(&err)
+
+	if err := requireRole(ctx, uc.users, actorID, domain.RoleAdmin); err != nil {
+		return err
+	}
+	if err := uc.keys.DeleteAPIKey(ctx, id); err != nil {
+		return notFound(err, "delete api key", "api key %d not found", id)
+	}
+	uc.log.InfoContext(ctx, "api key deleted",
+		slog.Int("api_key_id", id),
+		slog.Int("actor_id", actorID),
+	)
+	return nil
+}
+
+// AuthenticateAPIKeyUseCase checks the API key a request was made with.
+type AuthenticateAPIKeyUseCase struct {
+	keys    APIKeyRepository
+	metrics Metrics
+	log     *slog.Logger
+}
+
+// NewAuthenticateAPIKeyUseCase constructs a new AuthenticateAPIKeyUseCase.
+func NewAuthenticateAPIKeyUseCase(k APIKeyRepository, m Metrics, l *slog.Logger) *AuthenticateAPIKeyUseCase {
+	return &AuthenticateAPIKeyUseCase{keys: k, metrics: m, log: l}
+}
+
+// Execute returns the stored key matching secret, or an error matching
+// ErrUnauthenticated when there is none. It records when the key was used,
+// at most once per minute; failing to do so does not fail the request.
+func (uc *AuthenticateAPIKeyUseCase) Execute(ctx context.Context, secret string) (_ domain.APIKey, err error) {
+	ctx, end := track(ctx, uc.metrics, uc.log, "authenticate_api_key")
+	defer end(&err)
+
+	if len(secret) <= apiKeyPrefixLength || !strings.HasPrefix(secret, apiKeyScheme) || secret[apiKeyPrefixLength] != '_' {
+		return domain.APIKey{}, &unauthenticatedError{msg: "invalid API key"}
+	}
+	key, err := uc.keys.GetAPIKeyByPrefix(ctx, secret[:apiKeyPrefixLength])
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.APIKey{}, &unauthenticatedError{msg: "invalid API key"}
+	}
+	if err != nil {
+		return domain.APIKey{}, fmt.Errorf("get api key: %w", err)
+	}
+	if subtle.ConstantTimeCompare([]byte(hashAPIKey(secret)), []byte(key.Hash)) != 1 {
+		return domain.APIKey{}, &unauthenticatedError{msg: "invalid API key"}
+	}
+
+	now := time.Now()
+	stale := key.LastUsedAt == nil
+	if !stale {
+		last, err := time.Parse(time.RFC3339, *key.LastUsedAt)
+		stale = err != nil || now.Sub(last) >= apiKeyTouchInterval
+	}
+	if stale {
+		if err := uc.keys.TouchAPIKey(ctx, key.ID, now); err != nil {
+			uc.log.WarnContext(ctx, "record api key use", slog.Int("api_key_id", key.ID), slog.String("error", err.Error()))
+		}
+	}
+	return key, nil
+}
@@ -0,0 +1,143 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"eve/domain"
+	"eve/internal/logging"
+	"eve/internal/repository/memory"
+	"eve/internal/usecase"
+)
+
+func TestCreateAPIKeyUseCase(t *testing.T) {
+	ctx := context.Background()
+	users := memory.NewUserRepo()
+	admin := seedUser(t, users, "admin@example.com", domain.RoleAdmin)
+	service := seedUser(t, users, "service@example.com", domain.RoleUser)
+	keys := memory.NewAPIKeyRepo()
+	uc := usecase.NewCreateAPIKeyUseCase(users, seedTypes(t, "product"), keys, usecase.NopMetrics{}, logging.Nop())
+
+	valid := domain.CreateAPIKeyRequest{Name: "catalog sync", Permissions: []string{domain.PermissionReviewsRead}}
+	for _, tt := range []struct {
+		name    string
+		req     domain.CreateAPIKeyRequest
+		actorID int
+		want    error
+	}{
+		{"not an admin", valid, service, usecase.ErrForbidden},
+		{"no name", domain.CreateAPIKeyRequest{Permissions: valid.Permissions}, admin, usecase.ErrInvalidInput},
+		{"no permissions", domain.CreateAPIKeyRequest{Name: "sync"}, admin, usecase.ErrInvalidInput},
+		{"unknown permission", domain.CreateAPIKeyRequest{Name: "sync", Permissions: []string{"users:write"}}, admin, usecase.ErrInvalidInput},
+		{"unknown user", domain.CreateAPIKeyRequest{Name: "sync", Permissions: valid.Permissions, UserID: 999}, admin, usecase.ErrNotFound},
+		{"unknown type", domain.CreateAPIKeyRequest{Name: "sync", Permissions: valid.Permissions, ReviewableType: "hotel"}, admin, usecase.ErrInvalidInput},
+	} {
+		if _, err := uc.Execute(ctx, tt.req, tt.actorID); !errors.Is(err, tt.want) {
+			t.Errorf("Execute(%s) error = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+
+	req := domain.CreateAPIKeyRequest{
+		Name:           "catalog sync",
+		UserID:         service,
+		Permissions:    []string{domain.PermissionReviewsWrite, domain.PermissionReviewsRead, domain.PermissionReviewsWrite},
+		ReviewableType: "product",
+	}
+	key, err := uc.Execute(ctx, req, admin)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if !strings.HasPrefix(key.Key, key.Prefix+"_") || !strings.HasPrefix(key.Prefix, "eve_") {
+		t.Errorf("Execute() key = %q with prefix %q, want eve_<prefix>_<secret>", key.Key, key.Prefix)
+	}
+	if key.UserID != service || key.CreatedBy != admin || key.ReviewableType != "product" {
+		t.Errorf("Execute() = %+v, want the service's product key created by the admin", key)
+	}
+	if len(key.Permissions) != 2 || key.Permissions[0] != domain.PermissionReviewsRead || key.Permissions[1] != domain.PermissionReviewsWrite {
+		t.Errorf("Permissions = %v, want them sorted without duplicates", key.Permissions)
+	}
+
+	stored, err := keys.GetAPIKeyByPrefix(ctx, key.Prefix)
+	if err != nil {
+		t.Fatalf("GetAPIKeyByPrefix() error = %v", err)
+	}
+	if stored.Hash == "" || strings.Contains(stored.Hash, key.Key) || stored.Key != "" {
+		t.Errorf("stored key = %+v, want only a hash of the key", stored)
+	}
+
+	own, err := uc.Execute(ctx, valid, admin)
+	if err != nil || own.UserID != admin {
+		t.Errorf("Execute(no user) = %+v, %v; want a key acting as the admin", own, err)
+	}
+}
+
+func TestAuthenticateAPIKeyUseCase(t *testing.T) {
+	ctx := context.Background()
+	users := memory.NewUserRepo()
+	admin := seedUser(t, users, "admin@example.com", domain.RoleAdmin)
+	keys := memory.NewAPIKeyRepo()
+	create := usecase.NewCreateAPIKeyUseCase(users, seedTypes(t), keys, usecase.NopMetrics{}, logging.Nop())
+	auth := usecase.NewAuthenticateAPIKeyUseCase(keys, usecase.NopMetrics{}, logging.Nop())
+	remove := usecase.NewDeleteAPIKeyUseCase(users, keys, usecase.NopMetrics{}, logging.Nop())
+
+	created, err := create.Execute(ctx, domain.CreateAPIKeyRequest{Name: "sync", Permissions: []string{domain.PermissionReviewsRead}}, admin)
+	if err != nil {
+		t.Fatalf("create key: %v", err)
+	}
+
+	for _, secret := range []string{"", "nonsense", created.Prefix, created.Key + "0", "eve_000000000000" + created.Key[len(created.Prefix):]} {
+		if _, err := auth.Execute(ctx, secret); !errors.Is(err, usecase.ErrUnauthenticated) {
+			t.Errorf("Execute(%q) error = %v, want ErrUnauthenticated", secret, err)
+		}
+	}
+
+	key, err := auth.Execute(ctx, created.Key)
+	if err != nil || key.ID != created.ID || !key.Allows(domain.PermissionReviewsRead) || key.Allows(domain.PermissionReviewsWrite) {
+		t.Fatalf("Execute() = %+v, %v; want the read-only key", key, err)
+	}
+	stored, _ := keys.GetAPIKeyByPrefix(ctx, created.Prefix)
+	if stored.LastUsedAt == nil {
+		t.Fatal("LastUsedAt = nil after use")
+	}
+	used, err := time.Parse(time.RFC3339, *stored.LastUsedAt)
+	if err != nil || time.Since(used) > time.Minute {
+		t.Errorf("LastUsedAt = %q, want about now", *stored.LastUsedAt)
+	}
+
+	if err := remove.Execute(ctx, created.ID, admin); err != nil {
+		t.Fatalf("delete key: %v", err)
+	}
+	if _, err := auth.Execute(ctx, created.Key); !errors.Is(err, usecase.ErrUnauthenticated) {
+		t.Errorf("Execute(deleted key) error = %v, want ErrUnauthenticated", err)
+	}
+	if err := remove.Execute(ctx, created.ID, admin); !errors.Is(err, usecase.ErrNotFound) {
+		t.Errorf("delete missing key error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestAPIKeyTypeScope(t *testing.T) {
+	reviews := memory.NewReviewRepo()
+	product := seedReview(t, reviews, "product", 1)
+	hotel := seedReview(t, reviews, "hotel", 1)
+	get := usecase.NewGetReviewUseCase(reviews, memory.NewUserRepo(), usecase.NopMetrics{}, logging.Nop())
+	list := usecase.NewListReviewsUseCase(reviews, usecase.NopMetrics{}, logging.Nop())
+
+	ctx := usecase.WithAPIKey(context.Background(), domain.APIKey{Prefix: "eve_000000000001", ReviewableType: "product"})
+	if _, _, err := get.Execute(ctx, product, 0); err != nil {
+		t.Errorf("GetReview(product) error = %v", err)
+	}
+	if _, _, err := get.Execute(ctx, hotel, 0); !errors.Is(err, usecase.ErrForbidden) {
+		t.Errorf("GetReview(hotel) error = %v, want ErrForbidden", err)
+	}
+	if _, err := list.Execute(ctx, "hotel", 1, false); !errors.Is(err, usecase.ErrForbidden) {
+		t.Errorf("ListReviews(hotel) error = %v, want ErrForbidden", err)
+	}
+
+	unscoped := usecase.WithAPIKey(context.Background(), domain.APIKey{Prefix: "eve_000000000002"})
+	if _, _, err := get.Execute(unscoped, hotel, 0); err != nil {
+		t.Errorf("GetReview(hotel) with an unscoped key error = %v", err)
+	}
+}
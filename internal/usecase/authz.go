@@ -0,0 +1,81 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+
+	"eve/domain"
+)
+
+// requireRole returns an error matching ErrForbidden unless the user exists
+// and has one of the given roles.
+func requireRole(ctx context.Context, users UserRepository, userID int, roles ...string) error {
+	u, err := users.GetByID(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return forbidden("user %d is not allowed to do this", userID)
+	}
+	if err != nil {
+		return fmt.Errorf("get user: %w", err)
+	}
+	if !slices.Contains(roles, u.Role) {
+		return forbidden("user %d is not allowed to do this", userID)
+	}
+	return nil
+}
+
+// requireAuthorOrRole is requireRole that also lets the author of the content through.
+func requireAuthorOrRole(ctx context.Context, users UserRepository, userID, authorID int, roles ...string) error {
+	if userID == authorID {
+		return nil
+	}
+	return requireRole(ctx, users, userID, roles...)
+}
+
+// checkReviewVisible hides a pending review from everyone but its author,
+// moderators and admins: they get an error matching ErrNotFound, as if it
+// did not exist. viewerID is 0 for anonymous callers.
+func checkReviewVisible(ctx context.Context, users UserRepository, review domain.Review, viewerID int) error {
+	if review.Status != domain.ReviewStatusPending {
+		return nil
+	}
+	err := requireAuthorOrRole(ctx, users, viewerID, review.UserID, domain.RoleModerator, domain.RoleAdmin)
+	if errors.Is(err, ErrForbidden) {
+		return &notFoundError{msg: fmt.Sprintf("review %d not found", review.ID)}
+	}
+	return err
+}
+
+// checkTypeScope returns an error matching ErrForbidden when ctx carries an
+// API key restricted to another reviewable type.
+func checkTypeScope(ctx context.Context, reviewableType string) error {
+	if key, ok := APIKey(ctx); ok && !key.AllowsType(reviewableType) {
+		return forbidden("API key %s is restricted to %s reviews", key.Prefix, key.ReviewableType)
+	}
+	return nil
+}
+
+// scopedType returns reviewableType, or the type the API key in ctx is
+// restricted to when reviewableType is empty, so that listings by such a key
+// only cover its type.
+func scopedType(ctx context.Context, reviewableType string) string {
+	if key, ok := APIKey(ctx); ok && reviewableType == "" {
+		return key.ReviewableType
+	}
+	return reviewableType
+}
+
+// checkReviewScope is checkTypeScope for the type of review reviewID, which
+// is only loaded when ctx carries an API key restricted to a type.
+func checkReviewScope(ctx context.Context, reviews ReviewRepository, reviewID int) error {
+	if key, ok := APIKey(ctx); !ok || key.ReviewableType == "" {
+		return nil
+	}
+	review, err := reviews.GetByID(ctx, reviewID)
+	if err != nil {
+		return notFound(err, "get review", "review %d not found", reviewID)
+	}
+	return checkTypeScope(ctx, review.ReviewableType)
+}
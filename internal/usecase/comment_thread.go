@@ -0,0 +1,37 @@
+package usecase
+
+import "eve/domain"
+
+// MaxCommentDepth is the deepest reply level allowed; top-level comments have depth 0.
+const MaxCommentDepth = 5
+
+// threadComments orders comments depth-first: each comment is followed by its
+// replies, siblings keep their (oldest first) input order. Replies whose parent
+// is not in the list are treated as top-level so nothing is dropped.
+func threadComments(comments []domain.ReviewComment) []domain.ReviewComment {
+	present := make(map[int]bool, len(comments))
+	for _, c := range comments {
+		present[c.ID] = true
+	}
+
+	children := make(map[int][]domain.ReviewComment)
+	var roots []domain.ReviewComment
+	for _, c := range comments {
+		if c.ParentID != nil && present[*c.ParentID] {
+			children[*c.ParentID] = append(children[*c.ParentID], c)
+		} else {
+			roots = append(roots, c)
+		}
+	}
+
+	ordered := make([]domain.ReviewComment, 0, len(comments))
+	var walk func([]domain.ReviewComment)
+	walk = func(level []domain.ReviewComment) {
+		for _, c := range level {
+			ordered = append(ordered, c)
+			walk(children[c.ID])
+		}
+	}
+	walk(roots)
+	return ordered
+}
@@ -0,0 +1,106 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"eve/domain"
+	"eve/internal/logging"
+	"eve/internal/repository/memory"
+	"eve/internal/usecase"
+)
+
+func TestCreateCommentReplies(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewReviewRepo()
+	reviewID := seedReview(t, repo, "product", 1)
+	otherReviewID := seedReview(t, repo, "product", 2)
+	uc := usecase.NewCreateCommentUseCase(repo, memory.NewUserRepo(), usecase.NopMetrics{}, logging.Nop())
+
+	// Build a chain root -> reply -> ... up to the maximum depth.
+	chain := make([]int, 0, usecase.MaxCommentDepth+1)
+	parent := 0
+	for depth := 0; depth <= usecase.MaxCommentDepth; depth++ {
+		id, err := uc.Execute(ctx, domain.CreateCommentRequest{ReviewID: reviewID, ParentID: parent, Body: "c"}, 1)
+		if err != nil {
+			t.Fatalf("Execute() at depth %d error = %v", depth, err)
+		}
+		c, _ := repo.GetComment(ctx, id)
+		if c.Depth != depth {
+			t.Errorf("comment %d depth = %d, want %d", id, c.Depth, depth)
+		}
+		chain = append(chain, id)
+		parent = id
+	}
+
+	tests := []struct {
+		name    string
+		req     domain.CreateCommentRequest
+		wantErr error
+	}{
+		{
+			name:    "too deep",
+			req:     domain.CreateCommentRequest{ReviewID: reviewID, ParentID: chain[len(chain)-1], Body: "c"},
+			wantErr: usecase.ErrInvalidInput,
+		},
+		{
+			name:    "parent on another review",
+			req:     domain.CreateCommentRequest{ReviewID: otherReviewID, ParentID: chain[0], Body: "c"},
+			wantErr: usecase.ErrInvalidInput,
+		},
+		{
+			name:    "missing parent",
+			req:     domain.CreateCommentRequest{ReviewID: reviewID, ParentID: 999, Body: "c"},
+			wantErr: usecase.ErrNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := uc.Execute(ctx, tt.req, 1); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Execute() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetReviewReturnsThreadOrder(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewReviewRepo()
+	reviewID := seedReview(t, repo, "product", 1)
+	create := usecase.NewCreateCommentUseCase(repo, memory.NewUserRepo(), usecase.NopMetrics{}, logging.Nop())
+
+	add := func(parent int, body string) int {
+		t.Helper()
+		id, err := create.Execute(ctx, domain.CreateCommentRequest{ReviewID: reviewID, ParentID: parent, Body: body}, 1)
+		if err != nil {
+			t.Fatalf("add %s: %v", body, err)
+		}
+		return id
+	}
+	a := add(0, "a")
+	b := add(0, "b")
+	add(a, "a.1")
+	add(b, "b.1")
+	a2 := add(a, "a.2")
+	add(a2, "a.2.1")
+
+	uc := usecase.NewGetReviewUseCase(repo, memory.NewUserRepo(), usecase.NopMetrics{}, logging.Nop())
+	_, comments, err := uc.Execute(ctx, reviewID, 0)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	want := []struct {
+		body  string
+		depth int
+	}{{"a", 0}, {"a.1", 1}, {"a.2", 1}, {"a.2.1", 2}, {"b", 0}, {"b.1", 1}}
+	if len(comments) != len(want) {
+		t.Fatalf("got %d comments, want %d", len(comments), len(want))
+	}
+	for i, w := range want {
+		if comments[i].Body != w.body || comments[i].Depth != w.depth {
+			t.Errorf("comments[%d] = %q at depth %d, want %q at depth %d", i, comments[i].Body, comments[i].Depth, w.body, w.depth)
+		}
+	}
+}
@@ -1,21 +1,34 @@
 package usecase
 
-import "eve/domain"
+import (
+	"context"
+	"log/slog"
+
+	"eve/domain"
+)
 
 type CreateUserUseCase struct {
 	repo           UserRepository
 	passwordHasher PasswordHasher
+	hashing        *HashLimiter
+	metrics        Metrics
+	log            *slog.Logger
 }
 
-func NewCreateUserUseCase(r UserRepository, h PasswordHasher) *CreateUserUseCase {
-	return &CreateUserUseCase{repo: r, passwordHasher: h}
+func NewCreateUserUseCase(r UserRepository, h PasswordHasher, hl *HashLimiter, m Metrics, l *slog.Logger) *CreateUserUseCase {
+	return &CreateUserUseCase{repo: r, passwordHasher: h, hashing: hl, metrics: m, log: l}
 }
 
-func (cu *CreateUserUseCase) Execute(user domain.User) error {
-	hashedPassword, err := cu.passwordHasher.Hash(user.Password)
+func (cu *CreateUserUseCase) Execute(ctx context.Context, user domain.User) (err error) {
+	ctx, end := track(ctx, cu.metrics, cu.log, "create_user")
+	defer end(&err)
+
+	hashedPassword, err := limited(ctx, cu.hashing, func() (string, error) { return cu.passwordHasher.Hash(user.Password) })
 	if err != nil {
 		return err
 	}
 	user.Password = hashedPassword
-	return cu.repo.Save(user)
+	// Elevated roles are never granted through self sign-up.
+	user.Role = domain.RoleUser
+	return cu.repo.Save(ctx, user)
 }
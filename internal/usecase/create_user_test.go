@@ -0,0 +1,92 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"eve/domain"
+	"eve/internal/infrastructure"
+	"eve/internal/logging"
+	"eve/internal/repository/memory"
+	"eve/internal/usecase"
+)
+
+type failingHasher struct{}
+
+func (failingHasher) Hash(string) (string, error)          { return "", errors.New("hash failed") }
+func (failingHasher) Compare(string, string) (bool, error) { return false, errors.New("hash failed") }
+func (failingHasher) NeedsRehash(string) bool              { return false }
+
+func TestCreateUserUseCase(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing []domain.User
+		hasher   usecase.PasswordHasher
+		user     domain.User
+		wantErr  bool
+	}{
+		{
+			name:   "stores hashed password",
+			hasher: infrastructure.NewFakeHasher(),
+			user:   domain.User{Email: "a@example.com", Password: "secret"},
+		},
+		{
+			name:   "ignores requested role",
+			hasher: infrastructure.NewFakeHasher(),
+			user:   domain.User{Email: "a@example.com", Password: "secret", Role: domain.RoleAdmin},
+		},
+		{
+			name:     "duplicate email",
+			existing: []domain.User{{Email: "a@example.com", Password: "x"}},
+			hasher:   infrastructure.NewFakeHasher(),
+			user:     domain.User{Email: "a@example.com", Password: "secret"},
+			wantErr:  true,
+		},
+		{
+			name:    "hasher failure",
+			hasher:  failingHasher{},
+			user:    domain.User{Email: "a@example.com", Password: "secret"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := memory.NewUserRepo()
+			for _, u := range tt.existing {
+				if err := repo.Save(ctx, u); err != nil {
+					t.Fatalf("seed user: %v", err)
+				}
+			}
+			metrics := &spyMetrics{}
+			uc := usecase.NewCreateUserUseCase(repo, tt.hasher, usecase.NewHashLimiter(1), metrics, logging.Nop())
+
+			err := uc.Execute(ctx, tt.user)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := metrics.failures["create_user"]; (got == 1) != tt.wantErr {
+				t.Errorf("recorded %d failures, wantErr %v", got, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			users, _ := repo.GetAll(ctx)
+			if len(users) != 1 {
+				t.Fatalf("got %d users, want 1", len(users))
+			}
+			if users[0].Role != domain.RoleUser {
+				t.Errorf("stored role = %q, want %q", users[0].Role, domain.RoleUser)
+			}
+			if users[0].Password == tt.user.Password {
+				t.Error("password stored in plain text")
+			}
+			if ok, err := tt.hasher.Compare(tt.user.Password, users[0].Password); !ok || err != nil {
+				t.Errorf("stored hash does not match password: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package usecase
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"eve/domain"
+)
+
+// ErrInvalidInput is matched (via errors.Is) by errors caused by invalid
+// caller input, so delivery layers can report them as client errors.
+var ErrInvalidInput = errors.New("invalid input")
+
+// ErrNotFound is matched by errors reporting that a referenced entity does not exist.
+var ErrNotFound = errors.New("not found")
+
+// ErrForbidden is matched by errors reporting that the caller may not perform an action.
+var ErrForbidden = errors.New("forbidden")
+
+// ErrUnauthenticated is matched by errors reporting that the caller's
+// credentials are wrong.
+var ErrUnauthenticated = errors.New("unauthenticated")
+
+// ErrPreconditionFailed is matched by errors refusing a change because the
+// entity changed since the caller read it.
+var ErrPreconditionFailed = errors.New("precondition failed")
+
+// ErrThrottled is matched by errors refusing a request because the caller
+// made too many; RetryAfter tells how long to wait.
+var ErrThrottled = errors.New("throttled")
+
+// inputError carries a validation message and matches ErrInvalidInput.
+type inputError struct {
+	msg string
+}
+
+func (e *inputError) Error() string {
+	return e.msg
+}
+
+func (e *inputError) Is(target error) bool {
+	return target == ErrInvalidInput
+}
+
+// invalidInput formats a validation error that matches ErrInvalidInput.
+func invalidInput(format string, args ...any) error {
+	return &inputError{msg: fmt.Sprintf(format, args...)}
+}
+
+// notFoundError reports a missing entity, matches ErrNotFound and unwraps to
+// the storage error that caused it.
+type notFoundError struct {
+	msg string
+	err error
+}
+
+func (e *notFoundError) Error() string {
+	return e.msg
+}
+
+func (e *notFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+func (e *notFoundError) Unwrap() error {
+	return e.err
+}
+
+// notFound converts a storage "no rows" error into one matching ErrNotFound.
+// Other errors are wrapped with the given context unchanged.
+func notFound(err error, context, format string, args ...any) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return &notFoundError{msg: fmt.Sprintf(format, args...), err: err}
+	}
+	return fmt.Errorf("%s: %w", context, err)
+}
+
+// forbiddenError carries an authorization message and matches ErrForbidden.
+type forbiddenError struct {
+	msg string
+}
+
+func (e *forbiddenError) Error() string {
+	return e.msg
+}
+
+func (e *forbiddenError) Is(target error) bool {
+	return target == ErrForbidden
+}
+
+// forbidden formats an authorization error that matches ErrForbidden.
+func forbidden(format string, args ...any) error {
+	return &forbiddenError{msg: fmt.Sprintf(format, args...)}
+}
+
+// unauthenticatedError carries a message for wrong credentials and matches
+// ErrUnauthenticated.
+type unauthenticatedError struct {
+	msg string
+}
+
+func (e *unauthenticatedError) Error() string {
+	return e.msg
+}
+
+func (e *unauthenticatedError) Is(target error) bool {
+	return target == ErrUnauthenticated
+}
+
+// preconditionError carries a message for a stale change and matches
+// ErrPreconditionFailed.
+type preconditionError struct {
+	msg string
+}
+
+func (e *preconditionError) Error() string {
+	return e.msg
+}
+
+func (e *preconditionError) Is(target error) bool {
+	return target == ErrPreconditionFailed
+}
+
+// reviewChanged converts domain.ErrReviewChanged from a conditional write
+// into an error matching ErrPreconditionFailed. Other errors are handled as
+// by notFound.
+func reviewChanged(err error, context string, reviewID int) error {
+	if errors.Is(err, domain.ErrReviewChanged) {
+		return &preconditionError{msg: fmt.Sprintf("review %d has changed; fetch it again and retry", reviewID)}
+	}
+	return notFound(err, context, "review %d not found", reviewID)
+}
+
+// throttledError refuses a request until retryAfter has passed and matches
+// ErrThrottled.
+type throttledError struct {
+	msg        string
+	retryAfter time.Duration
+}
+
+func (e *throttledError) Error() string {
+	return e.msg
+}
+
+func (e *throttledError) Is(target error) bool {
+	return target == ErrThrottled
+}
+
+// throttled formats an error matching ErrThrottled.
+func throttled(retryAfter time.Duration, format string, args ...any) error {
+	return &throttledError{msg: fmt.Sprintf(format, args...), retryAfter: retryAfter}
+}
+
+// RetryAfter returns how long the caller should wait before retrying a
+// request refused with an error matching ErrThrottled.
+func RetryAfter(err error) (time.Duration, bool) {
+	var te *throttledError
+	if errors.As(err, &te) {
+		return te.retryAfter, true
+	}
+	return 0, false
+}
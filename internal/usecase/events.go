@@ -0,0 +1,136 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"eve/domain"
+)
+
+// relayBatchSize bounds the number of events loaded from the outbox at once.
+const relayBatchSize = 100
+
+// OutboxRepository reads the domain events ReviewRepository records in the
+// outbox. Implementations live in internal/repository.
+type OutboxRepository interface {
+	// ListUnpublished returns up to limit events that are neither published
+	// nor dead-lettered and whose next attempt is due at now, oldest first.
+	// An event is left out while an earlier event of the same review waits
+	// for a later attempt, so that a review's events are published in order.
+	ListUnpublished(ctx context.Context, now time.Time, limit int) ([]domain.Event, error)
+
+	// MarkPublished marks events as published so they are not listed again.
+	MarkPublished(ctx context.Context, ids []int) error
+
+	// RecordFailure counts a failed attempt to publish an event, keeps the
+	// reason and schedules the next attempt at nextAttemptAt. When dead is set
+	// the event is dead-lettered instead and never listed again.
+	RecordFailure(ctx context.Context, id int, reason string, nextAttemptAt time.Time, dead bool) error
+}
+
+// EventPublisher delivers domain events to other services. Implementations
+// live in internal/infrastructure.
+type EventPublisher interface {
+	// Publish delivers a single event. Delivery is at least once: an event is
+	// published again when it could not be marked published afterwards, so
+	// consumers should deduplicate by Event.ID.
+	Publish(ctx context.Context, event domain.Event) error
+}
+
+// RelayEventsUseCase publishes the events recorded in the outbox. It is run
+// periodically by a background worker.
+type RelayEventsUseCase struct {
+	outbox    OutboxRepository
+	publisher EventPublisher
+	policy    RetryPolicy
+	metrics   Metrics
+	log       *slog.Logger
+}
+
+// NewRelayEventsUseCase constructs a new RelayEventsUseCase.
+func NewRelayEventsUseCase(o OutboxRepository, p EventPublisher, rp RetryPolicy, m Metrics, l *slog.Logger) *RelayEventsUseCase {
+	return &RelayEventsUseCase{outbox: o, publisher: p, policy: rp, metrics: m, log: l}
+}
+
+// Execute publishes every unpublished event that is due, oldest first, and
+// returns how many were published. When publishing an event fails, it is
+// retried with exponential backoff and the later events of the same review
+// are held back meanwhile, so that consumers see every review's events in
+// order. An event that failed as often as the retry policy allows is
+// dead-lettered, which releases the events behind it.
+func (uc *RelayEventsUseCase) Execute(ctx context.Context) (published int, err error) {
+	ctx, end := track(ctx, uc.metrics, uc.log, "relay_events")
+	defer end(&err)
+
+	for {
+		events, err := uc.outbox.ListUnpublished(ctx, time.Now(), relayBatchSize)
+		if err != nil {
+			return published, fmt.Errorf("list unpublished events: %w", err)
+		}
+
+		done, failure := uc.publish(ctx, events)
+		if len(done) > 0 {
+			if err := uc.outbox.MarkPublished(ctx, done); err != nil {
+				return published, fmt.Errorf("mark events published: %w", err)
+			}
+		}
+		published += len(done)
+		if failure != nil {
+			return published, failure
+		}
+		if len(events) < relayBatchSize {
+			break
+		}
+	}
+
+	if published > 0 {
+		uc.log.InfoContext(ctx, "events relayed", slog.Int("count", published))
+	}
+	return published, nil
+}
+
+// publish publishes events in order and returns the IDs of those delivered
+// together with the first failure.
+func (uc *RelayEventsUseCase) publish(ctx context.Context, events []domain.Event) (done []int, failure error) {
+	held := make(map[int]bool) // review IDs with an undelivered event
+	for _, ev := range events {
+		if held[ev.ReviewID] {
+			continue
+		}
+		if err := uc.publisher.Publish(ctx, ev); err != nil {
+			held[ev.ReviewID] = true
+			if failure == nil {
+				failure = fmt.Errorf("publish event %d: %w", ev.ID, err)
+			}
+			uc.recordFailure(ctx, ev, err)
+			continue
+		}
+		done = append(done, ev.ID)
+	}
+	return done, failure
+}
+
+// recordFailure schedules the next attempt to publish ev, or dead-letters it
+// after the last attempt allowed by the retry policy.
+func (uc *RelayEventsUseCase) recordFailure(ctx context.Context, ev domain.Event, pubErr error) {
+	attempts := ev.Attempts + 1
+	dead := attempts >= uc.policy.MaxAttempts
+	next := time.Now().Add(uc.policy.backoff(attempts))
+	if err := uc.outbox.RecordFailure(ctx, ev.ID, pubErr.Error(), next, dead); err != nil {
+		uc.log.WarnContext(ctx, "record event failure",
+			slog.Int("event_id", ev.ID),
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+	if dead {
+		uc.log.WarnContext(ctx, "event dead-lettered",
+			slog.Int("event_id", ev.ID),
+			slog.String("event_type", ev.Type),
+			slog.Int("attempts", attempts),
+			slog.String("error", pubErr.Error()),
+		)
+	}
+}
@@ -0,0 +1,149 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"eve/domain"
+	"eve/internal/logging"
+	"eve/internal/repository/memory"
+	"eve/internal/usecase"
+)
+
+// recordingPublisher remembers published events and fails for one review.
+type recordingPublisher struct {
+	published []domain.Event
+	failing   int // review ID
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, e domain.Event) error {
+	if e.ReviewID == p.failing {
+		return errors.New("broker unavailable")
+	}
+	p.published = append(p.published, e)
+	return nil
+}
+
+func (p *recordingPublisher) types() []string {
+	var types []string
+	for _, e := range p.published {
+		types = append(types, e.Type)
+	}
+	return types
+}
+
+func TestRelayEventsUseCase(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewReviewRepo()
+	publisher := &recordingPublisher{}
+	uc := usecase.NewRelayEventsUseCase(repo, publisher, usecase.RetryPolicy{MaxAttempts: 3}, usecase.NopMetrics{}, logging.Nop())
+
+	first := seedReview(t, repo, "product", 1)
+	seedReview(t, repo, "product", 2)
+	if _, err := repo.AddComment(ctx, domain.ReviewComment{ReviewID: first, UserID: 2, Body: "hi"}); err != nil {
+		t.Fatalf("seed comment: %v", err)
+	}
+
+	n, err := uc.Execute(ctx)
+	if err != nil || n != 3 {
+		t.Fatalf("Execute() = %d, %v; want 3 events", n, err)
+	}
+	want := []string{domain.EventReviewCreated, domain.EventReviewCreated, domain.EventCommentAdded}
+	if got := publisher.types(); !slices.Equal(got, want) {
+		t.Errorf("published %v, want %v", got, want)
+	}
+
+	if n, err := uc.Execute(ctx); err != nil || n != 0 {
+		t.Errorf("second Execute() = %d, %v; want nothing left to publish", n, err)
+	}
+}
+
+func TestRelayEventsUseCaseHoldsBackFailedReview(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewReviewRepo()
+	failing := seedReview(t, repo, "product", 1)
+	other := seedReview(t, repo, "product", 2)
+	if err := repo.DeleteReview(ctx, failing, ""); err != nil {
+		t.Fatalf("DeleteReview() error = %v", err)
+	}
+
+	publisher := &recordingPublisher{failing: failing}
+	uc := usecase.NewRelayEventsUseCase(repo, publisher, usecase.RetryPolicy{MaxAttempts: 3}, usecase.NopMetrics{}, logging.Nop())
+
+	n, err := uc.Execute(ctx)
+	if err == nil {
+		t.Fatal("Execute() error = nil, want the publish failure")
+	}
+	if n != 1 || len(publisher.published) != 1 || publisher.published[0].ReviewID != other {
+		t.Fatalf("Execute() published %d: %+v; want only review %d's event", n, publisher.published, other)
+	}
+
+	// Once the broker recovers, the held-back events follow in order.
+	publisher.failing = 0
+	if n, err := uc.Execute(ctx); err != nil || n != 2 {
+		t.Fatalf("Execute() after recovery = %d, %v; want 2 events", n, err)
+	}
+	want := []string{domain.EventReviewCreated, domain.EventReviewCreated, domain.EventReviewDeleted}
+	if got := publisher.types(); !slices.Equal(got, want) {
+		t.Errorf("published %v, want %v", got, want)
+	}
+}
+
+func TestRelayEventsUseCaseBacksOff(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewReviewRepo()
+	failing := seedReview(t, repo, "product", 1)
+	if err := repo.DeleteReview(ctx, failing, ""); err != nil {
+		t.Fatalf("DeleteReview() error = %v", err)
+	}
+
+	publisher := &recordingPublisher{failing: failing}
+	policy := usecase.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Hour, MaxDelay: time.Hour}
+	uc := usecase.NewRelayEventsUseCase(repo, publisher, policy, usecase.NopMetrics{}, logging.Nop())
+
+	if _, err := uc.Execute(ctx); err == nil {
+		t.Fatal("Execute() error = nil, want the publish failure")
+	}
+
+	// The failed event and the one behind it wait for the backoff to pass.
+	publisher.failing = 0
+	if n, err := uc.Execute(ctx); err != nil || n != 0 {
+		t.Fatalf("Execute() during backoff = %d, %v; want nothing published", n, err)
+	}
+	if due, _ := repo.ListUnpublished(ctx, time.Now().Add(2*time.Hour), 10); len(due) != 2 || due[0].Attempts != 1 {
+		t.Errorf("events due after the backoff = %+v; want both, the first attempted once", due)
+	}
+}
+
+func TestRelayEventsUseCaseDeadLetters(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewReviewRepo()
+	failing := seedReview(t, repo, "product", 1)
+	if err := repo.DeleteReview(ctx, failing, ""); err != nil {
+		t.Fatalf("DeleteReview() error = %v", err)
+	}
+
+	publisher := &recordingPublisher{failing: failing}
+	uc := usecase.NewRelayEventsUseCase(repo, publisher, usecase.RetryPolicy{MaxAttempts: 2}, usecase.NopMetrics{}, logging.Nop())
+
+	for range 2 {
+		if _, err := uc.Execute(ctx); err == nil {
+			t.Fatal("Execute() error = nil, want the publish failure")
+		}
+	}
+
+	// The poison event is given up on; the rest of the review's events follow.
+	publisher.failing = 0
+	if n, err := uc.Execute(ctx); err != nil || n != 1 {
+		t.Fatalf("Execute() after dead-lettering = %d, %v; want 1 event", n, err)
+	}
+	if got := publisher.types(); !slices.Equal(got, []string{domain.EventReviewDeleted}) {
+		t.Errorf("published %v, want only the deletion", got)
+	}
+	if n, err := uc.Execute(ctx); err != nil || n != 0 {
+		t.Errorf("Execute() = %d, %v; want the dead event not to be retried", n, err)
+	}
+}
@@ -0,0 +1,32 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"golang.org/x/sync/semaphore"
+)
+
+// HashLimiter bounds how many passwords are hashed or compared at once by
+// the use-cases sharing it. Hashing is slow on purpose and argon2id holds its
+// whole memory cost, 64 MiB by default, until it is done, so a burst of
+// logins or sign-ups would otherwise exhaust the memory of the instance.
+type HashLimiter struct {
+	slots *semaphore.Weighted
+}
+
+// NewHashLimiter lets n passwords be hashed or compared at once.
+func NewHashLimiter(n int) *HashLimiter {
+	return &HashLimiter{slots: semaphore.NewWeighted(int64(n))}
+}
+
+// limited calls f once l has a free slot. Callers wait for one as long as ctx
+// allows and get its error otherwise.
+func limited[T any](ctx context.Context, l *HashLimiter, f func() (T, error)) (T, error) {
+	if err := l.slots.Acquire(ctx, 1); err != nil {
+		var zero T
+		return zero, fmt.Errorf("wait to hash password: %w", err)
+	}
+	defer l.slots.Release(1)
+	return f()
+}
@@ -1,15 +1,29 @@
 package usecase
 
-import "eve/domain"
+import (
+	"context"
+	"log/slog"
+
+	"eve/domain"
+)
 
 type GetUserUseCase struct {
-	repo UserRepository
+	repo    UserRepository
+	metrics Metrics
+	log     *slog.Logger
 }
 
-func NewGetUserUseCase(r UserRepository) *GetUserUseCase {
-	return &GetUserUseCase{repo: r}
+func NewGetUserUseCase(r UserRepository, m Metrics, l *slog.Logger) *GetUserUseCase {
+	return &GetUserUseCase{repo: r, metrics: m, log: l}
 }
 
-func (cu *GetUserUseCase) Execute() ([]domain.User, error) {
-	return cu.repo.GetAll()
+// Execute returns every user on behalf of actorID, who must be an admin.
+func (cu *GetUserUseCase) Execute(ctx context.Context, actorID int) (users []domain.User, err error) {
+	ctx, end := track(ctx, cu.metrics, cu.log, "get_users")
+	defer end(&err)
+
+	if err := requireRole(ctx, cu.repo, actorID, domain.RoleAdmin); err != nil {
+		return nil, err
+	}
+	return cu.repo.GetAll(ctx)
 }
@@ -0,0 +1,35 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"eve/domain"
+	"eve/internal/logging"
+	"eve/internal/repository/memory"
+	"eve/internal/usecase"
+)
+
+func TestGetUserUseCase(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewUserRepo()
+	for _, email := range []string{"a@example.com", "b@example.com"} {
+		if err := repo.Save(ctx, domain.User{Email: email, Password: "x"}); err != nil {
+			t.Fatalf("seed user: %v", err)
+		}
+	}
+	repo.SetRole(1, domain.RoleAdmin)
+	uc := usecase.NewGetUserUseCase(repo, usecase.NopMetrics{}, logging.Nop())
+
+	users, err := uc.Execute(ctx, 1)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if len(users) != 2 || users[0].Email != "a@example.com" || users[1].Email != "b@example.com" {
+		t.Errorf("Execute() = %+v", users)
+	}
+	if _, err := uc.Execute(ctx, 2); !errors.Is(err, usecase.ErrForbidden) {
+		t.Errorf("Execute(user) error = %v, want ErrForbidden", err)
+	}
+}
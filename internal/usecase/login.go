@@ -0,0 +1,307 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"eve/domain"
+)
+
+// LoginAttemptRepository records failed logins per account and per client IP.
+type LoginAttemptRepository interface {
+	// GetAttempts returns the attempts recorded under key, or LoginAttempts
+	// with only the key set when there are none.
+	GetAttempts(ctx context.Context, key string) (domain.LoginAttempts, error)
+
+	// ReserveAttempt counts a login under key at the given time as failed
+	// before its password is checked, provided the count is still seen and
+	// the key is not locked at that time, and returns the updated attempts.
+	// It counts nothing and reports false when another login was counted or a
+	// lockout began since the caller read seen. The count starts over when
+	// the previous attempt is older than window.
+	ReserveAttempt(ctx context.Context, key string, seen int, at time.Time, window time.Duration) (domain.LoginAttempts, bool, error)
+
+	// ReleaseAttempt takes back a login counted by ReserveAttempt that did
+	// not fail. The time of the last attempt is left alone.
+	ReleaseAttempt(ctx context.Context, key string) error
+
+	// Lock refuses logins under key until the given time and starts the
+	// failure count over, so that the first attempts after the lockout are
+	// not delayed.
+	Lock(ctx context.Context, key string, until time.Time) error
+
+	// Reset forgets the attempts recorded under key, lifting any lockout.
+	Reset(ctx context.Context, key string) error
+
+	// DeleteStale removes the attempts whose last attempt and lockout both
+	// ended before the given time and returns how many were removed.
+	DeleteStale(ctx context.Context, before time.Time) (int, error)
+}
+
+// LoginPolicy controls how failed logins are throttled, separately for every
+// account and client IP. After FreeAttempts failures each further attempt
+// has to wait BaseDelay*2^(n-1) after the n-th extra failure, at most
+// MaxDelay. An account with AccountLockout failures, or an IP with IPLockout
+// failures, is locked for LockoutDuration. Failures are forgotten
+// FailureWindow after the last one.
+type LoginPolicy struct {
+	FreeAttempts    int
+	BaseDelay       time.Duration
+	MaxDelay        time.Duration
+	AccountLockout  int
+	IPLockout       int
+	LockoutDuration time.Duration
+	FailureWindow   time.Duration
+}
+
+// wait returns how long logins under a are refused at now.
+func (p LoginPolicy) wait(a domain.LoginAttempts, now time.Time) time.Duration {
+	if a.Locked(now) {
+		return a.LockedUntil.Sub(now)
+	}
+	if now.Sub(a.LastAttemptAt) > p.FailureWindow {
+		return 0
+	}
+	extra := a.Failures - p.FreeAttempts
+	if extra <= 0 {
+		return 0
+	}
+	d := p.BaseDelay
+	for i := 1; i < extra && d < p.MaxDelay; i++ {
+		d *= 2
+	}
+	return max(0, a.LastAttemptAt.Add(min(d, p.MaxDelay)).Sub(now))
+}
+
+// accountKey and ipKey are the keys failed logins are recorded under.
+// Emails are compared case-insensitively so that case variations of the same
+// address share the count.
+func accountKey(email string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func ipKey(ip string) string {
+	return "ip:" + ip
+}
+
+// reserveTries is how often a login re-reads the attempts of a key when
+// concurrent logins keep getting counted first, before it is refused.
+const reserveTries = 5
+
+// timingPassword is hashed once to compare passwords of unknown emails
+// against, so that they take about as long as those of existing accounts.
+const timingPassword = "eve-unknown-account"
+
+// LoginUseCase checks a user's email and password and issues a session
+// token.
+type LoginUseCase struct {
+	users     UserRepository
+	attempts  LoginAttemptRepository
+	hasher    PasswordHasher
+	hashing   *HashLimiter
+	policy    LoginPolicy
+	sessions  SessionPolicy
+	dummyHash func() (string, error)
+	metrics   Metrics
+	log       *slog.Logger
+}
+
+// NewLoginUseCase constructs a new LoginUseCase.
+func NewLoginUseCase(u UserRepository, a LoginAttemptRepository, h PasswordHasher, hl *HashLimiter, p LoginPolicy, sp SessionPolicy, m Metrics, l *slog.Logger) *LoginUseCase {
+	return &LoginUseCase{
+		users:     u,
+		attempts:  a,
+		hasher:    h,
+		hashing:   hl,
+		policy:    p,
+		sessions:  sp,
+		dummyHash: sync.OnceValues(func() (string, error) { return h.Hash(timingPassword) }),
+		metrics:   m,
+		log:       l,
+	}
+}
+
+// Execute returns a session of the user with the given email when the
+// password matches. Throttled attempts fail with ErrThrottled and wrong
+// passwords or unknown emails alike with ErrUnauthenticated.
+func (uc *LoginUseCase) Execute(ctx context.Context, req domain.LoginRequest, ip string) (_ domain.Session, err error) {
+	ctx, end := track(ctx, uc.metrics, uc.log, "login")
+	defer end(&err)
+
+	if req.Email == "" || req.Password == "" {
+		return domain.Session{}, invalidInput("email and password are required")
+	}
+
+	// Every attempt is counted as a failure before its password is checked
+	// and taken back once it matches, so that concurrent attempts are
+	// throttled one after the other rather than all let through.
+	now := time.Now()
+	keys := []string{accountKey(req.Email), ipKey(ip)}
+	reserved := make([]domain.LoginAttempts, 0, len(keys))
+	for _, key := range keys {
+		a, err := uc.reserve(ctx, key, now)
+		if err != nil {
+			uc.release(ctx, keys[:len(reserved)])
+			return domain.Session{}, err
+		}
+		reserved = append(reserved, a)
+	}
+
+	user, err := uc.users.GetByEmail(ctx, req.Email)
+	known := err == nil
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		uc.release(ctx, keys)
+		return domain.Session{}, fmt.Errorf("get user: %w", err)
+	}
+	// Unknown emails are compared against a dummy hash so that they fail in
+	// about the same time as wrong passwords. It is made with the current
+	// algorithm, so accounts with outdated hashes can be told apart by timing
+	// until their owners log in again; this is accepted while a migration
+	// between algorithms lasts.
+	hash := user.Password
+	if !known {
+		if hash, err = limited(ctx, uc.hashing, uc.dummyHash); err != nil {
+			uc.release(ctx, keys)
+			return domain.Session{}, fmt.Errorf("hash password: %w", err)
+		}
+	}
+	ok, err := limited(ctx, uc.hashing, func() (bool, error) {
+		ok, _ := uc.hasher.Compare(req.Password, hash)
+		return ok, nil
+	})
+	if err != nil {
+		uc.release(ctx, keys)
+		return domain.Session{}, err
+	}
+	// Unknown emails count as failures of the account too, so that neither
+	// the answer nor a lockout tells whether an account exists.
+	if !ok || !known {
+		if err := uc.lockout(ctx, keys, reserved, now, ip); err != nil {
+			return domain.Session{}, err
+		}
+		return domain.Session{}, &unauthenticatedError{msg: "invalid email or password"}
+	}
+
+	if err := uc.attempts.Reset(ctx, keys[0]); err != nil {
+		return domain.Session{}, fmt.Errorf("reset login attempts: %w", err)
+	}
+	if err := uc.attempts.ReleaseAttempt(ctx, keys[1]); err != nil {
+		return domain.Session{}, fmt.Errorf("release login attempt: %w", err)
+	}
+	// Failing to replace an outdated hash does not fail the login.
+	if uc.hasher.NeedsRehash(user.Password) {
+		uc.rehash(ctx, &user, req.Password)
+	}
+	expiresAt := now.Add(uc.sessions.TTL).Truncate(time.Second)
+	uc.log.InfoContext(ctx, "user logged in", slog.Int("user_id", user.ID), slog.String("ip", ip))
+	return domain.Session{User: user, Token: uc.sessions.sign(user.ID, expiresAt), ExpiresAt: expiresAt}, nil
+}
+
+// rehash stores a hash of password made with the current algorithm and
+// costs for user.
+func (uc *LoginUseCase) rehash(ctx context.Context, user *domain.User, password string) {
+	hash, err := limited(ctx, uc.hashing, func() (string, error) { return uc.hasher.Hash(password) })
+	if err == nil {
+		err = uc.users.UpdatePassword(ctx, user.ID, hash)
+	}
+	if err != nil {
+		uc.log.WarnContext(ctx, "rehash password", slog.Int("user_id", user.ID), slog.String("error", err.Error()))
+		return
+	}
+	user.Password = hash
+	uc.log.InfoContext(ctx, "password rehashed", slog.Int("user_id", user.ID))
+}
+
+// reserve counts a login under key at now as failed, unless logins under key
+// are refused at now. A login that another one got counted before re-reads
+// the attempts, so that it is checked against the count that one left.
+func (uc *LoginUseCase) reserve(ctx context.Context, key string, now time.Time) (domain.LoginAttempts, error) {
+	for range reserveTries {
+		a, err := uc.attempts.GetAttempts(ctx, key)
+		if err != nil {
+			return domain.LoginAttempts{}, fmt.Errorf("get login attempts: %w", err)
+		}
+		if wait := uc.policy.wait(a, now); wait > 0 {
+			return domain.LoginAttempts{}, throttled(wait, "too many failed logins; try again later")
+		}
+		a, ok, err := uc.attempts.ReserveAttempt(ctx, key, a.Failures, now, uc.policy.FailureWindow)
+		if err != nil {
+			return domain.LoginAttempts{}, fmt.Errorf("reserve login attempt: %w", err)
+		}
+		if ok {
+			return a, nil
+		}
+	}
+	return domain.LoginAttempts{}, throttled(uc.policy.BaseDelay, "too many concurrent logins; try again later")
+}
+
+// release takes back the logins counted under keys when the password could
+// not be checked. It also runs when ctx is done, which is often why the
+// password was not checked. Failing to do so only leaves them counted as
+// failures.
+func (uc *LoginUseCase) release(ctx context.Context, keys []string) {
+	ctx = context.WithoutCancel(ctx)
+	for _, key := range keys {
+		if err := uc.attempts.ReleaseAttempt(ctx, key); err != nil {
+			uc.log.WarnContext(ctx, "release login attempt", slog.String("key", key), slog.String("error", err.Error()))
+		}
+	}
+}
+
+// lockout locks the account and the IP in keys whose reserved count of
+// failures reached their limit.
+func (uc *LoginUseCase) lockout(ctx context.Context, keys []string, reserved []domain.LoginAttempts, now time.Time, ip string) error {
+	for i, key := range keys {
+		limit, kind := uc.policy.AccountLockout, "account"
+		if i > 0 {
+			limit, kind = uc.policy.IPLockout, "ip"
+		}
+		if reserved[i].Failures < limit {
+			continue
+		}
+		if err := uc.attempts.Lock(ctx, key, now.Add(uc.policy.LockoutDuration)); err != nil {
+			return fmt.Errorf("lock logins: %w", err)
+		}
+		uc.log.WarnContext(ctx, "logins locked", slog.String("locked", kind), slog.String("ip", ip), slog.Int("failures", reserved[i].Failures))
+	}
+	return nil
+}
+
+// UnlockUserUseCase lets an admin lift the login lockout of an account.
+type UnlockUserUseCase struct {
+	users    UserRepository
+	attempts LoginAttemptRepository
+	metrics  Metrics
+	log      *slog.Logger
+}
+
+// NewUnlockUserUseCase constructs a new UnlockUserUseCase.
+func NewUnlockUserUseCase(u UserRepository, a LoginAttemptRepository, m Metrics, l *slog.Logger) *UnlockUserUseCase {
+	return &UnlockUserUseCase{users: u, attempts: a, metrics: m, log: l}
+}
+
+// Execute forgets the failed logins of user userID, lifting its lockout. The
+// lockouts of the IPs the failures came from are left alone.
+func (uc *UnlockUserUseCase) Execute(ctx context.Context, userID, actorID int) (err error) {
+	ctx, end := track(ctx, uc.metrics, uc.log, "unlock_user")
+	defer end(&err)
+
+	if err := requireRole(ctx, uc.users, actorID, domain.RoleAdmin); err != nil {
+		return err
+	}
+	user, err := uc.users.GetByID(ctx, userID)
+	if err != nil {
+		return notFound(err, "get user", "user %d not found", userID)
+	}
+	if err := uc.attempts.Reset(ctx, accountKey(user.Email)); err != nil {
+		return fmt.Errorf("reset login attempts: %w", err)
+	}
+	uc.log.InfoContext(ctx, "user unlocked", slog.Int("user_id", userID), slog.Int("actor_id", actorID))
+	return nil
+}
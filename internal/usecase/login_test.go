@@ -0,0 +1,403 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"eve/domain"
+	"eve/internal/infrastructure"
+	"eve/internal/logging"
+	"eve/internal/repository/memory"
+	"eve/internal/usecase"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// countingHasher is a FakeHasher that counts password comparisons.
+type countingHasher struct {
+	infrastructure.FakeHasher
+	compares int
+}
+
+func (h *countingHasher) Compare(password, hash string) (bool, error) {
+	h.compares++
+	return h.FakeHasher.Compare(password, hash)
+}
+
+// blockingHasher is a FakeHasher whose comparisons wait until release is
+// closed and which tracks how many run at once.
+type blockingHasher struct {
+	infrastructure.FakeHasher
+	release chan struct{}
+
+	mu            sync.Mutex
+	running, peak int
+}
+
+func (h *blockingHasher) Compare(password, hash string) (bool, error) {
+	h.mu.Lock()
+	h.running++
+	h.peak = max(h.peak, h.running)
+	h.mu.Unlock()
+	<-h.release
+	h.mu.Lock()
+	h.running--
+	h.mu.Unlock()
+	return h.FakeHasher.Compare(password, hash)
+}
+
+func (h *blockingHasher) comparing() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.running
+}
+
+// seedLoginUser stores a user whose password is "secret" hashed by FakeHasher.
+func seedLoginUser(t *testing.T, repo *memory.UserRepo, email string) domain.User {
+	t.Helper()
+	ctx := context.Background()
+	hash, _ := infrastructure.NewFakeHasher().Hash("secret")
+	if err := repo.Save(ctx, domain.User{Email: email, Password: hash}); err != nil {
+		t.Fatalf("seed user: %v", err)
+	}
+	user, err := repo.GetByEmail(ctx, email)
+	if err != nil {
+		t.Fatalf("seed user: %v", err)
+	}
+	return user
+}
+
+// testSessionPolicy signs session tokens with a fixed secret.
+var testSessionPolicy = usecase.SessionPolicy{Secret: []byte("0123456789abcdef0123456789abcdef"), TTL: time.Hour}
+
+func newLoginUseCase(users *memory.UserRepo, attempts usecase.LoginAttemptRepository, hasher usecase.PasswordHasher, p usecase.LoginPolicy) *usecase.LoginUseCase {
+	return usecase.NewLoginUseCase(users, attempts, hasher, usecase.NewHashLimiter(4), p, testSessionPolicy, usecase.NopMetrics{}, logging.Nop())
+}
+
+// seedFailures records n failed logins under key at the given time.
+func seedFailures(t *testing.T, attempts *memory.LoginAttemptRepo, key string, n int, at time.Time) {
+	t.Helper()
+	ctx := context.Background()
+	for range n {
+		a, _ := attempts.GetAttempts(ctx, key)
+		if _, ok, err := attempts.ReserveAttempt(ctx, key, a.Failures, at, time.Hour); err != nil || !ok {
+			t.Fatalf("ReserveAttempt(%s) = %v, %v", key, ok, err)
+		}
+	}
+}
+
+// retryAfter returns how long err asks to wait, rounded to the second.
+func retryAfter(t *testing.T, err error) time.Duration {
+	t.Helper()
+	if !errors.Is(err, usecase.ErrThrottled) {
+		t.Fatalf("error = %v, want ErrThrottled", err)
+	}
+	wait, ok := usecase.RetryAfter(err)
+	if !ok {
+		t.Fatalf("RetryAfter(%v) not set", err)
+	}
+	return wait.Round(time.Second)
+}
+
+func TestLoginUseCase(t *testing.T) {
+	ctx := context.Background()
+	users := memory.NewUserRepo()
+	alice := seedLoginUser(t, users, "alice@example.com")
+	hasher := &countingHasher{}
+	policy := usecase.LoginPolicy{FreeAttempts: 2, BaseDelay: time.Minute, MaxDelay: 4 * time.Minute, AccountLockout: 10, IPLockout: 100, LockoutDuration: 15 * time.Minute, FailureWindow: time.Hour}
+	login := newLoginUseCase(users, memory.NewLoginAttemptRepo(), hasher, policy)
+
+	if _, err := login.Execute(ctx, domain.LoginRequest{Email: "alice@example.com"}, "192.0.2.1"); !errors.Is(err, usecase.ErrInvalidInput) {
+		t.Errorf("Execute(no password) error = %v, want ErrInvalidInput", err)
+	}
+	got, err := login.Execute(ctx, domain.LoginRequest{Email: "alice@example.com", Password: "secret"}, "192.0.2.1")
+	if err != nil || got.User.ID != alice.ID {
+		t.Fatalf("Execute() = %+v, %v; want alice", got, err)
+	}
+
+	var wrongPassword error
+	for i := range 3 {
+		_, wrongPassword = login.Execute(ctx, domain.LoginRequest{Email: "alice@example.com", Password: "wrong"}, "192.0.2.1")
+		if !errors.Is(wrongPassword, usecase.ErrUnauthenticated) {
+			t.Fatalf("Execute(wrong password #%d) error = %v, want ErrUnauthenticated", i+1, wrongPassword)
+		}
+	}
+	_, err = login.Execute(ctx, domain.LoginRequest{Email: "alice@example.com", Password: "secret"}, "198.51.100.7")
+	if wait := retryAfter(t, err); wait != time.Minute {
+		t.Errorf("RetryAfter() = %v, want 1m after 3 failures", wait)
+	}
+
+	compares := hasher.compares
+	_, unknown := login.Execute(ctx, domain.LoginRequest{Email: "ghost@example.com", Password: "secret"}, "203.0.113.5")
+	if !errors.Is(unknown, usecase.ErrUnauthenticated) || unknown.Error() != wrongPassword.Error() {
+		t.Errorf("Execute(unknown email) error = %v, want the wrong password error %q", unknown, wrongPassword)
+	}
+	if hasher.compares != compares+1 {
+		t.Errorf("unknown email compared %d passwords, want 1 like a known one", hasher.compares-compares)
+	}
+}
+
+func TestLoginPolicyDelays(t *testing.T) {
+	ctx := context.Background()
+	users := memory.NewUserRepo()
+	seedLoginUser(t, users, "alice@example.com")
+	attempts := memory.NewLoginAttemptRepo()
+	policy := usecase.LoginPolicy{FreeAttempts: 2, BaseDelay: time.Minute, MaxDelay: 4 * time.Minute, AccountLockout: 10, IPLockout: 100, LockoutDuration: 15 * time.Minute, FailureWindow: time.Hour}
+	login := newLoginUseCase(users, attempts, infrastructure.NewFakeHasher(), policy)
+
+	for failures, want := range map[int]time.Duration{2: 0, 3: time.Minute, 4: 2 * time.Minute, 5: 4 * time.Minute, 7: 4 * time.Minute} {
+		_ = attempts.Reset(ctx, "account:alice@example.com")
+		seedFailures(t, attempts, "account:alice@example.com", failures, time.Now())
+		_, err := login.Execute(ctx, domain.LoginRequest{Email: "alice@example.com", Password: "secret"}, "192.0.2.1")
+		if want == 0 {
+			if err != nil {
+				t.Errorf("Execute() after %d failures error = %v, want no delay", failures, err)
+			}
+			continue
+		}
+		if wait := retryAfter(t, err); wait != want {
+			t.Errorf("RetryAfter() after %d failures = %v, want %v", failures, wait, want)
+		}
+	}
+
+	_ = attempts.Reset(ctx, "account:alice@example.com")
+	seedFailures(t, attempts, "account:alice@example.com", 5, time.Now().Add(-5*time.Minute))
+	if _, err := login.Execute(ctx, domain.LoginRequest{Email: "alice@example.com", Password: "secret"}, "192.0.2.1"); err != nil {
+		t.Errorf("Execute() after the delay passed error = %v", err)
+	}
+}
+
+func TestLoginLockout(t *testing.T) {
+	ctx := context.Background()
+	users := memory.NewUserRepo()
+	alice := seedLoginUser(t, users, "alice@example.com")
+	seedLoginUser(t, users, "bob@example.com")
+	admin := seedUser(t, users, "admin@example.com", domain.RoleAdmin)
+	attempts := memory.NewLoginAttemptRepo()
+	policy := usecase.LoginPolicy{BaseDelay: time.Nanosecond, MaxDelay: time.Nanosecond, AccountLockout: 3, IPLockout: 5, LockoutDuration: 15 * time.Minute, FailureWindow: time.Hour}
+	login := newLoginUseCase(users, attempts, infrastructure.NewFakeHasher(), policy)
+	unlock := usecase.NewUnlockUserUseCase(users, attempts, usecase.NopMetrics{}, logging.Nop())
+
+	fail := func(email, ip string) {
+		t.Helper()
+		if _, err := login.Execute(ctx, domain.LoginRequest{Email: email, Password: "wrong"}, ip); !errors.Is(err, usecase.ErrUnauthenticated) {
+			t.Fatalf("Execute(%s from %s, wrong password) error = %v, want ErrUnauthenticated", email, ip, err)
+		}
+	}
+
+	// A success starts the count over.
+	fail("alice@example.com", "192.0.2.1")
+	fail("alice@example.com", "192.0.2.2")
+	if _, err := login.Execute(ctx, domain.LoginRequest{Email: "alice@example.com", Password: "secret"}, "192.0.2.3"); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	for _, ip := range []string{"192.0.2.1", "192.0.2.2", "192.0.2.3"} {
+		fail("alice@example.com", ip)
+	}
+	_, err := login.Execute(ctx, domain.LoginRequest{Email: "alice@example.com", Password: "secret"}, "198.51.100.7")
+	if wait := retryAfter(t, err); wait != 15*time.Minute {
+		t.Errorf("RetryAfter(locked account) = %v, want 15m", wait)
+	}
+
+	// Unknown emails are locked alike.
+	for range 3 {
+		fail("ghost@example.com", "198.51.100.8")
+	}
+	if _, err := login.Execute(ctx, domain.LoginRequest{Email: "ghost@example.com", Password: "secret"}, "198.51.100.9"); !errors.Is(err, usecase.ErrThrottled) {
+		t.Errorf("Execute(locked unknown email) error = %v, want ErrThrottled", err)
+	}
+
+	// An IP is locked after failures across accounts.
+	for _, email := range []string{"a@example.com", "b@example.com", "c@example.com", "d@example.com", "e@example.com"} {
+		fail(email, "203.0.113.5")
+	}
+	if _, err := login.Execute(ctx, domain.LoginRequest{Email: "bob@example.com", Password: "secret"}, "203.0.113.5"); !errors.Is(err, usecase.ErrThrottled) {
+		t.Errorf("Execute(locked IP) error = %v, want ErrThrottled", err)
+	}
+	if _, err := login.Execute(ctx, domain.LoginRequest{Email: "bob@example.com", Password: "secret"}, "203.0.113.6"); err != nil {
+		t.Errorf("Execute(other IP) error = %v", err)
+	}
+
+	if err := unlock.Execute(ctx, alice.ID, alice.ID); !errors.Is(err, usecase.ErrForbidden) {
+		t.Errorf("Unlock(by user) error = %v, want ErrForbidden", err)
+	}
+	if err := unlock.Execute(ctx, 999, admin); !errors.Is(err, usecase.ErrNotFound) {
+		t.Errorf("Unlock(unknown user) error = %v, want ErrNotFound", err)
+	}
+	if err := unlock.Execute(ctx, alice.ID, admin); err != nil {
+		t.Fatalf("Unlock() error = %v", err)
+	}
+	if _, err := login.Execute(ctx, domain.LoginRequest{Email: "alice@example.com", Password: "secret"}, "198.51.100.7"); err != nil {
+		t.Errorf("Execute() after unlock error = %v", err)
+	}
+}
+
+func TestLoginThrottlesConcurrentAttempts(t *testing.T) {
+	ctx := context.Background()
+	users := memory.NewUserRepo()
+	seedLoginUser(t, users, "alice@example.com")
+	attempts := memory.NewLoginAttemptRepo()
+	policy := usecase.LoginPolicy{FreeAttempts: 2, BaseDelay: time.Minute, MaxDelay: time.Minute, AccountLockout: 100, IPLockout: 100, LockoutDuration: time.Minute, FailureWindow: time.Hour}
+	login := newLoginUseCase(users, attempts, infrastructure.NewFakeHasher(), policy)
+
+	// Successful logins are not counted against the IP.
+	for i := range 3 {
+		if _, err := login.Execute(ctx, domain.LoginRequest{Email: "alice@example.com", Password: "secret"}, "192.0.2.1"); err != nil {
+			t.Fatalf("Execute(#%d) error = %v", i+1, err)
+		}
+	}
+	if a, _ := attempts.GetAttempts(ctx, "ip:192.0.2.1"); a.Failures != 0 {
+		t.Errorf("IP attempts after successful logins = %+v, want no failures", a)
+	}
+
+	// Of parallel wrong guesses only the free attempts and the first delayed
+	// one get their password checked.
+	var (
+		wg               sync.WaitGroup
+		mu               sync.Mutex
+		checked, refused int
+	)
+	for i := range 20 {
+		wg.Go(func() {
+			_, err := login.Execute(ctx, domain.LoginRequest{Email: "alice@example.com", Password: "wrong"}, fmt.Sprintf("198.51.100.%d", i))
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case errors.Is(err, usecase.ErrUnauthenticated):
+				checked++
+			case errors.Is(err, usecase.ErrThrottled):
+				refused++
+			default:
+				t.Errorf("Execute(wrong password) error = %v, want ErrUnauthenticated or ErrThrottled", err)
+			}
+		})
+	}
+	wg.Wait()
+	if checked != policy.FreeAttempts+1 || refused != 20-checked {
+		t.Errorf("parallel wrong passwords: %d checked, %d throttled; want %d checked", checked, refused, policy.FreeAttempts+1)
+	}
+	if a, _ := attempts.GetAttempts(ctx, "account:alice@example.com"); a.Failures != policy.FreeAttempts+1 {
+		t.Errorf("account attempts = %+v, want %d failures", a, policy.FreeAttempts+1)
+	}
+	for i := range 20 {
+		if a, _ := attempts.GetAttempts(ctx, fmt.Sprintf("ip:198.51.100.%d", i)); a.Failures > 1 {
+			t.Errorf("IP attempts = %+v, want at most the checked login counted", a)
+		}
+	}
+}
+
+func TestLoginLimitsHashing(t *testing.T) {
+	ctx := context.Background()
+	users := memory.NewUserRepo()
+	seedLoginUser(t, users, "alice@example.com")
+	attempts := memory.NewLoginAttemptRepo()
+	hasher := &blockingHasher{release: make(chan struct{})}
+	policy := usecase.LoginPolicy{FreeAttempts: 5, BaseDelay: time.Second, MaxDelay: time.Second, AccountLockout: 10, IPLockout: 100, LockoutDuration: time.Minute, FailureWindow: time.Hour}
+	login := usecase.NewLoginUseCase(users, attempts, hasher, usecase.NewHashLimiter(1), policy, testSessionPolicy, usecase.NopMetrics{}, logging.Nop())
+
+	done := make(chan error)
+	go func() {
+		_, err := login.Execute(ctx, domain.LoginRequest{Email: "alice@example.com", Password: "secret"}, "192.0.2.1")
+		done <- err
+	}()
+	for hasher.comparing() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// A second login waits for the slot until its deadline and is not counted.
+	waiting, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if _, err := login.Execute(waiting, domain.LoginRequest{Email: "alice@example.com", Password: "wrong"}, "192.0.2.2"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Execute(while hashing is busy) error = %v, want DeadlineExceeded", err)
+	}
+	if a, _ := attempts.GetAttempts(ctx, "ip:192.0.2.2"); a.Failures != 0 {
+		t.Errorf("attempts of the login that timed out = %+v, want released", a)
+	}
+
+	close(hasher.release)
+	if err := <-done; err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if hasher.peak != 1 {
+		t.Errorf("%d passwords compared at once, want 1", hasher.peak)
+	}
+}
+
+func TestLoginRehashesOutdatedPasswords(t *testing.T) {
+	ctx := context.Background()
+	users := memory.NewUserRepo()
+	legacy := infrastructure.NewBcryptHasher(bcrypt.MinCost)
+	hash, _ := legacy.Hash("secret")
+	if err := users.Save(ctx, domain.User{Email: "alice@example.com", Password: hash}); err != nil {
+		t.Fatalf("seed user: %v", err)
+	}
+	policy := usecase.LoginPolicy{FreeAttempts: 5, BaseDelay: time.Second, MaxDelay: time.Second, AccountLockout: 10, IPLockout: 100, LockoutDuration: time.Minute, FailureWindow: time.Hour}
+	login := newLoginUseCase(users, memory.NewLoginAttemptRepo(), infrastructure.NewMultiHasher(infrastructure.NewFakeHasher(), legacy), policy)
+
+	if _, err := login.Execute(ctx, domain.LoginRequest{Email: "alice@example.com", Password: "wrong"}, "192.0.2.1"); !errors.Is(err, usecase.ErrUnauthenticated) {
+		t.Fatalf("Execute(wrong password) error = %v, want ErrUnauthenticated", err)
+	}
+	if stored, _ := users.GetByEmail(ctx, "alice@example.com"); stored.Password != hash {
+		t.Errorf("password after a failed login = %q, want it unchanged", stored.Password)
+	}
+
+	for range 2 {
+		if _, err := login.Execute(ctx, domain.LoginRequest{Email: "alice@example.com", Password: "secret"}, "192.0.2.1"); err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+		if stored, _ := users.GetByEmail(ctx, "alice@example.com"); stored.Password != "fake$secret" {
+			t.Errorf("password after login = %q, want it rehashed with the current hasher", stored.Password)
+		}
+	}
+}
+
+func TestLoginIssuesSessions(t *testing.T) {
+	ctx := context.Background()
+	users := memory.NewUserRepo()
+	alice := seedLoginUser(t, users, "alice@example.com")
+	policy := usecase.LoginPolicy{FreeAttempts: 5, BaseDelay: time.Second, MaxDelay: time.Second, AccountLockout: 10, IPLockout: 100, LockoutDuration: time.Minute, FailureWindow: time.Hour}
+	login := newLoginUseCase(users, memory.NewLoginAttemptRepo(), infrastructure.NewFakeHasher(), policy)
+	auth := usecase.NewAuthenticateSessionUseCase(testSessionPolicy, usecase.NopMetrics{}, logging.Nop())
+
+	session, err := login.Execute(ctx, domain.LoginRequest{Email: "alice@example.com", Password: "secret"}, "192.0.2.1")
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if !usecase.IsSessionToken(session.Token) {
+		t.Errorf("token %q does not look like a session token", session.Token)
+	}
+	if left := time.Until(session.ExpiresAt); left <= 59*time.Minute || left > time.Hour {
+		t.Errorf("ExpiresAt = %v, want in an hour", session.ExpiresAt)
+	}
+	if got, err := auth.Execute(ctx, session.Token); err != nil || got != alice.ID {
+		t.Errorf("Authenticate(token) = %d, %v; want %d", got, err, alice.ID)
+	}
+
+	expired := usecase.NewLoginUseCase(users, memory.NewLoginAttemptRepo(), infrastructure.NewFakeHasher(), usecase.NewHashLimiter(1), policy,
+		usecase.SessionPolicy{Secret: testSessionPolicy.Secret, TTL: -time.Second}, usecase.NopMetrics{}, logging.Nop())
+	old, err := expired.Execute(ctx, domain.LoginRequest{Email: "alice@example.com", Password: "secret"}, "192.0.2.1")
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	other := usecase.NewAuthenticateSessionUseCase(usecase.SessionPolicy{Secret: []byte("another secret of thirty-two byte"), TTL: time.Hour}, usecase.NopMetrics{}, logging.Nop())
+	for _, tt := range []struct {
+		name  string
+		auth  *usecase.AuthenticateSessionUseCase
+		token string
+	}{
+		{"expired", auth, old.Token},
+		{"other user", auth, strings.Replace(session.Token, fmt.Sprintf("evs_%d_", alice.ID), "evs_99_", 1)},
+		{"truncated", auth, session.Token[:len(session.Token)-2]},
+		{"other secret", other, session.Token},
+		{"malformed", auth, "evs_1"},
+	} {
+		if _, err := tt.auth.Execute(ctx, tt.token); !errors.Is(err, usecase.ErrUnauthenticated) {
+			t.Errorf("Authenticate(%s) error = %v, want ErrUnauthenticated", tt.name, err)
+		}
+	}
+}
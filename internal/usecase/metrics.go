@@ -0,0 +1,48 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+)
+
+var tracer = otel.Tracer("eve/internal/usecase")
+
+// NopMetrics is a Metrics implementation that discards everything.
+type NopMetrics struct{}
+
+func (NopMetrics) ObserveUseCase(string, time.Duration, error) {}
+func (NopMetrics) ReviewCreated()                              {}
+func (NopMetrics) CommentCreated()                             {}
+func (NopMetrics) PhotosAttached(int)                          {}
+
+// track starts a span for the named use-case and returns a function that
+// ends it, reports the duration and the final value of *err to m and logs
+// failures to log.
+// It is meant to be used at the top of Execute with a named error result:
+//
+//	ctx, end := track(ctx, uc.metrics, uc.log, "create_review")
+//	defer end(&err)
+func track(ctx context.Context, m Metrics, log *slog.Logger, name string) (context.Context, func(err *error)) {
+	start := time.Now()
+	ctx, span := tracer.Start(ctx, "usecase."+name)
+	return ctx, func(err *error) {
+		if *err != nil {
+			span.RecordError(*err)
+			span.SetStatus(codes.Error, (*err).Error())
+		}
+		span.End()
+		elapsed := time.Since(start)
+		m.ObserveUseCase(name, elapsed, *err)
+		if *err != nil {
+			log.WarnContext(ctx, "use-case failed",
+				slog.String("usecase", name),
+				slog.Duration("duration", elapsed),
+				slog.String("error", (*err).Error()),
+			)
+		}
+	}
+}
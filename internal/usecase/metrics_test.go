@@ -0,0 +1,27 @@
+package usecase_test
+
+import "time"
+
+// spyMetrics records calls made by use-cases.
+type spyMetrics struct {
+	calls          map[string]int
+	failures       map[string]int
+	reviews        int
+	comments       int
+	photosAttached int
+}
+
+func (m *spyMetrics) ObserveUseCase(name string, _ time.Duration, err error) {
+	if m.calls == nil {
+		m.calls = make(map[string]int)
+		m.failures = make(map[string]int)
+	}
+	m.calls[name]++
+	if err != nil {
+		m.failures[name]++
+	}
+}
+
+func (m *spyMetrics) ReviewCreated()       { m.reviews++ }
+func (m *spyMetrics) CommentCreated()      { m.comments++ }
+func (m *spyMetrics) PhotosAttached(n int) { m.photosAttached += n }
@@ -1,13 +1,140 @@
 package usecase
 
-import "eve/domain"
+import (
+	"context"
+	"time"
+
+	"eve/domain"
+)
 
 type UserRepository interface {
-	Save(domain.User) error
-	GetAll() ([]domain.User, error)
+	Save(context.Context, domain.User) error
+	GetAll(context.Context) ([]domain.User, error)
+
+	// GetByID loads a single user. A missing user yields an error wrapping sql.ErrNoRows.
+	GetByID(ctx context.Context, id int) (domain.User, error)
+
+	// GetByEmail loads the user with exactly this email. A missing user yields
+	// an error wrapping sql.ErrNoRows.
+	GetByEmail(ctx context.Context, email string) (domain.User, error)
+
+	// UpdatePassword replaces the password hash of user id. A missing user
+	// yields an error wrapping sql.ErrNoRows.
+	UpdatePassword(ctx context.Context, id int, hash string) error
 }
 
 type PasswordHasher interface {
 	Hash(password string) (string, error)
 	Compare(password, hash string) (bool, error)
+
+	// NeedsRehash reports whether hash was made with another algorithm or
+	// other costs than Hash uses now, so it should be replaced by a fresh one
+	// the next time the password is known.
+	NeedsRehash(hash string) bool
+}
+
+// BlobStore holds the files referenced by review photos.
+type BlobStore interface {
+	// Delete removes the file stored under path. Deleting a missing file is not an error.
+	Delete(ctx context.Context, path string) error
+}
+
+// ReviewableResolver tells whether a reviewable entity exists in the system
+// that owns it (a product catalog, a vendor directory, ...). Implementations
+// live in internal/infrastructure and internal/repository.
+type ReviewableResolver interface {
+	// Exists reports whether the entity exists. An error means the answer is
+	// unknown, not that the entity is missing.
+	Exists(ctx context.Context, reviewableType string, reviewableID int) (bool, error)
+}
+
+// PurchaseVerifier tells whether a user bought a reviewable entity, so that
+// their review can be marked verified. Implementations live in
+// internal/infrastructure and internal/repository.
+type PurchaseVerifier interface {
+	// HasPurchased reports whether userID bought the entity. An error means the
+	// answer is unknown, not that there was no purchase.
+	HasPurchased(ctx context.Context, userID int, reviewableType string, reviewableID int) (bool, error)
+}
+
+// IdempotencyStore remembers requests made with an Idempotency-Key header
+// and their responses, so that retries are answered with the original
+// response instead of repeating the request. Implementations live in
+// internal/repository and internal/infrastructure.
+type IdempotencyStore interface {
+	// Reserve claims key for a request with the given fingerprint for lease.
+	// When the key is already claimed it returns the existing record and
+	// false. Records past their expiry are free to claim again.
+	Reserve(ctx context.Context, key, fingerprint string, lease time.Duration) (domain.IdempotencyRecord, bool, error)
+
+	// Complete stores the response of the request that reserved rec.Key and
+	// keeps the record for ttl from now.
+	Complete(ctx context.Context, rec domain.IdempotencyRecord, ttl time.Duration) error
+
+	// Release forgets key so that the request can be retried; it is called
+	// when the request failed without a response worth replaying.
+	Release(ctx context.Context, key string) error
+
+	// DeleteExpired removes the records whose ttl ended before the given time
+	// and returns how many were removed. Stores that expire records on their
+	// own return 0.
+	DeleteExpired(ctx context.Context, before time.Time) (int, error)
+}
+
+// RateLimit is a token bucket: it holds up to Burst tokens, every request
+// takes one, and it refills at Burst tokens per Period.
+type RateLimit struct {
+	Burst  int
+	Period time.Duration
+}
+
+// Interval is the time it takes to refill a single token.
+func (l RateLimit) Interval() time.Duration {
+	return l.Period / time.Duration(l.Burst)
+}
+
+// Result describes a bucket holding tokens after a request took one
+// (allowed) or found none left.
+func (l RateLimit) Result(allowed bool, tokens float64) RateLimitResult {
+	interval := float64(l.Interval())
+	res := RateLimitResult{
+		Allowed:    allowed,
+		Remaining:  int(tokens),
+		ResetAfter: time.Duration((float64(l.Burst) - tokens) * interval),
+	}
+	if !allowed {
+		res.RetryAfter = time.Duration((1 - tokens) * interval)
+	}
+	return res
+}
+
+// RateLimitResult is the outcome of taking a token from a bucket.
+type RateLimitResult struct {
+	Allowed    bool
+	Remaining  int           // whole tokens left
+	RetryAfter time.Duration // until the next token, when not allowed
+	ResetAfter time.Duration // until the bucket is full again
+}
+
+// RateLimiter keeps token buckets. Implementations live in
+// internal/infrastructure.
+type RateLimiter interface {
+	// Take takes a token from the bucket key, creating it full when missing.
+	Take(ctx context.Context, key string, limit RateLimit) (RateLimitResult, error)
+}
+
+// Metrics records use-case level measurements. Implementations live in
+// internal/infrastructure (for example a Prometheus implementation).
+type Metrics interface {
+	// ObserveUseCase records the duration and outcome of a single Execute call.
+	ObserveUseCase(name string, duration time.Duration, err error)
+
+	// ReviewCreated increments the number of created reviews.
+	ReviewCreated()
+
+	// CommentCreated increments the number of created comments.
+	CommentCreated()
+
+	// PhotosAttached adds n to the number of photos attached to reviews.
+	PhotosAttached(n int)
 }
@@ -0,0 +1,201 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"eve/domain"
+)
+
+// CriteriaRepository stores the rating criteria of each reviewable type.
+type CriteriaRepository interface {
+	// SetCriteria replaces every criterion of reviewableType with criteria in a
+	// single transaction. An empty slice removes them all.
+	SetCriteria(ctx context.Context, reviewableType string, criteria []domain.RatingCriterion) error
+
+	// ListCriteria returns the criteria of reviewableType ordered by SortOrder.
+	ListCriteria(ctx context.Context, reviewableType string) ([]domain.RatingCriterion, error)
+}
+
+// SetCriteriaUseCase defines the rating criteria of a reviewable type. Only admins may call it.
+type SetCriteriaUseCase struct {
+	users    UserRepository
+	types    ReviewableTypeRepository
+	criteria CriteriaRepository
+	metrics  Metrics
+	log      *slog.Logger
+}
+
+// NewSetCriteriaUseCase constructs a new SetCriteriaUseCase.
+func NewSetCriteriaUseCase(u UserRepository, t ReviewableTypeRepository, c CriteriaRepository, m Metrics, l *slog.Logger) *SetCriteriaUseCase {
+	return &SetCriteriaUseCase{users: u, types: t, criteria: c, metrics: m, log: l}
+}
+
+// Execute replaces the criteria of reviewableType on behalf of actorID and
+// returns them as stored. Sub-ratings already given for removed criteria are
+// kept but no longer summarized.
+func (uc *SetCriteriaUseCase) Execute(ctx context.Context, reviewableType string, req domain.SetCriteriaRequest, actorID int) (_ []domain.RatingCriterion, err error) {
+	ctx, end := track(ctx, uc.metrics, uc.log, "set_criteria")
+	defer end(&err)
+
+	if err := requireRole(ctx, uc.users, actorID, domain.RoleAdmin); err != nil {
+		return nil, err
+	}
+	if reviewableType == "" {
+		return nil, invalidInput("reviewable_type is required")
+	}
+	if _, err := lookupType(ctx, uc.types, reviewableType); err != nil {
+		return nil, err
+	}
+
+	criteria := make([]domain.RatingCriterion, len(req.Criteria))
+	seen := make(map[string]bool, len(req.Criteria))
+	for i, c := range req.Criteria {
+		if !identifier.MatchString(c.Key) {
+			return nil, invalidInput("criterion key %q must be lowercase letters, digits and underscores", c.Key)
+		}
+		if seen[c.Key] {
+			return nil, invalidInput("criterion %q is listed twice", c.Key)
+		}
+		seen[c.Key] = true
+		if c.Label == "" {
+			c.Label = c.Key
+		}
+		c.ReviewableType = reviewableType
+		c.SortOrder = i
+		criteria[i] = c
+	}
+
+	if err := uc.criteria.SetCriteria(ctx, reviewableType, criteria); err != nil {
+		return nil, fmt.Errorf("set criteria: %w", err)
+	}
+	uc.log.InfoContext(ctx, "rating criteria set",
+		slog.String("reviewable_type", reviewableType),
+		slog.Int("count", len(criteria)),
+		slog.Int("user_id", actorID),
+	)
+	return criteria, nil
+}
+
+// ListCriteriaUseCase returns the rating criteria of a reviewable type.
+type ListCriteriaUseCase struct {
+	criteria CriteriaRepository
+	metrics  Metrics
+	log      *slog.Logger
+}
+
+// NewListCriteriaUseCase constructs a new ListCriteriaUseCase.
+func NewListCriteriaUseCase(c CriteriaRepository, m Metrics, l *slog.Logger) *ListCriteriaUseCase {
+	return &ListCriteriaUseCase{criteria: c, metrics: m, log: l}
+}
+
+// Execute returns the criteria of reviewableType ordered by SortOrder.
+func (uc *ListCriteriaUseCase) Execute(ctx context.Context, reviewableType string) (_ []domain.RatingCriterion, err error) {
+	ctx, end := track(ctx, uc.metrics, uc.log, "list_criteria")
+	defer end(&err)
+
+	if reviewableType == "" {
+		return nil, invalidInput("reviewable_type is required")
+	}
+	if err := checkTypeScope(ctx, reviewableType); err != nil {
+		return nil, err
+	}
+	return uc.criteria.ListCriteria(ctx, reviewableType)
+}
+
+// GetReviewSummaryUseCase aggregates the ratings of a reviewable entity.
+type GetReviewSummaryUseCase struct {
+	reviews  ReviewRepository
+	criteria CriteriaRepository
+	metrics  Metrics
+	log      *slog.Logger
+}
+
+// NewGetReviewSummaryUseCase constructs a new GetReviewSummaryUseCase.
+func NewGetReviewSummaryUseCase(r ReviewRepository, c CriteriaRepository, m Metrics, l *slog.Logger) *GetReviewSummaryUseCase {
+	return &GetReviewSummaryUseCase{reviews: r, criteria: c, metrics: m, log: l}
+}
+
+// Execute returns the review count, the average overall rating and the average
+// of every criterion currently defined for the reviewable's type, in criteria
+// order. Criteria nobody rated yet are reported with a zero Count.
+func (uc *GetReviewSummaryUseCase) Execute(ctx context.Context, reviewableType string, reviewableID int) (_ domain.ReviewSummary, err error) {
+	ctx, end := track(ctx, uc.metrics, uc.log, "get_review_summary")
+	defer end(&err)
+
+	if reviewableType == "" || reviewableID == 0 {
+		return domain.ReviewSummary{}, invalidInput("reviewable_type and reviewable_id are required")
+	}
+	if err := checkTypeScope(ctx, reviewableType); err != nil {
+		return domain.ReviewSummary{}, err
+	}
+
+	summary, err := uc.reviews.Summarize(ctx, reviewableType, reviewableID)
+	if err != nil {
+		return domain.ReviewSummary{}, fmt.Errorf("summarize reviews: %w", err)
+	}
+	criteria, err := uc.criteria.ListCriteria(ctx, reviewableType)
+	if err != nil {
+		return domain.ReviewSummary{}, fmt.Errorf("list criteria: %w", err)
+	}
+
+	averages := make([]domain.CriterionAverage, len(criteria))
+	for i, c := range criteria {
+		averages[i] = domain.CriterionAverage{Key: c.Key, Label: c.Label}
+		if j := slices.IndexFunc(summary.Criteria, func(a domain.CriterionAverage) bool { return a.Key == c.Key }); j >= 0 {
+			averages[i].Count = summary.Criteria[j].Count
+			averages[i].Average = summary.Criteria[j].Average
+		}
+	}
+	summary.Criteria = averages
+	return summary, nil
+}
+
+// validateSubRatings checks sub-ratings against the criteria of the review's
+// type: every key must be a defined criterion, every rating must be within
+// 1..5 and every required criterion must be rated.
+func validateSubRatings(criteria []domain.RatingCriterion, subRatings map[string]int) error {
+	defined := make(map[string]bool, len(criteria))
+	for _, c := range criteria {
+		defined[c.Key] = true
+		if _, ok := subRatings[c.Key]; c.Required && !ok {
+			return invalidInput("sub-rating %q is required", c.Key)
+		}
+	}
+	// Sorted so that the reported key does not depend on map order.
+	keys := make([]string, 0, len(subRatings))
+	for k := range subRatings {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		if !defined[k] {
+			return invalidInput("unknown rating criterion %q", k)
+		}
+		if r := subRatings[k]; r < 1 || r > 5 {
+			return invalidInput("sub-rating %q must be between 1 and 5", k)
+		}
+	}
+	return nil
+}
+
+// attachSubRatings sets the SubRatings of every review that has any.
+func attachSubRatings(ctx context.Context, repo ReviewRepository, reviews []domain.Review) error {
+	if len(reviews) == 0 {
+		return nil
+	}
+	ids := make([]int, len(reviews))
+	for i, r := range reviews {
+		ids[i] = r.ID
+	}
+	subRatings, err := repo.ListSubRatings(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("list sub-ratings: %w", err)
+	}
+	for i := range reviews {
+		reviews[i].SubRatings = subRatings[reviews[i].ID]
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"eve/domain"
+	"eve/internal/logging"
+	"eve/internal/repository/memory"
+	"eve/internal/usecase"
+)
+
+func TestSetCriteriaUseCase(t *testing.T) {
+	users := memory.NewUserRepo()
+	admin := seedUser(t, users, "admin@example.com", domain.RoleAdmin)
+	moderator := seedUser(t, users, "mod@example.com", domain.RoleModerator)
+
+	tests := []struct {
+		name     string
+		typ      string
+		actor    int
+		criteria []domain.RatingCriterion
+		wantErr  error
+		wantKeys []string
+	}{
+		{
+			name:     "admin sets criteria",
+			typ:      "hotel",
+			actor:    admin,
+			criteria: []domain.RatingCriterion{{Key: "location", Label: "Location"}, {Key: "cleanliness", Required: true}},
+			wantKeys: []string{"location", "cleanliness"},
+		},
+		{name: "moderator is forbidden", typ: "hotel", actor: moderator, criteria: []domain.RatingCriterion{{Key: "value"}}, wantErr: usecase.ErrForbidden},
+		{name: "missing type", actor: admin, wantErr: usecase.ErrInvalidInput},
+		{name: "unregistered type", typ: "hotle", actor: admin, criteria: []domain.RatingCriterion{{Key: "value"}}, wantErr: usecase.ErrInvalidInput},
+		{name: "invalid key", typ: "hotel", actor: admin, criteria: []domain.RatingCriterion{{Key: "Wi-Fi"}}, wantErr: usecase.ErrInvalidInput},
+		{name: "duplicate key", typ: "hotel", actor: admin, criteria: []domain.RatingCriterion{{Key: "value"}, {Key: "value"}}, wantErr: usecase.ErrInvalidInput},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := memory.NewCriteriaRepo()
+			uc := usecase.NewSetCriteriaUseCase(users, seedTypes(t, "hotel"), repo, usecase.NopMetrics{}, logging.Nop())
+
+			_, err := uc.Execute(ctx, tt.typ, domain.SetCriteriaRequest{Criteria: tt.criteria}, tt.actor)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Execute() error = %v, want %v", err, tt.wantErr)
+			}
+
+			stored, _ := repo.ListCriteria(ctx, tt.typ)
+			var keys []string
+			for _, c := range stored {
+				keys = append(keys, c.Key)
+				if c.Label == "" || c.ReviewableType != tt.typ {
+					t.Errorf("stored criterion = %+v, want label and type filled in", c)
+				}
+			}
+			if !slices.Equal(keys, tt.wantKeys) {
+				t.Errorf("stored keys = %v, want %v", keys, tt.wantKeys)
+			}
+		})
+	}
+}
+
+func TestGetReviewSummaryUseCase(t *testing.T) {
+	ctx := context.Background()
+	reviews := memory.NewReviewRepo()
+	criteria := memory.NewCriteriaRepo()
+	_ = criteria.SetCriteria(ctx, "hotel", []domain.RatingCriterion{
+		{Key: "location", Label: "Location"},
+		{Key: "cleanliness", Label: "Cleanliness", SortOrder: 1},
+	})
+	for _, r := range []domain.Review{
+		{Rating: 5, SubRatings: map[string]int{"cleanliness": 5, "breakfast": 2}},
+		{Rating: 2, SubRatings: map[string]int{"cleanliness": 2}},
+	} {
+		r.ReviewableType, r.ReviewableID, r.UserID = "hotel", 7, 1
+		if _, err := reviews.Create(ctx, r); err != nil {
+			t.Fatalf("seed review: %v", err)
+		}
+	}
+	uc := usecase.NewGetReviewSummaryUseCase(reviews, criteria, usecase.NopMetrics{}, logging.Nop())
+
+	got, err := uc.Execute(ctx, "hotel", 7)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	// Criteria follow their definition order; "breakfast" is no longer defined.
+	want := []domain.CriterionAverage{
+		{Key: "location", Label: "Location"},
+		{Key: "cleanliness", Label: "Cleanliness", Count: 2, Average: 3.5},
+	}
+	if got.Count != 2 || got.AverageRating != 3.5 || !slices.Equal(got.Criteria, want) {
+		t.Errorf("Execute() = %+v, want 2 reviews averaging 3.5 and criteria %+v", got, want)
+	}
+
+	if _, err := uc.Execute(ctx, "hotel", 0); !errors.Is(err, usecase.ErrInvalidInput) {
+		t.Errorf("Execute(no id) error = %v, want ErrInvalidInput", err)
+	}
+}
@@ -0,0 +1,148 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"eve/domain"
+)
+
+// purgeBatchSize bounds the number of reviews hard-deleted per transaction.
+const purgeBatchSize = 100
+
+// DeleteReviewUseCase soft-deletes a review. Authors may delete their own
+// reviews; moderators and admins may delete any review.
+type DeleteReviewUseCase struct {
+	reviews ReviewRepository
+	users   UserRepository
+	metrics Metrics
+	log     *slog.Logger
+}
+
+// NewDeleteReviewUseCase constructs a new DeleteReviewUseCase.
+func NewDeleteReviewUseCase(r ReviewRepository, u UserRepository, m Metrics, l *slog.Logger) *DeleteReviewUseCase {
+	return &DeleteReviewUseCase{reviews: r, users: u, metrics: m, log: l}
+}
+
+// Execute hides the review on behalf of actorID. It can be restored until
+// the purge job removes it. As with UpdateReviewUseCase, a review that
+// changes after check was evaluated is not deleted.
+func (uc *DeleteReviewUseCase) Execute(ctx context.Context, reviewID, actorID int, check ReviewPrecondition) (err error) {
+	ctx, end := track(ctx, uc.metrics, uc.log, "delete_review")
+	defer end(&err)
+
+	if reviewID == 0 {
+		return invalidInput("review id is required")
+	}
+	review, err := uc.reviews.GetByID(ctx, reviewID)
+	if err != nil {
+		return notFound(err, "get review", "review %d not found", reviewID)
+	}
+	if err := checkTypeScope(ctx, review.ReviewableType); err != nil {
+		return err
+	}
+	if err := requireAuthorOrRole(ctx, uc.users, actorID, review.UserID, domain.RoleModerator, domain.RoleAdmin); err != nil {
+		return err
+	}
+	if err := checkPrecondition(ctx, uc.reviews, review, check); err != nil {
+		return err
+	}
+
+	if err := uc.reviews.DeleteReview(ctx, reviewID, review.UpdatedAt); err != nil {
+		return reviewChanged(err, "delete review", reviewID)
+	}
+	uc.log.InfoContext(ctx, "review deleted",
+		slog.Int("review_id", reviewID),
+		slog.Int("user_id", actorID),
+	)
+	return nil
+}
+
+// RestoreReviewUseCase brings back a soft-deleted review. Only moderators and admins may call it.
+type RestoreReviewUseCase struct {
+	reviews ReviewRepository
+	users   UserRepository
+	metrics Metrics
+	log     *slog.Logger
+}
+
+// NewRestoreReviewUseCase constructs a new RestoreReviewUseCase.
+func NewRestoreReviewUseCase(r ReviewRepository, u UserRepository, m Metrics, l *slog.Logger) *RestoreReviewUseCase {
+	return &RestoreReviewUseCase{reviews: r, users: u, metrics: m, log: l}
+}
+
+// Execute restores the review on behalf of actorID.
+func (uc *RestoreReviewUseCase) Execute(ctx context.Context, reviewID, actorID int) (err error) {
+	ctx, end := track(ctx, uc.metrics, uc.log, "restore_review")
+	defer end(&err)
+
+	if err := requireRole(ctx, uc.users, actorID, domain.RoleModerator, domain.RoleAdmin); err != nil {
+		return err
+	}
+	if reviewID == 0 {
+		return invalidInput("review id is required")
+	}
+
+	if err := uc.reviews.RestoreReview(ctx, reviewID); err != nil {
+		return notFound(err, "restore review", "no deleted review %d", reviewID)
+	}
+	uc.log.InfoContext(ctx, "review restored",
+		slog.Int("review_id", reviewID),
+		slog.Int("user_id", actorID),
+	)
+	return nil
+}
+
+// PurgeDeletedReviewsUseCase hard-deletes reviews whose soft deletion is older
+// than the retention period and removes their photo files. It is run
+// periodically by a background worker.
+type PurgeDeletedReviewsUseCase struct {
+	reviews   ReviewRepository
+	blobs     BlobStore
+	retention time.Duration
+	metrics   Metrics
+	log       *slog.Logger
+}
+
+// NewPurgeDeletedReviewsUseCase constructs a new PurgeDeletedReviewsUseCase.
+func NewPurgeDeletedReviewsUseCase(r ReviewRepository, b BlobStore, retention time.Duration, m Metrics, l *slog.Logger) *PurgeDeletedReviewsUseCase {
+	return &PurgeDeletedReviewsUseCase{reviews: r, blobs: b, retention: retention, metrics: m, log: l}
+}
+
+// Execute purges every review deleted more than the retention period ago, in
+// batches, and returns how many were purged. Files that cannot be removed are
+// logged and left behind; the database rows are gone either way.
+func (uc *PurgeDeletedReviewsUseCase) Execute(ctx context.Context) (purged int, err error) {
+	ctx, end := track(ctx, uc.metrics, uc.log, "purge_deleted_reviews")
+	defer end(&err)
+
+	cutoff := time.Now().Add(-uc.retention)
+	for {
+		batch, err := uc.reviews.PurgeDeletedReviews(ctx, cutoff, purgeBatchSize)
+		if err != nil {
+			return purged, fmt.Errorf("purge deleted reviews: %w", err)
+		}
+		for _, path := range batch.FilePaths {
+			if err := uc.blobs.Delete(ctx, path); err != nil {
+				uc.log.WarnContext(ctx, "remove purged photo",
+					slog.String("path", path),
+					slog.String("error", err.Error()),
+				)
+			}
+		}
+		purged += len(batch.ReviewIDs)
+		if len(batch.ReviewIDs) < purgeBatchSize {
+			break
+		}
+	}
+
+	if purged > 0 {
+		uc.log.InfoContext(ctx, "deleted reviews purged",
+			slog.Int("count", purged),
+			slog.Duration("retention", uc.retention),
+		)
+	}
+	return purged, nil
+}
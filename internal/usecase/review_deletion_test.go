@@ -0,0 +1,150 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"eve/domain"
+	"eve/internal/logging"
+	"eve/internal/repository/memory"
+	"eve/internal/usecase"
+)
+
+func TestDeleteAndRestoreReview(t *testing.T) {
+	users := memory.NewUserRepo()
+	author := seedUser(t, users, "author@example.com", domain.RoleUser) // seedReview authors reviews as user 1
+	stranger := seedUser(t, users, "stranger@example.com", domain.RoleUser)
+	moderator := seedUser(t, users, "mod@example.com", domain.RoleModerator)
+
+	tests := []struct {
+		name       string
+		reviewID   int // 0 uses the seeded review
+		actor      int
+		wantErr    error
+		wantHidden bool
+	}{
+		{name: "author deletes", actor: author, wantHidden: true},
+		{name: "moderator deletes", actor: moderator, wantHidden: true},
+		{name: "stranger is forbidden", actor: stranger, wantErr: usecase.ErrForbidden},
+		{name: "unknown review", reviewID: 999, actor: author, wantErr: usecase.ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := memory.NewReviewRepo()
+			id := seedReview(t, repo, "product", 1)
+			if tt.reviewID != 0 {
+				id = tt.reviewID
+			}
+			uc := usecase.NewDeleteReviewUseCase(repo, users, usecase.NopMetrics{}, logging.Nop())
+
+			err := uc.Execute(ctx, id, tt.actor, nil)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Execute() error = %v, want %v", err, tt.wantErr)
+			}
+			if _, err := repo.GetByID(ctx, id); (err != nil) != tt.wantHidden && tt.reviewID == 0 {
+				t.Errorf("GetByID() error = %v, want hidden %v", err, tt.wantHidden)
+			}
+		})
+	}
+
+	t.Run("changed after the precondition", func(t *testing.T) {
+		ctx := context.Background()
+		repo := memory.NewReviewRepo()
+		id := seedReview(t, repo, "product", 1)
+		uc := usecase.NewDeleteReviewUseCase(repo, users, usecase.NopMetrics{}, logging.Nop())
+
+		raced := func(review domain.Review, _ []domain.ReviewComment) error {
+			review.Title = "Edited meanwhile"
+			_, err := repo.UpdateReview(ctx, review, author, "")
+			return err
+		}
+		if err := uc.Execute(ctx, id, author, raced); !errors.Is(err, usecase.ErrPreconditionFailed) {
+			t.Fatalf("Execute() error = %v, want ErrPreconditionFailed", err)
+		}
+		if _, err := repo.GetByID(ctx, id); err != nil {
+			t.Errorf("GetByID() error = %v, want the review kept", err)
+		}
+	})
+
+	t.Run("restore", func(t *testing.T) {
+		ctx := context.Background()
+		repo := memory.NewReviewRepo()
+		id := seedReview(t, repo, "product", 1)
+		uc := usecase.NewRestoreReviewUseCase(repo, users, usecase.NopMetrics{}, logging.Nop())
+
+		if err := uc.Execute(ctx, id, moderator); !errors.Is(err, usecase.ErrNotFound) {
+			t.Errorf("restore live review error = %v, want ErrNotFound", err)
+		}
+		if err := repo.DeleteReview(ctx, id, ""); err != nil {
+			t.Fatalf("DeleteReview: %v", err)
+		}
+		if err := uc.Execute(ctx, id, author); !errors.Is(err, usecase.ErrForbidden) {
+			t.Errorf("author restore error = %v, want ErrForbidden", err)
+		}
+		if err := uc.Execute(ctx, id, moderator); err != nil {
+			t.Fatalf("moderator restore: %v", err)
+		}
+		if _, err := repo.GetByID(ctx, id); err != nil {
+			t.Errorf("restored review is still hidden: %v", err)
+		}
+	})
+}
+
+// recordingBlobStore remembers which paths were deleted and fails for one of them.
+type recordingBlobStore struct {
+	deleted []string
+	failing string
+}
+
+func (s *recordingBlobStore) Delete(_ context.Context, path string) error {
+	if path == s.failing {
+		return errors.New("disk on fire")
+	}
+	s.deleted = append(s.deleted, path)
+	return nil
+}
+
+func TestPurgeDeletedReviewsUseCase(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewReviewRepo()
+	deleted := seedReview(t, repo, "product", 1)
+	kept := seedReview(t, repo, "product", 1)
+	for id, path := range map[int]string{deleted: "a.jpg", kept: "b.jpg"} {
+		if err := repo.AddPhotos(ctx, id, []domain.ReviewPhoto{{FilePath: path}, {FilePath: "broken-" + path}}); err != nil {
+			t.Fatalf("AddPhotos: %v", err)
+		}
+	}
+	if err := repo.DeleteReview(ctx, deleted, ""); err != nil {
+		t.Fatalf("DeleteReview: %v", err)
+	}
+	blobs := &recordingBlobStore{failing: "broken-a.jpg"}
+
+	// A long retention keeps the fresh deletion around.
+	uc := usecase.NewPurgeDeletedReviewsUseCase(repo, blobs, time.Hour, usecase.NopMetrics{}, logging.Nop())
+	if n, err := uc.Execute(ctx); err != nil || n != 0 {
+		t.Fatalf("Execute() = %d, %v; want nothing purged", n, err)
+	}
+
+	uc = usecase.NewPurgeDeletedReviewsUseCase(repo, blobs, 0, usecase.NopMetrics{}, logging.Nop())
+	n, err := uc.Execute(ctx)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if n != 1 {
+		t.Errorf("Execute() purged %d reviews, want 1", n)
+	}
+	if !slices.Equal(blobs.deleted, []string{"a.jpg"}) {
+		t.Errorf("deleted blobs = %v, want [a.jpg]", blobs.deleted)
+	}
+	if err := repo.RestoreReview(ctx, deleted); err == nil {
+		t.Error("purged review can still be restored")
+	}
+	if _, err := repo.GetByID(ctx, kept); err != nil {
+		t.Errorf("live review was purged: %v", err)
+	}
+}
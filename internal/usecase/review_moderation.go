@@ -0,0 +1,91 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"eve/domain"
+)
+
+// ApproveReviewUseCase publishes a review held back by pre-moderation. Only
+// moderators and admins may call it.
+type ApproveReviewUseCase struct {
+	reviews ReviewRepository
+	users   UserRepository
+	metrics Metrics
+	log     *slog.Logger
+}
+
+// NewApproveReviewUseCase constructs a new ApproveReviewUseCase.
+func NewApproveReviewUseCase(r ReviewRepository, u UserRepository, m Metrics, l *slog.Logger) *ApproveReviewUseCase {
+	return &ApproveReviewUseCase{reviews: r, users: u, metrics: m, log: l}
+}
+
+// Execute publishes the pending review on behalf of actorID.
+func (uc *ApproveReviewUseCase) Execute(ctx context.Context, reviewID, actorID int) (err error) {
+	ctx, end := track(ctx, uc.metrics, uc.log, "approve_review")
+	defer end(&err)
+
+	if err := requireRole(ctx, uc.users, actorID, domain.RoleModerator, domain.RoleAdmin); err != nil {
+		return err
+	}
+	if reviewID == 0 {
+		return invalidInput("review id is required")
+	}
+
+	if err := uc.reviews.ApproveReview(ctx, reviewID); err != nil {
+		return notFound(err, "approve review", "no pending review %d", reviewID)
+	}
+	uc.log.InfoContext(ctx, "review approved",
+		slog.Int("review_id", reviewID),
+		slog.Int("user_id", actorID),
+	)
+	return nil
+}
+
+// Moderation queue paging limits.
+const (
+	DefaultPendingLimit = 50
+	MaxPendingLimit     = 100
+)
+
+// ListPendingReviewsUseCase returns the reviews awaiting moderation. Only
+// moderators and admins may call it.
+type ListPendingReviewsUseCase struct {
+	reviews ReviewRepository
+	users   UserRepository
+	metrics Metrics
+	log     *slog.Logger
+}
+
+// NewListPendingReviewsUseCase constructs a new ListPendingReviewsUseCase.
+func NewListPendingReviewsUseCase(r ReviewRepository, u UserRepository, m Metrics, l *slog.Logger) *ListPendingReviewsUseCase {
+	return &ListPendingReviewsUseCase{reviews: r, users: u, metrics: m, log: l}
+}
+
+// Execute returns a page of the moderation queue, oldest review first, on
+// behalf of actorID. A zero limit means DefaultPendingLimit.
+func (uc *ListPendingReviewsUseCase) Execute(ctx context.Context, limit, offset, actorID int) (_ []domain.Review, err error) {
+	ctx, end := track(ctx, uc.metrics, uc.log, "list_pending_reviews")
+	defer end(&err)
+
+	if err := requireRole(ctx, uc.users, actorID, domain.RoleModerator, domain.RoleAdmin); err != nil {
+		return nil, err
+	}
+	if limit == 0 {
+		limit = DefaultPendingLimit
+	}
+	if limit < 0 || limit > MaxPendingLimit {
+		return nil, invalidInput("limit must be between 1 and %d", MaxPendingLimit)
+	}
+	if offset < 0 {
+		return nil, invalidInput("offset must not be negative")
+	}
+
+	reviews, err := uc.reviews.ListPending(ctx, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("list pending reviews: %w", err)
+	}
+	return reviews, nil
+}
@@ -0,0 +1,259 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"eve/domain"
+)
+
+// OwnerRepository stores which users own which reviewable entities.
+type OwnerRepository interface {
+	// AddOwner grants ownership. Granting an existing ownership again is a no-op.
+	AddOwner(ctx context.Context, owner domain.ReviewableOwner) error
+
+	// RemoveOwner revokes ownership. Revoking an ownership that does not exist
+	// yields an error wrapping sql.ErrNoRows.
+	RemoveOwner(ctx context.Context, reviewableType string, reviewableID, userID int) error
+
+	// ListOwners returns the owners of a reviewable entity ordered by user ID.
+	ListOwners(ctx context.Context, reviewableType string, reviewableID int) ([]domain.ReviewableOwner, error)
+
+	// IsOwner reports whether the user owns the reviewable entity.
+	IsOwner(ctx context.Context, reviewableType string, reviewableID, userID int) (bool, error)
+}
+
+// AddOwnerUseCase grants a user ownership of a reviewable entity. Only admins may call it.
+type AddOwnerUseCase struct {
+	users   UserRepository
+	owners  OwnerRepository
+	metrics Metrics
+	log     *slog.Logger
+}
+
+// NewAddOwnerUseCase constructs a new AddOwnerUseCase.
+func NewAddOwnerUseCase(u UserRepository, o OwnerRepository, m Metrics, l *slog.Logger) *AddOwnerUseCase {
+	return &AddOwnerUseCase{users: u, owners: o, metrics: m, log: l}
+}
+
+// Execute makes owner.UserID an owner of the given reviewable on behalf of actorID.
+func (uc *AddOwnerUseCase) Execute(ctx context.Context, owner domain.ReviewableOwner, actorID int) (err error) {
+	ctx, end := track(ctx, uc.metrics, uc.log, "add_owner")
+	defer end(&err)
+
+	if err := requireRole(ctx, uc.users, actorID, domain.RoleAdmin); err != nil {
+		return err
+	}
+	if owner.ReviewableType == "" || owner.ReviewableID == 0 {
+		return invalidInput("reviewable_type and reviewable_id are required")
+	}
+	if owner.UserID == 0 {
+		return invalidInput("user_id is required")
+	}
+	if err := checkTypeScope(ctx, owner.ReviewableType); err != nil {
+		return err
+	}
+	if _, err := uc.users.GetByID(ctx, owner.UserID); err != nil {
+		return notFound(err, "get user", "user %d not found", owner.UserID)
+	}
+
+	if err := uc.owners.AddOwner(ctx, owner); err != nil {
+		return fmt.Errorf("add owner: %w", err)
+	}
+	uc.log.InfoContext(ctx, "owner added",
+		slog.String("reviewable_type", owner.ReviewableType),
+		slog.Int("reviewable_id", owner.ReviewableID),
+		slog.Int("owner_id", owner.UserID),
+		slog.Int("user_id", actorID),
+	)
+	return nil
+}
+
+// RemoveOwnerUseCase revokes a user's ownership of a reviewable entity. Only admins may call it.
+type RemoveOwnerUseCase struct {
+	users   UserRepository
+	owners  OwnerRepository
+	metrics Metrics
+	log     *slog.Logger
+}
+
+// NewRemoveOwnerUseCase constructs a new RemoveOwnerUseCase.
+func NewRemoveOwnerUseCase(u UserRepository, o OwnerRepository, m Metrics, l *slog.Logger) *RemoveOwnerUseCase {
+	return &RemoveOwnerUseCase{users: u, owners: o, metrics: m, log: l}
+}
+
+// Execute revokes the ownership on behalf of actorID. Existing responses stay in place.
+func (uc *RemoveOwnerUseCase) Execute(ctx context.Context, reviewableType string, reviewableID, userID, actorID int) (err error) {
+	ctx, end := track(ctx, uc.metrics, uc.log, "remove_owner")
+	defer end(&err)
+
+	if err := requireRole(ctx, uc.users, actorID, domain.RoleAdmin); err != nil {
+		return err
+	}
+	if err := checkTypeScope(ctx, reviewableType); err != nil {
+		return err
+	}
+	if err := uc.owners.RemoveOwner(ctx, reviewableType, reviewableID, userID); err != nil {
+		return notFound(err, "remove owner", "user %d does not own %s:%d", userID, reviewableType, reviewableID)
+	}
+	uc.log.InfoContext(ctx, "owner removed",
+		slog.String("reviewable_type", reviewableType),
+		slog.Int("reviewable_id", reviewableID),
+		slog.Int("owner_id", userID),
+		slog.Int("user_id", actorID),
+	)
+	return nil
+}
+
+// ListOwnersUseCase returns the owners of a reviewable entity.
+type ListOwnersUseCase struct {
+	owners  OwnerRepository
+	metrics Metrics
+	log     *slog.Logger
+}
+
+// NewListOwnersUseCase constructs a new ListOwnersUseCase.
+func NewListOwnersUseCase(o OwnerRepository, m Metrics, l *slog.Logger) *ListOwnersUseCase {
+	return &ListOwnersUseCase{owners: o, metrics: m, log: l}
+}
+
+// Execute returns the owners of the given reviewable ordered by user ID.
+func (uc *ListOwnersUseCase) Execute(ctx context.Context, reviewableType string, reviewableID int) (_ []domain.ReviewableOwner, err error) {
+	ctx, end := track(ctx, uc.metrics, uc.log, "list_owners")
+	defer end(&err)
+
+	if reviewableType == "" || reviewableID == 0 {
+		return nil, invalidInput("reviewable_type and reviewable_id are required")
+	}
+	if err := checkTypeScope(ctx, reviewableType); err != nil {
+		return nil, err
+	}
+	owners, err := uc.owners.ListOwners(ctx, reviewableType, reviewableID)
+	if err != nil {
+		return nil, fmt.Errorf("list owners: %w", err)
+	}
+	if owners == nil {
+		owners = []domain.ReviewableOwner{}
+	}
+	return owners, nil
+}
+
+// SaveResponseUseCase creates or edits the official response to a review.
+// Only owners of the reviewed entity may respond; every save is kept as a version.
+type SaveResponseUseCase struct {
+	reviews ReviewRepository
+	owners  OwnerRepository
+	metrics Metrics
+	log     *slog.Logger
+}
+
+// NewSaveResponseUseCase constructs a new SaveResponseUseCase.
+func NewSaveResponseUseCase(r ReviewRepository, o OwnerRepository, m Metrics, l *slog.Logger) *SaveResponseUseCase {
+	return &SaveResponseUseCase{reviews: r, owners: o, metrics: m, log: l}
+}
+
+// Execute stores req as the response of actorID to the review and returns the stored response.
+func (uc *SaveResponseUseCase) Execute(ctx context.Context, reviewID int, req domain.SaveResponseRequest, actorID int) (_ domain.ReviewResponse, err error) {
+	ctx, end := track(ctx, uc.metrics, uc.log, "save_response")
+	defer end(&err)
+
+	if reviewID == 0 {
+		return domain.ReviewResponse{}, invalidInput("review id is required")
+	}
+	if req.Body == "" {
+		return domain.ReviewResponse{}, invalidInput("body is required")
+	}
+
+	review, err := uc.reviews.GetByID(ctx, reviewID)
+	if err != nil {
+		return domain.ReviewResponse{}, notFound(err, "get review", "review %d not found", reviewID)
+	}
+	if err := checkTypeScope(ctx, review.ReviewableType); err != nil {
+		return domain.ReviewResponse{}, err
+	}
+	owner, err := uc.owners.IsOwner(ctx, review.ReviewableType, review.ReviewableID, actorID)
+	if err != nil {
+		return domain.ReviewResponse{}, fmt.Errorf("check owner: %w", err)
+	}
+	if !owner {
+		return domain.ReviewResponse{}, forbidden("only owners of %s:%d may respond to its reviews", review.ReviewableType, review.ReviewableID)
+	}
+
+	resp, err := uc.reviews.SaveResponse(ctx, domain.ReviewResponse{ReviewID: reviewID, UserID: actorID, Body: req.Body})
+	if err != nil {
+		return domain.ReviewResponse{}, fmt.Errorf("save response: %w", err)
+	}
+	uc.log.InfoContext(ctx, "response saved",
+		slog.Int("response_id", resp.ID),
+		slog.Int("review_id", reviewID),
+		slog.Int("user_id", actorID),
+	)
+	return resp, nil
+}
+
+// ListResponseVersionsUseCase returns the edit history of a review's official response.
+type ListResponseVersionsUseCase struct {
+	reviews ReviewRepository
+	users   UserRepository
+	metrics Metrics
+	log     *slog.Logger
+}
+
+// NewListResponseVersionsUseCase constructs a new ListResponseVersionsUseCase.
+func NewListResponseVersionsUseCase(r ReviewRepository, u UserRepository, m Metrics, l *slog.Logger) *ListResponseVersionsUseCase {
+	return &ListResponseVersionsUseCase{reviews: r, users: u, metrics: m, log: l}
+}
+
+// Execute returns every version of the response, oldest first. A review
+// without a response has an empty history. As with GetReviewUseCase, a
+// review awaiting moderation is only found by its author, moderators and
+// admins; viewerID is 0 for anonymous callers.
+func (uc *ListResponseVersionsUseCase) Execute(ctx context.Context, reviewID, viewerID int) (_ []domain.ReviewResponseVersion, err error) {
+	ctx, end := track(ctx, uc.metrics, uc.log, "list_response_versions")
+	defer end(&err)
+
+	if reviewID == 0 {
+		return nil, invalidInput("review id is required")
+	}
+	review, err := uc.reviews.GetByID(ctx, reviewID)
+	if err != nil {
+		return nil, notFound(err, "get review", "review %d not found", reviewID)
+	}
+	if err := checkReviewVisible(ctx, uc.users, review, viewerID); err != nil {
+		return nil, err
+	}
+	if err := checkTypeScope(ctx, review.ReviewableType); err != nil {
+		return nil, err
+	}
+
+	versions, err := uc.reviews.ListResponseVersions(ctx, reviewID)
+	if err != nil {
+		return nil, fmt.Errorf("list response versions: %w", err)
+	}
+	if versions == nil {
+		versions = []domain.ReviewResponseVersion{}
+	}
+	return versions, nil
+}
+
+// attachResponses sets the Response of every review that has one.
+func attachResponses(ctx context.Context, repo ReviewRepository, reviews []domain.Review) error {
+	if len(reviews) == 0 {
+		return nil
+	}
+	ids := make([]int, len(reviews))
+	for i, r := range reviews {
+		ids[i] = r.ID
+	}
+	responses, err := repo.ListResponses(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("list responses: %w", err)
+	}
+	for i := range reviews {
+		if resp, ok := responses[reviews[i].ID]; ok {
+			reviews[i].Response = &resp
+		}
+	}
+	return nil
+}
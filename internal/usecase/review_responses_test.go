@@ -0,0 +1,179 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"eve/domain"
+	"eve/internal/logging"
+	"eve/internal/repository/memory"
+	"eve/internal/usecase"
+)
+
+// seedUser saves a user with the given role and returns its ID.
+func seedUser(t *testing.T, repo *memory.UserRepo, email, role string) int {
+	t.Helper()
+	ctx := context.Background()
+	if err := repo.Save(ctx, domain.User{Email: email, Password: "x"}); err != nil {
+		t.Fatalf("seed user: %v", err)
+	}
+	users, _ := repo.GetAll(ctx)
+	id := users[len(users)-1].ID
+	repo.SetRole(id, role)
+	return id
+}
+
+func TestAddOwnerUseCase(t *testing.T) {
+	users := memory.NewUserRepo()
+	admin := seedUser(t, users, "admin@example.com", domain.RoleAdmin)
+	moderator := seedUser(t, users, "mod@example.com", domain.RoleModerator)
+	vendor := seedUser(t, users, "vendor@example.com", domain.RoleUser)
+
+	tests := []struct {
+		name    string
+		actor   int
+		owner   domain.ReviewableOwner
+		wantErr error
+	}{
+		{name: "admin grants ownership", actor: admin, owner: domain.ReviewableOwner{ReviewableType: "product", ReviewableID: 42, UserID: vendor}},
+		{name: "moderator is forbidden", actor: moderator, owner: domain.ReviewableOwner{ReviewableType: "product", ReviewableID: 42, UserID: vendor}, wantErr: usecase.ErrForbidden},
+		{name: "unknown actor is forbidden", actor: 999, owner: domain.ReviewableOwner{ReviewableType: "product", ReviewableID: 42, UserID: vendor}, wantErr: usecase.ErrForbidden},
+		{name: "missing reviewable", actor: admin, owner: domain.ReviewableOwner{UserID: vendor}, wantErr: usecase.ErrInvalidInput},
+		{name: "missing user", actor: admin, owner: domain.ReviewableOwner{ReviewableType: "product", ReviewableID: 42}, wantErr: usecase.ErrInvalidInput},
+		{name: "unknown user", actor: admin, owner: domain.ReviewableOwner{ReviewableType: "product", ReviewableID: 42, UserID: 999}, wantErr: usecase.ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			owners := memory.NewOwnerRepo()
+			uc := usecase.NewAddOwnerUseCase(users, owners, usecase.NopMetrics{}, logging.Nop())
+
+			err := uc.Execute(ctx, tt.owner, tt.actor)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Execute() error = %v, want %v", err, tt.wantErr)
+			}
+			ok, _ := owners.IsOwner(ctx, tt.owner.ReviewableType, tt.owner.ReviewableID, tt.owner.UserID)
+			if ok != (tt.wantErr == nil) {
+				t.Errorf("IsOwner() = %v after Execute() error %v", ok, err)
+			}
+		})
+	}
+}
+
+func TestRemoveOwnerUseCase(t *testing.T) {
+	ctx := context.Background()
+	users := memory.NewUserRepo()
+	admin := seedUser(t, users, "admin@example.com", domain.RoleAdmin)
+	vendor := seedUser(t, users, "vendor@example.com", domain.RoleUser)
+	owners := memory.NewOwnerRepo()
+	_ = owners.AddOwner(ctx, domain.ReviewableOwner{ReviewableType: "product", ReviewableID: 42, UserID: vendor})
+
+	uc := usecase.NewRemoveOwnerUseCase(users, owners, usecase.NopMetrics{}, logging.Nop())
+
+	if err := uc.Execute(ctx, "product", 42, vendor, vendor); !errors.Is(err, usecase.ErrForbidden) {
+		t.Errorf("Execute() by owner error = %v, want ErrForbidden", err)
+	}
+	if err := uc.Execute(ctx, "product", 42, vendor, admin); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if err := uc.Execute(ctx, "product", 42, vendor, admin); !errors.Is(err, usecase.ErrNotFound) {
+		t.Errorf("Execute() twice error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestSaveResponseUseCase(t *testing.T) {
+	ctx := context.Background()
+	reviews := memory.NewReviewRepo()
+	owners := memory.NewOwnerRepo()
+	reviewID := seedReview(t, reviews, "product", 42)
+	otherID := seedReview(t, reviews, "product", 43)
+	const vendor, stranger = 10, 11
+	_ = owners.AddOwner(ctx, domain.ReviewableOwner{ReviewableType: "product", ReviewableID: 42, UserID: vendor})
+
+	uc := usecase.NewSaveResponseUseCase(reviews, owners, usecase.NopMetrics{}, logging.Nop())
+
+	tests := []struct {
+		name     string
+		reviewID int
+		actor    int
+		body     string
+		wantErr  error
+	}{
+		{name: "owner responds", reviewID: reviewID, actor: vendor, body: "Thanks!"},
+		{name: "owner edits", reviewID: reviewID, actor: vendor, body: "Thanks, we fixed it."},
+		{name: "empty body", reviewID: reviewID, actor: vendor, wantErr: usecase.ErrInvalidInput},
+		{name: "missing review id", actor: vendor, body: "x", wantErr: usecase.ErrInvalidInput},
+		{name: "unknown review", reviewID: 999, actor: vendor, body: "x", wantErr: usecase.ErrNotFound},
+		{name: "stranger is forbidden", reviewID: reviewID, actor: stranger, body: "x", wantErr: usecase.ErrForbidden},
+		{name: "owner of another reviewable is forbidden", reviewID: otherID, actor: vendor, body: "x", wantErr: usecase.ErrForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := uc.Execute(ctx, tt.reviewID, domain.SaveResponseRequest{Body: tt.body}, tt.actor)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Execute() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if resp.ReviewID != tt.reviewID || resp.UserID != tt.actor || resp.Body != tt.body {
+				t.Errorf("Execute() = %+v", resp)
+			}
+		})
+	}
+
+	listVersions := usecase.NewListResponseVersionsUseCase(reviews, memory.NewUserRepo(), usecase.NopMetrics{}, logging.Nop())
+	versions, err := listVersions.Execute(ctx, reviewID, 0)
+	if err != nil {
+		t.Fatalf("list versions: %v", err)
+	}
+	if len(versions) != 2 || versions[0].Body != "Thanks!" || versions[1].Body != "Thanks, we fixed it." {
+		t.Errorf("versions = %+v, want both saves oldest first", versions)
+	}
+
+	// The history of a review awaiting moderation is hidden like the review.
+	pendingID, err := reviews.Create(ctx, domain.Review{ReviewableType: "product", ReviewableID: 42, UserID: 1, Rating: 4, Status: domain.ReviewStatusPending})
+	if err != nil {
+		t.Fatalf("seed pending review: %v", err)
+	}
+	if _, err := listVersions.Execute(ctx, pendingID, stranger); !errors.Is(err, usecase.ErrNotFound) {
+		t.Errorf("list versions of a pending review error = %v, want ErrNotFound", err)
+	}
+	if _, err := listVersions.Execute(ctx, pendingID, 1); err != nil {
+		t.Errorf("list versions of a pending review by its author error = %v", err)
+	}
+}
+
+func TestReadUseCasesAttachResponse(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewReviewRepo()
+	answered := seedReview(t, repo, "product", 1)
+	unanswered := seedReview(t, repo, "product", 1)
+	if _, err := repo.SaveResponse(ctx, domain.ReviewResponse{ReviewID: answered, UserID: 10, Body: "Thanks!"}); err != nil {
+		t.Fatalf("seed response: %v", err)
+	}
+
+	review, _, err := usecase.NewGetReviewUseCase(repo, memory.NewUserRepo(), usecase.NopMetrics{}, logging.Nop()).Execute(ctx, answered, 0)
+	if err != nil {
+		t.Fatalf("get review: %v", err)
+	}
+	if review.Response == nil || review.Response.Body != "Thanks!" {
+		t.Errorf("GetReview response = %+v, want Thanks!", review.Response)
+	}
+
+	list, err := usecase.NewListReviewsUseCase(repo, usecase.NopMetrics{}, logging.Nop()).Execute(ctx, "product", 1, false)
+	if err != nil {
+		t.Fatalf("list reviews: %v", err)
+	}
+	for _, r := range list {
+		switch {
+		case r.ID == answered && (r.Response == nil || r.Response.Body != "Thanks!"):
+			t.Errorf("listed review %d response = %+v, want Thanks!", r.ID, r.Response)
+		case r.ID == unanswered && r.Response != nil:
+			t.Errorf("listed review %d response = %+v, want none", r.ID, r.Response)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"eve/domain"
+)
+
+// UpdateReviewUseCase edits a review. Authors may edit their own reviews;
+// moderators and admins may edit any review. Every change is kept as a revision.
+type UpdateReviewUseCase struct {
+	reviews ReviewRepository
+	types   ReviewableTypeRepository
+	users   UserRepository
+	metrics Metrics
+	log     *slog.Logger
+}
+
+// NewUpdateReviewUseCase constructs a new UpdateReviewUseCase.
+func NewUpdateReviewUseCase(r ReviewRepository, t ReviewableTypeRepository, u UserRepository, m Metrics, l *slog.Logger) *UpdateReviewUseCase {
+	return &UpdateReviewUseCase{reviews: r, types: t, users: u, metrics: m, log: l}
+}
+
+// Execute applies the fields set in req to the review on behalf of actorID and
+// returns the stored review. The edited review must satisfy the settings of
+// its type as a new one does, and an edited review of a pre-moderated type
+// is pending again until approved. The change is based on the review as loaded to
+// evaluate check: when the review changes before it is stored, the change is
+// refused with an error matching ErrPreconditionFailed.
+func (uc *UpdateReviewUseCase) Execute(ctx context.Context, reviewID int, req domain.UpdateReviewRequest, actorID int, check ReviewPrecondition) (_ domain.Review, err error) {
+	ctx, end := track(ctx, uc.metrics, uc.log, "update_review")
+	defer end(&err)
+
+	if reviewID == 0 {
+		return domain.Review{}, invalidInput("review id is required")
+	}
+	if req.Rating == nil && req.Title == nil && req.Body == nil {
+		return domain.Review{}, invalidInput("at least one of rating, title and body is required")
+	}
+	if req.Rating != nil && (*req.Rating < 1 || *req.Rating > 5) {
+		return domain.Review{}, invalidInput("rating must be between 1 and 5")
+	}
+
+	review, err := uc.reviews.GetByID(ctx, reviewID)
+	if err != nil {
+		return domain.Review{}, notFound(err, "get review", "review %d not found", reviewID)
+	}
+	if err := checkTypeScope(ctx, review.ReviewableType); err != nil {
+		return domain.Review{}, err
+	}
+	if err := requireAuthorOrRole(ctx, uc.users, actorID, review.UserID, domain.RoleModerator, domain.RoleAdmin); err != nil {
+		return domain.Review{}, err
+	}
+	if err := checkPrecondition(ctx, uc.reviews, review, check); err != nil {
+		return domain.Review{}, err
+	}
+
+	if req.Rating != nil {
+		review.Rating = *req.Rating
+	}
+	if req.Title != nil {
+		review.Title = *req.Title
+	}
+	if req.Body != nil {
+		review.Body = *req.Body
+	}
+	rt, err := lookupType(ctx, uc.types, review.ReviewableType)
+	if err != nil {
+		return domain.Review{}, err
+	}
+	if err := checkTypeRules(rt, review, 0); err != nil {
+		return domain.Review{}, err
+	}
+	if rt.ModerationMode == domain.ModerationPre {
+		review.Status = domain.ReviewStatusPending
+	}
+
+	updated, err := uc.reviews.UpdateReview(ctx, review, actorID, review.UpdatedAt)
+	if err != nil {
+		return domain.Review{}, reviewChanged(err, "update review", reviewID)
+	}
+	uc.log.InfoContext(ctx, "review updated",
+		slog.Int("review_id", reviewID),
+		slog.Int("user_id", actorID),
+		slog.String("status", updated.Status),
+	)
+	return updated, nil
+}
+
+// UpdateCommentUseCase edits a comment. Authors may edit their own comments;
+// moderators and admins may edit any comment. Every change is kept as a revision.
+type UpdateCommentUseCase struct {
+	reviews ReviewRepository
+	users   UserRepository
+	metrics Metrics
+	log     *slog.Logger
+}
+
+// NewUpdateCommentUseCase constructs a new UpdateCommentUseCase.
+func NewUpdateCommentUseCase(r ReviewRepository, u UserRepository, m Metrics, l *slog.Logger) *UpdateCommentUseCase {
+	return &UpdateCommentUseCase{reviews: r, users: u, metrics: m, log: l}
+}
+
+// Execute replaces the body of a comment on the given review on behalf of
+// actorID and returns the stored comment.
+func (uc *UpdateCommentUseCase) Execute(ctx context.Context, reviewID, commentID int, req domain.UpdateCommentRequest, actorID int) (_ domain.ReviewComment, err error) {
+	ctx, end := track(ctx, uc.metrics, uc.log, "update_comment")
+	defer end(&err)
+
+	if reviewID == 0 || commentID == 0 {
+		return domain.ReviewComment{}, invalidInput("review id and comment id are required")
+	}
+	if req.Body == "" {
+		return domain.ReviewComment{}, invalidInput("body is required")
+	}
+
+	comment, err := uc.reviews.GetComment(ctx, commentID)
+	if err != nil {
+		return domain.ReviewComment{}, notFound(err, "get comment", "comment %d not found", commentID)
+	}
+	if comment.ReviewID != reviewID {
+		return domain.ReviewComment{}, &notFoundError{msg: fmt.Sprintf("comment %d not found on review %d", commentID, reviewID)}
+	}
+	if err := checkReviewScope(ctx, uc.reviews, reviewID); err != nil {
+		return domain.ReviewComment{}, err
+	}
+	if err := requireAuthorOrRole(ctx, uc.users, actorID, comment.UserID, domain.RoleModerator, domain.RoleAdmin); err != nil {
+		return domain.ReviewComment{}, err
+	}
+
+	comment.Body = req.Body
+	updated, err := uc.reviews.UpdateComment(ctx, comment, actorID)
+	if err != nil {
+		return domain.ReviewComment{}, notFound(err, "update comment", "comment %d not found", commentID)
+	}
+	uc.log.InfoContext(ctx, "comment updated",
+		slog.Int("comment_id", commentID),
+		slog.Int("review_id", reviewID),
+		slog.Int("user_id", actorID),
+	)
+	return updated, nil
+}
+
+// ListRevisionsUseCase returns the audit trail of a review. Only the review's
+// author, moderators and admins may read it.
+type ListRevisionsUseCase struct {
+	reviews ReviewRepository
+	users   UserRepository
+	metrics Metrics
+	log     *slog.Logger
+}
+
+// NewListRevisionsUseCase constructs a new ListRevisionsUseCase.
+func NewListRevisionsUseCase(r ReviewRepository, u UserRepository, m Metrics, l *slog.Logger) *ListRevisionsUseCase {
+	return &ListRevisionsUseCase{reviews: r, users: u, metrics: m, log: l}
+}
+
+// Execute returns the revisions of the review and its comments, oldest first.
+func (uc *ListRevisionsUseCase) Execute(ctx context.Context, reviewID, actorID int) (_ []domain.Revision, err error) {
+	ctx, end := track(ctx, uc.metrics, uc.log, "list_revisions")
+	defer end(&err)
+
+	if reviewID == 0 {
+		return nil, invalidInput("review id is required")
+	}
+	review, err := uc.reviews.GetByID(ctx, reviewID)
+	if err != nil {
+		return nil, notFound(err, "get review", "review %d not found", reviewID)
+	}
+	if err := checkTypeScope(ctx, review.ReviewableType); err != nil {
+		return nil, err
+	}
+	if err := requireAuthorOrRole(ctx, uc.users, actorID, review.UserID, domain.RoleModerator, domain.RoleAdmin); err != nil {
+		return nil, err
+	}
+
+	revisions, err := uc.reviews.ListRevisions(ctx, reviewID)
+	if err != nil {
+		return nil, fmt.Errorf("list revisions: %w", err)
+	}
+	if revisions == nil {
+		revisions = []domain.Revision{}
+	}
+	return revisions, nil
+}
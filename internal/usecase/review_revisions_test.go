@@ -0,0 +1,170 @@
+package usecase_test
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"testing"
+
+	"eve/domain"
+	"eve/internal/logging"
+	"eve/internal/repository/memory"
+	"eve/internal/usecase"
+)
+
+func TestUpdateReviewUseCase(t *testing.T) {
+	users := memory.NewUserRepo()
+	author := seedUser(t, users, "author@example.com", domain.RoleUser) // seedReview authors reviews as user 1
+	stranger := seedUser(t, users, "stranger@example.com", domain.RoleUser)
+	moderator := seedUser(t, users, "mod@example.com", domain.RoleModerator)
+
+	types := seedTypes(t, "product")
+	if _, err := types.SaveType(context.Background(), domain.ReviewableType{
+		Name: "book", MaxBodyLength: 10, TitleRequired: true, ModerationMode: domain.ModerationPre,
+	}); err != nil {
+		t.Fatalf("seed type: %v", err)
+	}
+
+	rating := func(n int) *int { return &n }
+	text := func(s string) *string { return &s }
+
+	tests := []struct {
+		name       string
+		typ        string // "" reviews a product
+		reviewID   int    // 0 uses the seeded review
+		actor      int
+		req        domain.UpdateReviewRequest
+		wantErr    error
+		wantTitle  string
+		wantStatus string // "" expects the review to stay published
+	}{
+		{name: "author edits", actor: author, req: domain.UpdateReviewRequest{Title: text("Edited")}, wantTitle: "Edited"},
+		{name: "moderator edits", actor: moderator, req: domain.UpdateReviewRequest{Title: text("Moderated")}, wantTitle: "Moderated"},
+		{name: "stranger is forbidden", actor: stranger, req: domain.UpdateReviewRequest{Title: text("x")}, wantErr: usecase.ErrForbidden},
+		{name: "empty request", actor: author, wantErr: usecase.ErrInvalidInput},
+		{name: "rating out of range", actor: author, req: domain.UpdateReviewRequest{Rating: rating(6)}, wantErr: usecase.ErrInvalidInput},
+		{name: "unknown review", reviewID: 999, actor: author, req: domain.UpdateReviewRequest{Body: text("x")}, wantErr: usecase.ErrNotFound},
+		{name: "title required by type", typ: "book", actor: author, req: domain.UpdateReviewRequest{Body: text("short")}, wantErr: usecase.ErrInvalidInput},
+		{name: "body too long for type", typ: "book", actor: author, req: domain.UpdateReviewRequest{Title: text("T"), Body: text("far too long")}, wantErr: usecase.ErrInvalidInput},
+		{name: "pre-moderated edit is pending", typ: "book", actor: author, req: domain.UpdateReviewRequest{Title: text("Edited")}, wantTitle: "Edited", wantStatus: domain.ReviewStatusPending},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := memory.NewReviewRepo()
+			id := seedReview(t, repo, cmp.Or(tt.typ, "product"), 1)
+			if tt.reviewID != 0 {
+				id = tt.reviewID
+			}
+			uc := usecase.NewUpdateReviewUseCase(repo, types, users, usecase.NopMetrics{}, logging.Nop())
+
+			got, err := uc.Execute(ctx, id, tt.req, tt.actor, nil)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Execute() error = %v, want %v", err, tt.wantErr)
+			}
+			revisions, _ := repo.ListRevisions(ctx, id)
+			if tt.wantErr != nil {
+				if len(revisions) != 0 {
+					t.Errorf("failed update recorded revisions %+v", revisions)
+				}
+				return
+			}
+			if got.Title != tt.wantTitle || got.Rating != 4 {
+				t.Errorf("Execute() = %+v, want title %q and rating kept", got, tt.wantTitle)
+			}
+			if want := cmp.Or(tt.wantStatus, domain.ReviewStatusPublished); got.Status != want {
+				t.Errorf("Execute() status = %q, want %q", got.Status, want)
+			}
+			if len(revisions) != 1 || revisions[0].ActorID != tt.actor {
+				t.Errorf("revisions = %+v, want one by %d", revisions, tt.actor)
+			}
+		})
+	}
+}
+
+func TestUpdateReviewUseCasePrecondition(t *testing.T) {
+	ctx := context.Background()
+	users := memory.NewUserRepo()
+	author := seedUser(t, users, "author@example.com", domain.RoleUser)
+	stranger := seedUser(t, users, "stranger@example.com", domain.RoleUser)
+	repo := memory.NewReviewRepo()
+	id := seedReview(t, repo, "product", 1)
+	uc := usecase.NewUpdateReviewUseCase(repo, seedTypes(t, "product"), users, usecase.NopMetrics{}, logging.Nop())
+	title := "Mine"
+	req := domain.UpdateReviewRequest{Title: &title}
+
+	refused := errors.New("refused")
+	refuse := func(domain.Review, []domain.ReviewComment) error { return refused }
+	if _, err := uc.Execute(ctx, id, req, stranger, refuse); !errors.Is(err, usecase.ErrForbidden) {
+		t.Errorf("Execute(stranger) error = %v, want ErrForbidden before the precondition", err)
+	}
+	if _, err := uc.Execute(ctx, id, req, author, refuse); !errors.Is(err, refused) {
+		t.Errorf("Execute() error = %v, want the precondition's error", err)
+	}
+
+	// Another request edits the review after the precondition held.
+	raced := func(review domain.Review, _ []domain.ReviewComment) error {
+		review.Body = "Theirs"
+		_, err := repo.UpdateReview(ctx, review, author, "")
+		return err
+	}
+	if _, err := uc.Execute(ctx, id, req, author, raced); !errors.Is(err, usecase.ErrPreconditionFailed) {
+		t.Fatalf("Execute() error = %v, want ErrPreconditionFailed", err)
+	}
+	if got, _ := repo.GetByID(ctx, id); got.Title == title || got.Body != "Theirs" {
+		t.Errorf("review = %+v, want the other edit kept and this one refused", got)
+	}
+}
+
+func TestUpdateCommentUseCase(t *testing.T) {
+	ctx := context.Background()
+	users := memory.NewUserRepo()
+	commenter := seedUser(t, users, "commenter@example.com", domain.RoleUser)
+	stranger := seedUser(t, users, "stranger@example.com", domain.RoleUser)
+	repo := memory.NewReviewRepo()
+	reviewID := seedReview(t, repo, "product", 1)
+	otherID := seedReview(t, repo, "product", 1)
+	commentID, _ := repo.AddComment(ctx, domain.ReviewComment{ReviewID: reviewID, UserID: commenter, Body: "first"})
+
+	uc := usecase.NewUpdateCommentUseCase(repo, users, usecase.NopMetrics{}, logging.Nop())
+
+	if _, err := uc.Execute(ctx, reviewID, commentID, domain.UpdateCommentRequest{Body: "x"}, stranger); !errors.Is(err, usecase.ErrForbidden) {
+		t.Errorf("stranger edit error = %v, want ErrForbidden", err)
+	}
+	if _, err := uc.Execute(ctx, otherID, commentID, domain.UpdateCommentRequest{Body: "x"}, commenter); !errors.Is(err, usecase.ErrNotFound) {
+		t.Errorf("edit through other review error = %v, want ErrNotFound", err)
+	}
+	if _, err := uc.Execute(ctx, reviewID, commentID, domain.UpdateCommentRequest{}, commenter); !errors.Is(err, usecase.ErrInvalidInput) {
+		t.Errorf("empty body error = %v, want ErrInvalidInput", err)
+	}
+	got, err := uc.Execute(ctx, reviewID, commentID, domain.UpdateCommentRequest{Body: "second"}, commenter)
+	if err != nil || got.Body != "second" {
+		t.Fatalf("Execute() = %+v, %v", got, err)
+	}
+}
+
+func TestListRevisionsUseCase(t *testing.T) {
+	ctx := context.Background()
+	users := memory.NewUserRepo()
+	author := seedUser(t, users, "author@example.com", domain.RoleUser)
+	stranger := seedUser(t, users, "stranger@example.com", domain.RoleUser)
+	moderator := seedUser(t, users, "mod@example.com", domain.RoleModerator)
+	repo := memory.NewReviewRepo()
+	reviewID := seedReview(t, repo, "product", 1)
+
+	uc := usecase.NewListRevisionsUseCase(repo, users, usecase.NopMetrics{}, logging.Nop())
+
+	for _, actor := range []int{author, moderator} {
+		got, err := uc.Execute(ctx, reviewID, actor)
+		if err != nil || got == nil {
+			t.Errorf("Execute() by %d = %v, %v; want empty history", actor, got, err)
+		}
+	}
+	if _, err := uc.Execute(ctx, reviewID, stranger); !errors.Is(err, usecase.ErrForbidden) {
+		t.Errorf("Execute() by stranger error = %v, want ErrForbidden", err)
+	}
+	if _, err := uc.Execute(ctx, reviewID+1, moderator); !errors.Is(err, usecase.ErrNotFound) {
+		t.Errorf("Execute() unknown review error = %v, want ErrNotFound", err)
+	}
+}
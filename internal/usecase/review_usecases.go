@@ -1,70 +1,216 @@
 package usecase
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"log/slog"
+	"time"
 
 	"eve/domain"
 )
 
 // ReviewRepository defines the methods the use-cases expect from a persistence layer.
 // Implementations live in internal/repository (for example a Postgres implementation).
+// Every method receives the request context and must abort when it is cancelled.
+//
+// Create, AddComment, UpdateReview, DeleteReview, RestoreReview and
+// ApproveReview record a domain.Event describing their change in the outbox,
+// atomically with the change itself (see OutboxRepository). Calls that change
+// nothing record no event.
 type ReviewRepository interface {
-	// Create inserts a new review and returns its generated ID.
-	Create(review domain.Review) (int, error)
+	// Create inserts a new review together with its SubRatings and returns its
+	// generated ID. An empty Status is stored as domain.ReviewStatusPublished.
+	Create(ctx context.Context, review domain.Review) (int, error)
 
 	// AddPhotos attaches photos to an existing review.
-	AddPhotos(reviewID int, photos []domain.ReviewPhoto) error
+	AddPhotos(ctx context.Context, reviewID int, photos []domain.ReviewPhoto) error
 
 	// AddComment inserts a comment for a review and returns the comment ID.
-	AddComment(comment domain.ReviewComment) (int, error)
+	AddComment(ctx context.Context, comment domain.ReviewComment) (int, error)
 
-	// GetByID loads a single review by ID.
-	GetByID(id int) (domain.Review, error)
+	// GetByID loads a single review by ID. A missing review yields an error wrapping sql.ErrNoRows.
+	GetByID(ctx context.Context, id int) (domain.Review, error)
 
-	// ListByReviewable returns reviews for a specific reviewable entity.
-	ListByReviewable(reviewableType string, reviewableID int) ([]domain.Review, error)
+	// ListByReviewable returns the published reviews of a specific reviewable
+	// entity, newest first. With verifiedOnly, reviews that are not Verified are skipped.
+	ListByReviewable(ctx context.Context, reviewableType string, reviewableID int, verifiedOnly bool) ([]domain.Review, error)
 
-	// ListComments returns comments for a review.
-	ListComments(reviewID int) ([]domain.ReviewComment, error)
+	// ListComments returns comments for a review, oldest first.
+	ListComments(ctx context.Context, reviewID int) ([]domain.ReviewComment, error)
+
+	// GetComment loads a single comment by ID. A missing comment yields an error wrapping sql.ErrNoRows.
+	GetComment(ctx context.Context, id int) (domain.ReviewComment, error)
+
+	// ListPhotos returns photos for a review ordered by SortOrder.
+	ListPhotos(ctx context.Context, reviewID int) ([]domain.ReviewPhoto, error)
+
+	// DeleteReview soft-deletes a review. A deleted review and everything
+	// attached to it disappear from every read method of the repository (lookups
+	// report sql.ErrNoRows, lists and searches skip it) until it is restored or
+	// purged. Deleting a missing or already deleted review is a no-op. A
+	// non-empty version must be the UpdatedAt of the review when it is
+	// deleted, or domain.ErrReviewChanged is reported.
+	DeleteReview(ctx context.Context, id int, version string) error
+
+	// RestoreReview undoes DeleteReview. A review that is not soft-deleted
+	// yields an error wrapping sql.ErrNoRows.
+	RestoreReview(ctx context.Context, id int) error
+
+	// PurgeDeletedReviews hard-deletes up to limit reviews soft-deleted before
+	// deletedBefore, together with their photos, comments, responses and
+	// revisions, and reports the purged review IDs and photo file paths.
+	PurgeDeletedReviews(ctx context.Context, deletedBefore time.Time, limit int) (domain.PurgedReviews, error)
+
+	// SaveResponse creates the official response to resp.ReviewID, or replaces the
+	// body and author of the existing one, and appends the new body to its version
+	// history in the same transaction. Returns the stored response.
+	SaveResponse(ctx context.Context, resp domain.ReviewResponse) (domain.ReviewResponse, error)
+
+	// GetResponse loads the official response to a review. A review without a
+	// response yields an error wrapping sql.ErrNoRows.
+	GetResponse(ctx context.Context, reviewID int) (domain.ReviewResponse, error)
+
+	// ListResponses returns the official responses to the given reviews keyed by
+	// review ID. Reviews without a response have no entry.
+	ListResponses(ctx context.Context, reviewIDs []int) (map[int]domain.ReviewResponse, error)
+
+	// ListResponseVersions returns every saved version of a review's response, oldest first.
+	ListResponseVersions(ctx context.Context, reviewID int) ([]domain.ReviewResponseVersion, error)
+
+	// UpdateReview stores the rating, title and body of review.ID. When any of
+	// them changed it also records a Revision by actorID, in the same transaction
+	// and computed from the row as stored at that time, and stores Status unless
+	// it is empty; the status alone is never updated. Returns the stored review.
+	// A missing review yields an error wrapping sql.ErrNoRows. A non-empty
+	// version must be the UpdatedAt of the stored row, compared in the same
+	// transaction, or domain.ErrReviewChanged is reported.
+	UpdateReview(ctx context.Context, review domain.Review, actorID int, version string) (domain.Review, error)
+
+	// UpdateComment is UpdateReview for the body of comment.ID.
+	UpdateComment(ctx context.Context, comment domain.ReviewComment, actorID int) (domain.ReviewComment, error)
+
+	// ListRevisions returns the revisions of a review and of its comments, oldest first.
+	ListRevisions(ctx context.Context, reviewID int) ([]domain.Revision, error)
+
+	// ListSubRatings returns the sub-ratings of the given reviews keyed by review
+	// ID. Reviews without sub-ratings have no entry.
+	ListSubRatings(ctx context.Context, reviewIDs []int) (map[int]map[string]int, error)
+
+	// Summarize counts the published reviews of a reviewable entity and averages their
+	// overall rating and every criterion they were sub-rated on. Criteria are
+	// ordered by key and carry no Label. An entity without reviews yields a
+	// zero summary rather than an error.
+	Summarize(ctx context.Context, reviewableType string, reviewableID int) (domain.ReviewSummary, error)
+
+	// ApproveReview publishes a pending review. A review that is not pending
+	// yields an error wrapping sql.ErrNoRows.
+	ApproveReview(ctx context.Context, id int) error
+
+	// ListPending returns the live reviews awaiting moderation, oldest first,
+	// skipping offset of them and returning at most limit.
+	ListPending(ctx context.Context, limit, offset int) ([]domain.Review, error)
 }
 
-// CreateReviewUseCase handles the creation of reviews and optional photos.
+// CreateReviewUseCase handles the creation of reviews, their sub-ratings and optional photos.
 type CreateReviewUseCase struct {
-	repo ReviewRepository
+	repo     ReviewRepository
+	types    ReviewableTypeRepository
+	criteria CriteriaRepository
+	resolver ReviewableResolver
+	verifier PurchaseVerifier
+	metrics  Metrics
+	log      *slog.Logger
 }
 
 // NewCreateReviewUseCase constructs a new CreateReviewUseCase.
-func NewCreateReviewUseCase(r ReviewRepository) *CreateReviewUseCase {
-	return &CreateReviewUseCase{repo: r}
+func NewCreateReviewUseCase(r ReviewRepository, t ReviewableTypeRepository, c CriteriaRepository, rr ReviewableResolver, pv PurchaseVerifier, m Metrics, l *slog.Logger) *CreateReviewUseCase {
+	return &CreateReviewUseCase{repo: r, types: t, criteria: c, resolver: rr, verifier: pv, metrics: m, log: l}
 }
 
-// Execute creates a review authored by authorID from the given request.
-// Returns the created review ID.
-func (uc *CreateReviewUseCase) Execute(req domain.CreateReviewRequest, authorID int) (int, error) {
+// Execute creates a review authored by authorID from the given request,
+// enforcing the settings of its reviewable type. The reviewable itself must
+// exist according to the ReviewableResolver. Reviews of pre-moderated types
+// are created pending, and reviews by buyers of the reviewable are marked
+// verified; when the PurchaseVerifier fails the review is created unverified
+// rather than rejected. Returns the created review ID.
+func (uc *CreateReviewUseCase) Execute(ctx context.Context, req domain.CreateReviewRequest, authorID int) (_ int, err error) {
+	ctx, end := track(ctx, uc.metrics, uc.log, "create_review")
+	defer end(&err)
+
 	// Basic validation
 	if req.ReviewableType == "" {
-		return 0, fmt.Errorf("reviewable_type is required")
+		return 0, invalidInput("reviewable_type is required")
 	}
 	if req.ReviewableID == 0 {
-		return 0, fmt.Errorf("reviewable_id is required")
+		return 0, invalidInput("reviewable_id is required")
+	}
+	if err := checkTypeScope(ctx, req.ReviewableType); err != nil {
+		return 0, err
 	}
 	if req.Rating < 1 || req.Rating > 5 {
-		return 0, fmt.Errorf("rating must be between 1 and 5")
+		return 0, invalidInput("rating must be between 1 and 5")
+	}
+	rt, err := lookupType(ctx, uc.types, req.ReviewableType)
+	if err != nil {
+		return 0, err
 	}
-
 	rev := domain.Review{
 		ReviewableType: req.ReviewableType,
 		ReviewableID:   req.ReviewableID,
 		UserID:         authorID,
 		Rating:         req.Rating,
+		SubRatings:     req.SubRatings,
 		Title:          req.Title,
 		Body:           req.Body,
+		Status:         domain.ReviewStatusPublished,
+	}
+	if rt.ModerationMode == domain.ModerationPre {
+		rev.Status = domain.ReviewStatusPending
+	}
+	if err := checkTypeRules(rt, rev, len(req.PhotoPaths)); err != nil {
+		return 0, err
+	}
+	exists, err := uc.resolver.Exists(ctx, req.ReviewableType, req.ReviewableID)
+	if err != nil {
+		return 0, fmt.Errorf("resolve reviewable: %w", err)
+	}
+	if !exists {
+		return 0, invalidInput("%s %d does not exist", req.ReviewableType, req.ReviewableID)
+	}
+	criteria, err := uc.criteria.ListCriteria(ctx, req.ReviewableType)
+	if err != nil {
+		return 0, fmt.Errorf("list criteria: %w", err)
+	}
+	if err := validateSubRatings(criteria, req.SubRatings); err != nil {
+		return 0, err
 	}
 
-	id, err := uc.repo.Create(rev)
+	rev.Verified, err = uc.verifier.HasPurchased(ctx, authorID, req.ReviewableType, req.ReviewableID)
+	if err != nil {
+		uc.log.WarnContext(ctx, "purchase verification failed, creating review unverified",
+			slog.Int("user_id", authorID),
+			slog.String("reviewable_type", req.ReviewableType),
+			slog.Int("reviewable_id", req.ReviewableID),
+			slog.String("error", err.Error()),
+		)
+		rev.Verified, err = false, nil
+	}
+
+	id, err := uc.repo.Create(ctx, rev)
 	if err != nil {
 		return 0, fmt.Errorf("create review: %w", err)
 	}
+	uc.metrics.ReviewCreated()
+	uc.log.InfoContext(ctx, "review created",
+		slog.Int("review_id", id),
+		slog.Int("user_id", authorID),
+		slog.String("reviewable_type", req.ReviewableType),
+		slog.Int("reviewable_id", req.ReviewableID),
+		slog.String("status", rev.Status),
+		slog.Bool("verified", rev.Verified),
+	)
 
 	// Attach photos if provided
 	if len(req.PhotoPaths) > 0 {
@@ -81,9 +227,10 @@ func (uc *CreateReviewUseCase) Execute(req domain.CreateReviewRequest, authorID
 				SortOrder: i,
 			})
 		}
-		if err := uc.repo.AddPhotos(id, photos); err != nil {
+		if err := uc.repo.AddPhotos(ctx, id, photos); err != nil {
 			return id, fmt.Errorf("add photos: %w", err)
 		}
+		uc.metrics.PhotosAttached(len(photos))
 	}
 
 	return id, nil
@@ -91,21 +238,43 @@ func (uc *CreateReviewUseCase) Execute(req domain.CreateReviewRequest, authorID
 
 // CreateCommentUseCase handles adding comments to reviews.
 type CreateCommentUseCase struct {
-	repo ReviewRepository
+	repo    ReviewRepository
+	users   UserRepository
+	metrics Metrics
+	log     *slog.Logger
 }
 
 // NewCreateCommentUseCase constructs a new CreateCommentUseCase.
-func NewCreateCommentUseCase(r ReviewRepository) *CreateCommentUseCase {
-	return &CreateCommentUseCase{repo: r}
+func NewCreateCommentUseCase(r ReviewRepository, u UserRepository, m Metrics, l *slog.Logger) *CreateCommentUseCase {
+	return &CreateCommentUseCase{repo: r, users: u, metrics: m, log: l}
 }
 
-// Execute creates a comment authored by authorID on the specified review.
-func (uc *CreateCommentUseCase) Execute(req domain.CreateCommentRequest, authorID int) (int, error) {
+// Execute creates a comment authored by authorID on the specified review, or a
+// reply when req.ParentID is set.
+func (uc *CreateCommentUseCase) Execute(ctx context.Context, req domain.CreateCommentRequest, authorID int) (_ int, err error) {
+	ctx, end := track(ctx, uc.metrics, uc.log, "create_comment")
+	defer end(&err)
+
 	if req.ReviewID == 0 {
-		return 0, fmt.Errorf("review_id is required")
+		return 0, invalidInput("review_id is required")
 	}
 	if req.Body == "" {
-		return 0, fmt.Errorf("body is required")
+		return 0, invalidInput("body is required")
+	}
+
+	// Deleted and pending reviews accept no new comments, although the foreign key would allow them.
+	review, err := uc.repo.GetByID(ctx, req.ReviewID)
+	if err != nil {
+		return 0, notFound(err, "get review", "review %d not found", req.ReviewID)
+	}
+	if err := checkReviewVisible(ctx, uc.users, review, authorID); err != nil {
+		return 0, err
+	}
+	if review.Status == domain.ReviewStatusPending {
+		return 0, invalidInput("review %d is awaiting moderation and cannot be commented on", req.ReviewID)
+	}
+	if err := checkTypeScope(ctx, review.ReviewableType); err != nil {
+		return 0, err
 	}
 
 	c := domain.ReviewComment{
@@ -114,56 +283,150 @@ func (uc *CreateCommentUseCase) Execute(req domain.CreateCommentRequest, authorI
 		Body:     req.Body,
 	}
 
-	id, err := uc.repo.AddComment(c)
+	if req.ParentID != 0 {
+		parent, err := uc.repo.GetComment(ctx, req.ParentID)
+		if err != nil {
+			return 0, notFound(err, "get parent comment", "comment %d not found", req.ParentID)
+		}
+		if parent.ReviewID != req.ReviewID {
+			return 0, invalidInput("comment %d does not belong to review %d", req.ParentID, req.ReviewID)
+		}
+		if parent.Depth >= MaxCommentDepth {
+			return 0, invalidInput("replies can be nested at most %d levels deep", MaxCommentDepth)
+		}
+		c.ParentID = &parent.ID
+		c.Depth = parent.Depth + 1
+	}
+
+	id, err := uc.repo.AddComment(ctx, c)
 	if err != nil {
 		return 0, fmt.Errorf("add comment: %w", err)
 	}
+	uc.metrics.CommentCreated()
+	uc.log.InfoContext(ctx, "comment created",
+		slog.Int("comment_id", id),
+		slog.Int("review_id", req.ReviewID),
+		slog.Int("parent_id", req.ParentID),
+		slog.Int("user_id", authorID),
+	)
 	return id, nil
 }
 
 // ListReviewsUseCase returns reviews for a given reviewable entity.
 type ListReviewsUseCase struct {
-	repo ReviewRepository
+	repo    ReviewRepository
+	metrics Metrics
+	log     *slog.Logger
 }
 
 // NewListReviewsUseCase constructs a new ListReviewsUseCase.
-func NewListReviewsUseCase(r ReviewRepository) *ListReviewsUseCase {
-	return &ListReviewsUseCase{repo: r}
+func NewListReviewsUseCase(r ReviewRepository, m Metrics, l *slog.Logger) *ListReviewsUseCase {
+	return &ListReviewsUseCase{repo: r, metrics: m, log: l}
 }
 
-// Execute returns reviews for the provided reviewable identifier.
-func (uc *ListReviewsUseCase) Execute(reviewableType string, reviewableID int) ([]domain.Review, error) {
+// Execute returns reviews for the provided reviewable identifier, each with its
+// sub-ratings and its official response attached when there is one. With
+// verifiedOnly, only reviews by verified buyers are returned.
+func (uc *ListReviewsUseCase) Execute(ctx context.Context, reviewableType string, reviewableID int, verifiedOnly bool) (_ []domain.Review, err error) {
+	ctx, end := track(ctx, uc.metrics, uc.log, "list_reviews")
+	defer end(&err)
+
 	if reviewableType == "" || reviewableID == 0 {
-		return nil, fmt.Errorf("reviewable_type and reviewable_id are required")
+		return nil, invalidInput("reviewable_type and reviewable_id are required")
+	}
+	if err := checkTypeScope(ctx, reviewableType); err != nil {
+		return nil, err
+	}
+	reviews, err := uc.repo.ListByReviewable(ctx, reviewableType, reviewableID, verifiedOnly)
+	if err != nil {
+		return nil, err
+	}
+	if err := attachSubRatings(ctx, uc.repo, reviews); err != nil {
+		return nil, err
 	}
-	return uc.repo.ListByReviewable(reviewableType, reviewableID)
+	if err := attachResponses(ctx, uc.repo, reviews); err != nil {
+		return nil, err
+	}
+	return reviews, nil
 }
 
 // GetReviewUseCase loads a single review together with its comments.
 type GetReviewUseCase struct {
-	repo ReviewRepository
+	repo    ReviewRepository
+	users   UserRepository
+	metrics Metrics
+	log     *slog.Logger
 }
 
 // NewGetReviewUseCase constructs a new GetReviewUseCase.
-func NewGetReviewUseCase(r ReviewRepository) *GetReviewUseCase {
-	return &GetReviewUseCase{repo: r}
+func NewGetReviewUseCase(r ReviewRepository, u UserRepository, m Metrics, l *slog.Logger) *GetReviewUseCase {
+	return &GetReviewUseCase{repo: r, users: u, metrics: m, log: l}
 }
 
-// Execute returns the review and its comments.
-func (uc *GetReviewUseCase) Execute(reviewID int) (domain.Review, []domain.ReviewComment, error) {
+// Execute returns the review, with its sub-ratings and official response attached, and its
+// comments in thread order: every comment is followed by its replies (oldest
+// first), each carrying its Depth. A review awaiting moderation is only
+// found by its author, moderators and admins; viewerID is 0 for anonymous
+// callers.
+func (uc *GetReviewUseCase) Execute(ctx context.Context, reviewID, viewerID int) (_ domain.Review, _ []domain.ReviewComment, err error) {
+	ctx, end := track(ctx, uc.metrics, uc.log, "get_review")
+	defer end(&err)
+
 	if reviewID == 0 {
-		return domain.Review{}, nil, fmt.Errorf("review id is required")
+		return domain.Review{}, nil, invalidInput("review id is required")
+	}
+
+	review, err := uc.repo.GetByID(ctx, reviewID)
+	if err != nil {
+		return domain.Review{}, nil, notFound(err, "get review", "review %d not found", reviewID)
+	}
+	if err := checkReviewVisible(ctx, uc.users, review, viewerID); err != nil {
+		return domain.Review{}, nil, err
 	}
+	if err := checkTypeScope(ctx, review.ReviewableType); err != nil {
+		return domain.Review{}, nil, err
+	}
+	return reviewDetails(ctx, uc.repo, review)
+}
 
-	review, err := uc.repo.GetByID(reviewID)
+// reviewDetails attaches the sub-ratings and official response to review and
+// loads its comments in thread order, as GET /reviews/:id returns them.
+func reviewDetails(ctx context.Context, repo ReviewRepository, review domain.Review) (domain.Review, []domain.ReviewComment, error) {
+	subRatings, err := repo.ListSubRatings(ctx, []int{review.ID})
 	if err != nil {
-		return domain.Review{}, nil, fmt.Errorf("get review: %w", err)
+		return domain.Review{}, nil, fmt.Errorf("list sub-ratings: %w", err)
 	}
+	review.SubRatings = subRatings[review.ID]
 
-	comments, err := uc.repo.ListComments(reviewID)
+	resp, err := repo.GetResponse(ctx, review.ID)
+	switch {
+	case err == nil:
+		review.Response = &resp
+	case !errors.Is(err, sql.ErrNoRows):
+		return domain.Review{}, nil, fmt.Errorf("get response: %w", err)
+	}
+
+	comments, err := repo.ListComments(ctx, review.ID)
 	if err != nil {
 		return review, nil, fmt.Errorf("list comments: %w", err)
 	}
 
-	return review, comments, nil
+	return review, threadComments(comments), nil
+}
+
+// ReviewPrecondition decides whether a change of a review may go ahead, given
+// the review and its comments as GetReviewUseCase returns them. It is checked
+// once the caller is authorized; its error is returned unchanged.
+type ReviewPrecondition func(review domain.Review, comments []domain.ReviewComment) error
+
+// checkPrecondition runs check, when there is one, against review.
+func checkPrecondition(ctx context.Context, repo ReviewRepository, review domain.Review, check ReviewPrecondition) error {
+	if check == nil {
+		return nil
+	}
+	review, comments, err := reviewDetails(ctx, repo, review)
+	if err != nil {
+		return err
+	}
+	return check(review, comments)
 }
@@ -0,0 +1,360 @@
+package usecase_test
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"maps"
+	"testing"
+
+	"eve/domain"
+	"eve/internal/logging"
+	"eve/internal/repository/memory"
+	"eve/internal/usecase"
+)
+
+// resolverFunc adapts a function to usecase.ReviewableResolver.
+type resolverFunc func(reviewableType string, reviewableID int) (bool, error)
+
+func (f resolverFunc) Exists(_ context.Context, reviewableType string, reviewableID int) (bool, error) {
+	return f(reviewableType, reviewableID)
+}
+
+// purchaseFunc adapts a function to usecase.PurchaseVerifier.
+type purchaseFunc func(userID int, reviewableType string, reviewableID int) (bool, error)
+
+func (f purchaseFunc) HasPurchased(_ context.Context, userID int, reviewableType string, reviewableID int) (bool, error) {
+	return f(userID, reviewableType, reviewableID)
+}
+
+func TestCreateReviewUseCase(t *testing.T) {
+	valid := domain.CreateReviewRequest{
+		ReviewableType: "product",
+		ReviewableID:   42,
+		Rating:         5,
+		Title:          "Great",
+		Body:           "Works as advertised",
+	}
+
+	// book reviews are restricted by every setting a reviewable type has.
+	book := func(mutate func(*domain.CreateReviewRequest)) func(*domain.CreateReviewRequest) {
+		return func(r *domain.CreateReviewRequest) {
+			r.ReviewableType, r.Title, r.Body = "book", "Gripping", "Read it"
+			mutate(r)
+		}
+	}
+	hotel := func(subRatings map[string]int) func(*domain.CreateReviewRequest) {
+		return func(r *domain.CreateReviewRequest) { r.ReviewableType, r.SubRatings = "hotel", subRatings }
+	}
+
+	tests := []struct {
+		name           string
+		mutate         func(*domain.CreateReviewRequest)
+		wantErr        bool
+		wantPhotos     int
+		wantSubRatings map[string]int
+		wantStatus     string
+		wantVerified   bool
+	}{
+		{name: "valid review", mutate: func(*domain.CreateReviewRequest) {}},
+		{
+			name: "with photos",
+			mutate: func(r *domain.CreateReviewRequest) {
+				r.PhotoPaths = []string{"a.jpg", "b.jpg"}
+				r.PhotoMetadata = []json.RawMessage{json.RawMessage(`{"width":10}`)}
+			},
+			wantPhotos: 2,
+		},
+		{name: "missing reviewable type", mutate: func(r *domain.CreateReviewRequest) { r.ReviewableType = "" }, wantErr: true},
+		{name: "missing reviewable id", mutate: func(r *domain.CreateReviewRequest) { r.ReviewableID = 0 }, wantErr: true},
+		{name: "rating too low", mutate: func(r *domain.CreateReviewRequest) { r.Rating = 0 }, wantErr: true},
+		{name: "rating too high", mutate: func(r *domain.CreateReviewRequest) { r.Rating = 6 }, wantErr: true},
+		{
+			name:           "with sub-ratings",
+			mutate:         hotel(map[string]int{"cleanliness": 4, "location": 5}),
+			wantSubRatings: map[string]int{"cleanliness": 4, "location": 5},
+		},
+		{name: "optional sub-rating omitted", mutate: hotel(map[string]int{"cleanliness": 3}), wantSubRatings: map[string]int{"cleanliness": 3}},
+		{name: "required sub-rating missing", mutate: hotel(map[string]int{"location": 5}), wantErr: true},
+		{name: "sub-rating out of range", mutate: hotel(map[string]int{"cleanliness": 6}), wantErr: true},
+		{name: "unknown criterion", mutate: hotel(map[string]int{"cleanliness": 4, "wifi": 2}), wantErr: true},
+		{name: "type without criteria", mutate: func(r *domain.CreateReviewRequest) { r.SubRatings = map[string]int{"quality": 4} }, wantErr: true},
+		{name: "unregistered type", mutate: func(r *domain.CreateReviewRequest) { r.ReviewableType = "prodcut" }, wantErr: true},
+		{name: "pre-moderated type", mutate: book(func(*domain.CreateReviewRequest) {}), wantStatus: domain.ReviewStatusPending},
+		{name: "title required", mutate: book(func(r *domain.CreateReviewRequest) { r.Title = " " }), wantErr: true},
+		{name: "body too long", mutate: book(func(r *domain.CreateReviewRequest) { r.Body = "Read it twice" }), wantErr: true},
+		{name: "body at limit counts characters", mutate: book(func(r *domain.CreateReviewRequest) { r.Body = "Прочитал!!" }), wantStatus: domain.ReviewStatusPending},
+		{name: "photos not allowed", mutate: book(func(r *domain.CreateReviewRequest) { r.PhotoPaths = []string{"a.jpg"} }), wantErr: true},
+		{name: "reviewable does not exist", mutate: func(r *domain.CreateReviewRequest) { r.ReviewableID = 404 }, wantErr: true},
+		{name: "resolver unavailable", mutate: func(r *domain.CreateReviewRequest) { r.ReviewableID = 503 }, wantErr: true},
+		{name: "verified buyer", mutate: func(r *domain.CreateReviewRequest) { r.ReviewableID = 77 }, wantVerified: true},
+		{name: "verifier unavailable creates unverified", mutate: func(r *domain.CreateReviewRequest) { r.ReviewableID = 500 }},
+	}
+
+	// The catalog knows every reviewable except 404 and cannot be reached for 503.
+	resolver := resolverFunc(func(_ string, id int) (bool, error) {
+		if id == 503 {
+			return false, errors.New("catalog unavailable")
+		}
+		return id != 404, nil
+	})
+	// The author bought product 77; the order system fails for 500.
+	verifier := purchaseFunc(func(userID int, typ string, id int) (bool, error) {
+		if id == 500 {
+			return false, errors.New("order system unavailable")
+		}
+		return userID == 7 && typ == "product" && id == 77, nil
+	})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := memory.NewReviewRepo()
+			types := seedTypes(t, "product", "hotel")
+			_, _ = types.SaveType(ctx, domain.ReviewableType{
+				Name: "book", MaxBodyLength: 10, TitleRequired: true, ModerationMode: domain.ModerationPre,
+			})
+			criteria := memory.NewCriteriaRepo()
+			_ = criteria.SetCriteria(ctx, "hotel", []domain.RatingCriterion{
+				{Key: "cleanliness", Label: "Cleanliness", Required: true},
+				{Key: "location", Label: "Location", SortOrder: 1},
+			})
+			metrics := &spyMetrics{}
+			uc := usecase.NewCreateReviewUseCase(repo, types, criteria, resolver, verifier, metrics, logging.Nop())
+
+			req := valid
+			tt.mutate(&req)
+
+			id, err := uc.Execute(ctx, req, 7)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if metrics.reviews != 0 {
+					t.Errorf("ReviewCreated called %d times for invalid request", metrics.reviews)
+				}
+				return
+			}
+
+			got, err := repo.GetByID(ctx, id)
+			if err != nil {
+				t.Fatalf("GetByID(%d) error = %v", id, err)
+			}
+			if got.UserID != 7 || got.ReviewableType != req.ReviewableType || got.ReviewableID != req.ReviewableID ||
+				got.Rating != req.Rating || got.Title != req.Title || got.Body != req.Body {
+				t.Errorf("stored review = %+v, request %+v", got, req)
+			}
+			if want := cmp.Or(tt.wantStatus, domain.ReviewStatusPublished); got.Status != want {
+				t.Errorf("stored status = %q, want %q", got.Status, want)
+			}
+			if got.Verified != tt.wantVerified {
+				t.Errorf("stored verified = %v, want %v", got.Verified, tt.wantVerified)
+			}
+			subRatings, _ := repo.ListSubRatings(ctx, []int{id})
+			if !maps.Equal(subRatings[id], tt.wantSubRatings) {
+				t.Errorf("stored sub-ratings = %v, want %v", subRatings[id], tt.wantSubRatings)
+			}
+			if metrics.reviews != 1 {
+				t.Errorf("ReviewCreated called %d times, want 1", metrics.reviews)
+			}
+			if metrics.photosAttached != tt.wantPhotos {
+				t.Errorf("PhotosAttached total = %d, want %d", metrics.photosAttached, tt.wantPhotos)
+			}
+		})
+	}
+}
+
+func TestCreateCommentUseCase(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     func(reviewID int) domain.CreateCommentRequest
+		wantErr bool
+	}{
+		{
+			name: "valid comment",
+			req: func(id int) domain.CreateCommentRequest {
+				return domain.CreateCommentRequest{ReviewID: id, Body: "Agreed"}
+			},
+		},
+		{
+			name:    "missing review id",
+			req:     func(int) domain.CreateCommentRequest { return domain.CreateCommentRequest{Body: "Agreed"} },
+			wantErr: true,
+		},
+		{
+			name:    "missing body",
+			req:     func(id int) domain.CreateCommentRequest { return domain.CreateCommentRequest{ReviewID: id} },
+			wantErr: true,
+		},
+		{
+			name: "unknown review",
+			req: func(id int) domain.CreateCommentRequest {
+				return domain.CreateCommentRequest{ReviewID: id + 100, Body: "Agreed"}
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := memory.NewReviewRepo()
+			reviewID := seedReview(t, repo, "product", 1)
+			metrics := &spyMetrics{}
+			uc := usecase.NewCreateCommentUseCase(repo, memory.NewUserRepo(), metrics, logging.Nop())
+
+			id, err := uc.Execute(ctx, tt.req(reviewID), 3)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			comments, _ := repo.ListComments(ctx, reviewID)
+			if len(comments) != 1 || comments[0].ID != id || comments[0].UserID != 3 {
+				t.Errorf("comments = %+v, want one comment %d by user 3", comments, id)
+			}
+			if metrics.comments != 1 {
+				t.Errorf("CommentCreated called %d times, want 1", metrics.comments)
+			}
+		})
+	}
+}
+
+func TestListReviewsUseCase(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewReviewRepo()
+	first := seedReview(t, repo, "product", 1)
+	second := seedReview(t, repo, "product", 1)
+	seedReview(t, repo, "product", 2)
+	seedReview(t, repo, "vendor", 1)
+	verified, err := repo.Create(ctx, domain.Review{ReviewableType: "product", ReviewableID: 1, UserID: 1, Rating: 4, Verified: true})
+	if err != nil {
+		t.Fatalf("seed verified review: %v", err)
+	}
+
+	uc := usecase.NewListReviewsUseCase(repo, usecase.NopMetrics{}, logging.Nop())
+
+	tests := []struct {
+		name           string
+		reviewableType string
+		reviewableID   int
+		verifiedOnly   bool
+		wantIDs        []int
+		wantErr        bool
+	}{
+		{name: "newest first", reviewableType: "product", reviewableID: 1, wantIDs: []int{verified, second, first}},
+		{name: "verified only", reviewableType: "product", reviewableID: 1, verifiedOnly: true, wantIDs: []int{verified}},
+		{name: "no reviews", reviewableType: "product", reviewableID: 99},
+		{name: "missing type", reviewableID: 1, wantErr: true},
+		{name: "missing id", reviewableType: "product", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reviews, err := uc.Execute(ctx, tt.reviewableType, tt.reviewableID, tt.verifiedOnly)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(reviews) != len(tt.wantIDs) {
+				t.Fatalf("got %d reviews, want %d", len(reviews), len(tt.wantIDs))
+			}
+			for i, r := range reviews {
+				if r.ID != tt.wantIDs[i] {
+					t.Errorf("reviews[%d].ID = %d, want %d", i, r.ID, tt.wantIDs[i])
+				}
+			}
+		})
+	}
+}
+
+func TestGetReviewUseCase(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewReviewRepo()
+	reviewID := seedReview(t, repo, "product", 1)
+	for _, body := range []string{"first", "second"} {
+		if _, err := repo.AddComment(ctx, domain.ReviewComment{ReviewID: reviewID, UserID: 2, Body: body}); err != nil {
+			t.Fatalf("seed comment: %v", err)
+		}
+	}
+
+	uc := usecase.NewGetReviewUseCase(repo, memory.NewUserRepo(), usecase.NopMetrics{}, logging.Nop())
+
+	tests := []struct {
+		name         string
+		id           int
+		wantErr      bool
+		wantNotFound bool
+		wantComments []string
+	}{
+		{name: "review with comments", id: reviewID, wantComments: []string{"first", "second"}},
+		{name: "missing id", id: 0, wantErr: true},
+		{name: "unknown review", id: reviewID + 1, wantErr: true, wantNotFound: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			review, comments, err := uc.Execute(ctx, tt.id, 0)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantNotFound && !errors.Is(err, sql.ErrNoRows) {
+				t.Errorf("Execute() error = %v, want sql.ErrNoRows", err)
+			}
+			if tt.wantErr {
+				return
+			}
+			if review.ID != tt.id {
+				t.Errorf("review.ID = %d, want %d", review.ID, tt.id)
+			}
+			if len(comments) != len(tt.wantComments) {
+				t.Fatalf("got %d comments, want %d", len(comments), len(tt.wantComments))
+			}
+			for i, c := range comments {
+				if c.Body != tt.wantComments[i] {
+					t.Errorf("comments[%d].Body = %q, want %q", i, c.Body, tt.wantComments[i])
+				}
+			}
+		})
+	}
+}
+
+func TestUseCaseHonoursCancelledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	uc := usecase.NewListReviewsUseCase(memory.NewReviewRepo(), usecase.NopMetrics{}, logging.Nop())
+	if _, err := uc.Execute(ctx, "product", 1, false); !errors.Is(err, context.Canceled) {
+		t.Errorf("Execute() error = %v, want context.Canceled", err)
+	}
+}
+
+// seedTypes registers permissive reviewable types, as the default migration does.
+func seedTypes(t *testing.T, names ...string) *memory.ReviewableTypeRepo {
+	t.Helper()
+	types := memory.NewReviewableTypeRepo()
+	for _, name := range names {
+		rt := domain.ReviewableType{Name: name, PhotosAllowed: true, ModerationMode: domain.ModerationPost}
+		if _, err := types.SaveType(context.Background(), rt); err != nil {
+			t.Fatalf("seed type: %v", err)
+		}
+	}
+	return types
+}
+
+func seedReview(t *testing.T, repo usecase.ReviewRepository, reviewableType string, reviewableID int) int {
+	t.Helper()
+	id, err := repo.Create(context.Background(), domain.Review{
+		ReviewableType: reviewableType,
+		ReviewableID:   reviewableID,
+		UserID:         1,
+		Rating:         4,
+	})
+	if err != nil {
+		t.Fatalf("seed review: %v", err)
+	}
+	return id
+}
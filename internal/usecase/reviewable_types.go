@@ -0,0 +1,132 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"eve/domain"
+)
+
+// ReviewableTypeRepository stores the registry of reviewable types.
+type ReviewableTypeRepository interface {
+	// SaveType registers rt.Name or replaces its settings and returns the stored type.
+	SaveType(ctx context.Context, rt domain.ReviewableType) (domain.ReviewableType, error)
+
+	// GetType loads a registered type. An unknown name yields an error wrapping sql.ErrNoRows.
+	GetType(ctx context.Context, name string) (domain.ReviewableType, error)
+
+	// ListTypes returns every registered type ordered by name.
+	ListTypes(ctx context.Context) ([]domain.ReviewableType, error)
+}
+
+// identifier restricts reviewable type names and criterion keys to lowercase
+// names that read well in JSON and URLs.
+var identifier = regexp.MustCompile(`^[a-z][a-z0-9_]{0,49}$`)
+
+// SaveReviewableTypeUseCase registers a reviewable type or changes its
+// settings. Only admins may call it.
+type SaveReviewableTypeUseCase struct {
+	users   UserRepository
+	types   ReviewableTypeRepository
+	metrics Metrics
+	log     *slog.Logger
+}
+
+// NewSaveReviewableTypeUseCase constructs a new SaveReviewableTypeUseCase.
+func NewSaveReviewableTypeUseCase(u UserRepository, t ReviewableTypeRepository, m Metrics, l *slog.Logger) *SaveReviewableTypeUseCase {
+	return &SaveReviewableTypeUseCase{users: u, types: t, metrics: m, log: l}
+}
+
+// Execute stores the settings of the named type on behalf of actorID. The new
+// settings apply to reviews created from now on; existing reviews are kept.
+func (uc *SaveReviewableTypeUseCase) Execute(ctx context.Context, name string, req domain.SaveReviewableTypeRequest, actorID int) (_ domain.ReviewableType, err error) {
+	ctx, end := track(ctx, uc.metrics, uc.log, "save_reviewable_type")
+	defer end(&err)
+
+	if err := requireRole(ctx, uc.users, actorID, domain.RoleAdmin); err != nil {
+		return domain.ReviewableType{}, err
+	}
+	if !identifier.MatchString(name) {
+		return domain.ReviewableType{}, invalidInput("reviewable type %q must be lowercase letters, digits and underscores", name)
+	}
+	if req.MaxBodyLength < 0 {
+		return domain.ReviewableType{}, invalidInput("max_body_length must not be negative")
+	}
+	switch req.ModerationMode {
+	case "":
+		req.ModerationMode = domain.ModerationPost
+	case domain.ModerationPost, domain.ModerationPre:
+	default:
+		return domain.ReviewableType{}, invalidInput("moderation_mode must be %q or %q", domain.ModerationPost, domain.ModerationPre)
+	}
+
+	saved, err := uc.types.SaveType(ctx, domain.ReviewableType{
+		Name:           name,
+		PhotosAllowed:  req.PhotosAllowed,
+		MaxBodyLength:  req.MaxBodyLength,
+		TitleRequired:  req.TitleRequired,
+		ModerationMode: req.ModerationMode,
+	})
+	if err != nil {
+		return domain.ReviewableType{}, fmt.Errorf("save reviewable type: %w", err)
+	}
+	uc.log.InfoContext(ctx, "reviewable type saved",
+		slog.String("reviewable_type", name),
+		slog.String("moderation_mode", saved.ModerationMode),
+		slog.Int("user_id", actorID),
+	)
+	return saved, nil
+}
+
+// ListReviewableTypesUseCase returns the registered reviewable types.
+type ListReviewableTypesUseCase struct {
+	types   ReviewableTypeRepository
+	metrics Metrics
+	log     *slog.Logger
+}
+
+// NewListReviewableTypesUseCase constructs a new ListReviewableTypesUseCase.
+func NewListReviewableTypesUseCase(t ReviewableTypeRepository, m Metrics, l *slog.Logger) *ListReviewableTypesUseCase {
+	return &ListReviewableTypesUseCase{types: t, metrics: m, log: l}
+}
+
+// Execute returns every registered type ordered by name.
+func (uc *ListReviewableTypesUseCase) Execute(ctx context.Context) (_ []domain.ReviewableType, err error) {
+	ctx, end := track(ctx, uc.metrics, uc.log, "list_reviewable_types")
+	defer end(&err)
+
+	return uc.types.ListTypes(ctx)
+}
+
+// lookupType loads a registered reviewable type, reporting an unknown name as invalid input.
+func lookupType(ctx context.Context, types ReviewableTypeRepository, name string) (domain.ReviewableType, error) {
+	rt, err := types.GetType(ctx, name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.ReviewableType{}, invalidInput("unknown reviewable_type %q", name)
+	}
+	if err != nil {
+		return domain.ReviewableType{}, fmt.Errorf("get reviewable type: %w", err)
+	}
+	return rt, nil
+}
+
+// checkTypeRules validates a review as it is about to be stored, with
+// newPhotos photos being added to it, against the settings of its type.
+func checkTypeRules(rt domain.ReviewableType, review domain.Review, newPhotos int) error {
+	if rt.TitleRequired && strings.TrimSpace(review.Title) == "" {
+		return invalidInput("title is required for %s reviews", rt.Name)
+	}
+	if rt.MaxBodyLength > 0 && utf8.RuneCountInString(review.Body) > rt.MaxBodyLength {
+		return invalidInput("body must be at most %d characters for %s reviews", rt.MaxBodyLength, rt.Name)
+	}
+	if !rt.PhotosAllowed && newPhotos > 0 {
+		return invalidInput("photos are not allowed for %s reviews", rt.Name)
+	}
+	return nil
+}
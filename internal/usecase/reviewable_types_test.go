@@ -0,0 +1,124 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"eve/domain"
+	"eve/internal/logging"
+	"eve/internal/repository/memory"
+	"eve/internal/usecase"
+)
+
+func TestSaveReviewableTypeUseCase(t *testing.T) {
+	users := memory.NewUserRepo()
+	admin := seedUser(t, users, "admin@example.com", domain.RoleAdmin)
+	moderator := seedUser(t, users, "mod@example.com", domain.RoleModerator)
+
+	tests := []struct {
+		name     string
+		typ      string
+		actor    int
+		req      domain.SaveReviewableTypeRequest
+		wantErr  error
+		wantMode string
+	}{
+		{name: "defaults to post-moderation", typ: "book", actor: admin, wantMode: domain.ModerationPost},
+		{name: "pre-moderation", typ: "book", actor: admin, req: domain.SaveReviewableTypeRequest{ModerationMode: domain.ModerationPre}, wantMode: domain.ModerationPre},
+		{name: "moderator is forbidden", typ: "book", actor: moderator, wantErr: usecase.ErrForbidden},
+		{name: "invalid name", typ: "Books!", actor: admin, wantErr: usecase.ErrInvalidInput},
+		{name: "negative body length", typ: "book", actor: admin, req: domain.SaveReviewableTypeRequest{MaxBodyLength: -1}, wantErr: usecase.ErrInvalidInput},
+		{name: "unknown moderation mode", typ: "book", actor: admin, req: domain.SaveReviewableTypeRequest{ModerationMode: "never"}, wantErr: usecase.ErrInvalidInput},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			types := memory.NewReviewableTypeRepo()
+			uc := usecase.NewSaveReviewableTypeUseCase(users, types, usecase.NopMetrics{}, logging.Nop())
+
+			got, err := uc.Execute(ctx, tt.typ, tt.req, tt.actor)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Execute() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if all, _ := types.ListTypes(ctx); len(all) != 0 {
+					t.Errorf("failed save stored %+v", all)
+				}
+				return
+			}
+			if got.Name != tt.typ || got.ModerationMode != tt.wantMode {
+				t.Errorf("Execute() = %+v, want %s with mode %q", got, tt.typ, tt.wantMode)
+			}
+		})
+	}
+}
+
+func TestApproveReviewUseCase(t *testing.T) {
+	ctx := context.Background()
+	users := memory.NewUserRepo()
+	author := seedUser(t, users, "author@example.com", domain.RoleUser)
+	moderator := seedUser(t, users, "mod@example.com", domain.RoleModerator)
+	repo := memory.NewReviewRepo()
+	id, _ := repo.Create(ctx, domain.Review{ReviewableType: "book", ReviewableID: 1, UserID: author, Rating: 4, Status: domain.ReviewStatusPending})
+	uc := usecase.NewApproveReviewUseCase(repo, users, usecase.NopMetrics{}, logging.Nop())
+
+	if err := uc.Execute(ctx, id, author); !errors.Is(err, usecase.ErrForbidden) {
+		t.Errorf("author approve error = %v, want ErrForbidden", err)
+	}
+	if err := uc.Execute(ctx, id, moderator); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if got, _ := repo.GetByID(ctx, id); got.Status != domain.ReviewStatusPublished {
+		t.Errorf("status after approval = %q, want published", got.Status)
+	}
+	if err := uc.Execute(ctx, id, moderator); !errors.Is(err, usecase.ErrNotFound) {
+		t.Errorf("second approve error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestPendingReviewVisibility(t *testing.T) {
+	ctx := context.Background()
+	users := memory.NewUserRepo()
+	author := seedUser(t, users, "author@example.com", domain.RoleUser)
+	stranger := seedUser(t, users, "stranger@example.com", domain.RoleUser)
+	moderator := seedUser(t, users, "mod@example.com", domain.RoleModerator)
+	repo := memory.NewReviewRepo()
+	pending, _ := repo.Create(ctx, domain.Review{ReviewableType: "book", ReviewableID: 1, UserID: author, Rating: 4, Status: domain.ReviewStatusPending})
+	get := usecase.NewGetReviewUseCase(repo, users, usecase.NopMetrics{}, logging.Nop())
+	comment := usecase.NewCreateCommentUseCase(repo, users, usecase.NopMetrics{}, logging.Nop())
+	queue := usecase.NewListPendingReviewsUseCase(repo, users, usecase.NopMetrics{}, logging.Nop())
+
+	for _, tt := range []struct {
+		name   string
+		viewer int
+		want   error
+	}{
+		{"anonymous", 0, usecase.ErrNotFound},
+		{"stranger", stranger, usecase.ErrNotFound},
+		{"author", author, nil},
+		{"moderator", moderator, nil},
+	} {
+		if _, _, err := get.Execute(ctx, pending, tt.viewer); !errors.Is(err, tt.want) {
+			t.Errorf("GetReview(%s) error = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+
+	if _, err := comment.Execute(ctx, domain.CreateCommentRequest{ReviewID: pending, Body: "hi"}, stranger); !errors.Is(err, usecase.ErrNotFound) {
+		t.Errorf("CreateComment(stranger) error = %v, want ErrNotFound", err)
+	}
+	if _, err := comment.Execute(ctx, domain.CreateCommentRequest{ReviewID: pending, Body: "hi"}, author); !errors.Is(err, usecase.ErrInvalidInput) {
+		t.Errorf("CreateComment(author) error = %v, want ErrInvalidInput", err)
+	}
+
+	if _, err := queue.Execute(ctx, 0, 0, author); !errors.Is(err, usecase.ErrForbidden) {
+		t.Errorf("ListPending(author) error = %v, want ErrForbidden", err)
+	}
+	if _, err := queue.Execute(ctx, usecase.MaxPendingLimit+1, 0, moderator); !errors.Is(err, usecase.ErrInvalidInput) {
+		t.Errorf("ListPending(limit too large) error = %v, want ErrInvalidInput", err)
+	}
+	if got, err := queue.Execute(ctx, 0, 0, moderator); err != nil || len(got) != 1 || got[0].ID != pending {
+		t.Errorf("ListPending() = %+v, %v; want the pending review", got, err)
+	}
+}
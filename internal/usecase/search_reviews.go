@@ -0,0 +1,79 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"unicode/utf8"
+
+	"eve/domain"
+)
+
+// ReviewSearcher performs full-text search over reviews. Storage adapters
+// implement it next to their ReviewRepository.
+type ReviewSearcher interface {
+	// SearchReviews returns the page of reviews matching every term in q.Query,
+	// best match first, together with the total number of matches.
+	SearchReviews(ctx context.Context, q domain.ReviewSearchQuery) (domain.ReviewSearchResult, error)
+}
+
+// Search paging limits.
+const (
+	DefaultSearchLimit = 20
+	MaxSearchLimit     = 100
+	maxSearchQueryLen  = 256
+)
+
+// SearchReviewsUseCase validates and runs review searches.
+type SearchReviewsUseCase struct {
+	searcher ReviewSearcher
+	metrics  Metrics
+	log      *slog.Logger
+}
+
+// NewSearchReviewsUseCase constructs a new SearchReviewsUseCase.
+func NewSearchReviewsUseCase(s ReviewSearcher, m Metrics, l *slog.Logger) *SearchReviewsUseCase {
+	return &SearchReviewsUseCase{searcher: s, metrics: m, log: l}
+}
+
+// Execute normalizes paging (a zero limit means DefaultSearchLimit) and
+// returns the matching page.
+func (uc *SearchReviewsUseCase) Execute(ctx context.Context, q domain.ReviewSearchQuery) (_ domain.ReviewSearchResult, err error) {
+	ctx, end := track(ctx, uc.metrics, uc.log, "search_reviews")
+	defer end(&err)
+
+	q.Query = strings.TrimSpace(q.Query)
+	if q.Query == "" {
+		return domain.ReviewSearchResult{}, invalidInput("q is required")
+	}
+	if utf8.RuneCountInString(q.Query) > maxSearchQueryLen {
+		return domain.ReviewSearchResult{}, invalidInput("q must be at most %d characters", maxSearchQueryLen)
+	}
+	q.ReviewableType = scopedType(ctx, q.ReviewableType)
+	if err := checkTypeScope(ctx, q.ReviewableType); err != nil {
+		return domain.ReviewSearchResult{}, err
+	}
+	if q.ReviewableID != 0 && q.ReviewableType == "" {
+		return domain.ReviewSearchResult{}, invalidInput("reviewable_id requires reviewable_type")
+	}
+	if q.Limit == 0 {
+		q.Limit = DefaultSearchLimit
+	}
+	if q.Limit < 0 || q.Limit > MaxSearchLimit {
+		return domain.ReviewSearchResult{}, invalidInput("limit must be between 1 and %d", MaxSearchLimit)
+	}
+	if q.Offset < 0 {
+		return domain.ReviewSearchResult{}, invalidInput("offset must not be negative")
+	}
+
+	res, err := uc.searcher.SearchReviews(ctx, q)
+	if err != nil {
+		return domain.ReviewSearchResult{}, fmt.Errorf("search reviews: %w", err)
+	}
+	if res.Hits == nil {
+		res.Hits = []domain.ReviewSearchHit{}
+	}
+	res.Limit, res.Offset = q.Limit, q.Offset
+	return res, nil
+}
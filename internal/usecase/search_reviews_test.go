@@ -0,0 +1,62 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"eve/domain"
+	"eve/internal/logging"
+	"eve/internal/repository/memory"
+	"eve/internal/usecase"
+)
+
+func TestSearchReviewsUseCase(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewReviewRepo()
+	for i := 0; i < 3; i++ {
+		if _, err := repo.Create(ctx, domain.Review{ReviewableType: "product", ReviewableID: 1, UserID: 1, Rating: 4, Body: "solid battery"}); err != nil {
+			t.Fatalf("seed review: %v", err)
+		}
+	}
+	uc := usecase.NewSearchReviewsUseCase(repo, usecase.NopMetrics{}, logging.Nop())
+
+	tests := []struct {
+		name      string
+		q         domain.ReviewSearchQuery
+		wantErr   bool
+		wantHits  int
+		wantLimit int
+	}{
+		{name: "default limit", q: domain.ReviewSearchQuery{Query: "  battery "}, wantHits: 3, wantLimit: usecase.DefaultSearchLimit},
+		{name: "explicit page", q: domain.ReviewSearchQuery{Query: "battery", Limit: 2, Offset: 2}, wantHits: 1, wantLimit: 2},
+		{name: "no matches", q: domain.ReviewSearchQuery{Query: "zebra"}, wantHits: 0, wantLimit: usecase.DefaultSearchLimit},
+		{name: "empty query", q: domain.ReviewSearchQuery{Query: "   "}, wantErr: true},
+		{name: "query too long", q: domain.ReviewSearchQuery{Query: strings.Repeat("a", 257)}, wantErr: true},
+		{name: "id without type", q: domain.ReviewSearchQuery{Query: "battery", ReviewableID: 1}, wantErr: true},
+		{name: "limit too large", q: domain.ReviewSearchQuery{Query: "battery", Limit: usecase.MaxSearchLimit + 1}, wantErr: true},
+		{name: "negative offset", q: domain.ReviewSearchQuery{Query: "battery", Offset: -1}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := uc.Execute(ctx, tt.q)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if !errors.Is(err, usecase.ErrInvalidInput) {
+					t.Errorf("Execute() error = %v, want ErrInvalidInput", err)
+				}
+				return
+			}
+			if len(res.Hits) != tt.wantHits || res.Hits == nil {
+				t.Errorf("got %d hits (nil=%v), want %d", len(res.Hits), res.Hits == nil, tt.wantHits)
+			}
+			if res.Limit != tt.wantLimit {
+				t.Errorf("Limit = %d, want %d", res.Limit, tt.wantLimit)
+			}
+		})
+	}
+}
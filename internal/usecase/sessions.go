@@ -0,0 +1,104 @@
+package usecase
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SessionPolicy controls the session tokens issued at login. Tokens are
+// signed with Secret and accepted until TTL after they were issued. They are
+// not stored, so a token cannot be revoked before it expires; changing
+// Secret invalidates every token at once.
+type SessionPolicy struct {
+	Secret []byte
+	TTL    time.Duration
+}
+
+// Session tokens look like "evs_<user id>_<expiry>_<signature>", the expiry
+// in Unix seconds and the signature a hex-encoded HMAC-SHA256 of everything
+// before it.
+const sessionScheme = "evs_"
+
+// IsSessionToken reports whether token looks like a session token rather
+// than an API key; it does not check the signature.
+func IsSessionToken(token string) bool {
+	return strings.HasPrefix(token, sessionScheme)
+}
+
+// sign returns a token for userID that expires at expiresAt.
+func (p SessionPolicy) sign(userID int, expiresAt time.Time) string {
+	claims := sessionScheme + strconv.Itoa(userID) + "_" + strconv.FormatInt(expiresAt.Unix(), 10)
+	return claims + "_" + hex.EncodeToString(p.mac(claims))
+}
+
+// verify returns the user of token when it carries a valid signature and has
+// not expired at now.
+func (p SessionPolicy) verify(token string, now time.Time) (int, bool) {
+	claims, sig, ok := cutLast(token, "_")
+	if !ok || !IsSessionToken(claims) {
+		return 0, false
+	}
+	mac, err := hex.DecodeString(sig)
+	if err != nil || !hmac.Equal(mac, p.mac(claims)) {
+		return 0, false
+	}
+	user, expiry, ok := strings.Cut(strings.TrimPrefix(claims, sessionScheme), "_")
+	if !ok {
+		return 0, false
+	}
+	userID, err := strconv.Atoi(user)
+	if err != nil || userID <= 0 {
+		return 0, false
+	}
+	exp, err := strconv.ParseInt(expiry, 10, 64)
+	if err != nil || !now.Before(time.Unix(exp, 0)) {
+		return 0, false
+	}
+	return userID, true
+}
+
+func (p SessionPolicy) mac(claims string) []byte {
+	h := hmac.New(sha256.New, p.Secret)
+	h.Write([]byte(claims))
+	return h.Sum(nil)
+}
+
+// cutLast slices s around the last instance of sep.
+func cutLast(s, sep string) (before, after string, found bool) {
+	if i := strings.LastIndex(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
+
+// AuthenticateSessionUseCase checks the session token a request was made
+// with.
+type AuthenticateSessionUseCase struct {
+	policy  SessionPolicy
+	metrics Metrics
+	log     *slog.Logger
+}
+
+// NewAuthenticateSessionUseCase constructs a new AuthenticateSessionUseCase.
+func NewAuthenticateSessionUseCase(p SessionPolicy, m Metrics, l *slog.Logger) *AuthenticateSessionUseCase {
+	return &AuthenticateSessionUseCase{policy: p, metrics: m, log: l}
+}
+
+// Execute returns the user token was issued to, or an error matching
+// ErrUnauthenticated when it is forged, malformed or expired.
+func (uc *AuthenticateSessionUseCase) Execute(ctx context.Context, token string) (_ int, err error) {
+	_, end := track(ctx, uc.metrics, uc.log, "authenticate_session")
+	defer end(&err)
+
+	userID, ok := uc.policy.verify(token, time.Now())
+	if !ok {
+		return 0, &unauthenticatedError{msg: "invalid or expired session token"}
+	}
+	return userID, nil
+}
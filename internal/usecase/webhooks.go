@@ -0,0 +1,502 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"slices"
+	"sync/atomic"
+	"time"
+
+	"eve/domain"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// WebhookRepository stores webhook subscriptions and their deliveries.
+type WebhookRepository interface {
+	// CreateSubscription stores a subscription and returns it with its ID and CreatedAt.
+	CreateSubscription(ctx context.Context, sub domain.WebhookSubscription) (domain.WebhookSubscription, error)
+
+	// GetSubscription loads a subscription. A missing one yields an error wrapping sql.ErrNoRows.
+	GetSubscription(ctx context.Context, id int) (domain.WebhookSubscription, error)
+
+	// ListSubscriptions returns every subscription ordered by ID.
+	ListSubscriptions(ctx context.Context) ([]domain.WebhookSubscription, error)
+
+	// DeleteSubscription removes a subscription together with its deliveries.
+	// A missing one yields an error wrapping sql.ErrNoRows.
+	DeleteSubscription(ctx context.Context, id int) error
+
+	// EnqueueDeliveries creates a pending delivery of event, due immediately,
+	// for each of the subscriptions, with the JSON-encoded event as payload.
+	// Deliveries that already exist for the event are left untouched, so an
+	// event published twice is delivered once.
+	EnqueueDeliveries(ctx context.Context, event domain.Event, subscriptionIDs []int) error
+
+	// ClaimDueDeliveries claims up to limit pending deliveries whose next
+	// attempt is due at now, the longest due first, by moving their next
+	// attempt to until. Until then they are not claimed again, so concurrent
+	// workers never send the same delivery; a delivery whose outcome is not
+	// recorded by then, because its worker died, is retried afterwards.
+	ClaimDueDeliveries(ctx context.Context, now, until time.Time, limit int) ([]domain.WebhookDelivery, error)
+
+	// ListDeliveries returns up to limit deliveries of a subscription, newest
+	// first. A non-empty status restricts them to that status.
+	ListDeliveries(ctx context.Context, subscriptionID int, status string, limit int) ([]domain.WebhookDelivery, error)
+
+	// GetDelivery loads a delivery. A missing one yields an error wrapping sql.ErrNoRows.
+	GetDelivery(ctx context.Context, id int) (domain.WebhookDelivery, error)
+
+	// UpdateDelivery stores the Status, Attempts, LastStatusCode and LastError
+	// of d and schedules its next attempt at nextAttemptAt. A missing delivery
+	// yields an error wrapping sql.ErrNoRows.
+	UpdateDelivery(ctx context.Context, d domain.WebhookDelivery, nextAttemptAt time.Time) error
+}
+
+// webhookEvents are the event types subscriptions may filter on.
+var webhookEvents = []string{
+	domain.EventReviewCreated,
+	domain.EventReviewUpdated,
+	domain.EventReviewDeleted,
+	domain.EventCommentAdded,
+}
+
+// minSecretLength is the shortest secret accepted from callers. Generated
+// secrets are 32 random bytes, hex-encoded.
+const minSecretLength = 16
+
+// CreateWebhookUseCase subscribes a URL to domain events. Only admins may call it.
+type CreateWebhookUseCase struct {
+	users    UserRepository
+	types    ReviewableTypeRepository
+	webhooks WebhookRepository
+	metrics  Metrics
+	log      *slog.Logger
+}
+
+// NewCreateWebhookUseCase constructs a new CreateWebhookUseCase.
+func NewCreateWebhookUseCase(u UserRepository, t ReviewableTypeRepository, w WebhookRepository, m Metrics, l *slog.Logger) *CreateWebhookUseCase {
+	return &CreateWebhookUseCase{users: u, types: t, webhooks: w, metrics: m, log: l}
+}
+
+// Execute stores the subscription on behalf of actorID and returns it,
+// including its secret, which is not shown again.
+func (uc *CreateWebhookUseCase) Execute(ctx context.Context, req domain.CreateWebhookRequest, actorID int) (_ domain.WebhookSubscription, err error) {
+	ctx, end := track(ctx, uc.metrics, uc.log, "create_webhook")
+	defer end(&err)
+
+	if err := requireRole(ctx, uc.users, actorID, domain.RoleAdmin); err != nil {
+		return domain.WebhookSubscription{}, err
+	}
+	u, err := url.Parse(req.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return domain.WebhookSubscription{}, invalidInput("url must be an absolute http or https URL")
+	}
+	for _, e := range req.Events {
+		if !slices.Contains(webhookEvents, e) {
+			return domain.WebhookSubscription{}, invalidInput("unknown event %q, want one of %v", e, webhookEvents)
+		}
+	}
+	if req.ReviewableType != "" {
+		if _, err := lookupType(ctx, uc.types, req.ReviewableType); err != nil {
+			return domain.WebhookSubscription{}, err
+		}
+	}
+	switch {
+	case req.Secret == "":
+		b := make([]byte, 32)
+		if _, err := rand.Read(b); err != nil {
+			return domain.WebhookSubscription{}, fmt.Errorf("generate secret: %w", err)
+		}
+		req.Secret = hex.EncodeToString(b)
+	case len(req.Secret) < minSecretLength:
+		return domain.WebhookSubscription{}, invalidInput("secret must be at least %d characters", minSecretLength)
+	}
+
+	events := slices.Clone(req.Events)
+	slices.Sort(events)
+	sub, err := uc.webhooks.CreateSubscription(ctx, domain.WebhookSubscription{
+		URL:            req.URL,
+		Events:         slices.Compact(events),
+		ReviewableType: req.ReviewableType,
+		Secret:         req.Secret,
+	})
+	if err != nil {
+		return domain.WebhookSubscription{}, fmt.Errorf("create webhook: %w", err)
+	}
+	uc.log.InfoContext(ctx, "webhook created",
+		slog.Int("webhook_id", sub.ID),
+		slog.String("url", sub.URL),
+		slog.Int("actor_id", actorID),
+	)
+	return sub, nil
+}
+
+// ListWebhooksUseCase returns the webhook subscriptions, without their
+// secrets. Only admins may call it.
+type ListWebhooksUseCase struct {
+	users    UserRepository
+	webhooks WebhookRepository
+	metrics  Metrics
+	log      *slog.Logger
+}
+
+// NewListWebhooksUseCase constructs a new ListWebhooksUseCase.
+func NewListWebhooksUseCase(u UserRepository, w WebhookRepository, m Metrics, l *slog.Logger) *ListWebhooksUseCase {
+	return &ListWebhooksUseCase{users: u, webhooks: w, metrics: m, log: l}
+}
+
+// Execute returns every subscription ordered by ID.
+func (uc *ListWebhooksUseCase) Execute(ctx context.Context, actorID int) (_ []domain.WebhookSubscription, err error) {
+	ctx, end := track(ctx, uc.metrics, uc.log, "list_webhooks")
+	defer end(&err)
+
+	if err := requireRole(ctx, uc.users, actorID, domain.RoleAdmin); err != nil {
+		return nil, err
+	}
+	subs, err := uc.webhooks.ListSubscriptions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list webhooks: %w", err)
+	}
+	for i := range subs {
+		subs[i].Secret = ""
+	}
+	return subs, nil
+}
+
+// DeleteWebhookUseCase unsubscribes a webhook and drops its delivery log.
+// Only admins may call it.
+type DeleteWebhookUseCase struct {
+	users    UserRepository
+	webhooks WebhookRepository
+	metrics  Metrics
+	log      *slog.Logger
+}
+
+// NewDeleteWebhookUseCase constructs a new DeleteWebhookUseCase.
+func NewDeleteWebhookUseCase(u UserRepository, w WebhookRepository, m Metrics, l *slog.Logger) *DeleteWebhookUseCase {
+	return &DeleteWebhookUseCase{users: u, webhooks: w, metrics: m, log: l}
+}
+
+// Execute deletes subscription id on behalf of actorID.
+func (uc *DeleteWebhookUseCase) Execute(ctx context.Context, id, actorID int) (err error) {
+	ctx, end := track(ctx, uc.metrics, uc.log, "delete_webhook")
+	defer end(&err)
+
+	if err := requireRole(ctx, uc.users, actorID, domain.RoleAdmin); err != nil {
+		return err
+	}
+	if err := uc.webhooks.DeleteSubscription(ctx, id); err != nil {
+		return notFound(err, "delete webhook", "webhook %d not found", id)
+	}
+	uc.log.InfoContext(ctx, "webhook deleted",
+		slog.Int("webhook_id", id),
+		slog.Int("actor_id", actorID),
+	)
+	return nil
+}
+
+// WebhookEventPublisher is an EventPublisher that queues a webhook delivery
+// of every event for each subscription matching it. The deliveries are sent
+// by DeliverWebhooksUseCase.
+type WebhookEventPublisher struct {
+	webhooks WebhookRepository
+}
+
+// NewWebhookEventPublisher constructs a new WebhookEventPublisher.
+func NewWebhookEventPublisher(w WebhookRepository) *WebhookEventPublisher {
+	return &WebhookEventPublisher{webhooks: w}
+}
+
+func (p *WebhookEventPublisher) Publish(ctx context.Context, event domain.Event) error {
+	subs, err := p.webhooks.ListSubscriptions(ctx)
+	if err != nil {
+		return fmt.Errorf("list webhooks: %w", err)
+	}
+	var ids []int
+	for _, s := range subs {
+		if s.Matches(event) {
+			ids = append(ids, s.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	if err := p.webhooks.EnqueueDeliveries(ctx, event, ids); err != nil {
+		return fmt.Errorf("enqueue webhook deliveries: %w", err)
+	}
+	return nil
+}
+
+// deliveryLogLimit bounds the number of deliveries returned by the delivery log.
+const deliveryLogLimit = 100
+
+// ListWebhookDeliveriesUseCase returns the delivery log of a subscription.
+// Only admins may call it.
+type ListWebhookDeliveriesUseCase struct {
+	users    UserRepository
+	webhooks WebhookRepository
+	metrics  Metrics
+	log      *slog.Logger
+}
+
+// NewListWebhookDeliveriesUseCase constructs a new ListWebhookDeliveriesUseCase.
+func NewListWebhookDeliveriesUseCase(u UserRepository, w WebhookRepository, m Metrics, l *slog.Logger) *ListWebhookDeliveriesUseCase {
+	return &ListWebhookDeliveriesUseCase{users: u, webhooks: w, metrics: m, log: l}
+}
+
+// Execute returns the latest deliveries of subscription id, newest first,
+// optionally restricted to one status.
+func (uc *ListWebhookDeliveriesUseCase) Execute(ctx context.Context, id int, status string, actorID int) (_ []domain.WebhookDelivery, err error) {
+	ctx, end := track(ctx, uc.metrics, uc.log, "list_webhook_deliveries")
+	defer end(&err)
+
+	if err := requireRole(ctx, uc.users, actorID, domain.RoleAdmin); err != nil {
+		return nil, err
+	}
+	switch status {
+	case "", domain.DeliveryPending, domain.DeliverySucceeded, domain.DeliveryDead:
+	default:
+		return nil, invalidInput("invalid status %q", status)
+	}
+	if _, err := uc.webhooks.GetSubscription(ctx, id); err != nil {
+		return nil, notFound(err, "get webhook", "webhook %d not found", id)
+	}
+	deliveries, err := uc.webhooks.ListDeliveries(ctx, id, status, deliveryLogLimit)
+	if err != nil {
+		return nil, fmt.Errorf("list webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+// RedeliverWebhookUseCase queues a delivery to be sent again right away, with
+// a fresh set of attempts. It is how dead deliveries are retried once the
+// receiver is fixed. Only admins may call it.
+type RedeliverWebhookUseCase struct {
+	users    UserRepository
+	webhooks WebhookRepository
+	metrics  Metrics
+	log      *slog.Logger
+}
+
+// NewRedeliverWebhookUseCase constructs a new RedeliverWebhookUseCase.
+func NewRedeliverWebhookUseCase(u UserRepository, w WebhookRepository, m Metrics, l *slog.Logger) *RedeliverWebhookUseCase {
+	return &RedeliverWebhookUseCase{users: u, webhooks: w, metrics: m, log: l}
+}
+
+// Execute requeues delivery deliveryID of subscription id on behalf of actorID.
+func (uc *RedeliverWebhookUseCase) Execute(ctx context.Context, id, deliveryID, actorID int) (err error) {
+	ctx, end := track(ctx, uc.metrics, uc.log, "redeliver_webhook")
+	defer end(&err)
+
+	if err := requireRole(ctx, uc.users, actorID, domain.RoleAdmin); err != nil {
+		return err
+	}
+	d, err := uc.webhooks.GetDelivery(ctx, deliveryID)
+	if err != nil {
+		return notFound(err, "get webhook delivery", "delivery %d not found", deliveryID)
+	}
+	if d.SubscriptionID != id {
+		return &notFoundError{msg: fmt.Sprintf("delivery %d not found", deliveryID)}
+	}
+	d.Status = domain.DeliveryPending
+	d.Attempts = 0
+	if err := uc.webhooks.UpdateDelivery(ctx, d, time.Now()); err != nil {
+		return notFound(err, "redeliver webhook", "delivery %d not found", deliveryID)
+	}
+	uc.log.InfoContext(ctx, "webhook redelivery queued",
+		slog.Int("webhook_id", id),
+		slog.Int("delivery_id", deliveryID),
+		slog.Int("actor_id", actorID),
+	)
+	return nil
+}
+
+// WebhookSender sends a delivery to the URL of its subscription.
+// Implementations live in internal/infrastructure.
+type WebhookSender interface {
+	// Send posts d.Payload to sub.URL, signed with sub.Secret, and returns the
+	// status code of the response. err is set when no response was received.
+	Send(ctx context.Context, sub domain.WebhookSubscription, d domain.WebhookDelivery) (statusCode int, err error)
+}
+
+// RetryPolicy controls how failed webhook deliveries and outbox events are
+// retried. The n-th retry waits BaseDelay*2^(n-1), at most MaxDelay; a
+// delivery or event that failed MaxAttempts times is dead-lettered.
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// backoff returns the delay before the attempt following the given number
+// of failed ones.
+func (p RetryPolicy) backoff(attempts int) time.Duration {
+	d := p.BaseDelay
+	for i := 1; i < attempts && d < p.MaxDelay; i++ {
+		d *= 2
+	}
+	return min(d, p.MaxDelay)
+}
+
+// deliveryBatchSize bounds the number of deliveries claimed per run.
+const deliveryBatchSize = 100
+
+// DeliverWebhooksUseCase sends the webhook deliveries that are due. It is run
+// periodically by a background worker, possibly on several instances at once.
+type DeliverWebhooksUseCase struct {
+	webhooks WebhookRepository
+	sender   WebhookSender
+	policy   RetryPolicy
+	workers  int
+	lease    time.Duration
+	metrics  Metrics
+	log      *slog.Logger
+}
+
+// NewDeliverWebhooksUseCase constructs a new DeliverWebhooksUseCase that sends
+// up to workers deliveries at once. Each run claims its deliveries for lease;
+// sends still running when the lease ends are cancelled.
+func NewDeliverWebhooksUseCase(w WebhookRepository, s WebhookSender, p RetryPolicy, workers int, lease time.Duration, m Metrics, l *slog.Logger) *DeliverWebhooksUseCase {
+	return &DeliverWebhooksUseCase{webhooks: w, sender: s, policy: p, workers: workers, lease: lease, metrics: m, log: l}
+}
+
+// subscriptionLookup is the subscription of a batch's deliveries, or why it
+// could not be loaded.
+type subscriptionLookup struct {
+	sub domain.WebhookSubscription
+	err error
+}
+
+// Execute claims one batch of due deliveries, sends them and returns how many
+// succeeded. A delivery is successful when the receiver answers with a 2xx
+// status; otherwise it is rescheduled with exponential backoff, or marked
+// dead after the last attempt allowed by the retry policy. A delivery whose
+// subscription cannot be loaded fails the same way without holding up the
+// rest of the batch.
+func (uc *DeliverWebhooksUseCase) Execute(ctx context.Context) (delivered int, err error) {
+	ctx, end := track(ctx, uc.metrics, uc.log, "deliver_webhooks")
+	defer end(&err)
+
+	now := time.Now()
+	until := now.Add(uc.lease)
+	due, err := uc.webhooks.ClaimDueDeliveries(ctx, now, until, deliveryBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("claim due webhook deliveries: %w", err)
+	}
+
+	subs := make(map[int]subscriptionLookup)
+	for _, d := range due {
+		if _, ok := subs[d.SubscriptionID]; ok {
+			continue
+		}
+		sub, err := uc.webhooks.GetSubscription(ctx, d.SubscriptionID)
+		if err != nil {
+			uc.log.WarnContext(ctx, "get webhook",
+				slog.Int("webhook_id", d.SubscriptionID),
+				slog.String("error", err.Error()),
+			)
+			err = fmt.Errorf("get webhook %d: %w", d.SubscriptionID, err)
+		}
+		subs[d.SubscriptionID] = subscriptionLookup{sub: sub, err: err}
+	}
+
+	// Once the lease ends other workers may claim the deliveries again, so
+	// sends must not outlive it.
+	sendCtx, cancel := context.WithDeadline(ctx, until)
+	defer cancel()
+
+	var (
+		succeeded atomic.Int64
+		g         errgroup.Group
+	)
+	g.SetLimit(uc.workers)
+	for _, d := range due {
+		if sendCtx.Err() != nil {
+			break
+		}
+		g.Go(func() error {
+			ok, err := uc.deliver(ctx, sendCtx, subs[d.SubscriptionID], d)
+			if ok {
+				succeeded.Add(1)
+			}
+			return err
+		})
+	}
+	err = g.Wait()
+	delivered = int(succeeded.Load())
+	if ctx.Err() != nil {
+		return delivered, ctx.Err()
+	}
+	if err != nil {
+		return delivered, err
+	}
+	if sendCtx.Err() != nil {
+		uc.log.WarnContext(ctx, "webhook delivery lease expired; the rest of the batch is retried later",
+			slog.Duration("lease", uc.lease),
+		)
+	}
+
+	if delivered > 0 {
+		uc.log.InfoContext(ctx, "webhooks delivered", slog.Int("count", delivered))
+	}
+	return delivered, nil
+}
+
+// deliver sends d within sendCtx and records the outcome, reporting whether
+// the delivery succeeded. A send cut off by sendCtx is not recorded: the
+// delivery is retried when its lease ends.
+func (uc *DeliverWebhooksUseCase) deliver(ctx, sendCtx context.Context, sub subscriptionLookup, d domain.WebhookDelivery) (bool, error) {
+	code, sendErr := 0, sub.err
+	if sendErr == nil {
+		code, sendErr = uc.sender.Send(sendCtx, sub.sub, d)
+	}
+	if sendCtx.Err() != nil {
+		return false, nil
+	}
+
+	next := uc.record(&d, code, sendErr)
+	if err := uc.webhooks.UpdateDelivery(ctx, d, next); err != nil {
+		return false, fmt.Errorf("update webhook delivery %d: %w", d.ID, err)
+	}
+	if d.Status == domain.DeliveryDead {
+		uc.log.WarnContext(ctx, "webhook delivery dead-lettered",
+			slog.Int("webhook_id", d.SubscriptionID),
+			slog.Int("delivery_id", d.ID),
+			slog.Int("attempts", d.Attempts),
+			slog.String("error", d.LastError),
+		)
+	}
+	return d.Status == domain.DeliverySucceeded, nil
+}
+
+// record applies the outcome of an attempt to d and returns when the next
+// attempt is due.
+func (uc *DeliverWebhooksUseCase) record(d *domain.WebhookDelivery, code int, sendErr error) time.Time {
+	now := time.Now()
+	d.Attempts++
+	d.LastStatusCode = nil
+	if sendErr == nil {
+		d.LastStatusCode = &code
+	}
+	switch {
+	case sendErr != nil:
+		d.LastError = sendErr.Error()
+	case code < 200 || code > 299:
+		d.LastError = fmt.Sprintf("unexpected status %d", code)
+	default:
+		d.Status = domain.DeliverySucceeded
+		d.LastError = ""
+		return now
+	}
+	if d.Attempts >= uc.policy.MaxAttempts {
+		d.Status = domain.DeliveryDead
+		return now
+	}
+	d.Status = domain.DeliveryPending
+	return now.Add(uc.policy.backoff(d.Attempts))
+}
@@ -0,0 +1,440 @@
+package usecase_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"eve/domain"
+	"eve/internal/infrastructure"
+	"eve/internal/logging"
+	"eve/internal/repository/memory"
+	"eve/internal/usecase"
+)
+
+func TestCreateWebhookUseCase(t *testing.T) {
+	users := memory.NewUserRepo()
+	admin := seedUser(t, users, "admin@example.com", domain.RoleAdmin)
+	moderator := seedUser(t, users, "mod@example.com", domain.RoleModerator)
+	types := seedTypes(t, "product")
+
+	tests := []struct {
+		name    string
+		actor   int
+		req     domain.CreateWebhookRequest
+		wantErr error
+	}{
+		{name: "all events", actor: admin, req: domain.CreateWebhookRequest{URL: "https://partner.example.com/hook"}},
+		{name: "filtered", actor: admin, req: domain.CreateWebhookRequest{
+			URL: "http://partner.example.com/hook", Events: []string{domain.EventReviewDeleted, domain.EventReviewCreated, domain.EventReviewCreated},
+			ReviewableType: "product", Secret: "0123456789abcdef",
+		}},
+		{name: "moderator is forbidden", actor: moderator, req: domain.CreateWebhookRequest{URL: "https://partner.example.com/hook"}, wantErr: usecase.ErrForbidden},
+		{name: "relative url", actor: admin, req: domain.CreateWebhookRequest{URL: "/hook"}, wantErr: usecase.ErrInvalidInput},
+		{name: "unsupported scheme", actor: admin, req: domain.CreateWebhookRequest{URL: "ftp://partner.example.com/hook"}, wantErr: usecase.ErrInvalidInput},
+		{name: "unknown event", actor: admin, req: domain.CreateWebhookRequest{URL: "https://partner.example.com/hook", Events: []string{"review.liked"}}, wantErr: usecase.ErrInvalidInput},
+		{name: "unknown type", actor: admin, req: domain.CreateWebhookRequest{URL: "https://partner.example.com/hook", ReviewableType: "vendor"}, wantErr: usecase.ErrInvalidInput},
+		{name: "short secret", actor: admin, req: domain.CreateWebhookRequest{URL: "https://partner.example.com/hook", Secret: "short"}, wantErr: usecase.ErrInvalidInput},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			webhooks := memory.NewWebhookRepo()
+			uc := usecase.NewCreateWebhookUseCase(users, types, webhooks, usecase.NopMetrics{}, logging.Nop())
+
+			got, err := uc.Execute(context.Background(), tt.req, tt.actor)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Execute() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if got.ID == 0 || got.URL != tt.req.URL || got.ReviewableType != tt.req.ReviewableType {
+				t.Errorf("Execute() = %+v, want the requested subscription", got)
+			}
+			if tt.req.Secret != "" && got.Secret != tt.req.Secret {
+				t.Errorf("secret = %q, want the given one", got.Secret)
+			}
+			if tt.req.Secret == "" && len(got.Secret) != 64 {
+				t.Errorf("generated secret = %q, want 64 hex characters", got.Secret)
+			}
+			if len(tt.req.Events) > 0 && len(got.Events) != 2 {
+				t.Errorf("events = %v, want them deduplicated", got.Events)
+			}
+		})
+	}
+}
+
+func TestWebhookManagementUseCases(t *testing.T) {
+	ctx := context.Background()
+	users := memory.NewUserRepo()
+	admin := seedUser(t, users, "admin@example.com", domain.RoleAdmin)
+	member := seedUser(t, users, "member@example.com", "")
+	webhooks := memory.NewWebhookRepo()
+	sub, err := usecase.NewCreateWebhookUseCase(users, seedTypes(t), webhooks, usecase.NopMetrics{}, logging.Nop()).
+		Execute(ctx, domain.CreateWebhookRequest{URL: "https://partner.example.com/hook"}, admin)
+	if err != nil {
+		t.Fatalf("create webhook: %v", err)
+	}
+
+	list := usecase.NewListWebhooksUseCase(users, webhooks, usecase.NopMetrics{}, logging.Nop())
+	all, err := list.Execute(ctx, admin)
+	if err != nil || len(all) != 1 || all[0].ID != sub.ID || all[0].Secret != "" {
+		t.Fatalf("List() = %+v, %v; want the subscription without its secret", all, err)
+	}
+	if _, err := list.Execute(ctx, member); !errors.Is(err, usecase.ErrForbidden) {
+		t.Errorf("List(member) error = %v, want ErrForbidden", err)
+	}
+
+	deliveries := usecase.NewListWebhookDeliveriesUseCase(users, webhooks, usecase.NopMetrics{}, logging.Nop())
+	if _, err := deliveries.Execute(ctx, sub.ID, "lost", admin); !errors.Is(err, usecase.ErrInvalidInput) {
+		t.Errorf("Deliveries(invalid status) error = %v, want ErrInvalidInput", err)
+	}
+	if _, err := deliveries.Execute(ctx, 999, "", admin); !errors.Is(err, usecase.ErrNotFound) {
+		t.Errorf("Deliveries(missing webhook) error = %v, want ErrNotFound", err)
+	}
+
+	redeliver := usecase.NewRedeliverWebhookUseCase(users, webhooks, usecase.NopMetrics{}, logging.Nop())
+	if err := redeliver.Execute(ctx, sub.ID, 999, admin); !errors.Is(err, usecase.ErrNotFound) {
+		t.Errorf("Redeliver(missing delivery) error = %v, want ErrNotFound", err)
+	}
+
+	del := usecase.NewDeleteWebhookUseCase(users, webhooks, usecase.NopMetrics{}, logging.Nop())
+	if err := del.Execute(ctx, sub.ID, member); !errors.Is(err, usecase.ErrForbidden) {
+		t.Errorf("Delete(member) error = %v, want ErrForbidden", err)
+	}
+	if err := del.Execute(ctx, sub.ID, admin); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err := del.Execute(ctx, sub.ID, admin); !errors.Is(err, usecase.ErrNotFound) {
+		t.Errorf("Delete(deleted) error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestWebhookEventPublisherFilters(t *testing.T) {
+	ctx := context.Background()
+	webhooks := memory.NewWebhookRepo()
+	all, _ := webhooks.CreateSubscription(ctx, domain.WebhookSubscription{URL: "https://a.example.com", Secret: "x"})
+	deletes, _ := webhooks.CreateSubscription(ctx, domain.WebhookSubscription{URL: "https://b.example.com", Secret: "x", Events: []string{domain.EventReviewDeleted}})
+	vendors, _ := webhooks.CreateSubscription(ctx, domain.WebhookSubscription{URL: "https://c.example.com", Secret: "x", ReviewableType: "vendor"})
+
+	p := usecase.NewWebhookEventPublisher(webhooks)
+	for _, e := range []domain.Event{
+		{ID: 1, Type: domain.EventReviewCreated, ReviewableType: "product"},
+		{ID: 2, Type: domain.EventReviewDeleted, ReviewableType: "product"},
+		{ID: 3, Type: domain.EventCommentAdded, ReviewableType: "vendor"},
+	} {
+		if err := p.Publish(ctx, e); err != nil {
+			t.Fatalf("Publish(%d) error = %v", e.ID, err)
+		}
+	}
+
+	for _, tt := range []struct {
+		sub    domain.WebhookSubscription
+		events []int
+	}{
+		{sub: all, events: []int{3, 2, 1}},
+		{sub: deletes, events: []int{2}},
+		{sub: vendors, events: []int{3}},
+	} {
+		log, err := webhooks.ListDeliveries(ctx, tt.sub.ID, "", 10)
+		if err != nil {
+			t.Fatalf("ListDeliveries() error = %v", err)
+		}
+		var got []int
+		for _, d := range log {
+			got = append(got, d.EventID)
+		}
+		if !slices.Equal(got, tt.events) {
+			t.Errorf("subscription %s got events %v, want %v", tt.sub.URL, got, tt.events)
+		}
+	}
+}
+
+func TestWebhookEventPublisherSkipsPendingReviews(t *testing.T) {
+	ctx := context.Background()
+	reviews := memory.NewReviewRepo()
+	webhooks := memory.NewWebhookRepo()
+	sub, _ := webhooks.CreateSubscription(ctx, domain.WebhookSubscription{URL: "https://a.example.com", Secret: "x"})
+
+	id, err := reviews.Create(ctx, domain.Review{ReviewableType: "product", ReviewableID: 1, UserID: 1, Rating: 4, Status: domain.ReviewStatusPending})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	relay := usecase.NewRelayEventsUseCase(reviews, usecase.NewWebhookEventPublisher(webhooks), usecase.RetryPolicy{MaxAttempts: 3}, usecase.NopMetrics{}, logging.Nop())
+	if _, err := relay.Execute(ctx); err != nil {
+		t.Fatalf("relay error = %v", err)
+	}
+	if log, _ := webhooks.ListDeliveries(ctx, sub.ID, "", 10); len(log) != 0 {
+		t.Fatalf("deliveries of a pending review = %+v, want none", log)
+	}
+
+	if err := reviews.ApproveReview(ctx, id); err != nil {
+		t.Fatalf("ApproveReview() error = %v", err)
+	}
+	if _, err := relay.Execute(ctx); err != nil {
+		t.Fatalf("relay error = %v", err)
+	}
+	log, _ := webhooks.ListDeliveries(ctx, sub.ID, "", 10)
+	if len(log) != 1 || log[0].EventType != domain.EventReviewCreated {
+		t.Errorf("deliveries after approval = %+v, want one %s", log, domain.EventReviewCreated)
+	}
+}
+
+// receiver is a local webhook endpoint that checks signatures and answers
+// with the queued status codes, then 204.
+type receiver struct {
+	secret string
+
+	mu       sync.Mutex
+	statuses []int
+	events   []domain.Event
+	bad      int // requests with an invalid signature
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	signature := "sha256=" + infrastructure.SignWebhook(rc.secret, r.Header.Get(infrastructure.WebhookTimestampHeader), body)
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if r.Header.Get(infrastructure.WebhookSignatureHeader) != signature {
+		rc.bad++
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	status := http.StatusNoContent
+	if len(rc.statuses) > 0 {
+		status, rc.statuses = rc.statuses[0], rc.statuses[1:]
+	}
+	if status < 300 {
+		var e domain.Event
+		_ = json.Unmarshal(body, &e)
+		rc.events = append(rc.events, e)
+	}
+	w.WriteHeader(status)
+}
+
+func TestDeliverWebhooksUseCase(t *testing.T) {
+	ctx := context.Background()
+	users := memory.NewUserRepo()
+	admin := seedUser(t, users, "admin@example.com", domain.RoleAdmin)
+	reviews := memory.NewReviewRepo()
+	webhooks := memory.NewWebhookRepo()
+
+	rc := &receiver{secret: "partner-secret-123", statuses: []int{http.StatusServiceUnavailable}}
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+	sub, err := usecase.NewCreateWebhookUseCase(users, seedTypes(t), webhooks, usecase.NopMetrics{}, logging.Nop()).
+		Execute(ctx, domain.CreateWebhookRequest{URL: srv.URL, Secret: rc.secret}, admin)
+	if err != nil {
+		t.Fatalf("create webhook: %v", err)
+	}
+
+	reviewID := seedReview(t, reviews, "product", 1)
+	relay := usecase.NewRelayEventsUseCase(reviews, usecase.NewWebhookEventPublisher(webhooks), usecase.RetryPolicy{MaxAttempts: 3}, usecase.NopMetrics{}, logging.Nop())
+	if n, err := relay.Execute(ctx); err != nil || n != 1 {
+		t.Fatalf("relay = %d, %v; want 1 event", n, err)
+	}
+
+	policy := usecase.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Nanosecond, MaxDelay: time.Nanosecond}
+	deliver := usecase.NewDeliverWebhooksUseCase(webhooks, infrastructure.NewHTTPWebhookSender(srv.Client()), policy, 4, time.Minute, usecase.NopMetrics{}, logging.Nop())
+
+	if n, err := deliver.Execute(ctx); err != nil || n != 0 {
+		t.Fatalf("first Execute() = %d, %v; want the 503 to be retried", n, err)
+	}
+	log, _ := webhooks.ListDeliveries(ctx, sub.ID, "", 10)
+	if len(log) != 1 || log[0].Status != domain.DeliveryPending || log[0].Attempts != 1 ||
+		log[0].LastStatusCode == nil || *log[0].LastStatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("delivery after failure = %+v, want pending with 1 attempt and status 503", log)
+	}
+
+	if n, err := deliver.Execute(ctx); err != nil || n != 1 {
+		t.Fatalf("second Execute() = %d, %v; want 1 delivered", n, err)
+	}
+	if rc.bad != 0 || len(rc.events) != 1 || rc.events[0].Type != domain.EventReviewCreated || rc.events[0].ReviewID != reviewID {
+		t.Errorf("receiver got %+v (%d bad signatures), want the created event", rc.events, rc.bad)
+	}
+	if n, err := deliver.Execute(ctx); err != nil || n != 0 {
+		t.Errorf("third Execute() = %d, %v; want nothing due", n, err)
+	}
+}
+
+func TestDeliverWebhooksUseCaseDeadLettersAndRedelivers(t *testing.T) {
+	ctx := context.Background()
+	users := memory.NewUserRepo()
+	admin := seedUser(t, users, "admin@example.com", domain.RoleAdmin)
+	webhooks := memory.NewWebhookRepo()
+
+	rc := &receiver{secret: "partner-secret-123", statuses: []int{500, 500, 500}}
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+	sub, _ := webhooks.CreateSubscription(ctx, domain.WebhookSubscription{URL: srv.URL, Secret: rc.secret})
+	if err := usecase.NewWebhookEventPublisher(webhooks).Publish(ctx, domain.Event{ID: 1, Type: domain.EventReviewDeleted}); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+
+	policy := usecase.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Nanosecond, MaxDelay: time.Nanosecond}
+	deliver := usecase.NewDeliverWebhooksUseCase(webhooks, infrastructure.NewHTTPWebhookSender(srv.Client()), policy, 4, time.Minute, usecase.NopMetrics{}, logging.Nop())
+	for range 4 { // the fourth run finds nothing due
+		if _, err := deliver.Execute(ctx); err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+	}
+
+	deliveries := usecase.NewListWebhookDeliveriesUseCase(users, webhooks, usecase.NopMetrics{}, logging.Nop())
+	dead, err := deliveries.Execute(ctx, sub.ID, domain.DeliveryDead, admin)
+	if err != nil || len(dead) != 1 || dead[0].Attempts != 3 || dead[0].LastError == "" {
+		t.Fatalf("dead deliveries = %+v, %v; want one after 3 attempts", dead, err)
+	}
+
+	redeliver := usecase.NewRedeliverWebhookUseCase(users, webhooks, usecase.NopMetrics{}, logging.Nop())
+	if err := redeliver.Execute(ctx, sub.ID+1, dead[0].ID, admin); !errors.Is(err, usecase.ErrNotFound) {
+		t.Errorf("Redeliver(other webhook) error = %v, want ErrNotFound", err)
+	}
+	if err := redeliver.Execute(ctx, sub.ID, dead[0].ID, admin); err != nil {
+		t.Fatalf("Redeliver() error = %v", err)
+	}
+	if n, err := deliver.Execute(ctx); err != nil || n != 1 {
+		t.Fatalf("Execute() after redeliver = %d, %v; want 1 delivered", n, err)
+	}
+	done, _ := deliveries.Execute(ctx, sub.ID, domain.DeliverySucceeded, admin)
+	if len(done) != 1 || done[0].Attempts != 1 || done[0].LastError != "" {
+		t.Errorf("succeeded deliveries = %+v, want the redelivered one", done)
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	ctx := context.Background()
+	webhooks := memory.NewWebhookRepo()
+	sub, _ := webhooks.CreateSubscription(ctx, domain.WebhookSubscription{URL: "https://partner.example.com", Secret: "x"})
+	_ = webhooks.EnqueueDeliveries(ctx, domain.Event{ID: 1, Type: domain.EventReviewCreated}, []int{sub.ID})
+
+	failing := senderFunc(func(context.Context, domain.WebhookSubscription, domain.WebhookDelivery) (int, error) {
+		return 0, errors.New("connection refused")
+	})
+	policy := usecase.RetryPolicy{MaxAttempts: 10, BaseDelay: time.Minute, MaxDelay: 3 * time.Minute}
+	deliver := usecase.NewDeliverWebhooksUseCase(webhooks, failing, policy, 4, time.Minute, usecase.NopMetrics{}, logging.Nop())
+
+	var delays []time.Duration
+	for range 3 {
+		// Move the delivery's next attempt into the past to make it due.
+		log, _ := webhooks.ListDeliveries(ctx, sub.ID, "", 1)
+		_ = webhooks.UpdateDelivery(ctx, log[0], time.Now().Add(-time.Second))
+		if _, err := deliver.Execute(ctx); err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+		due, _ := webhooks.ListDeliveries(ctx, sub.ID, "", 1)
+		next, _ := time.Parse(time.RFC3339, due[0].NextAttemptAt)
+		delays = append(delays, time.Until(next).Round(time.Minute))
+		if due[0].LastStatusCode != nil || due[0].LastError != "connection refused" {
+			t.Errorf("delivery = %+v, want the send error recorded without a status", due[0])
+		}
+	}
+	want := []time.Duration{time.Minute, 2 * time.Minute, 3 * time.Minute}
+	for i := range want {
+		if delays[i] != want[i] {
+			t.Errorf("delay after attempt %d = %v, want %v", i+1, delays[i], want[i])
+		}
+	}
+}
+
+// brokenLookups fails to load one subscription.
+type brokenLookups struct {
+	usecase.WebhookRepository
+	broken int
+}
+
+func (r brokenLookups) GetSubscription(ctx context.Context, id int) (domain.WebhookSubscription, error) {
+	if id == r.broken {
+		return domain.WebhookSubscription{}, errors.New("connection reset")
+	}
+	return r.WebhookRepository.GetSubscription(ctx, id)
+}
+
+func TestDeliverWebhooksUseCaseFailsDeliveriesOfUnloadableWebhook(t *testing.T) {
+	ctx := context.Background()
+	webhooks := memory.NewWebhookRepo()
+	broken, _ := webhooks.CreateSubscription(ctx, domain.WebhookSubscription{URL: "https://a.example.com", Secret: "x"})
+	healthy, _ := webhooks.CreateSubscription(ctx, domain.WebhookSubscription{URL: "https://b.example.com", Secret: "x"})
+	_ = webhooks.EnqueueDeliveries(ctx, domain.Event{ID: 1, Type: domain.EventReviewDeleted}, []int{broken.ID, healthy.ID})
+
+	ok := senderFunc(func(context.Context, domain.WebhookSubscription, domain.WebhookDelivery) (int, error) {
+		return http.StatusNoContent, nil
+	})
+	policy := usecase.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Hour, MaxDelay: time.Hour}
+	deliver := usecase.NewDeliverWebhooksUseCase(brokenLookups{webhooks, broken.ID}, ok, policy, 4, time.Minute, usecase.NopMetrics{}, logging.Nop())
+
+	if n, err := deliver.Execute(ctx); err != nil || n != 1 {
+		t.Fatalf("Execute() = %d, %v; want the healthy webhook's delivery sent", n, err)
+	}
+	log, _ := webhooks.ListDeliveries(ctx, broken.ID, "", 10)
+	if len(log) != 1 || log[0].Status != domain.DeliveryPending || log[0].Attempts != 1 || log[0].LastError == "" {
+		t.Errorf("delivery of the broken webhook = %+v, want a failed attempt to retry", log)
+	}
+}
+
+func TestDeliverWebhooksUseCaseBoundsConcurrency(t *testing.T) {
+	ctx := context.Background()
+	webhooks := memory.NewWebhookRepo()
+	sub, _ := webhooks.CreateSubscription(ctx, domain.WebhookSubscription{URL: "https://a.example.com", Secret: "x"})
+	for id := 1; id <= 10; id++ {
+		_ = webhooks.EnqueueDeliveries(ctx, domain.Event{ID: id, Type: domain.EventReviewDeleted}, []int{sub.ID})
+	}
+
+	var mu sync.Mutex
+	inFlight, peak := 0, 0
+	slow := senderFunc(func(context.Context, domain.WebhookSubscription, domain.WebhookDelivery) (int, error) {
+		mu.Lock()
+		inFlight++
+		peak = max(peak, inFlight)
+		mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		return http.StatusNoContent, nil
+	})
+	deliver := usecase.NewDeliverWebhooksUseCase(webhooks, slow, usecase.RetryPolicy{MaxAttempts: 3}, 3, time.Minute, usecase.NopMetrics{}, logging.Nop())
+
+	if n, err := deliver.Execute(ctx); err != nil || n != 10 {
+		t.Fatalf("Execute() = %d, %v; want 10 delivered", n, err)
+	}
+	if peak > 3 {
+		t.Errorf("%d deliveries sent at once, want at most 3", peak)
+	}
+}
+
+func TestDeliverWebhooksUseCaseStopsAtLeaseEnd(t *testing.T) {
+	ctx := context.Background()
+	webhooks := memory.NewWebhookRepo()
+	sub, _ := webhooks.CreateSubscription(ctx, domain.WebhookSubscription{URL: "https://a.example.com", Secret: "x"})
+	_ = webhooks.EnqueueDeliveries(ctx, domain.Event{ID: 1, Type: domain.EventReviewDeleted}, []int{sub.ID})
+
+	hanging := senderFunc(func(ctx context.Context, _ domain.WebhookSubscription, _ domain.WebhookDelivery) (int, error) {
+		<-ctx.Done()
+		return 0, ctx.Err()
+	})
+	deliver := usecase.NewDeliverWebhooksUseCase(webhooks, hanging, usecase.RetryPolicy{MaxAttempts: 3}, 4, 20*time.Millisecond, usecase.NopMetrics{}, logging.Nop())
+
+	if n, err := deliver.Execute(ctx); err != nil || n != 0 {
+		t.Fatalf("Execute() = %d, %v; want nothing delivered", n, err)
+	}
+	log, _ := webhooks.ListDeliveries(ctx, sub.ID, "", 10)
+	if len(log) != 1 || log[0].Status != domain.DeliveryPending || log[0].Attempts != 0 {
+		t.Errorf("delivery = %+v, want it left to be retried when the lease ends", log)
+	}
+}
+
+type senderFunc func(context.Context, domain.WebhookSubscription, domain.WebhookDelivery) (int, error)
+
+func (f senderFunc) Send(ctx context.Context, sub domain.WebhookSubscription, d domain.WebhookDelivery) (int, error) {
+	return f(ctx, sub, d)
+}
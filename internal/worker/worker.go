@@ -0,0 +1,27 @@
+// Package worker runs periodic background jobs next to the HTTP server.
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// Every runs job immediately and then once per interval until ctx is done.
+// A failing run is logged and does not stop later runs. Every blocks, so it
+// is usually started in its own goroutine.
+func Every(ctx context.Context, log *slog.Logger, name string, interval time.Duration, job func(context.Context) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := job(ctx); err != nil && ctx.Err() == nil {
+			log.ErrorContext(ctx, "job failed", slog.String("job", name), slog.String("error", err.Error()))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
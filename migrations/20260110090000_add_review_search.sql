@@ -0,0 +1,26 @@
+-- +goose Up
+BEGIN;
+
+-- Full-text search over review titles and bodies.
+-- search_language holds the text search configuration the row was indexed with
+-- (set by the application from EVE_SEARCH_LANGUAGE), so a deployment can pick
+-- its language without a schema change.
+ALTER TABLE reviews ADD COLUMN search_language regconfig NOT NULL DEFAULT 'english';
+
+ALTER TABLE reviews ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector(search_language, coalesce(title, '')), 'A') ||
+    setweight(to_tsvector(search_language, coalesce(body, '')), 'B')
+) STORED;
+
+CREATE INDEX idx_reviews_search_vector ON reviews USING GIN (search_vector);
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+DROP INDEX IF EXISTS idx_reviews_search_vector;
+ALTER TABLE reviews DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE reviews DROP COLUMN IF EXISTS search_language;
+
+COMMIT;
@@ -0,0 +1,21 @@
+-- +goose Up
+BEGIN;
+
+-- Threaded replies: a comment may answer another comment on the same review.
+-- depth is 0 for top-level comments and is maintained by the application.
+ALTER TABLE review_comments
+    ADD COLUMN parent_id INTEGER REFERENCES review_comments(id) ON DELETE CASCADE,
+    ADD COLUMN depth SMALLINT NOT NULL DEFAULT 0 CHECK (depth >= 0);
+
+CREATE INDEX idx_review_comments_parent_id ON review_comments (parent_id);
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+DROP INDEX IF EXISTS idx_review_comments_parent_id;
+ALTER TABLE review_comments DROP COLUMN IF EXISTS depth;
+ALTER TABLE review_comments DROP COLUMN IF EXISTS parent_id;
+
+COMMIT;
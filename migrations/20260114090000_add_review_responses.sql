@@ -0,0 +1,65 @@
+-- +goose Up
+BEGIN;
+
+-- Roles: moderators and admins are granted here directly; the API only ever
+-- creates plain users.
+ALTER TABLE users
+    ADD COLUMN role TEXT NOT NULL DEFAULT 'user' CHECK (role IN ('user', 'moderator', 'admin'));
+
+-- Ownership of reviewable entities (e.g. the vendor account owning product:42).
+-- An entity may have several owners.
+CREATE TABLE reviewable_owners (
+    reviewable_type TEXT NOT NULL,
+    reviewable_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT now(),
+    PRIMARY KEY (reviewable_type, reviewable_id, user_id)
+);
+
+CREATE INDEX idx_reviewable_owners_user_id ON reviewable_owners (user_id);
+
+-- Official owner responses: at most one per review.
+CREATE TABLE review_responses (
+    id SERIAL PRIMARY KEY,
+    review_id INTEGER NOT NULL UNIQUE REFERENCES reviews(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    body TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT now(),
+    updated_at TIMESTAMP DEFAULT now()
+);
+
+CREATE OR REPLACE FUNCTION review_responses_updated_at_trigger() RETURNS trigger AS $$
+BEGIN
+    NEW.updated_at := now();
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_review_responses_updated_at
+BEFORE UPDATE ON review_responses
+FOR EACH ROW EXECUTE FUNCTION review_responses_updated_at_trigger();
+
+-- Every saved version of a response, including the current one.
+CREATE TABLE review_response_versions (
+    id SERIAL PRIMARY KEY,
+    response_id INTEGER NOT NULL REFERENCES review_responses(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    body TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT now()
+);
+
+CREATE INDEX idx_review_response_versions_response_id ON review_response_versions (response_id);
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+DROP TABLE IF EXISTS review_response_versions;
+DROP TRIGGER IF EXISTS trg_review_responses_updated_at ON review_responses;
+DROP FUNCTION IF EXISTS review_responses_updated_at_trigger();
+DROP TABLE IF EXISTS review_responses;
+DROP TABLE IF EXISTS reviewable_owners;
+ALTER TABLE users DROP COLUMN IF EXISTS role;
+
+COMMIT;
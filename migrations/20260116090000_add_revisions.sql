@@ -0,0 +1,28 @@
+-- +goose Up
+BEGIN;
+
+-- Audit trail of edits to reviews and their comments. Rows are written by the
+-- application in the same transaction as the edit. actor_id has no foreign key
+-- so that the trail outlives deleted users.
+CREATE TABLE revisions (
+    id SERIAL PRIMARY KEY,
+    review_id INTEGER NOT NULL REFERENCES reviews(id) ON DELETE CASCADE,
+    entity_type TEXT NOT NULL CHECK (entity_type IN ('review', 'comment')),
+    entity_id INTEGER NOT NULL,
+    actor_id INTEGER NOT NULL,
+    old_values JSONB NOT NULL,
+    new_values JSONB NOT NULL,
+    created_at TIMESTAMP DEFAULT now()
+);
+
+CREATE INDEX idx_revisions_review_id ON revisions (review_id);
+CREATE INDEX idx_revisions_entity ON revisions (entity_type, entity_id);
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+DROP TABLE IF EXISTS revisions;
+
+COMMIT;
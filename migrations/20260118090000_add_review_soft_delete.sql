@@ -0,0 +1,19 @@
+-- +goose Up
+BEGIN;
+
+-- Soft deletion: deleted reviews are hidden from every read path and hard
+-- deleted by the purge job once the retention period has passed.
+ALTER TABLE reviews ADD COLUMN deleted_at TIMESTAMP;
+
+-- Partial index for the purge job; live reviews are not indexed.
+CREATE INDEX idx_reviews_deleted_at ON reviews (deleted_at) WHERE deleted_at IS NOT NULL;
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+DROP INDEX IF EXISTS idx_reviews_deleted_at;
+ALTER TABLE reviews DROP COLUMN IF EXISTS deleted_at;
+
+COMMIT;
@@ -0,0 +1,32 @@
+-- +goose Up
+BEGIN;
+
+-- Rating criteria: the dimensions reviews of a reviewable type are rated on in
+-- addition to the overall rating, e.g. cleanliness/location/value for hotels.
+CREATE TABLE rating_criteria (
+    reviewable_type TEXT NOT NULL,
+    criterion TEXT NOT NULL,
+    label TEXT NOT NULL,
+    required BOOLEAN NOT NULL DEFAULT FALSE,
+    sort_order INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (reviewable_type, criterion)
+);
+
+-- Sub-ratings of a review, one per criterion. Criteria may be redefined later,
+-- so there is deliberately no foreign key to rating_criteria.
+CREATE TABLE review_sub_ratings (
+    review_id INTEGER NOT NULL REFERENCES reviews(id) ON DELETE CASCADE,
+    criterion TEXT NOT NULL,
+    rating SMALLINT NOT NULL CHECK (rating BETWEEN 1 AND 5),
+    PRIMARY KEY (review_id, criterion)
+);
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+DROP TABLE IF EXISTS review_sub_ratings;
+DROP TABLE IF EXISTS rating_criteria;
+
+COMMIT;
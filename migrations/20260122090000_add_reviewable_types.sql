@@ -0,0 +1,56 @@
+-- +goose Up
+BEGIN;
+
+-- Registry of the reviewable types reviews may be attached to, with the rules
+-- applied to new reviews of each type.
+--   max_body_length: in characters, 0 for no limit.
+--   moderation_mode: 'post' publishes reviews immediately; 'pre' keeps them
+--                    pending until a moderator approves them.
+CREATE TABLE reviewable_types (
+    name TEXT PRIMARY KEY,
+    photos_allowed BOOLEAN NOT NULL DEFAULT TRUE,
+    max_body_length INTEGER NOT NULL DEFAULT 0 CHECK (max_body_length >= 0),
+    title_required BOOLEAN NOT NULL DEFAULT FALSE,
+    moderation_mode TEXT NOT NULL DEFAULT 'post' CHECK (moderation_mode IN ('post', 'pre')),
+    created_at TIMESTAMP DEFAULT now(),
+    updated_at TIMESTAMP DEFAULT now()
+);
+
+CREATE OR REPLACE FUNCTION reviewable_types_updated_at_trigger() RETURNS trigger AS $$
+BEGIN
+    NEW.updated_at := now();
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_reviewable_types_updated_at
+BEFORE UPDATE ON reviewable_types
+FOR EACH ROW EXECUTE FUNCTION reviewable_types_updated_at_trigger();
+
+-- Register the documented types and every type already in use, with the
+-- permissive defaults that match the behaviour before the registry existed.
+INSERT INTO reviewable_types (name)
+SELECT name FROM (VALUES ('product'), ('vendor'), ('user')) AS defaults (name)
+UNION
+SELECT DISTINCT reviewable_type FROM reviews
+UNION
+SELECT DISTINCT reviewable_type FROM rating_criteria;
+
+-- Publication status of a review; 'pending' reviews await pre-moderation.
+ALTER TABLE reviews
+    ADD COLUMN status TEXT NOT NULL DEFAULT 'published' CHECK (status IN ('pending', 'published'));
+
+CREATE INDEX idx_reviews_pending ON reviews (created_at) WHERE status = 'pending';
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+DROP INDEX IF EXISTS idx_reviews_pending;
+ALTER TABLE reviews DROP COLUMN IF EXISTS status;
+DROP TRIGGER IF EXISTS trg_reviewable_types_updated_at ON reviewable_types;
+DROP FUNCTION IF EXISTS reviewable_types_updated_at_trigger();
+DROP TABLE IF EXISTS reviewable_types;
+
+COMMIT;
@@ -0,0 +1,21 @@
+-- +goose Up
+BEGIN;
+
+-- Local catalog of reviewable entities, for deployments that mirror the
+-- entities they accept reviews for instead of asking the owning service
+-- (EVE_REVIEWABLE_RESOLVER=catalog).
+CREATE TABLE catalog_items (
+    reviewable_type TEXT NOT NULL,
+    reviewable_id INTEGER NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (reviewable_type, reviewable_id)
+);
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+DROP TABLE IF EXISTS catalog_items;
+
+COMMIT;
@@ -0,0 +1,28 @@
+-- +goose Up
+BEGIN;
+
+-- Whether the author had bought the reviewed entity when the review was
+-- written, as reported by the purchase verifier at creation time.
+ALTER TABLE reviews ADD COLUMN verified BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Purchases written by the order system, consulted by the table-backed
+-- purchase verifier (EVE_PURCHASE_VERIFIER=table). One row per order line;
+-- order_ref is the order system's own identifier.
+CREATE TABLE purchases (
+    user_id INTEGER NOT NULL,
+    reviewable_type TEXT NOT NULL,
+    reviewable_id INTEGER NOT NULL,
+    order_ref TEXT NOT NULL,
+    purchased_at TIMESTAMP DEFAULT now(),
+    PRIMARY KEY (user_id, reviewable_type, reviewable_id, order_ref)
+);
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+DROP TABLE IF EXISTS purchases;
+ALTER TABLE reviews DROP COLUMN IF EXISTS verified;
+
+COMMIT;
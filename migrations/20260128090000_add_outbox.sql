@@ -0,0 +1,27 @@
+-- +goose Up
+BEGIN;
+
+-- Transactional outbox: domain events are inserted in the same transaction as
+-- the change they describe and published by the relay worker afterwards.
+-- Events outlive purged reviews, so there is no foreign key to reviews.
+CREATE TABLE outbox_events (
+    id SERIAL PRIMARY KEY,
+    event_type TEXT NOT NULL,
+    review_id INTEGER NOT NULL,
+    payload JSONB NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    created_at TIMESTAMP DEFAULT now(),
+    published_at TIMESTAMP
+);
+
+CREATE INDEX idx_outbox_events_unpublished ON outbox_events (id) WHERE published_at IS NULL;
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+DROP TABLE IF EXISTS outbox_events;
+
+COMMIT;
@@ -0,0 +1,51 @@
+-- +goose Up
+BEGIN;
+
+-- Lets webhook subscriptions filter events by reviewable type, including
+-- events whose payload is not the review itself.
+ALTER TABLE outbox_events ADD COLUMN reviewable_type TEXT NOT NULL DEFAULT '';
+
+-- Webhook subscriptions of partner integrations.
+--   events:          event types to deliver, empty for all.
+--   reviewable_type: only deliver events of reviews of this type, '' for all.
+--   secret:          HMAC key deliveries are signed with.
+CREATE TABLE webhook_subscriptions (
+    id SERIAL PRIMARY KEY,
+    url TEXT NOT NULL,
+    events TEXT[] NOT NULL DEFAULT '{}',
+    reviewable_type TEXT NOT NULL DEFAULT '',
+    secret TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT now()
+);
+
+-- One delivery per subscription and event. Failed attempts are retried with
+-- exponential backoff until the attempt limit, after which the delivery is
+-- dead-lettered ('dead') until redelivered by hand.
+CREATE TABLE webhook_deliveries (
+    id SERIAL PRIMARY KEY,
+    subscription_id INTEGER NOT NULL REFERENCES webhook_subscriptions(id) ON DELETE CASCADE,
+    event_id INTEGER NOT NULL,
+    event_type TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'succeeded', 'dead')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT now(),
+    last_status_code INTEGER,
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT now(),
+    updated_at TIMESTAMP DEFAULT now(),
+    UNIQUE (subscription_id, event_id)
+);
+
+CREATE INDEX idx_webhook_deliveries_due ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhook_subscriptions;
+ALTER TABLE outbox_events DROP COLUMN IF EXISTS reviewable_type;
+
+COMMIT;
@@ -0,0 +1,29 @@
+-- +goose Up
+BEGIN;
+
+-- Requests made with an Idempotency-Key header and their responses, replayed
+-- when a client retries the request.
+--   key:         the client's key, scoped by the caller.
+--   fingerprint: hash of the method, path and body of the request.
+--   status:      status of the stored response, 0 while the request runs.
+--   expires_at:  after this time the key may be reused for a new request.
+CREATE TABLE idempotency_keys (
+    key TEXT PRIMARY KEY,
+    fingerprint TEXT NOT NULL,
+    status INTEGER NOT NULL DEFAULT 0,
+    content_type TEXT NOT NULL DEFAULT '',
+    body BYTEA,
+    created_at TIMESTAMP DEFAULT now(),
+    expires_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_idempotency_keys_expires_at ON idempotency_keys (expires_at);
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+DROP TABLE IF EXISTS idempotency_keys;
+
+COMMIT;
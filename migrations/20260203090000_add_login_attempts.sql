@@ -0,0 +1,21 @@
+-- +goose Up
+BEGIN;
+
+-- Failed logins per account ('account:<email>') and per client IP
+-- ('ip:<address>'), for throttling and lockouts. A key that never failed has
+-- no row; unset times are the epoch.
+CREATE TABLE login_attempts (
+    key TEXT PRIMARY KEY,
+    failures INTEGER NOT NULL DEFAULT 0,
+    last_failure_at TIMESTAMP NOT NULL DEFAULT '1970-01-01',
+    locked_until TIMESTAMP NOT NULL DEFAULT '1970-01-01'
+);
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+DROP TABLE IF EXISTS login_attempts;
+
+COMMIT;
@@ -0,0 +1,29 @@
+-- +goose Up
+BEGIN;
+
+-- API keys of backend services, which call the API as user_id.
+--   prefix:          public part of the key that identifies it.
+--   hash:            hex-encoded SHA-256 digest of the whole key.
+--   permissions:     what the key may be used for, e.g. 'reviews:read'.
+--   reviewable_type: only reviews of this type, '' for all.
+CREATE TABLE api_keys (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    prefix TEXT NOT NULL UNIQUE,
+    hash TEXT NOT NULL,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    permissions TEXT[] NOT NULL DEFAULT '{}',
+    reviewable_type TEXT NOT NULL DEFAULT '',
+    created_by INTEGER NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT now(),
+    last_used_at TIMESTAMP
+);
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+DROP TABLE IF EXISTS api_keys;
+
+COMMIT;
@@ -0,0 +1,27 @@
+-- +goose Up
+BEGIN;
+
+-- Failed events are retried with exponential backoff: next_attempt_at is when
+-- the relay may publish the event again, NULL when it is due right away.
+-- failed_at dead-letters an event that failed too often, so that it no longer
+-- blocks the events behind it.
+ALTER TABLE outbox_events ADD COLUMN next_attempt_at TIMESTAMP;
+ALTER TABLE outbox_events ADD COLUMN failed_at TIMESTAMP;
+
+DROP INDEX IF EXISTS idx_outbox_events_unpublished;
+CREATE INDEX idx_outbox_events_unpublished ON outbox_events (id) WHERE published_at IS NULL AND failed_at IS NULL;
+CREATE INDEX idx_outbox_events_pending_review ON outbox_events (review_id, id) WHERE published_at IS NULL AND failed_at IS NULL;
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+DROP INDEX IF EXISTS idx_outbox_events_pending_review;
+DROP INDEX IF EXISTS idx_outbox_events_unpublished;
+CREATE INDEX idx_outbox_events_unpublished ON outbox_events (id) WHERE published_at IS NULL;
+
+ALTER TABLE outbox_events DROP COLUMN IF EXISTS failed_at;
+ALTER TABLE outbox_events DROP COLUMN IF EXISTS next_attempt_at;
+
+COMMIT;
@@ -0,0 +1,15 @@
+-- +goose Up
+BEGIN;
+
+-- Every login attempt is reserved before its password is checked, so the
+-- column holds the time of the last attempt, not only of the last failure.
+ALTER TABLE login_attempts RENAME COLUMN last_failure_at TO last_attempt_at;
+
+COMMIT;
+
+-- +goose Down
+BEGIN;
+
+ALTER TABLE login_attempts RENAME COLUMN last_attempt_at TO last_failure_at;
+
+COMMIT;
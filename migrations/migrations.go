@@ -0,0 +1,11 @@
+// Package migrations embeds the SQL schema so that adapters which manage their
+// own schema (SQLite) can apply it without external tooling. Postgres
+// migrations in this directory are applied with goose.
+package migrations
+
+import "embed"
+
+// SQLite holds the goose-formatted SQLite migrations under sqlite/.
+//
+//go:embed sqlite/*.sql
+var SQLite embed.FS
@@ -0,0 +1,74 @@
+-- +goose Up
+-- SQLite counterpart of migrations/20260107120000_add_reviews.sql.
+-- Timestamps are stored as ISO-8601 text with millisecond precision so they sort
+-- chronologically; JSON metadata is stored as a BLOB.
+
+-- Table: reviews
+CREATE TABLE reviews (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    reviewable_type TEXT NOT NULL,             -- e.g. 'product', 'vendor', 'user', etc.
+    reviewable_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    rating INTEGER NOT NULL CHECK (rating BETWEEN 1 AND 5),
+    title TEXT,
+    body TEXT,
+    created_at TEXT DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+    updated_at TEXT DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now'))
+);
+
+CREATE INDEX idx_reviews_reviewable ON reviews (reviewable_type, reviewable_id);
+CREATE INDEX idx_reviews_user_id ON reviews (user_id);
+CREATE INDEX idx_reviews_created_at ON reviews (created_at);
+
+-- Trigger to auto-update `updated_at` on rows modification.
+-- The WHEN clause skips rows whose updated_at was set explicitly and keeps the
+-- trigger from re-firing on its own UPDATE.
+CREATE TRIGGER trg_reviews_updated_at
+AFTER UPDATE ON reviews
+FOR EACH ROW WHEN NEW.updated_at IS OLD.updated_at
+BEGIN
+    UPDATE reviews SET updated_at = strftime('%Y-%m-%dT%H:%M:%fZ', 'now') WHERE id = NEW.id;
+END;
+
+-- Table: review_photos
+CREATE TABLE review_photos (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    review_id INTEGER NOT NULL REFERENCES reviews(id) ON DELETE CASCADE,
+    file_path TEXT NOT NULL,       -- path or URL to the photo
+    metadata BLOB,                 -- optional JSON metadata (width/height/mime/etc)
+    sort_order INTEGER DEFAULT 0,  -- ordering of photos for a single review
+    created_at TEXT DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now'))
+);
+
+CREATE INDEX idx_review_photos_review_id ON review_photos (review_id);
+CREATE INDEX idx_review_photos_review_id_sort ON review_photos (review_id, sort_order);
+
+-- Table: review_comments
+CREATE TABLE review_comments (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    review_id INTEGER NOT NULL REFERENCES reviews(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    body TEXT NOT NULL,
+    created_at TEXT DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+    updated_at TEXT DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now'))
+);
+
+CREATE INDEX idx_review_comments_review_id ON review_comments (review_id);
+CREATE INDEX idx_review_comments_user_id ON review_comments (user_id);
+CREATE INDEX idx_review_comments_created_at ON review_comments (created_at);
+
+-- Trigger to auto-update `updated_at` on comment edits
+CREATE TRIGGER trg_review_comments_updated_at
+AFTER UPDATE ON review_comments
+FOR EACH ROW WHEN NEW.updated_at IS OLD.updated_at
+BEGIN
+    UPDATE review_comments SET updated_at = strftime('%Y-%m-%dT%H:%M:%fZ', 'now') WHERE id = NEW.id;
+END;
+
+-- +goose Down
+DROP TRIGGER IF EXISTS trg_review_comments_updated_at;
+DROP TRIGGER IF EXISTS trg_reviews_updated_at;
+
+DROP TABLE IF EXISTS review_comments;
+DROP TABLE IF EXISTS review_photos;
+DROP TABLE IF EXISTS reviews;
@@ -0,0 +1,43 @@
+-- +goose Up
+-- SQLite counterpart of migrations/20260110090000_add_review_search.sql, using an
+-- FTS5 index kept in sync with reviews by triggers. The porter tokenizer stems
+-- English words; EVE_SEARCH_LANGUAGE does not apply to SQLite.
+CREATE VIRTUAL TABLE reviews_fts USING fts5(
+    title,
+    body,
+    content = 'reviews',
+    content_rowid = 'id',
+    tokenize = 'porter unicode61'
+);
+
+INSERT INTO reviews_fts (rowid, title, body)
+SELECT id, coalesce(title, ''), coalesce(body, '') FROM reviews;
+
+CREATE TRIGGER trg_reviews_fts_insert
+AFTER INSERT ON reviews
+BEGIN
+    INSERT INTO reviews_fts (rowid, title, body)
+    VALUES (NEW.id, coalesce(NEW.title, ''), coalesce(NEW.body, ''));
+END;
+
+CREATE TRIGGER trg_reviews_fts_delete
+AFTER DELETE ON reviews
+BEGIN
+    INSERT INTO reviews_fts (reviews_fts, rowid, title, body)
+    VALUES ('delete', OLD.id, coalesce(OLD.title, ''), coalesce(OLD.body, ''));
+END;
+
+CREATE TRIGGER trg_reviews_fts_update
+AFTER UPDATE OF title, body ON reviews
+BEGIN
+    INSERT INTO reviews_fts (reviews_fts, rowid, title, body)
+    VALUES ('delete', OLD.id, coalesce(OLD.title, ''), coalesce(OLD.body, ''));
+    INSERT INTO reviews_fts (rowid, title, body)
+    VALUES (NEW.id, coalesce(NEW.title, ''), coalesce(NEW.body, ''));
+END;
+
+-- +goose Down
+DROP TRIGGER IF EXISTS trg_reviews_fts_update;
+DROP TRIGGER IF EXISTS trg_reviews_fts_delete;
+DROP TRIGGER IF EXISTS trg_reviews_fts_insert;
+DROP TABLE IF EXISTS reviews_fts;
@@ -0,0 +1,36 @@
+-- +goose Up
+-- SQLite counterpart of migrations/20260112100000_add_comment_replies.sql.
+ALTER TABLE review_comments ADD COLUMN parent_id INTEGER REFERENCES review_comments(id) ON DELETE CASCADE;
+ALTER TABLE review_comments ADD COLUMN depth INTEGER NOT NULL DEFAULT 0 CHECK (depth >= 0);
+
+CREATE INDEX idx_review_comments_parent_id ON review_comments (parent_id);
+
+-- +goose Down
+-- The table is rebuilt as 20260107120000_add_reviews.sql created it rather
+-- than dropping the columns: SQLite documents DROP COLUMN as failing for a
+-- column used in a foreign key, like parent_id.
+CREATE TABLE review_comments_without_replies (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    review_id INTEGER NOT NULL REFERENCES reviews(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    body TEXT NOT NULL,
+    created_at TEXT DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+    updated_at TEXT DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now'))
+);
+INSERT INTO review_comments_without_replies (id, review_id, user_id, body, created_at, updated_at)
+SELECT id, review_id, user_id, body, created_at, updated_at FROM review_comments;
+
+-- Dropping the table also drops its indexes and trigger.
+DROP TABLE review_comments;
+ALTER TABLE review_comments_without_replies RENAME TO review_comments;
+
+CREATE INDEX idx_review_comments_review_id ON review_comments (review_id);
+CREATE INDEX idx_review_comments_user_id ON review_comments (user_id);
+CREATE INDEX idx_review_comments_created_at ON review_comments (created_at);
+
+CREATE TRIGGER trg_review_comments_updated_at
+AFTER UPDATE ON review_comments
+FOR EACH ROW WHEN NEW.updated_at IS OLD.updated_at
+BEGIN
+    UPDATE review_comments SET updated_at = strftime('%Y-%m-%dT%H:%M:%fZ', 'now') WHERE id = NEW.id;
+END;
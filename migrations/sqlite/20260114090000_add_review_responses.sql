@@ -0,0 +1,46 @@
+-- +goose Up
+-- SQLite counterpart of migrations/20260114090000_add_review_responses.sql.
+ALTER TABLE users ADD COLUMN role TEXT NOT NULL DEFAULT 'user' CHECK (role IN ('user', 'moderator', 'admin'));
+
+CREATE TABLE reviewable_owners (
+    reviewable_type TEXT NOT NULL,
+    reviewable_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TEXT DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+    PRIMARY KEY (reviewable_type, reviewable_id, user_id)
+);
+
+CREATE INDEX idx_reviewable_owners_user_id ON reviewable_owners (user_id);
+
+CREATE TABLE review_responses (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    review_id INTEGER NOT NULL UNIQUE REFERENCES reviews(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    body TEXT NOT NULL,
+    created_at TEXT DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+    updated_at TEXT DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now'))
+);
+
+CREATE TRIGGER trg_review_responses_updated_at
+AFTER UPDATE ON review_responses
+FOR EACH ROW WHEN NEW.updated_at IS OLD.updated_at
+BEGIN
+    UPDATE review_responses SET updated_at = strftime('%Y-%m-%dT%H:%M:%fZ', 'now') WHERE id = NEW.id;
+END;
+
+CREATE TABLE review_response_versions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    response_id INTEGER NOT NULL REFERENCES review_responses(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    body TEXT NOT NULL,
+    created_at TEXT DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now'))
+);
+
+CREATE INDEX idx_review_response_versions_response_id ON review_response_versions (response_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS review_response_versions;
+DROP TRIGGER IF EXISTS trg_review_responses_updated_at;
+DROP TABLE IF EXISTS review_responses;
+DROP TABLE IF EXISTS reviewable_owners;
+ALTER TABLE users DROP COLUMN role;
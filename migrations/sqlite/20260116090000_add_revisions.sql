@@ -0,0 +1,18 @@
+-- +goose Up
+-- SQLite counterpart of migrations/20260116090000_add_revisions.sql.
+CREATE TABLE revisions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    review_id INTEGER NOT NULL REFERENCES reviews(id) ON DELETE CASCADE,
+    entity_type TEXT NOT NULL CHECK (entity_type IN ('review', 'comment')),
+    entity_id INTEGER NOT NULL,
+    actor_id INTEGER NOT NULL,
+    old_values TEXT NOT NULL,
+    new_values TEXT NOT NULL,
+    created_at TEXT DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now'))
+);
+
+CREATE INDEX idx_revisions_review_id ON revisions (review_id);
+CREATE INDEX idx_revisions_entity ON revisions (entity_type, entity_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS revisions;
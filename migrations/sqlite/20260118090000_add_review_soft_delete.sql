@@ -0,0 +1,9 @@
+-- +goose Up
+-- SQLite counterpart of migrations/20260118090000_add_review_soft_delete.sql.
+ALTER TABLE reviews ADD COLUMN deleted_at TEXT;
+
+CREATE INDEX idx_reviews_deleted_at ON reviews (deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_reviews_deleted_at;
+ALTER TABLE reviews DROP COLUMN deleted_at;
@@ -0,0 +1,21 @@
+-- +goose Up
+-- SQLite counterpart of migrations/20260120090000_add_rating_criteria.sql.
+CREATE TABLE rating_criteria (
+    reviewable_type TEXT NOT NULL,
+    criterion TEXT NOT NULL,
+    label TEXT NOT NULL,
+    required INTEGER NOT NULL DEFAULT 0,
+    sort_order INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (reviewable_type, criterion)
+);
+
+CREATE TABLE review_sub_ratings (
+    review_id INTEGER NOT NULL REFERENCES reviews(id) ON DELETE CASCADE,
+    criterion TEXT NOT NULL,
+    rating INTEGER NOT NULL CHECK (rating BETWEEN 1 AND 5),
+    PRIMARY KEY (review_id, criterion)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS review_sub_ratings;
+DROP TABLE IF EXISTS rating_criteria;
@@ -0,0 +1,33 @@
+-- +goose Up
+-- SQLite counterpart of migrations/20260122090000_add_reviewable_types.sql.
+CREATE TABLE reviewable_types (
+    name TEXT PRIMARY KEY,
+    photos_allowed INTEGER NOT NULL DEFAULT 1,
+    max_body_length INTEGER NOT NULL DEFAULT 0 CHECK (max_body_length >= 0),
+    title_required INTEGER NOT NULL DEFAULT 0,
+    moderation_mode TEXT NOT NULL DEFAULT 'post' CHECK (moderation_mode IN ('post', 'pre')),
+    created_at TEXT DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+    updated_at TEXT DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now'))
+);
+
+CREATE TRIGGER trg_reviewable_types_updated_at
+AFTER UPDATE ON reviewable_types
+FOR EACH ROW WHEN NEW.updated_at IS OLD.updated_at
+BEGIN
+    UPDATE reviewable_types SET updated_at = strftime('%Y-%m-%dT%H:%M:%fZ', 'now') WHERE name = NEW.name;
+END;
+
+INSERT INTO reviewable_types (name)
+SELECT 'product' UNION SELECT 'vendor' UNION SELECT 'user'
+UNION SELECT DISTINCT reviewable_type FROM reviews
+UNION SELECT DISTINCT reviewable_type FROM rating_criteria;
+
+ALTER TABLE reviews ADD COLUMN status TEXT NOT NULL DEFAULT 'published' CHECK (status IN ('pending', 'published'));
+
+CREATE INDEX idx_reviews_pending ON reviews (created_at) WHERE status = 'pending';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_reviews_pending;
+ALTER TABLE reviews DROP COLUMN status;
+DROP TRIGGER IF EXISTS trg_reviewable_types_updated_at;
+DROP TABLE IF EXISTS reviewable_types;
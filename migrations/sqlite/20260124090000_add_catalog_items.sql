@@ -0,0 +1,11 @@
+-- +goose Up
+-- SQLite counterpart of migrations/20260124090000_add_catalog_items.sql.
+CREATE TABLE catalog_items (
+    reviewable_type TEXT NOT NULL,
+    reviewable_id INTEGER NOT NULL,
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+    PRIMARY KEY (reviewable_type, reviewable_id)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS catalog_items;
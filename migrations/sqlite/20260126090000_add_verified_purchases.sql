@@ -0,0 +1,16 @@
+-- +goose Up
+-- SQLite counterpart of migrations/20260126090000_add_verified_purchases.sql.
+ALTER TABLE reviews ADD COLUMN verified INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE purchases (
+    user_id INTEGER NOT NULL,
+    reviewable_type TEXT NOT NULL,
+    reviewable_id INTEGER NOT NULL,
+    order_ref TEXT NOT NULL,
+    purchased_at TEXT DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+    PRIMARY KEY (user_id, reviewable_type, reviewable_id, order_ref)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS purchases;
+ALTER TABLE reviews DROP COLUMN verified;
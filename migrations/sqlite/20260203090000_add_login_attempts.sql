@@ -0,0 +1,11 @@
+-- +goose Up
+-- SQLite counterpart of migrations/20260203090000_add_login_attempts.sql.
+CREATE TABLE login_attempts (
+    key TEXT PRIMARY KEY,
+    failures INTEGER NOT NULL DEFAULT 0,
+    last_failure_at TEXT NOT NULL DEFAULT '1970-01-01T00:00:00.000Z',
+    locked_until TEXT NOT NULL DEFAULT '1970-01-01T00:00:00.000Z'
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS login_attempts;
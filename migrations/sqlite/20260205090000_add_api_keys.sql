@@ -0,0 +1,18 @@
+-- +goose Up
+-- SQLite counterpart of migrations/20260205090000_add_api_keys.sql.
+-- permissions holds a JSON array.
+CREATE TABLE api_keys (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    prefix TEXT NOT NULL UNIQUE,
+    hash TEXT NOT NULL,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    permissions TEXT NOT NULL DEFAULT '[]',
+    reviewable_type TEXT NOT NULL DEFAULT '',
+    created_by INTEGER NOT NULL REFERENCES users(id),
+    created_at TEXT DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+    last_used_at TEXT
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS api_keys;
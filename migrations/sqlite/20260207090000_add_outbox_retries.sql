@@ -0,0 +1,16 @@
+-- +goose Up
+-- SQLite counterpart of migrations/20260207090000_add_outbox_retries.sql.
+ALTER TABLE outbox_events ADD COLUMN next_attempt_at TEXT;
+ALTER TABLE outbox_events ADD COLUMN failed_at TEXT;
+
+DROP INDEX IF EXISTS idx_outbox_events_unpublished;
+CREATE INDEX idx_outbox_events_unpublished ON outbox_events (id) WHERE published_at IS NULL AND failed_at IS NULL;
+CREATE INDEX idx_outbox_events_pending_review ON outbox_events (review_id, id) WHERE published_at IS NULL AND failed_at IS NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_outbox_events_pending_review;
+DROP INDEX IF EXISTS idx_outbox_events_unpublished;
+CREATE INDEX idx_outbox_events_unpublished ON outbox_events (id) WHERE published_at IS NULL;
+
+ALTER TABLE outbox_events DROP COLUMN failed_at;
+ALTER TABLE outbox_events DROP COLUMN next_attempt_at;
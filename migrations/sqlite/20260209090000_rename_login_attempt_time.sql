@@ -0,0 +1,6 @@
+-- +goose Up
+-- SQLite counterpart of migrations/20260209090000_rename_login_attempt_time.sql.
+ALTER TABLE login_attempts RENAME COLUMN last_failure_at TO last_attempt_at;
+
+-- +goose Down
+ALTER TABLE login_attempts RENAME COLUMN last_attempt_at TO last_failure_at;